@@ -2,24 +2,79 @@
 package main
 
 import (
+    "context"
     "log"
     "net/http"
+    "os"
+    "time"
 
     "github.com/gin-gonic/gin"
+    "github.com/prometheus/client_golang/prometheus"
+    "github.com/prometheus/client_golang/prometheus/promhttp"
+    "gorm.io/driver/postgres"
+    "gorm.io/gorm"
     "secure-iran-intel/01_orchestrator/internal/handler"
     "secure-iran-intel/01_orchestrator/internal/service"
     "secure-iran-intel/01_orchestrator/internal/repository"
+    "secure-iran-intel/pkg/approval"
+    "secure-iran-intel/pkg/audit"
+    "secure-iran-intel/pkg/envelope"
+    "secure-iran-intel/pkg/normalizer"
+    "secure-iran-intel/pkg/purpose"
+    "secure-iran-intel/pkg/rbac"
+    "secure-iran-intel/pkg/subject"
+    "secure-iran-intel/pkg/suppression"
 )
 
 func main() {
     // Initialize dependencies
+    // Audit targets are keyed hashes; every service shares the key
+    if err := audit.SetTargetKey([]byte(os.Getenv("AUDIT_TARGET_KEY"))); err != nil {
+        log.Fatalf("Failed to set audit target key: %v", err)
+    }
+    auditStore, err := audit.Open(os.Getenv("AUDIT_STORE_URL"))
+    if err != nil {
+        log.Fatalf("Failed to open audit log: %v", err)
+    }
     jobRepo := repository.NewJobRepository()
     proxyService := service.NewProxyService()
-    jobService := service.NewJobService(jobRepo, proxyService)
+    db, err := gorm.Open(postgres.Open(os.Getenv("DATABASE_URL")), &gorm.Config{})
+    if err != nil {
+        log.Fatalf("Failed to connect to database: %v", err)
+    }
+    auditLog := audit.NewLogger(auditStore)
+    // Job records keep their numbers and justification sealed with the
+    // tenant's data key
+    kms, err := envelope.NewFileKMS(os.Getenv("MASTER_KEY_FILE"))
+    if err != nil {
+        log.Fatalf("Failed to load master keys: %v", err)
+    }
+    keyring := envelope.NewKeyring(envelope.NewGormKeyStore(db), kms)
+    purposes := purpose.NewValidator(purpose.NewGormCaseStore(db))
+    policy := rbac.NewEngine(rbac.NewGormStore(db))
+    approvals := approval.NewWorkflow(approval.NewGormStore(db), policy, auditLog)
+    // Subjects erased on request are never looked up again, nor are numbers
+    // on the tenant's or the global suppression list
+    suppressions := suppression.NewScreener(suppression.NewGormStore(db), auditLog)
+    jobService := service.NewJobService(jobRepo, keyring, proxyService, auditLog, purposes, approvals, policy, subject.NewGormStore(db), suppressions)
     httpHandler := handler.NewHTTPHandler(jobService)
 
+    // Jobs nobody decided on before their deadline are cancelled, by the
+    // system actor; nothing is allowed without an actor
+    system := audit.WithActor(context.Background(), audit.Actor{Type: audit.ActorSystem, ID: "orchestrator"})
+    go approvals.Run(system, time.Minute, approval.JobTypeIntelligence, jobService.ExpireJob)
+
+    // Normalization results are cached process-wide; expose its counters
+    if err := normalizer.RegisterCacheMetrics(prometheus.DefaultRegisterer, normalizer.DefaultNormalizationCache()); err != nil {
+        log.Fatalf("Failed to register cache metrics: %v", err)
+    }
+
     // Create router
     router := gin.Default()
+    router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
+    // Actor and justification are forwarded by the API gateway
+    router.Use(audit.ForwardedContext())
 
     // Routes
     api := router.Group("/api/v1")
@@ -27,6 +82,9 @@ func main() {
         api.POST("/jobs", httpHandler.CreateJob)
         api.GET("/jobs/:id", httpHandler.GetJobStatus)
         api.POST("/batch", httpHandler.CreateBatchJobs)
+        api.GET("/approvals", httpHandler.ListPendingApprovals)
+        api.POST("/jobs/:id/approve", httpHandler.ApproveJob)
+        api.POST("/jobs/:id/reject", httpHandler.RejectJob)
         api.GET("/proxies/health", httpHandler.GetProxyHealth)
     }
 
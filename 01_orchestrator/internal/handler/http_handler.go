@@ -2,11 +2,17 @@
 package handler
 
 import (
+    "context"
+    "errors"
     "net/http"
     "strconv"
 
     "github.com/gin-gonic/gin"
     "secure-iran-intel/01_orchestrator/internal/service"
+    "secure-iran-intel/pkg/approval"
+    "secure-iran-intel/pkg/purpose"
+    "secure-iran-intel/pkg/rbac"
+    "secure-iran-intel/pkg/refusal"
 )
 
 type HTTPHandler struct {
@@ -24,6 +30,7 @@ type CreateJobRequest struct {
     Platforms    []string          `json:"platforms" binding:"required"`
     Priority     string            `json:"priority,omitempty"`
     Options      map[string]interface{} `json:"options,omitempty"`
+    purpose.Purpose
 }
 
 func (h *HTTPHandler) CreateJob(c *gin.Context) {
@@ -33,12 +40,25 @@ func (h *HTTPHandler) CreateJob(c *gin.Context) {
         return
     }
 
-    jobID, err := h.jobService.CreateIntelligenceJob(req.PhoneNumbers, req.Platforms, req.Priority, req.Options)
+    jobID, status, err := h.jobService.CreateIntelligenceJob(c.Request.Context(), req.PhoneNumbers, req.Platforms, req.Priority, req.Options, req.Purpose)
+    if code := refusal.Code(err); code != "" {
+        c.JSON(refusal.Status(err), gin.H{"error": err.Error(), "code": code})
+        return
+    }
     if err != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
         return
     }
 
+    if status == service.StatusPendingApproval {
+        c.JSON(http.StatusAccepted, gin.H{
+            "job_id": jobID,
+            "status": status,
+            "message": "Job is waiting for approval by a second user",
+        })
+        return
+    }
+
     c.JSON(http.StatusOK, gin.H{
         "job_id": jobID,
         "status": "created",
@@ -54,26 +74,85 @@ func (h *HTTPHandler) CreateBatchJobs(c *gin.Context) {
     }
 
     var jobIDs []string
-    for _, req := range requests {
-        jobID, err := h.jobService.CreateIntelligenceJob(req.PhoneNumbers, req.Platforms, req.Priority, req.Options)
+    var pendingApproval []string
+    var failures []gin.H
+    for i, req := range requests {
+        jobID, status, err := h.jobService.CreateIntelligenceJob(c.Request.Context(), req.PhoneNumbers, req.Platforms, req.Priority, req.Options, req.Purpose)
         if err != nil {
             // Continue with other jobs even if some fail
+            failures = append(failures, gin.H{"index": i, "error": err.Error(), "code": refusal.Code(err)})
             continue
         }
         jobIDs = append(jobIDs, jobID)
+        if status == service.StatusPendingApproval {
+            pendingApproval = append(pendingApproval, jobID)
+        }
     }
 
     c.JSON(http.StatusOK, gin.H{
         "job_ids": jobIDs,
+        "pending_approval": pendingApproval,
         "total_created": len(jobIDs),
         "total_failed": len(requests) - len(jobIDs),
+        "failures": failures,
     })
 }
 
+type DecisionRequest struct {
+    Reason string `json:"reason" binding:"required"`
+}
+
+// ApproveJob queues a job held for approval. The caller must hold
+// approvals:grant and must not be the job's requester.
+func (h *HTTPHandler) ApproveJob(c *gin.Context) {
+    h.decide(c, h.jobService.ApproveJob)
+}
+
+// RejectJob stops a job held for approval from ever running
+func (h *HTTPHandler) RejectJob(c *gin.Context) {
+    h.decide(c, h.jobService.RejectJob)
+}
+
+func (h *HTTPHandler) decide(c *gin.Context, decide func(context.Context, string, string) (*approval.Request, error)) {
+    var req DecisionRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "A reason is required", "code": approval.ErrReasonRequired.Code()})
+        return
+    }
+
+    decision, err := decide(c.Request.Context(), c.Param("id"), req.Reason)
+    if code := refusal.Code(err); code != "" {
+        c.JSON(refusal.Status(err), gin.H{"error": err.Error(), "code": code})
+        return
+    }
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, decision)
+}
+
+// ListPendingApprovals returns the jobs waiting on a second user
+func (h *HTTPHandler) ListPendingApprovals(c *gin.Context) {
+    pending, err := h.jobService.PendingApprovals(c.Request.Context())
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"approvals": pending, "total": len(pending)})
+}
+
 func (h *HTTPHandler) GetJobStatus(c *gin.Context) {
     jobID := c.Param("id")
     
-    status, err := h.jobService.GetJobStatus(jobID)
+    status, err := h.jobService.GetJobStatus(c.Request.Context(), jobID)
+    var denied *rbac.DeniedError
+    if errors.As(err, &denied) {
+        c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions", "reason": denied.Decision.Reason})
+        return
+    }
     if err != nil {
         c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
         return
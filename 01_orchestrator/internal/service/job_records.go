@@ -0,0 +1,67 @@
+// 01_orchestrator/internal/service/job_records.go
+package service
+
+import (
+    "context"
+
+    "secure-iran-intel/01_orchestrator/internal/repository"
+    "secure-iran-intel/pkg/envelope"
+)
+
+// jobRecords keeps job records with their phone numbers and justification
+// sealed with the tenant's data key. Records stored before they were
+// sealed are read as they are.
+type jobRecords struct {
+    repo *repository.JobRepository
+    keys *envelope.Keyring
+}
+
+func (r *jobRecords) Create(ctx context.Context, job *repository.Job) error {
+    stored, err := r.seal(ctx, job)
+    if err != nil {
+        return err
+    }
+    return r.repo.Create(stored)
+}
+
+func (r *jobRecords) Update(ctx context.Context, job *repository.Job) error {
+    stored, err := r.seal(ctx, job)
+    if err != nil {
+        return err
+    }
+    return r.repo.Update(stored)
+}
+
+func (r *jobRecords) Get(ctx context.Context, jobID string) (*repository.Job, error) {
+    stored, err := r.repo.Get(jobID)
+    if err != nil {
+        return nil, err
+    }
+    job := *stored
+    job.PhoneNumbers = make([]string, len(stored.PhoneNumbers))
+    for i, number := range stored.PhoneNumbers {
+        if job.PhoneNumbers[i], err = r.keys.OpenString(ctx, number); err != nil {
+            return nil, err
+        }
+    }
+    if job.Justification, err = r.keys.OpenString(ctx, stored.Justification); err != nil {
+        return nil, err
+    }
+    return &job, nil
+}
+
+// seal returns a copy of job to store, leaving job as it is for the caller
+func (r *jobRecords) seal(ctx context.Context, job *repository.Job) (*repository.Job, error) {
+    var err error
+    stored := *job
+    stored.PhoneNumbers = make([]string, len(job.PhoneNumbers))
+    for i, number := range job.PhoneNumbers {
+        if stored.PhoneNumbers[i], err = r.keys.SealString(ctx, job.TenantID, number); err != nil {
+            return nil, err
+        }
+    }
+    if stored.Justification, err = r.keys.SealString(ctx, job.TenantID, job.Justification); err != nil {
+        return nil, err
+    }
+    return &stored, nil
+}
@@ -2,94 +2,354 @@
 package service
 
 import (
+    "context"
     "encoding/json"
+    "errors"
     "fmt"
+    "log"
+    "strings"
     "time"
 
     "secure-iran-intel/01_orchestrator/internal/repository"
+    "secure-iran-intel/pkg/approval"
+    "secure-iran-intel/pkg/audit"
+    "secure-iran-intel/pkg/envelope"
     "secure-iran-intel/pkg/normalizer"
+    "secure-iran-intel/pkg/purpose"
+    "secure-iran-intel/pkg/rbac"
+    "secure-iran-intel/pkg/subject"
+    "secure-iran-intel/pkg/suppression"
 )
 
 type JobService struct {
-    jobRepo      *repository.JobRepository
+    jobs         *jobRecords
     proxyService *ProxyService
     normalizer   *normalizer.PhoneNormalizer
     mqProducer   *MQProducer
+    auditLog     *audit.Logger
+    purposes     *purpose.Validator
+    approvals    *approval.Workflow
+    policy       *rbac.Engine
+    erasures     subject.Checker
+    suppressions *suppression.Screener
 }
 
-func NewJobService(jobRepo *repository.JobRepository, proxyService *ProxyService) *JobService {
+func NewJobService(jobRepo *repository.JobRepository, keys *envelope.Keyring, proxyService *ProxyService, auditLog *audit.Logger, purposes *purpose.Validator, approvals *approval.Workflow, policy *rbac.Engine, erasures subject.Checker, suppressions *suppression.Screener) *JobService {
     return &JobService{
-        jobRepo:      jobRepo,
+        jobs:         &jobRecords{repo: jobRepo, keys: keys},
         proxyService: proxyService,
-        normalizer:   normalizer.NewPhoneNormalizer("IR"),
+        auditLog:     auditLog,
+        purposes:     purposes,
+        approvals:    approvals,
+        policy:       policy,
+        erasures:     erasures,
+        suppressions: suppressions,
+        normalizer:   normalizer.NewPhoneNormalizer("IR").WithCache(normalizer.DefaultNormalizationCache()),
         mqProducer:   NewMQProducer(),
     }
 }
 
-func (js *JobService) CreateIntelligenceJob(phoneNumbers []string, platforms []string, priority string, options map[string]interface{}) (string, error) {
-    // Step 1: Normalize phone numbers
-    normalizedNumbers, errors := js.normalizer.NormalizeBatch(phoneNumbers, "")
-    if len(normalizedNumbers) == 0 {
-        return "", fmt.Errorf("no valid phone numbers after normalization")
+// Job states while waiting on a second user
+const (
+    StatusPendingApproval = "pending_approval"
+    StatusRejected        = "rejected"
+    StatusExpired         = "expired"
+)
+
+// CreateIntelligenceJob queues lookups for phoneNumbers and returns the job
+// ID and status. The caller needs jobs:create, and jobs must cite an open
+// case of the caller's tenant; otherwise a *refusal.Error is returned and
+// nothing is recorded or queued. Jobs the tenant's approval policy catches wait in
+// StatusPendingApproval until ApproveJob.
+func (js *JobService) CreateIntelligenceJob(ctx context.Context, phoneNumbers []string, platforms []string, priority string, options map[string]interface{}, jobPurpose purpose.Purpose) (string, string, error) {
+    // Step 0: Check the caller may create jobs, under an open case
+    actor := audit.ActorFromContext(ctx)
+    tenantID := actor.TenantID
+    if err := js.policy.Require(ctx, actor, rbac.PermJobsCreate, nil); err != nil {
+        return "", "", err
     }
+    if _, err := js.purposes.Validate(ctx, tenantID, jobPurpose); err != nil {
+        return "", "", err
+    }
+    ctx = audit.WithJustification(ctx, jobPurpose.Justification)
 
-    // Step 2: Create job record
+    // Step 1: Normalize phone numbers, leaving out erased subjects and
+    // suppressed numbers
     jobID := generateJobID()
+    batch := js.normalizer.NormalizeBatch(phoneNumbers, "")
+    numbers, err := js.dropErased(ctx, batch.Unique)
+    if err != nil {
+        return "", "", err
+    }
+    if numbers, err = js.dropSuppressed(ctx, tenantID, jobID, numbers); err != nil {
+        return "", "", err
+    }
+    // Fully suppressed jobs fail like invalid ones, revealing nothing more
+    if len(numbers) == 0 {
+        return "", "", fmt.Errorf("no valid phone numbers after normalization")
+    }
+    if len(numbers) < len(batch.Unique) {
+        // The job record must not keep the dropped numbers either
+        phoneNumbers = make([]string, 0, len(numbers))
+        for _, number := range numbers {
+            phoneNumbers = append(phoneNumbers, number.Phone.Original)
+        }
+    }
+
+    // Step 2: Create job record
     job := &repository.Job{
-        ID:           jobID,
-        PhoneNumbers: phoneNumbers,
-        Platforms:    platforms,
-        Priority:     priority,
-        Status:       "queued",
-        CreatedAt:    time.Now(),
-        Options:      options,
+        ID:            jobID,
+        TenantID:      tenantID,
+        CreatedBy:     actor.UserID(),
+        PhoneNumbers:  phoneNumbers,
+        Platforms:     platforms,
+        Priority:      priority,
+        Status:        "queued",
+        CreatedAt:     time.Now(),
+        Options:       options,
+        CaseReference: jobPurpose.CaseReference,
+        LegalBasis:    jobPurpose.LegalBasis,
+        Justification: jobPurpose.Justification,
+    }
+
+    if err := js.jobs.Create(ctx, job); err != nil {
+        return "", "", fmt.Errorf("failed to create job record: %w", err)
+    }
+
+    // Step 3: Record who is looking up which numbers before any lookup starts
+    if err := js.auditLog.Log(ctx, lookupEntries(jobID, numbers, platforms, jobPurpose)...); err != nil {
+        job.Status = "failed"
+        js.jobs.Update(ctx, job)
+        return "", "", err
+    }
+
+    // Step 4: Hold large or sensitive jobs for a second user
+    categories := append(append([]string{}, platforms...), jobPurpose.LegalBasis)
+    pending, err := js.approvals.Gate(ctx, approval.JobTypeIntelligence, jobID, approval.Subject{Size: len(numbers), Categories: categories})
+    if err != nil {
+        job.Status = "failed"
+        js.jobs.Update(ctx, job)
+        return "", "", err
+    }
+    if pending != nil {
+        job.Status = StatusPendingApproval
+        js.jobs.Update(ctx, job)
+        return jobID, job.Status, nil
     }
 
-    if err := js.jobRepo.Create(job); err != nil {
-        return "", fmt.Errorf("failed to create job record: %w", err)
+    // Step 5: Queue tasks
+    if err := js.queueJob(ctx, job, numbers); err != nil {
+        return "", "", err
     }
 
-    // Step 3: Create individual tasks for each phone-platform combination
-    tasks := js.createTasks(jobID, normalizedNumbers, platforms, priority)
+    return jobID, job.Status, nil
+}
+
+// queueJob sends one task per phone-platform combination and marks the job
+// as processing
+func (js *JobService) queueJob(ctx context.Context, job *repository.Job, numbers []*normalizer.UniquePhone) error {
+    jobPurpose := purpose.Purpose{CaseReference: job.CaseReference, LegalBasis: job.LegalBasis, Justification: job.Justification}
+    tasks, err := js.createTasks(ctx, job.TenantID, job.ID, numbers, job.Platforms, job.Priority, jobPurpose)
+    if err != nil {
+        return err
+    }
 
-    // Step 4: Send tasks to message queue
     if err := js.mqProducer.SendTasks(tasks); err != nil {
-        return "", fmt.Errorf("failed to queue tasks: %w", err)
+        return fmt.Errorf("failed to queue tasks: %w", err)
     }
 
-    // Step 5: Update job status
     job.Status = "processing"
-    js.jobRepo.Update(job)
+    js.jobs.Update(ctx, job)
+    return nil
+}
+
+// ApproveJob records the caller's approval and queues the job
+func (js *JobService) ApproveJob(ctx context.Context, jobID, reason string) (*approval.Request, error) {
+    decision, err := js.approvals.Approve(ctx, jobID, reason)
+    if err != nil {
+        return nil, err
+    }
+
+    job, err := js.jobs.Get(ctx, jobID)
+    if err != nil {
+        return nil, fmt.Errorf("failed to load approved job: %w", err)
+    }
+    // A subject may have been erased while the job waited
+    batch := js.normalizer.NormalizeBatch(job.PhoneNumbers, "")
+    numbers, err := js.dropErased(ctx, batch.Unique)
+    if err != nil {
+        return nil, err
+    }
+    if err := js.queueJob(ctx, job, numbers); err != nil {
+        return nil, err
+    }
+    return decision, nil
+}
+
+// dropErased leaves out the numbers of subjects whose data was erased on
+// their request (see pkg/subject)
+func (js *JobService) dropErased(ctx context.Context, numbers []*normalizer.UniquePhone) ([]*normalizer.UniquePhone, error) {
+    normalized := make([]string, 0, len(numbers))
+    for _, number := range numbers {
+        normalized = append(normalized, number.Phone.Normalized)
+    }
+    erased, err := subject.ErasedNumbers(ctx, js.erasures, normalized)
+    if err != nil {
+        return nil, err
+    }
+    if len(erased) == 0 {
+        return numbers, nil
+    }
+
+    kept := make([]*normalizer.UniquePhone, 0, len(numbers))
+    for _, number := range numbers {
+        if !erased[number.Phone.Normalized] {
+            kept = append(kept, number)
+        }
+    }
+    log.Printf("🚫 Left %d erased subjects out of a job", len(numbers)-len(kept))
+    return kept, nil
+}
+
+// dropSuppressed leaves out the numbers on the tenant's or the global
+// suppression list (see pkg/suppression), recording each against the job
+func (js *JobService) dropSuppressed(ctx context.Context, tenantID, jobID string, numbers []*normalizer.UniquePhone) ([]*normalizer.UniquePhone, error) {
+    normalized := make([]string, 0, len(numbers))
+    for _, number := range numbers {
+        normalized = append(normalized, number.Phone.Normalized)
+    }
+    blocked, err := js.suppressions.Screen(ctx, tenantID, normalized, map[string]string{"job_id": jobID})
+    if err != nil {
+        return nil, err
+    }
+    if len(blocked) == 0 {
+        return numbers, nil
+    }
+
+    kept := make([]*normalizer.UniquePhone, 0, len(numbers))
+    for _, number := range numbers {
+        if !blocked[number.Phone.Normalized] {
+            kept = append(kept, number)
+        }
+    }
+    return kept, nil
+}
+
+// RejectJob records the caller's rejection; the job never runs
+func (js *JobService) RejectJob(ctx context.Context, jobID, reason string) (*approval.Request, error) {
+    decision, err := js.approvals.Reject(ctx, jobID, reason)
+    if err != nil {
+        return nil, err
+    }
+    js.setStatus(ctx, jobID, StatusRejected)
+    return decision, nil
+}
 
-    return jobID, nil
+// ExpireJob cancels a job whose approval request passed its deadline
+func (js *JobService) ExpireJob(r approval.Request) {
+    ctx := audit.WithActor(context.Background(), audit.Actor{TenantID: r.TenantID, Type: audit.ActorSystem, ID: "approvals"})
+    js.setStatus(ctx, r.JobID, StatusExpired)
 }
 
-func (js *JobService) createTasks(jobID string, numbers map[string]*normalizer.NormalizedPhone, platforms []string, priority string) []*Task {
+// PendingApprovals lists the caller's tenant's jobs awaiting a decision
+func (js *JobService) PendingApprovals(ctx context.Context) ([]approval.Request, error) {
+    return js.approvals.Pending(ctx, audit.ActorFromContext(ctx).TenantID)
+}
+
+func (js *JobService) setStatus(ctx context.Context, jobID, status string) {
+    job, err := js.jobs.Get(ctx, jobID)
+    if err != nil {
+        log.Printf("⚠️ Failed to load job %s to mark it %s: %v", jobID, status, err)
+        return
+    }
+    job.Status = status
+    js.jobs.Update(ctx, job)
+}
+
+// createTasks screens numbers against the suppression list once more,
+// since entries may have been added while the job awaited approval
+func (js *JobService) createTasks(ctx context.Context, tenantID, jobID string, numbers []*normalizer.UniquePhone, platforms []string, priority string, jobPurpose purpose.Purpose) ([]*Task, error) {
+    numbers, err := js.dropSuppressed(ctx, tenantID, jobID, numbers)
+    if err != nil {
+        return nil, err
+    }
+
     var tasks []*Task
     
-    for original, normalized := range numbers {
+    // One task per distinct E.164 number, however many times it was submitted
+    for _, number := range numbers {
+        original, normalized := number.Phone.Original, number.Phone
         for _, platform := range platforms {
             task := &Task{
-                ID:           generateTaskID(),
-                JobID:        jobID,
-                PhoneNumber:  original,
-                Normalized:   normalized.Normalized,
-                Platform:     platform,
-                Priority:     priority,
-                Status:       "pending",
-                CreatedAt:    time.Now(),
+                ID:            generateTaskID(),
+                TenantID:      tenantID,
+                JobID:         jobID,
+                PhoneNumber:   original,
+                Normalized:    normalized.Normalized,
+                Platform:      platform,
+                Priority:      priority,
+                CaseReference: jobPurpose.CaseReference,
+                LegalBasis:    jobPurpose.LegalBasis,
+                Status:        "pending",
+                CreatedAt:     time.Now(),
                 // Each task gets a different proxy
-                ProxyConfig:  js.proxyService.GetProxyConfigForTask(platform, normalized.Normalized),
+                ProxyConfig:   js.proxyService.GetProxyConfigForTask(platform, normalized.Normalized),
             }
             tasks = append(tasks, task)
         }
     }
     
-    return tasks
+    return tasks, nil
+}
+
+func lookupEntries(jobID string, numbers []*normalizer.UniquePhone, platforms []string, jobPurpose purpose.Purpose) []audit.Entry {
+    entries := make([]audit.Entry, 0, len(numbers))
+    for _, number := range numbers {
+        entries = append(entries, audit.Entry{
+            Action:     audit.ActionJobCreate,
+            TargetType: "phone",
+            Target:     number.Phone.Normalized,
+            Metadata: map[string]string{
+                "job_id":         jobID,
+                "platforms":      strings.Join(platforms, ","),
+                "case_reference": jobPurpose.CaseReference,
+                "legal_basis":    jobPurpose.LegalBasis,
+            },
+        })
+    }
+    return entries
 }
 
-func (js *JobService) GetJobStatus(jobID string) (*repository.JobStatus, error) {
-    return js.jobRepo.GetStatus(jobID)
+// ErrJobNotFound is also returned for other tenants' jobs, so their IDs
+// cannot be probed
+var ErrJobNotFound = errors.New("job not found")
+
+// GetJobStatus returns a *rbac.DeniedError when the caller's roles do not
+// cover the job
+func (js *JobService) GetJobStatus(ctx context.Context, jobID string) (*repository.JobStatus, error) {
+    actor := audit.ActorFromContext(ctx)
+    job, err := js.jobs.Get(ctx, jobID)
+    if err != nil {
+        return nil, ErrJobNotFound
+    }
+    if actor.Type != audit.ActorSystem && job.TenantID != actor.TenantID {
+        return nil, ErrJobNotFound
+    }
+    err = js.policy.Require(ctx, actor, rbac.PermJobsRead, &rbac.Resource{
+        Type:          "job",
+        ID:            jobID,
+        TenantID:      job.TenantID,
+        OwnerID:       job.CreatedBy,
+        CaseReference: job.CaseReference,
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    if err := js.auditLog.Log(ctx, audit.Entry{Action: audit.ActionJobView, TargetType: "job", Target: jobID}); err != nil {
+        return nil, err
+    }
+    return js.jobs.repo.GetStatus(jobID)
 }
 
 func (js *JobService) GetProxyHealthStats() *ProxyHealthStats {
@@ -97,15 +357,18 @@ func (js *JobService) GetProxyHealthStats() *ProxyHealthStats {
 }
 
 type Task struct {
-    ID          string                      `json:"id"`
-    JobID       string                      `json:"job_id"`
-    PhoneNumber string                      `json:"phone_number"`
-    Normalized  string                      `json:"normalized"`
-    Platform    string                      `json:"platform"`
-    Priority    string                      `json:"priority"`
-    Status      string                      `json:"status"`
-    CreatedAt   time.Time                   `json:"created_at"`
-    ProxyConfig *ProxyConfig                `json:"proxy_config"`
+    ID            string                      `json:"id"`
+    TenantID      string                      `json:"tenant_id"` // Lets offboarding find the tenant's queued tasks
+    JobID         string                      `json:"job_id"`
+    PhoneNumber   string                      `json:"phone_number"`
+    Normalized    string                      `json:"normalized"`
+    Platform      string                      `json:"platform"`
+    Priority      string                      `json:"priority"`
+    CaseReference string                      `json:"case_reference"` // Every result traces back to its case
+    LegalBasis    string                      `json:"legal_basis"`
+    Status        string                      `json:"status"`
+    CreatedAt     time.Time                   `json:"created_at"`
+    ProxyConfig   *ProxyConfig                `json:"proxy_config"`
 }
 
 type ProxyConfig struct {
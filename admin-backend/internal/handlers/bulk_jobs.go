@@ -2,6 +2,7 @@
 package handlers
 
 import (
+    "context"
     "encoding/csv"
     "encoding/json"
     "fmt"
@@ -11,17 +12,39 @@ import (
     "strings"
 
     "github.com/gin-gonic/gin"
+    "secure-iran-intel/pkg/approval"
+    "secure-iran-intel/pkg/audit"
+    "secure-iran-intel/pkg/envelope"
+    "secure-iran-intel/pkg/normalizer"
+    "secure-iran-intel/pkg/purpose"
+    "secure-iran-intel/pkg/refusal"
+    "secure-iran-intel/pkg/subject"
+    "secure-iran-intel/pkg/suppression"
 )
 
 type BulkJobHandler struct {
     jobService     *service.JobService
     fileProcessor  *FileProcessor
+    normalizer     *normalizer.PhoneNormalizer
+    auditLog       *audit.Logger
+    purposes       *purpose.Validator
+    approvals      *approval.Workflow
+    erasures       subject.Checker
+    suppressions   *suppression.Screener
+    keys           *envelope.Keyring
 }
 
-func NewBulkJobHandler() *BulkJobHandler {
+func NewBulkJobHandler(auditLog *audit.Logger, purposes *purpose.Validator, approvals *approval.Workflow, erasures subject.Checker, suppressions *suppression.Screener, keys *envelope.Keyring) *BulkJobHandler {
     return &BulkJobHandler{
         jobService:    service.NewJobService(),
         fileProcessor: NewFileProcessor(),
+        normalizer:    normalizer.NewPhoneNormalizer("IR").WithCache(normalizer.DefaultNormalizationCache()),
+        auditLog:      auditLog,
+        purposes:      purposes,
+        approvals:     approvals,
+        erasures:      erasures,
+        suppressions:  suppressions,
+        keys:          keys,
     }
 }
 
@@ -38,6 +61,23 @@ func (h *BulkJobHandler) ProcessBulkUpload(c *gin.Context) {
     platforms := strings.Split(c.PostForm("platforms"), ",")
     priority := c.PostForm("priority")
     jobName := c.PostForm("job_name")
+    jobPurpose := purpose.Purpose{
+        CaseReference: c.PostForm("case_reference"),
+        LegalBasis:    c.PostForm("legal_basis"),
+        Justification: c.PostForm("justification"),
+    }
+
+    // Every bulk job must cite an open case of the caller's tenant
+    ctx := c.Request.Context()
+    if _, err := h.purposes.Validate(ctx, audit.ActorFromContext(ctx).TenantID, jobPurpose); err != nil {
+        if code := refusal.Code(err); code != "" {
+            c.JSON(refusal.Status(err), gin.H{"error": err.Error(), "code": code})
+            return
+        }
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check case reference"})
+        return
+    }
+    ctx = audit.WithJustification(ctx, jobPurpose.Justification)
 
     // Process file based on type
     var phoneNumbers []string
@@ -58,13 +98,53 @@ func (h *BulkJobHandler) ProcessBulkUpload(c *gin.Context) {
         return
     }
 
-    // Create bulk job
+    // Drop erased subjects and suppressed numbers without telling the
+    // uploader which they were
+    phoneNumbers, erased, err := h.dropErased(ctx, phoneNumbers)
+    if err != nil {
+        c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Erasure register unavailable"})
+        return
+    }
+    phoneNumbers, suppressed, err := h.dropSuppressed(ctx, phoneNumbers, jobName)
+    if err != nil {
+        c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Suppression list unavailable"})
+        return
+    }
+    suppressed += erased
+    if len(phoneNumbers) == 0 {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "No phone numbers to process"})
+        return
+    }
+
+    // Record every number before the job can look any of them up
+    entries := h.bulkLookupEntries(phoneNumbers, jobName, jobPurpose)
+    if err := h.auditLog.Log(ctx, entries...); err != nil {
+        c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Audit log unavailable"})
+        return
+    }
+
+    // Create bulk job, held until we know whether it needs approval. The
+    // record keeps the name and justification sealed, as they often name
+    // the subject.
+    tenantID := audit.ActorFromContext(ctx).TenantID
+    recorded := jobPurpose
+    sealedName, err := h.keys.SealString(ctx, tenantID, jobName)
+    if err == nil {
+        recorded.Justification, err = h.keys.SealString(ctx, tenantID, jobPurpose.Justification)
+    }
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create bulk job"})
+        return
+    }
     jobID, err := h.jobService.CreateBulkJob(service.BulkJobRequest{
-        Name:         jobName,
+        Name:         sealedName,
         PhoneNumbers: phoneNumbers,
         Platforms:    platforms,
         Priority:     priority,
+        TenantID:     tenantID,
         CreatedBy:    c.GetString("user_id"),
+        Held:         true,
+        Purpose:      recorded,
     })
 
     if err != nil {
@@ -72,13 +152,229 @@ func (h *BulkJobHandler) ProcessBulkUpload(c *gin.Context) {
         return
     }
 
+    // Bulk jobs over the tenant's size limit wait for a second user
+    categories := append(append([]string{}, platforms...), jobPurpose.LegalBasis)
+    pending, err := h.approvals.Gate(ctx, approval.JobTypeBulk, jobID, approval.Subject{Size: len(entries), Categories: categories})
+    if err != nil {
+        h.jobService.CancelBulkJob(jobID, "failed")
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check approval policy"})
+        return
+    }
+    if pending != nil {
+        c.JSON(http.StatusAccepted, gin.H{
+            "job_id": jobID,
+            "total_numbers": len(phoneNumbers),
+            "suppressed_numbers": suppressed,
+            "status": "pending_approval",
+            "approval": pending,
+        })
+        return
+    }
+
+    if err := h.jobService.StartBulkJob(jobID); err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start bulk job"})
+        return
+    }
+
     c.JSON(http.StatusOK, gin.H{
         "job_id": jobID,
         "total_numbers": len(phoneNumbers),
+        "suppressed_numbers": suppressed,
         "status": "processing",
     })
 }
 
+// ApproveBulkJob starts a bulk job held for approval. The caller must hold
+// approvals:grant and must not be the uploader.
+func (h *BulkJobHandler) ApproveBulkJob(c *gin.Context) {
+    reason, ok := bindReason(c)
+    if !ok {
+        return
+    }
+
+    jobID := c.Param("id")
+    ctx := auditContext(c)
+    decision, err := h.approvals.Approve(ctx, jobID, reason)
+    if err != nil {
+        approvalError(c, err)
+        return
+    }
+
+    // Numbers may have been suppressed, or their subjects erased, while
+    // the job waited
+    job, err := h.jobService.GetBulkJob(ctx, audit.ActorFromContext(ctx).TenantID, jobID)
+    if err == nil {
+        job.Name, err = h.keys.OpenString(ctx, job.Name)
+    }
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load approved bulk job"})
+        return
+    }
+    phoneNumbers, _, err := h.dropErased(ctx, job.PhoneNumbers)
+    if err != nil {
+        c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Erasure register unavailable"})
+        return
+    }
+    phoneNumbers, _, err = h.dropSuppressed(ctx, phoneNumbers, job.Name)
+    if err != nil {
+        c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Suppression list unavailable"})
+        return
+    }
+    if len(phoneNumbers) < len(job.PhoneNumbers) {
+        if len(phoneNumbers) == 0 {
+            h.jobService.CancelBulkJob(jobID, "failed")
+            c.JSON(http.StatusOK, decision)
+            return
+        }
+        // The job record must not keep the dropped numbers either
+        if err := h.jobService.SetBulkJobNumbers(jobID, phoneNumbers); err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update bulk job"})
+            return
+        }
+    }
+
+    if err := h.jobService.StartBulkJob(jobID); err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start bulk job"})
+        return
+    }
+
+    c.JSON(http.StatusOK, decision)
+}
+
+// RejectBulkJob cancels a bulk job held for approval
+func (h *BulkJobHandler) RejectBulkJob(c *gin.Context) {
+    reason, ok := bindReason(c)
+    if !ok {
+        return
+    }
+
+    jobID := c.Param("id")
+    decision, err := h.approvals.Reject(auditContext(c), jobID, reason)
+    if err != nil {
+        approvalError(c, err)
+        return
+    }
+    h.jobService.CancelBulkJob(jobID, "rejected")
+
+    c.JSON(http.StatusOK, decision)
+}
+
+// ExpireBulkJob cancels a bulk job nobody decided on in time; pass it to
+// approval.Workflow.Run with approval.JobTypeBulk
+func (h *BulkJobHandler) ExpireBulkJob(r approval.Request) {
+    h.jobService.CancelBulkJob(r.JobID, "expired")
+}
+
+// bindReason reads the reason every decision must carry
+func bindReason(c *gin.Context) (string, bool) {
+    var req struct {
+        Reason string `json:"reason" binding:"required"`
+    }
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "A reason is required", "code": approval.ErrReasonRequired.Code()})
+        return "", false
+    }
+    return req.Reason, true
+}
+
+func approvalError(c *gin.Context, err error) {
+    if code := refusal.Code(err); code != "" {
+        c.JSON(refusal.Status(err), gin.H{"error": err.Error(), "code": code})
+        return
+    }
+    c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record decision"})
+}
+
+// dropErased leaves out the uploaded numbers of subjects whose data was
+// erased on their request (see pkg/subject) and returns how many it left
+// out. Numbers that do not normalize cannot be matched and are kept.
+func (h *BulkJobHandler) dropErased(ctx context.Context, phoneNumbers []string) ([]string, int, error) {
+    batch := h.normalizer.NormalizeBatch(phoneNumbers, "")
+    normalized := make([]string, 0, len(batch.Unique))
+    for _, unique := range batch.Unique {
+        normalized = append(normalized, unique.Phone.Normalized)
+    }
+    erased, err := subject.ErasedNumbers(ctx, h.erasures, normalized)
+    if err != nil {
+        return nil, 0, err
+    }
+    if len(erased) == 0 {
+        return phoneNumbers, 0, nil
+    }
+
+    kept := make([]string, 0, len(phoneNumbers))
+    for _, result := range batch.Results {
+        if result.Phone == nil || !erased[result.Phone.Normalized] {
+            kept = append(kept, phoneNumbers[result.Index])
+        }
+    }
+    return kept, len(phoneNumbers) - len(kept), nil
+}
+
+// dropSuppressed leaves out the uploaded numbers on the tenant's or the
+// global suppression list and returns how many it left out. Numbers that
+// do not normalize cannot be matched and are kept.
+func (h *BulkJobHandler) dropSuppressed(ctx context.Context, phoneNumbers []string, jobName string) ([]string, int, error) {
+    batch := h.normalizer.NormalizeBatch(phoneNumbers, "")
+    normalized := make([]string, 0, len(batch.Unique))
+    for _, unique := range batch.Unique {
+        normalized = append(normalized, unique.Phone.Normalized)
+    }
+    metadata := map[string]string{"source": "bulk_upload", "job_name": jobName}
+    blocked, err := h.suppressions.Screen(ctx, audit.ActorFromContext(ctx).TenantID, normalized, metadata)
+    if err != nil {
+        return nil, 0, err
+    }
+    if len(blocked) == 0 {
+        return phoneNumbers, 0, nil
+    }
+
+    kept := make([]string, 0, len(phoneNumbers))
+    for _, result := range batch.Results {
+        if result.Phone == nil || !blocked[result.Phone.Normalized] {
+            kept = append(kept, phoneNumbers[result.Index])
+        }
+    }
+    return kept, len(phoneNumbers) - len(kept), nil
+}
+
+// bulkLookupEntries audits each distinct number once, in E.164 where it
+// normalizes and as uploaded where it does not
+func (h *BulkJobHandler) bulkLookupEntries(phoneNumbers []string, jobName string, jobPurpose purpose.Purpose) []audit.Entry {
+    batch := h.normalizer.NormalizeBatch(phoneNumbers, "")
+
+    entries := make([]audit.Entry, 0, len(batch.Unique)+len(batch.Failed))
+    add := func(target string) {
+        entries = append(entries, audit.Entry{
+            Action:     audit.ActionJobCreate,
+            TargetType: "phone",
+            Target:     target,
+            Metadata: map[string]string{
+                "source":         "bulk_upload",
+                "job_name":       jobName,
+                "case_reference": jobPurpose.CaseReference,
+                "legal_basis":    jobPurpose.LegalBasis,
+            },
+        })
+    }
+    for _, unique := range batch.Unique {
+        add(unique.Phone.Normalized)
+    }
+    for _, failed := range batch.Failed {
+        add(failed.Input)
+    }
+    return entries
+}
+
+// auditContext adds the caller's justification header to the request context
+func auditContext(c *gin.Context) context.Context {
+    ctx := c.Request.Context()
+    if justification := c.GetHeader(audit.JustificationHeader); justification != "" {
+        ctx = audit.WithJustification(ctx, justification)
+    }
+    return ctx
+}
+
 func (h *BulkJobHandler) processCSV(file io.Reader) ([]string, error) {
     reader := csv.NewReader(file)
     records, err := reader.ReadAll()
@@ -122,8 +418,15 @@ func (h *BulkJobHandler) processTXT(file io.Reader) ([]string, error) {
 // GetBulkJobStatus returns status of a bulk job
 func (h *BulkJobHandler) GetBulkJobStatus(c *gin.Context) {
     jobID := c.Param("id")
+    ctx := auditContext(c)
     
-    status, err := h.jobService.GetBulkJobStatus(jobID)
+    if err := h.auditLog.Log(ctx, audit.Entry{Action: audit.ActionJobView, TargetType: "job", Target: jobID}); err != nil {
+        c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Audit log unavailable"})
+        return
+    }
+    
+    // Other tenants' jobs are reported as missing, not forbidden
+    status, err := h.jobService.GetBulkJobStatus(ctx, audit.ActorFromContext(ctx).TenantID, jobID)
     if err != nil {
         c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
         return
@@ -138,7 +441,21 @@ func (h *BulkJobHandler) ListBulkJobs(c *gin.Context) {
     limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
     status := c.Query("status")
 
-    jobs, total, err := h.jobService.ListBulkJobs(page, limit, status)
+    ctx := auditContext(c)
+    if err := h.auditLog.Log(ctx, audit.Entry{
+        Action:     audit.ActionJobList,
+        TargetType: "bulk_jobs",
+        Target:     "bulk_jobs",
+        Metadata:   map[string]string{"page": strconv.Itoa(page), "status": status},
+    }); err != nil {
+        c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Audit log unavailable"})
+        return
+    }
+
+    jobs, total, err := h.jobService.ListBulkJobs(ctx, audit.ActorFromContext(ctx).TenantID, page, limit, status)
+    for i := 0; err == nil && i < len(jobs); i++ {
+        jobs[i].Name, err = h.keys.OpenString(ctx, jobs[i].Name)
+    }
     if err != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch jobs"})
         return
@@ -2,39 +2,112 @@
 package main
 
 import (
+    "context"
     "log"
+    "os"
+    "time"
     
     "github.com/gin-gonic/gin"
+    "github.com/go-redis/redis/v8"
+    "github.com/prometheus/client_golang/prometheus"
+    "github.com/prometheus/client_golang/prometheus/promhttp"
+    "gorm.io/driver/postgres"
+    "gorm.io/gorm"
     "secure-iran-intel/api-gateway/internal/handlers"
     "secure-iran-intel/api-gateway/internal/middleware"
+    "secure-iran-intel/pkg/apikey"
+    "secure-iran-intel/pkg/audit"
+    "secure-iran-intel/pkg/normalizer"
+    "secure-iran-intel/pkg/purpose"
+    "secure-iran-intel/pkg/rbac"
+    "secure-iran-intel/pkg/subject"
+    "secure-iran-intel/pkg/suppression"
+    "secure-iran-intel/pkg/token"
 )
 
 func main() {
+    // Load numbering plans; reloaded on SIGHUP or when the file changes
+    if planFile := os.Getenv("NUMBERING_PLAN_FILE"); planFile != "" {
+        plans := normalizer.DefaultPlanStore()
+        if err := plans.LoadFile(planFile); err != nil {
+            log.Fatalf("Failed to load numbering plans: %v", err)
+        }
+        go plans.Watch(context.Background(), planFile, 30*time.Second)
+    }
+    
+    // Normalization results are cached process-wide; expose its counters
+    if err := normalizer.RegisterCacheMetrics(prometheus.DefaultRegisterer, normalizer.DefaultNormalizationCache()); err != nil {
+        log.Fatalf("Failed to register cache metrics: %v", err)
+    }
+    
     // Initialize normalizer
     normalizationMiddleware := middleware.NewNormalizationMiddleware()
     
     // Initialize handlers
     normalizationHandler := handlers.NewNormalizationHandler()
-    jobHandler := handlers.NewJobCreationHandler(NewJobQueue())
+    // Audit targets are keyed hashes; every service shares the key
+    if err := audit.SetTargetKey([]byte(os.Getenv("AUDIT_TARGET_KEY"))); err != nil {
+        log.Fatalf("Failed to set audit target key: %v", err)
+    }
+    auditStore, err := audit.Open(os.Getenv("AUDIT_STORE_URL"))
+    if err != nil {
+        log.Fatalf("Failed to open audit log: %v", err)
+    }
+    db, err := gorm.Open(postgres.Open(os.Getenv("DATABASE_URL")), &gorm.Config{})
+    if err != nil {
+        log.Fatalf("Failed to connect to database: %v", err)
+    }
+    auditLog := audit.NewLogger(auditStore)
+    purposes := purpose.NewValidator(purpose.NewGormCaseStore(db))
+    policy := rbac.NewEngine(rbac.NewGormStore(db))
+    // Subjects erased on request are never looked up again, nor are numbers
+    // on the tenant's or the global suppression list
+    suppressions := suppression.NewScreener(suppression.NewGormStore(db), auditLog)
+    jobHandler := handlers.NewJobCreationHandler(NewJobQueue(), auditLog, purposes, policy, subject.NewGormStore(db), suppressions)
+    
+    // Callers sign in with the auth-service, whose tokens are verified
+    // against the keys it publishes, or use one of their API keys
+    redisClient := redis.NewClient(&redis.Options{
+        Addr:     os.Getenv("REDIS_URL"),
+        Password: os.Getenv("REDIS_PASSWORD"),
+    })
+    keys := apikey.NewService(apikey.NewGormStore(db), policy, auditLog)
+    go keys.Run(audit.WithActor(context.Background(), audit.Actor{Type: audit.ActorSystem, ID: "api-gateway"}), 30*time.Second)
+    callerAuth := middleware.NewCallerAuth(
+        token.NewVerifier(
+            token.NewJWKSCache(os.Getenv("JWKS_URL")),
+            token.NewRevocations(redisClient, 0),
+            os.Getenv("JWT_ISSUER"),
+            os.Getenv("JWT_AUDIENCE"),
+        ),
+        keys,
+        policy,
+    )
     
     // Create router
     router := gin.Default()
     
-    // Global middleware
-    router.Use(normalizationMiddleware.AutoNormalizePhoneNumbers())
+    router.GET("/metrics", gin.WrapH(promhttp.Handler()))
     
     // Normalization endpoints
     normalization := router.Group("/api/v1/normalize")
     {
         normalization.POST("/single", normalizationHandler.NormalizeSingle)
         normalization.POST("/batch", normalizationHandler.NormalizeBatch)
+        normalization.POST("/stream", normalizationHandler.NormalizeStream)
+        normalization.POST("/extract", normalizationHandler.ExtractPhones)
         normalization.POST("/validate", normalizationHandler.ValidatePhone)
     }
     
-    // Job creation endpoints (with auto-normalization)
+    // Job creation endpoints, normalizing the fields tagged normalize:"phone".
+    // Only callers whose roles grant jobs:create may submit jobs.
     jobs := router.Group("/api/v1/jobs")
     {
-        jobs.POST("/intelligence", jobHandler.CreateIntelligenceJob)
+        jobs.Use(callerAuth.Require(rbac.PermJobsCreate))
+        
+        jobs.POST("/intelligence",
+            normalizationMiddleware.NormalizePhoneFields(middleware.PhoneFieldsOf(handlers.IntelligenceJobRequest{})...),
+            jobHandler.CreateIntelligenceJob)
     }
     
     // Start server
@@ -46,16 +119,34 @@ func main() {
 package main
 
 import (
+    "context"
     "log"
     "os"
+    "time"
 
     "github.com/gin-gonic/gin"
     "github.com/go-redis/redis/v8"
+    "github.com/streadway/amqp"
     "gorm.io/gorm"
 
+    admin_handlers "secure-iran-intel/admin-backend/internal/handlers"
     "secure-iran-intel/api-gateway/internal/middleware"
     "secure-iran-intel/api-gateway/internal/services"
+    "secure-iran-intel/auth-service/internal/handlers"
     "secure-iran-intel/auth-service/internal/services"
+    "secure-iran-intel/pkg/apikey"
+    "secure-iran-intel/pkg/approval"
+    "secure-iran-intel/pkg/audit"
+    "secure-iran-intel/pkg/envelope"
+    "secure-iran-intel/pkg/lifecycle"
+    "secure-iran-intel/pkg/mfa"
+    "secure-iran-intel/pkg/purpose"
+    "secure-iran-intel/pkg/rbac"
+    "secure-iran-intel/pkg/retention"
+    "secure-iran-intel/pkg/sso"
+    "secure-iran-intel/pkg/subject"
+    "secure-iran-intel/pkg/suppression"
+    "secure-iran-intel/pkg/token"
 )
 
 func main() {
@@ -67,24 +158,199 @@ func main() {
     tenantService := auth_services.NewTenantService(db)
     rateLimitService := services.NewRateLimitService(redisClient, tenantService)
     quotaService := services.NewQuotaService(db, tenantService)
+    // Audit targets are keyed hashes; every service shares the key
+    if err := audit.SetTargetKey([]byte(os.Getenv("AUDIT_TARGET_KEY"))); err != nil {
+        log.Fatalf("Failed to set audit target key: %v", err)
+    }
+    auditLog := audit.NewLogger(audit.NewGormStore(db))
+    policy := rbac.NewEngine(rbac.NewGormStore(db))
+    // Background work acts as the system actor; nothing is allowed
+    // without an actor
+    background := audit.WithActor(context.Background(), audit.Actor{Type: audit.ActorSystem, ID: "api-gateway"})
+    authzHandler := auth_handlers.NewAuthzHandler(policy)
+    keys := apikey.NewService(apikey.NewGormStore(db), policy, auditLog)
+    apiKeyHandler := auth_handlers.NewAPIKeyHandler(keys)
+    go keys.Run(background, 30*time.Second)
+
+    // Sensitive columns are sealed with per-tenant data keys, as are
+    // the token signing keys
+    kms, err := envelope.NewFileKMS(os.Getenv("MASTER_KEY_FILE"))
+    if err != nil {
+        log.Fatalf("Failed to load master keys: %v", err)
+    }
+    keyring := envelope.NewKeyring(envelope.NewGormKeyStore(db), kms)
+    // Once seal-backfill has run, a value in plaintext was written around
+    // the keyring and is refused
+    backfilled, err := envelope.Backfilled(context.Background(), db)
+    if err != nil {
+        log.Fatalf("Failed to load field encryption state: %v", err)
+    }
+    if backfilled {
+        keyring.RejectPlaintext()
+    }
+    envelope.Register(keyring)
+
+    // Access tokens: signed with a rotating key ring, revocable in Redis
+    tokenConfig := token.Config{
+        Issuer:    os.Getenv("JWT_ISSUER"),
+        Audience:  os.Getenv("JWT_AUDIENCE"),
+        Algorithm: os.Getenv("JWT_ALGORITHM"), // RS256 or EdDSA (default)
+    }
+    issuer, err := token.NewIssuer(context.Background(), tokenConfig, token.NewGormKeyStore(db))
+    if err != nil {
+        log.Fatalf("Failed to load signing keys: %v", err)
+    }
+    go issuer.Run(background, time.Hour)
+    revocations := token.NewRevocations(redisClient, tokenConfig.TokenTTL)
+
+    // Gateways that do not run the issuer verify against its JWKS
+    var signingKeys token.KeySource = issuer
+    if jwksURL := os.Getenv("JWKS_URL"); jwksURL != "" {
+        signingKeys = token.NewJWKSCache(jwksURL)
+    }
+    tokenHandler := auth_handlers.NewTokenHandler(issuer, revocations, tenantService)
+
+    // Single sign-on through each tenant's IdP, and SCIM provisioning
+    ssoService := sso.NewService(
+        sso.NewGormStore(db),
+        sso.NewRedisStateStore(redisClient),
+        issuer,
+        revocations,
+        auditLog,
+        os.Getenv("PUBLIC_URL"),
+    )
+    ssoHandler := auth_handlers.NewSSOHandler(ssoService)
+    scimHandler := auth_handlers.NewSCIMHandler(ssoService)
+
+    // Second factors, and the step-up sensitive actions demand
+    mfaService, err := mfa.NewService(
+        mfa.NewGormStore(db),
+        mfa.NewChallenges(redisClient),
+        mfa.WebAuthnConfig{
+            RPID:          os.Getenv("WEBAUTHN_RP_ID"),
+            RPDisplayName: "Secure Iran Intel",
+            RPOrigins:     []string{os.Getenv("PUBLIC_URL")},
+        },
+        issuer,
+        auditLog,
+    )
+    if err != nil {
+        log.Fatalf("Failed to configure MFA: %v", err)
+    }
+    mfaHandler := auth_handlers.NewMFAHandler(mfaService, tenantService)
+
+    // Suspension and offboarding; offboarding purges every store
+    mqConn, err := amqp.Dial(os.Getenv("RABBITMQ_URL"))
+    if err != nil {
+        log.Fatalf("Failed to connect to RabbitMQ: %v", err)
+    }
+    lifecycleService := lifecycle.NewService(
+        lifecycle.NewGormStore(db),
+        audit.NewGormStore(db),
+        auditLog,
+        policy,
+        issuer,
+        os.Getenv("OFFBOARDING_EXPORT_DIR"),
+        lifecycle.NewPostgresPurger(db),
+        lifecycle.NewRedisPurger(redisClient),
+        lifecycle.NewQueuePurger(mqConn, "intelligence_tasks"),
+        lifecycle.NewKeyPurger(keyring),
+    )
+    lifecycleHandler := auth_handlers.NewTenantLifecycleHandler(lifecycleService)
+
+    // Each tenant's data_retention_days, paused by legal holds on a case
+    retentionService := retention.NewService(
+        retention.NewGormStore(db),
+        purpose.NewGormCaseStore(db),
+        auditLog,
+        policy,
+        retention.NewPostgresSweeper(db),
+        retention.NewFileSweeper(os.Getenv("REPORT_EXPORT_DIR")),
+        retention.NewRedisSweeper(redisClient),
+    )
+    go retentionService.Run(background, time.Hour)
+    legalHoldHandler := auth_handlers.NewLegalHoldHandler(retentionService)
+
+    // The cases jobs cite; opened and closed by the tenant
+    caseHandler := auth_handlers.NewCaseHandler(purpose.NewCaseService(purpose.NewGormCaseStore(db), auditLog, policy))
+
+    // Data-subject access, rectification and erasure across every tenant
+    subjectService := subject.NewService(
+        subject.NewGormStore(db),
+        auditLog,
+        policy,
+        os.Getenv("SUBJECT_EXPORT_DIR"),
+        subject.NewPostgresSource(db, keyring),
+        subject.NewQueueSource(mqConn, "intelligence_tasks"),
+        subject.NewRedisSource(redisClient),
+        subject.NewAuditSource(audit.NewGormStore(db)),
+    )
+    go subjectService.Run(background, 24*time.Hour)
+    subjectHandler := auth_handlers.NewSubjectRequestHandler(subjectService)
+
+    // Numbers never to process; the platform tenant's list is the global one
+    suppressionHandler := auth_handlers.NewSuppressionHandler(suppression.NewService(suppression.NewGormStore(db), auditLog, policy))
+
+    // Bulk jobs over the tenant's approval policy wait for a second user;
+    // those nobody decided on before their deadline are cancelled
+    approvals := approval.NewWorkflow(approval.NewGormStore(db), policy, auditLog)
+    approvalPolicyHandler := auth_handlers.NewApprovalPolicyHandler(approvals)
+    bulkJobHandler := admin_handlers.NewBulkJobHandler(
+        auditLog,
+        purpose.NewValidator(purpose.NewGormCaseStore(db)),
+        approvals,
+        subject.NewGormStore(db),
+        suppression.NewScreener(suppression.NewGormStore(db), auditLog),
+        keyring,
+    )
+    go approvals.Run(background, time.Minute, approval.JobTypeBulk, bulkJobHandler.ExpireBulkJob)
     
     // Initialize middleware
     authMiddleware := middleware.NewAuthMiddleware(
-        os.Getenv("JWT_SECRET"),
+        token.NewVerifier(signingKeys, revocations, tokenConfig.Issuer, tokenConfig.Audience),
         tenantService,
-        auth_services.NewUserService(db),
+        keys,
+        policy,
     )
     rateLimitMiddleware := middleware.NewRateLimitMiddleware(rateLimitService, tenantService)
 
     // Create router
     router := gin.Default()
 
+    // Verification keys for other gateways
+    router.GET("/.well-known/jwks.json", tokenHandler.JWKS)
+
     // Public routes (authentication)
     public := router.Group("/api/v1/auth")
     {
         public.POST("/login", authHandler.Login)
         public.POST("/register", authHandler.Register)
         public.POST("/refresh", authHandler.RefreshToken)
+
+        public.GET("/sso/providers/:provider/login", ssoHandler.Login)
+        public.GET("/sso/providers/:provider/saml/metadata", ssoHandler.SAMLMetadata)
+        public.GET("/sso/oidc/callback", ssoHandler.OIDCCallback)
+        public.POST("/sso/saml/acs", ssoHandler.SAMLACS)
+    }
+
+    // Signed-in account routes. These work before a second factor is
+    // verified, so users of tenants requiring MFA can enroll and sign out.
+    account := router.Group("/api/v1/auth")
+    {
+        account.Use(authMiddleware.JWTAuthMiddleware())
+
+        account.POST("/logout", tokenHandler.Logout)
+
+        account.GET("/mfa/factors", mfaHandler.ListFactors)
+        account.DELETE("/mfa/factors/:id", mfaHandler.RemoveFactor)
+        account.POST("/mfa/totp", mfaHandler.EnrollTOTP)
+        account.POST("/mfa/totp/:id/confirm", mfaHandler.ConfirmTOTP)
+        account.POST("/mfa/webauthn/register/begin", mfaHandler.BeginWebAuthnRegistration)
+        account.POST("/mfa/webauthn/register/finish", mfaHandler.FinishWebAuthnRegistration)
+        account.POST("/mfa/webauthn/challenge", mfaHandler.BeginWebAuthn)
+        account.POST("/mfa/webauthn/verify", mfaHandler.VerifyWebAuthn)
+        account.POST("/mfa/verify", mfaHandler.Verify)
+        account.POST("/mfa/recovery-codes", mfaHandler.RegenerateRecoveryCodes)
     }
 
     // Tenant-aware API routes
@@ -92,6 +358,7 @@ func main() {
     {
         // Apply authentication to all API routes
         api.Use(authMiddleware.JWTAuthMiddleware())
+        api.Use(authMiddleware.MFAPolicyMiddleware())
         
         // Apply rate limiting based on tenant plan
         api.Use(rateLimitMiddleware.RateLimitByTenant())
@@ -100,21 +367,88 @@ func main() {
         // Phone intelligence endpoints
         intelligence := api.Group("/intelligence")
         {
-            intelligence.POST("/phone-lookup", phoneHandler.LookupPhone)
+            intelligence.POST("/phone-lookup", authMiddleware.PermissionMiddleware(rbac.PermPhoneLookup), phoneHandler.LookupPhone)
             intelligence.POST("/email-discovery", emailHandler.DiscoverEmails)
-            intelligence.POST("/bulk-operations", bulkHandler.ProcessBulk)
+            intelligence.POST("/bulk-operations", authMiddleware.PermissionMiddleware(rbac.PermBulkJobs), bulkHandler.ProcessBulk)
+            // The workflow checks approvals:grant and refuses self-approval
+            intelligence.POST("/bulk-jobs/:id/approve", bulkJobHandler.ApproveBulkJob)
+            intelligence.POST("/bulk-jobs/:id/reject", bulkJobHandler.RejectBulkJob)
             intelligence.GET("/reports/:id", reportHandler.GetReport)
+            intelligence.GET("/reports/:id/export", authMiddleware.PermissionMiddleware(rbac.PermExportsRead), reportHandler.ExportReport)
+        }
+
+        // Why a request would be allowed or denied
+        api.GET("/authz/explain", authzHandler.Explain)
+
+        // API key lifecycle; users manage their own keys
+        apiKeys := api.Group("/api-keys")
+        apiKeys.Use(authMiddleware.PermissionMiddleware(rbac.PermAPIKeys))
+        {
+            apiKeys.POST("", apiKeyHandler.CreateKey)
+            apiKeys.GET("", apiKeyHandler.ListKeys)
+            apiKeys.POST("/:id/rotate", apiKeyHandler.RotateKey)
+            apiKeys.DELETE("/:id", apiKeyHandler.RevokeKey)
+        }
+
+        // Legal holds pause data retention for a case
+        legalHolds := api.Group("/legal-holds")
+        legalHolds.Use(authMiddleware.PermissionMiddleware(rbac.PermLegalHolds))
+        {
+            legalHolds.GET("", legalHoldHandler.ListHolds)
+            legalHolds.POST("", legalHoldHandler.PlaceHold)
+            legalHolds.POST("/:id/release", legalHoldHandler.ReleaseHold)
+        }
+
+        // Cases jobs are run under
+        cases := api.Group("/cases")
+        cases.Use(authMiddleware.PermissionMiddleware(rbac.PermCases))
+        {
+            cases.GET("", caseHandler.ListCases)
+            cases.POST("", caseHandler.OpenCase)
+            cases.POST("/:reference/close", caseHandler.CloseCase)
+        }
+
+        // Numbers the tenant, or the platform for everyone, will not process
+        suppressions := api.Group("/suppressions")
+        suppressions.Use(authMiddleware.PermissionMiddleware(rbac.PermSuppressions))
+        {
+            suppressions.GET("", suppressionHandler.ListEntries)
+            suppressions.POST("", suppressionHandler.AddEntry)
+            suppressions.POST("/:id/remove", suppressionHandler.RemoveEntry)
         }
 
         // Admin endpoints (require admin permissions)
         admin := api.Group("/admin")
-        admin.Use(authMiddleware.PermissionMiddleware("admin"))
+        admin.Use(authMiddleware.PermissionMiddleware(rbac.PermAdmin))
+        admin.Use(auditLog.Middleware(audit.ActionAdmin))
         {
             admin.GET("/users", adminHandler.GetUsers)
-            admin.POST("/users", adminHandler.CreateUser)
+            admin.POST("/users", authMiddleware.PermissionMiddleware(rbac.PermRolesWrite), adminHandler.CreateUser)
+            admin.POST("/users/:id/disable", authMiddleware.PermissionMiddleware(rbac.PermRolesWrite), tokenHandler.DisableUser)
             admin.GET("/tenants", adminHandler.GetTenants)
             admin.POST("/tenants", adminHandler.CreateTenant)
             admin.GET("/usage", adminHandler.GetUsage)
+            admin.GET("/identity-providers", ssoHandler.ListProviders)
+            // Identity providers map groups to roles
+            admin.POST("/identity-providers", authMiddleware.PermissionMiddleware(rbac.PermRolesWrite), ssoHandler.CreateProvider)
+            admin.PUT("/identity-providers/:id", authMiddleware.PermissionMiddleware(rbac.PermRolesWrite), ssoHandler.UpdateProvider)
+            admin.DELETE("/identity-providers/:id", authMiddleware.PermissionMiddleware(rbac.PermRolesWrite), ssoHandler.DeleteProvider)
+            admin.PUT("/mfa-policy", authMiddleware.PermissionMiddleware(rbac.PermRolesWrite), mfaHandler.SetPolicy)
+            admin.GET("/approval-policy", approvalPolicyHandler.GetPolicy)
+            admin.PUT("/approval-policy", authMiddleware.PermissionMiddleware(rbac.PermRolesWrite), approvalPolicyHandler.SetPolicy)
+            // Platform operators only; the service refuses other tenants
+            admin.GET("/tenants/:id/lifecycle", authMiddleware.PermissionMiddleware(rbac.PermTenantsManage), lifecycleHandler.GetTenant)
+            admin.POST("/tenants/:id/suspend", authMiddleware.PermissionMiddleware(rbac.PermTenantsManage), lifecycleHandler.Suspend)
+            admin.POST("/tenants/:id/resume", authMiddleware.PermissionMiddleware(rbac.PermTenantsManage), lifecycleHandler.Resume)
+            admin.POST("/tenants/:id/offboard", authMiddleware.PermissionMiddleware(rbac.PermTenantsManage), lifecycleHandler.Offboard)
+            admin.GET("/tenants/:id/deletion-certificate", authMiddleware.PermissionMiddleware(rbac.PermTenantsManage), lifecycleHandler.Certificate)
+            admin.GET("/subject-requests", authMiddleware.PermissionMiddleware(rbac.PermSubjectRequests), subjectHandler.ListRequests)
+            admin.POST("/subject-requests", authMiddleware.PermissionMiddleware(rbac.PermSubjectRequests), subjectHandler.OpenRequest)
+            admin.GET("/subject-requests/:id", authMiddleware.PermissionMiddleware(rbac.PermSubjectRequests), subjectHandler.GetRequest)
+            admin.GET("/subject-requests/:id/records", authMiddleware.PermissionMiddleware(rbac.PermSubjectRequests), subjectHandler.Search)
+            admin.POST("/subject-requests/:id/fulfil", authMiddleware.PermissionMiddleware(rbac.PermSubjectRequests), subjectHandler.Fulfil)
+            admin.POST("/subject-requests/:id/reject", authMiddleware.PermissionMiddleware(rbac.PermSubjectRequests), subjectHandler.Reject)
+            admin.POST("/subject-requests/:id/extend", authMiddleware.PermissionMiddleware(rbac.PermSubjectRequests), subjectHandler.Extend)
         }
     }
 
@@ -122,10 +456,26 @@ func main() {
     apiKeyRoutes := router.Group("/api/v1")
     {
         apiKeyRoutes.Use(authMiddleware.APIKeyAuthMiddleware())
+        apiKeyRoutes.Use(rateLimitMiddleware.RateLimitByAPIKey())
         apiKeyRoutes.Use(rateLimitMiddleware.AdaptiveRateLimit())
         
-        apiKeyRoutes.POST("/lookup", phoneHandler.LookupPhone)
-        apiKeyRoutes.GET("/status/:id", jobHandler.GetJobStatus)
+        apiKeyRoutes.POST("/lookup", authMiddleware.PermissionMiddleware(rbac.PermPhoneLookup), phoneHandler.LookupPhone)
+        apiKeyRoutes.GET("/status/:id", authMiddleware.PermissionMiddleware(rbac.PermJobsRead), jobHandler.GetJobStatus)
+    }
+
+    // SCIM 2.0 for enterprise directories, with a key holding scim:provision
+    scim := router.Group("/scim/v2")
+    {
+        scim.Use(authMiddleware.APIKeyAuthMiddleware())
+        scim.Use(rateLimitMiddleware.RateLimitByAPIKey())
+        scim.Use(authMiddleware.PermissionMiddleware(rbac.PermSCIM))
+
+        scim.GET("/Users", scimHandler.ListUsers)
+        scim.POST("/Users", scimHandler.CreateUser)
+        scim.GET("/Users/:id", scimHandler.GetUser)
+        scim.PUT("/Users/:id", scimHandler.ReplaceUser)
+        scim.PATCH("/Users/:id", scimHandler.PatchUser)
+        scim.DELETE("/Users/:id", scimHandler.DeleteUser)
     }
 
     // Start server
@@ -3,29 +3,48 @@ package handlers
 
 import (
     "net/http"
+    "time"
 
     "github.com/gin-gonic/gin"
+    "secure-iran-intel/api-gateway/internal/middleware"
+    "secure-iran-intel/pkg/audit"
     "secure-iran-intel/pkg/normalizer"
+    "secure-iran-intel/pkg/purpose"
+    "secure-iran-intel/pkg/rbac"
+    "secure-iran-intel/pkg/refusal"
+    "secure-iran-intel/pkg/subject"
+    "secure-iran-intel/pkg/suppression"
 )
 
 type JobCreationHandler struct {
-    normalizer *normalizer.PhoneNormalizer
-    queue      JobQueue
+    normalizer   *normalizer.PhoneNormalizer
+    queue        JobQueue
+    auditLog     *audit.Logger
+    purposes     *purpose.Validator
+    policy       *rbac.Engine
+    erasures     subject.Checker
+    suppressions *suppression.Screener
 }
 
-func NewJobCreationHandler(queue JobQueue) *JobCreationHandler {
+func NewJobCreationHandler(queue JobQueue, auditLog *audit.Logger, purposes *purpose.Validator, policy *rbac.Engine, erasures subject.Checker, suppressions *suppression.Screener) *JobCreationHandler {
     return &JobCreationHandler{
-        normalizer: normalizer.NewPhoneNormalizer("IR"),
-        queue:      queue,
+        auditLog:     auditLog,
+        purposes:     purposes,
+        policy:       policy,
+        erasures:     erasures,
+        suppressions: suppressions,
+        normalizer:   normalizer.NewPhoneNormalizer("IR").WithCache(normalizer.DefaultNormalizationCache()),
+        queue:        queue,
     }
 }
 
 type IntelligenceJobRequest struct {
-    PhoneNumbers []string `json:"phone_numbers" binding:"required"`
+    PhoneNumbers []string `json:"phone_numbers" binding:"required" normalize:"phone,hint=country_hint"`
     CountryHint  string   `json:"country_hint,omitempty"`
     Platforms    []string `json:"platforms" binding:"required"`
     Priority     string   `json:"priority,omitempty"`
     Options      JobOptions `json:"options,omitempty"`
+    purpose.Purpose
 }
 
 type NormalizedJob struct {
@@ -34,6 +53,8 @@ type NormalizedJob struct {
     Normalized   map[string]normalizer.NormalizedPhone `json:"normalized_numbers"`
     Platforms    []string                   `json:"platforms"`
     Priority     string                     `json:"priority"`
+    TenantID     string                     `json:"tenant_id"`
+    Purpose      purpose.Purpose            `json:"purpose"`
     CreatedAt    string                     `json:"created_at"`
 }
 
@@ -44,35 +65,136 @@ func (jch *JobCreationHandler) CreateIntelligenceJob(c *gin.Context) {
         return
     }
     
+    // Every job must cite an open case of the caller's tenant
+    ctx := c.Request.Context()
+    actor := audit.ActorFromContext(ctx)
+    tenantID := actor.TenantID
+    if err := jch.policy.Require(ctx, actor, rbac.PermJobsCreate, nil); err != nil {
+        if code := refusal.Code(err); code != "" {
+            c.JSON(refusal.Status(err), gin.H{"error": err.Error(), "code": code})
+            return
+        }
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check permissions"})
+        return
+    }
+    if _, err := jch.purposes.Validate(ctx, tenantID, req.Purpose); err != nil {
+        if code := refusal.Code(err); code != "" {
+            c.JSON(refusal.Status(err), gin.H{"error": err.Error(), "code": code})
+            return
+        }
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check case reference"})
+        return
+    }
+    ctx = audit.WithJustification(ctx, req.Justification)
+    
     // Normalize all phone numbers
-    normalizedNumbers, errors := jch.normalizer.NormalizeBatch(req.PhoneNumbers, req.CountryHint)
+    batch := jch.normalizer.NormalizeBatch(req.PhoneNumbers, req.CountryHint)
     
-    // Filter out invalid numbers if strict mode
+    // Keep each distinct number once. Emergency services and short codes
+    // are never investigated and count as invalid, before any screening.
     validNumbers := make(map[string]normalizer.NormalizedPhone)
-    for original, normalized := range normalizedNumbers {
-        if normalized.IsValid || normalized.IsPossible {
-            validNumbers[original] = *normalized
+    invalid := 0
+    for _, result := range batch.Results {
+        switch {
+        case result.Err != nil || !(result.Phone.IsValid || result.Phone.IsPossible):
+            invalid++
+        case result.Phone.Type == "EMERGENCY" || result.Phone.Type == "SHORT_CODE":
+            invalid++
+        default:
+            validNumbers[result.Phone.Normalized] = *result.Phone
+        }
+    }
+    
+    // Drop erased subjects and suppressed numbers without telling the
+    // requester which they were
+    jobID := generateJobID()
+    normalized := make([]string, 0, len(validNumbers))
+    for number := range validNumbers {
+        normalized = append(normalized, number)
+    }
+    blocked, err := subject.ErasedNumbers(ctx, jch.erasures, normalized)
+    if err != nil {
+        c.JSON(http.StatusServiceUnavailable, gin.H{
+            "error": "Erasure register unavailable",
+        })
+        return
+    }
+    screened := make([]string, 0, len(normalized))
+    for _, number := range normalized {
+        if !blocked[number] {
+            screened = append(screened, number)
         }
     }
+    suppressedNumbers, err := jch.suppressions.Screen(ctx, tenantID, screened, map[string]string{"job_id": jobID})
+    if err != nil {
+        c.JSON(http.StatusServiceUnavailable, gin.H{
+            "error": "Suppression list unavailable",
+        })
+        return
+    }
+    for number := range suppressedNumbers {
+        blocked[number] = true
+    }
+    suppressed := 0
+    for number := range validNumbers {
+        if blocked[number] {
+            delete(validNumbers, number)
+            suppressed++
+        }
+    }
+    original := middleware.OriginalStrings(c, "/phone_numbers", req.PhoneNumbers)
+    if suppressed > 0 {
+        kept := make([]string, 0, len(original))
+        for _, result := range batch.Results {
+            if result.Phone == nil || !blocked[result.Phone.Normalized] {
+                kept = append(kept, original[result.Index])
+            }
+        }
+        original = kept
+    }
     
+    // A fully suppressed job is refused like one of invalid numbers
     if len(validNumbers) == 0 {
         c.JSON(http.StatusBadRequest, gin.H{
             "error": "No valid phone numbers found after normalization",
-            "details": errors,
+            "details": batch.Failed,
         })
         return
     }
     
     // Create normalized job
     job := NormalizedJob{
-        JobID:      generateJobID(),
-        Original:   req.PhoneNumbers,
+        JobID:      jobID,
+        Original:   original,
         Normalized: validNumbers,
         Platforms:  req.Platforms,
         Priority:   req.Priority,
+        TenantID:   tenantID,
+        Purpose:    req.Purpose,
         CreatedAt:  time.Now().Format(time.RFC3339),
     }
     
+    // Record the lookups before they can start
+    entries := make([]audit.Entry, 0, len(validNumbers))
+    for _, phone := range validNumbers {
+        entries = append(entries, audit.Entry{
+            Action:     audit.ActionJobCreate,
+            TargetType: "phone",
+            Target:     phone.Normalized,
+            Metadata: map[string]string{
+                "job_id":         job.JobID,
+                "case_reference": req.CaseReference,
+                "legal_basis":    req.LegalBasis,
+            },
+        })
+    }
+    if err := jch.auditLog.Log(ctx, entries...); err != nil {
+        c.JSON(http.StatusServiceUnavailable, gin.H{
+            "error": "Audit log unavailable",
+        })
+        return
+    }
+    
     // Submit job to queue
     if err := jch.queue.SubmitJob(job); err != nil {
         c.JSON(http.StatusInternalServerError, gin.H{
@@ -85,15 +207,16 @@ func (jch *JobCreationHandler) CreateIntelligenceJob(c *gin.Context) {
         "success": true,
         "job_id":  job.JobID,
         "data": gin.H{
-            "total_submitted":   len(req.PhoneNumbers),
-            "valid_numbers":     len(validNumbers),
-            "invalid_numbers":   len(req.PhoneNumbers) - len(validNumbers),
-            "normalized_format": "E.164",
+            "total_submitted":    len(req.PhoneNumbers),
+            "valid_numbers":      len(validNumbers),
+            "invalid_numbers":    invalid,
+            "suppressed_numbers": suppressed,
+            "normalized_format":  "E.164",
         },
     }
     
-    if len(errors) > 0 {
-        response["normalization_errors"] = errors
+    if len(batch.Failed) > 0 {
+        response["normalization_errors"] = batch.Failed
     }
     
     c.JSON(http.StatusOK, response)
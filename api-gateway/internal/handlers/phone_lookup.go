@@ -1,11 +1,25 @@
 // api-gateway/internal/handlers/phone_lookup.go
 package handlers
 
+import (
+    "strings"
+
+    "secure-iran-intel/pkg/audit"
+    "secure-iran-intel/pkg/normalizer"
+    "secure-iran-intel/pkg/subject"
+    "secure-iran-intel/pkg/suppression"
+)
+
+var lookupNormalizer = normalizer.NewPhoneNormalizer("IR").WithCache(normalizer.DefaultNormalizationCache())
+
 type PhoneLookupHandler struct {
     proxyPool    *proxy.IranProxyPool
     aiClient     *ai.AIClient
     cache        *redis.Client
     circuitBreaker *resilience.CircuitBreaker
+    auditLog     *audit.Logger
+    erasures     subject.Checker
+    suppressions *suppression.Screener
 }
 
 func (h *PhoneLookupHandler) LookupPhone(c *gin.Context) {
@@ -21,6 +35,43 @@ func (h *PhoneLookupHandler) LookupPhone(c *gin.Context) {
         return
     }
 
+    ctx := c.Request.Context()
+    if justification := c.GetHeader(audit.JustificationHeader); justification != "" {
+        ctx = audit.WithJustification(ctx, justification)
+    }
+    // Phone targets are hashed in E.164 so every format of a number matches
+    target := req.PhoneNumber
+    if phone, err := lookupNormalizer.NormalizePhone(req.PhoneNumber, "IR"); err == nil {
+        target = phone.Normalized
+
+        // Erased subjects and suppressed numbers are never looked up; the
+        // refusal does not say which it was
+        erased, err := subject.ErasedNumbers(ctx, h.erasures, []string{target})
+        if err != nil {
+            c.JSON(503, gin.H{"error": "Erasure register unavailable"})
+            return
+        }
+        metadata := map[string]string{"source": "phone_lookup"}
+        blocked, err := h.suppressions.Screen(ctx, audit.ActorFromContext(ctx).TenantID, []string{target}, metadata)
+        if err != nil {
+            c.JSON(503, gin.H{"error": "Suppression list unavailable"})
+            return
+        }
+        if erased[target] || blocked[target] {
+            c.JSON(400, gin.H{"error": "Number cannot be looked up"})
+            return
+        }
+    }
+    if err := h.auditLog.Log(ctx, audit.Entry{
+        Action:     audit.ActionPhoneLookup,
+        TargetType: "phone",
+        Target:     target,
+        Metadata:   map[string]string{"platforms": strings.Join(req.Platforms, ",")},
+    }); err != nil {
+        c.JSON(503, gin.H{"error": "Audit log unavailable"})
+        return
+    }
+
     // Use circuit breaker for resilience
     result, err := h.circuitBreaker.Execute(func() (interface{}, error) {
         return h.performLookup(req)
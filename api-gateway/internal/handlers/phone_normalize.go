@@ -2,7 +2,9 @@
 package handlers
 
 import (
+    "context"
     "encoding/json"
+    "errors"
     "net/http"
 
     "github.com/gin-gonic/gin"
@@ -15,7 +17,7 @@ type NormalizationHandler struct {
 
 func NewNormalizationHandler() *NormalizationHandler {
     return &NormalizationHandler{
-        normalizer: normalizer.NewPhoneNormalizer("IR"), // Default to Iran
+        normalizer: normalizer.NewPhoneNormalizer("IR").WithCache(normalizer.DefaultNormalizationCache()), // Default to Iran
     }
 }
 
@@ -24,6 +26,7 @@ func (nh *NormalizationHandler) NormalizeSingle(c *gin.Context) {
     var req struct {
         PhoneNumber string `json:"phone_number" binding:"required"`
         Country     string `json:"country,omitempty"`
+        Lenient     bool   `json:"lenient,omitempty"` // Keep unparseable numbers as given
     }
     
     if err := c.ShouldBindJSON(&req); err != nil {
@@ -31,12 +34,35 @@ func (nh *NormalizationHandler) NormalizeSingle(c *gin.Context) {
         return
     }
     
-    normalized, err := nh.normalizer.NormalizePhone(req.PhoneNumber, req.Country)
+    phoneNormalizer := nh.normalizer
+    if req.Lenient {
+        phoneNormalizer = phoneNormalizer.WithStrictMode(false)
+    }
+    
+    normalized, err := phoneNormalizer.NormalizePhone(req.PhoneNumber, req.Country)
+    if errors.Is(err, normalizer.ErrFallbackUsed) {
+        c.JSON(http.StatusOK, gin.H{
+            "success": true,
+            "data":    normalized,
+            "code":    normalizer.CodeFallbackUsed,
+            "warning": err.Error(),
+        })
+        return
+    }
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{
-            "error": "Failed to normalize phone number",
+        response := gin.H{
+            "error":   "Failed to normalize phone number",
+            "code":    normalizer.ErrorCode(err),
             "details": err.Error(),
-        })
+        }
+        
+        // Let the client pick a country instead of us guessing one
+        var normErr *normalizer.NormalizationError
+        if errors.As(err, &normErr) && len(normErr.Candidates) > 0 {
+            response["candidates"] = normErr.Candidates
+        }
+        
+        c.JSON(http.StatusBadRequest, response)
         return
     }
     
@@ -58,20 +84,75 @@ func (nh *NormalizationHandler) NormalizeBatch(c *gin.Context) {
         return
     }
     
-    results, errors := nh.normalizer.NormalizeBatch(req.PhoneNumbers, req.Country)
+    batch := nh.normalizer.NormalizeBatch(req.PhoneNumbers, req.Country)
     
     response := gin.H{
         "success": true,
-        "data":    results,
+        "data":    batch.Results,
+        "unique":  len(batch.Unique),
     }
     
-    if len(errors) > 0 {
-        response["errors"] = errors
+    if len(batch.Failed) > 0 {
+        response["errors"] = batch.Failed
     }
     
     c.JSON(http.StatusOK, response)
 }
 
+// NormalizeStream - Normalize a newline-separated upload, streaming NDJSON results in input order
+func (nh *NormalizationHandler) NormalizeStream(c *gin.Context) {
+    ctx, cancel := context.WithCancel(c.Request.Context())
+    defer cancel()
+    
+    results, readErr := nh.normalizer.NormalizeReader(ctx, c.Request.Body, normalizer.BatchOptions{
+        CountryHint: c.Query("country"),
+    })
+    
+    c.Header("Content-Type", "application/x-ndjson")
+    c.Status(http.StatusOK)
+    
+    encoder := json.NewEncoder(c.Writer)
+    written := 0
+    for result := range results {
+        if err := encoder.Encode(result); err != nil {
+            return // Client went away; cancel stops the pipeline
+        }
+        
+        written++
+        if written%streamFlushEvery == 0 {
+            c.Writer.Flush()
+        }
+    }
+    
+    if err := readErr(); err != nil {
+        encoder.Encode(gin.H{"error": "Failed to read upload"})
+    }
+    c.Writer.Flush()
+}
+
+const streamFlushEvery = 500
+
+// ExtractPhones - Find and normalize every phone number in free text
+func (nh *NormalizationHandler) ExtractPhones(c *gin.Context) {
+    var req struct {
+        Text    string `json:"text" binding:"required"`
+        Country string `json:"country,omitempty"`
+    }
+    
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+        return
+    }
+    
+    spans := normalizer.NewPhoneExtractor(nh.normalizer).Extract(req.Text, req.Country)
+    
+    c.JSON(http.StatusOK, gin.H{
+        "success": true,
+        "data":    spans,
+        "count":   len(spans),
+    })
+}
+
 // ValidatePhone - Validate phone number format and existence
 func (nh *NormalizationHandler) ValidatePhone(c *gin.Context) {
     var req struct {
@@ -88,6 +169,7 @@ func (nh *NormalizationHandler) ValidatePhone(c *gin.Context) {
     if err != nil {
         c.JSON(http.StatusOK, gin.H{
             "valid":   false,
+            "code":    normalizer.ErrorCode(err),
             "message": err.Error(),
         })
         return
@@ -97,8 +179,11 @@ func (nh *NormalizationHandler) ValidatePhone(c *gin.Context) {
         "valid":    normalized.IsValid,
         "possible": normalized.IsPossible,
         "type":     normalized.Type,
+        "extension": normalized.Extension,
         "carrier":  normalized.Carrier,
         "region":   normalized.Region,
+        "timezone": normalized.Timezone,
+        "timezones": normalized.Timezones,
         "formats": gin.H{
             "e164":         normalized.Normalized,
             "international": normalized.International,
@@ -0,0 +1,176 @@
+// api-gateway/internal/middleware/json_fields.go
+package middleware
+
+import (
+    "encoding/json"
+    "sort"
+    "strconv"
+    "strings"
+)
+
+// jsonString is a string value in a JSON document and where its literal
+// (quotes included) sits in the raw bytes
+type jsonString struct {
+    path       []string
+    value      string
+    start, end int
+}
+
+// jsonEdit replaces data[start:end] with literal
+type jsonEdit struct {
+    start, end int
+    literal    []byte
+}
+
+// scanJSONStrings lists every string value in data, which must already be
+// valid JSON. Object keys are not values and are not listed.
+func scanJSONStrings(data []byte) []jsonString {
+    s := &jsonScanner{data: data}
+    s.value(nil)
+    return s.strings
+}
+
+type jsonScanner struct {
+    data    []byte
+    pos     int
+    strings []jsonString
+}
+
+func (s *jsonScanner) value(path []string) {
+    s.skipSpace()
+    switch s.data[s.pos] {
+    case '{':
+        s.pos++
+        for {
+            s.skipSpace()
+            if s.data[s.pos] == '}' {
+                s.pos++
+                return
+            }
+            if s.data[s.pos] == ',' {
+                s.pos++
+                s.skipSpace()
+            }
+
+            keyStart := s.pos
+            s.skipString()
+            var key string
+            json.Unmarshal(s.data[keyStart:s.pos], &key)
+
+            s.skipSpace()
+            s.pos++ // ':'
+            s.value(append(path[:len(path):len(path)], key))
+        }
+    case '[':
+        s.pos++
+        for index := 0; ; index++ {
+            s.skipSpace()
+            if s.data[s.pos] == ']' {
+                s.pos++
+                return
+            }
+            if s.data[s.pos] == ',' {
+                s.pos++
+            }
+            s.value(append(path[:len(path):len(path)], strconv.Itoa(index)))
+        }
+    case '"':
+        start := s.pos
+        s.skipString()
+        var value string
+        json.Unmarshal(s.data[start:s.pos], &value)
+        s.strings = append(s.strings, jsonString{path: path, value: value, start: start, end: s.pos})
+    default:
+        // Numbers, booleans and null run up to the next delimiter
+        for s.pos < len(s.data) && !strings.ContainsRune(",]} \t\r\n", rune(s.data[s.pos])) {
+            s.pos++
+        }
+    }
+}
+
+func (s *jsonScanner) skipString() {
+    s.pos++ // Opening quote
+    for s.pos < len(s.data) {
+        switch s.data[s.pos] {
+        case '\\':
+            s.pos += 2
+        case '"':
+            s.pos++
+            return
+        default:
+            s.pos++
+        }
+    }
+}
+
+func (s *jsonScanner) skipSpace() {
+    for s.pos < len(s.data) && strings.ContainsRune(" \t\r\n", rune(s.data[s.pos])) {
+        s.pos++
+    }
+}
+
+// applyEdits splices non-overlapping edits into a copy of data
+func applyEdits(data []byte, edits []jsonEdit) []byte {
+    sort.Slice(edits, func(i, j int) bool { return edits[i].start < edits[j].start })
+
+    out := make([]byte, 0, len(data))
+    last := 0
+    for _, edit := range edits {
+        out = append(out, data[last:edit.start]...)
+        out = append(out, edit.literal...)
+        last = edit.end
+    }
+    return append(out, data[last:]...)
+}
+
+// parsePointer splits an RFC 6901 JSON pointer into unescaped segments
+func parsePointer(pointer string) []string {
+    if pointer == "" {
+        return []string{}
+    }
+    segments := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+    for i, segment := range segments {
+        segments[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(segment)
+    }
+    return segments
+}
+
+func formatPointer(path []string) string {
+    var b strings.Builder
+    for _, segment := range path {
+        b.WriteByte('/')
+        b.WriteString(escapePointerSegment(segment))
+    }
+    return b.String()
+}
+
+func escapePointerSegment(segment string) string {
+    return strings.NewReplacer("~", "~0", "/", "~1").Replace(segment)
+}
+
+// matchPointer reports whether path matches pattern, where a "*" segment
+// matches any single segment
+func matchPointer(pattern, path []string) bool {
+    if len(pattern) != len(path) {
+        return false
+    }
+    for i, segment := range pattern {
+        if segment != "*" && segment != path[i] {
+            return false
+        }
+    }
+    return true
+}
+
+// bindPointer fills the "*" segments of pattern from the matched path at
+// the same positions
+func bindPointer(pattern, path []string) []string {
+    bound := make([]string, len(pattern))
+    for i, segment := range pattern {
+        if segment == "*" && i < len(path) {
+            segment = path[i]
+        }
+        bound[i] = segment
+    }
+    return bound
+}
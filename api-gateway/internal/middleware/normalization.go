@@ -5,109 +5,226 @@ import (
     "bytes"
     "encoding/json"
     "io"
-    "net/http"
+    "reflect"
+    "strconv"
+    "strings"
 
     "github.com/gin-gonic/gin"
     "secure-iran-intel/pkg/normalizer"
 )
 
+// NormalizedFieldsKey holds the []RewrittenField for the current request
+const NormalizedFieldsKey = "normalized_fields"
+
 type NormalizationMiddleware struct {
     normalizer *normalizer.PhoneNormalizer
 }
 
 func NewNormalizationMiddleware() *NormalizationMiddleware {
     return &NormalizationMiddleware{
-        normalizer: normalizer.NewPhoneNormalizer("IR"),
+        normalizer: normalizer.NewPhoneNormalizer("IR").WithCache(normalizer.DefaultNormalizationCache()),
     }
 }
 
-// AutoNormalizePhoneNumbers - Automatically normalize phone numbers in requests
-func (nm *NormalizationMiddleware) AutoNormalizePhoneNumbers() gin.HandlerFunc {
+// PhoneField selects request body strings to normalize. Pointer is an RFC
+// 6901 JSON pointer in which a "*" segment matches every array element or
+// object member. CountryHint optionally points at a string holding the
+// country hint; its "*" segments bind to the same positions as Pointer's.
+type PhoneField struct {
+    Pointer     string
+    CountryHint string
+}
+
+// RewrittenField records one value the middleware replaced
+type RewrittenField struct {
+    Pointer    string `json:"pointer"`
+    Original   string `json:"original"`
+    Normalized string `json:"normalized"`
+}
+
+// NormalizePhoneFields rewrites the listed fields of a JSON body to E.164,
+// keeping any extension as an ";ext=" parameter. Only those string values
+// are replaced, in place; every other byte of the body reaches the handler
+// unchanged. Values that fail to normalize are left as they are for the
+// handler to reject.
+func (nm *NormalizationMiddleware) NormalizePhoneFields(fields ...PhoneField) gin.HandlerFunc {
+    type rule struct {
+        pattern []string
+        hint    []string
+    }
+    rules := make([]rule, 0, len(fields))
+    for _, field := range fields {
+        r := rule{pattern: parsePointer(field.Pointer)}
+        if field.CountryHint != "" {
+            r.hint = parsePointer(field.CountryHint)
+        }
+        rules = append(rules, r)
+    }
+
     return func(c *gin.Context) {
         // Only process JSON requests
-        if c.ContentType() != "application/json" {
+        if c.ContentType() != "application/json" || c.Request.Body == nil {
             c.Next()
             return
         }
-        
-        // Read and parse the request body
-        var body map[string]interface{}
-        if err := c.ShouldBindJSON(&body); err != nil {
-            c.Next()
+
+        body, err := io.ReadAll(c.Request.Body)
+        c.Request.Body.Close()
+        c.Request.Body = io.NopCloser(bytes.NewReader(body))
+        if err != nil || !json.Valid(body) {
+            c.Next() // Let the handler report the malformed body
             return
         }
-        
-        // Normalize phone numbers in the request body
-        nm.normalizeRequestBody(body)
-        
-        // Set the normalized body back to the context
-        normalizedBody, err := json.Marshal(body)
-        if err != nil {
-            c.Next()
-            return
+
+        values := scanJSONStrings(body)
+        byPointer := make(map[string]jsonString, len(values))
+        for _, value := range values {
+            byPointer[formatPointer(value.path)] = value
         }
-        
-        c.Request.Body = io.NopCloser(bytes.NewBuffer(normalizedBody))
-        c.Set("normalized_body", body)
-        
-        c.Next()
-    }
-}
 
-func (nm *NormalizationMiddleware) normalizeRequestBody(body map[string]interface{}) {
-    for key, value := range body {
-        switch v := value.(type) {
-        case string:
-            if nm.looksLikePhoneNumber(v) {
-                normalized, err := nm.normalizer.NormalizePhone(v, "")
-                if err == nil {
-                    body[key] = normalized.Normalized
+        var edits []jsonEdit
+        var rewritten []RewrittenField
+        for _, value := range values {
+            for _, r := range rules {
+                if !matchPointer(r.pattern, value.path) {
+                    continue
+                }
+
+                var hint string
+                if r.hint != nil {
+                    if h, ok := byPointer[formatPointer(bindPointer(r.hint, value.path))]; ok {
+                        hint = h.value
+                    }
                 }
+
+                normalized, err := nm.normalizer.NormalizePhone(value.value, hint)
+                if err != nil || normalized.Dialable() == value.value {
+                    break
+                }
+
+                literal, _ := json.Marshal(normalized.Dialable())
+                edits = append(edits, jsonEdit{start: value.start, end: value.end, literal: literal})
+                rewritten = append(rewritten, RewrittenField{
+                    Pointer:    formatPointer(value.path),
+                    Original:   value.value,
+                    Normalized: normalized.Dialable(),
+                })
+                break
             }
-        case []interface{}:
-            nm.normalizeArray(v)
-        case map[string]interface{}:
-            nm.normalizeRequestBody(v)
         }
+
+        if len(edits) > 0 {
+            body = applyEdits(body, edits)
+            c.Request.Body = io.NopCloser(bytes.NewReader(body))
+            c.Request.ContentLength = int64(len(body))
+        }
+        c.Set(NormalizedFieldsKey, rewritten)
+
+        c.Next()
     }
 }
 
-func (nm *NormalizationMiddleware) normalizeArray(arr []interface{}) {
-    for i, item := range arr {
-        switch v := item.(type) {
-        case string:
-            if nm.looksLikePhoneNumber(v) {
-                normalized, err := nm.normalizer.NormalizePhone(v, "")
-                if err == nil {
-                    arr[i] = normalized.Normalized
+// PhoneFieldsOf derives rules from `normalize:"phone"` struct tags, using
+// the fields' JSON names. A sibling country hint field can be named with
+// `normalize:"phone,hint=country_hint"`.
+func PhoneFieldsOf(v interface{}) []PhoneField {
+    return phoneFieldsOf(reflect.TypeOf(v), "", map[reflect.Type]bool{})
+}
+
+func phoneFieldsOf(t reflect.Type, base string, seen map[reflect.Type]bool) []PhoneField {
+    for t.Kind() == reflect.Ptr {
+        t = t.Elem()
+    }
+    if t.Kind() != reflect.Struct || seen[t] {
+        return nil
+    }
+    seen[t] = true
+    defer delete(seen, t)
+
+    var fields []PhoneField
+    for i := 0; i < t.NumField(); i++ {
+        f := t.Field(i)
+        name, ok := jsonFieldName(f)
+        if !ok {
+            continue
+        }
+
+        // Embedded structs without a JSON name are flattened by encoding/json
+        if f.Anonymous && name == "" {
+            fields = append(fields, phoneFieldsOf(f.Type, base, seen)...)
+            continue
+        }
+        if name == "" {
+            name = f.Name
+        }
+        pointer := base + "/" + escapePointerSegment(name)
+
+        elem := f.Type
+        for elem.Kind() == reflect.Ptr {
+            elem = elem.Elem()
+        }
+        isList := elem.Kind() == reflect.Slice || elem.Kind() == reflect.Array
+        if isList {
+            elem = elem.Elem()
+        }
+
+        tag := strings.Split(f.Tag.Get("normalize"), ",")
+        if tag[0] == "phone" {
+            field := PhoneField{Pointer: pointer}
+            if isList {
+                field.Pointer += "/*"
+            }
+            for _, option := range tag[1:] {
+                if hint := strings.TrimPrefix(option, "hint="); hint != option {
+                    field.CountryHint = base + "/" + escapePointerSegment(hint)
                 }
             }
-        case map[string]interface{}:
-            nm.normalizeRequestBody(v)
-        case []interface{}:
-            nm.normalizeArray(v)
+            fields = append(fields, field)
+            continue
+        }
+
+        if isList {
+            pointer += "/*"
         }
+        fields = append(fields, phoneFieldsOf(elem, pointer, seen)...)
+    }
+
+    return fields
+}
+
+func jsonFieldName(f reflect.StructField) (string, bool) {
+    if !f.IsExported() && !f.Anonymous {
+        return "", false
+    }
+    name := strings.Split(f.Tag.Get("json"), ",")[0]
+    if name == "-" {
+        return "", false
     }
+    return name, true
 }
 
-func (nm *NormalizationMiddleware) looksLikePhoneNumber(input string) bool {
-    // Simple heuristic to identify phone number fields
-    phoneKeywords := []string{"phone", "mobile", "tel", "number", "contact"}
-    inputLower := strings.ToLower(input)
-    
-    for _, keyword := range phoneKeywords {
-        if strings.Contains(inputLower, keyword) {
-            return true
+// NormalizedFields returns the fields rewritten for this request
+func NormalizedFields(c *gin.Context) []RewrittenField {
+    if value, ok := c.Get(NormalizedFieldsKey); ok {
+        if fields, ok := value.([]RewrittenField); ok {
+            return fields
         }
     }
-    
-    // Check if the string contains mostly digits and common phone characters
-    digitCount := 0
-    for _, char := range input {
-        if char >= '0' && char <= '9' {
-            digitCount++
+    return nil
+}
+
+// OriginalStrings returns a copy of values, the decoded array at pointer,
+// with any elements the middleware rewrote restored to what the client sent
+func OriginalStrings(c *gin.Context, pointer string, values []string) []string {
+    originals := append([]string(nil), values...)
+    for _, field := range NormalizedFields(c) {
+        index, ok := strings.CutPrefix(field.Pointer, pointer+"/")
+        if !ok {
+            continue
+        }
+        if i, err := strconv.Atoi(index); err == nil && i >= 0 && i < len(originals) {
+            originals[i] = field.Original
         }
     }
-    
-    return digitCount >= 7 && digitCount <= 15
+    return originals
 }
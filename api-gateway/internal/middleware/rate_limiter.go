@@ -8,6 +8,7 @@ import (
 
     "github.com/gin-gonic/gin"
     "secure-iran-intel/api-gateway/internal/services"
+    "secure-iran-intel/pkg/apikey"
 )
 
 type RateLimitMiddleware struct {
@@ -78,6 +79,44 @@ func (rlm *RateLimitMiddleware) RateLimitByTenant() gin.HandlerFunc {
     }
 }
 
+// RateLimitByAPIKey applies the authenticated key's own limit
+func (rlm *RateLimitMiddleware) RateLimitByAPIKey() gin.HandlerFunc {
+    return func(c *gin.Context) {
+        ctx := c.Request.Context()
+        
+        key, ok := ctx.Value(middleware.APIKeyKey).(*apikey.Key)
+        if !ok {
+            c.JSON(http.StatusUnauthorized, gin.H{"error": "API key required"})
+            c.Abort()
+            return
+        }
+
+        result, err := rlm.rateLimitService.APIKeyRateLimit(ctx, key)
+        if err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": "Rate limit service error"})
+            c.Abort()
+            return
+        }
+
+        c.Header("X-RateLimit-Limit", strconv.FormatInt(result.Limit, 10))
+        c.Header("X-RateLimit-Remaining", strconv.FormatInt(result.Remaining, 10))
+        c.Header("X-RateLimit-Reset", strconv.FormatInt(result.ResetTime, 10))
+
+        if !result.Allowed {
+            c.Header("Retry-After", strconv.FormatInt(result.RetryAfter, 10))
+            c.JSON(http.StatusTooManyRequests, gin.H{
+                "error": "API key rate limit exceeded",
+                "retry_after": result.RetryAfter,
+                "limit": result.Limit,
+            })
+            c.Abort()
+            return
+        }
+
+        c.Next()
+    }
+}
+
 // AdaptiveRateLimit applies intelligent rate limiting
 func (rlm *RateLimitMiddleware) AdaptiveRateLimit() gin.HandlerFunc {
     return func(c *gin.Context) {
@@ -9,6 +9,7 @@ import (
     "time"
 
     "github.com/go-redis/redis/v8"
+    "secure-iran-intel/pkg/apikey"
 )
 
 type RateLimitService struct {
@@ -20,7 +21,7 @@ type RateLimitConfig struct {
     RequestsPerMinute int           `json:"requests_per_minute"`
     Burst            int           `json:"burst"`
     Window           time.Duration `json:"window"`
-    Scope            string        `json:"scope"` // "user", "tenant", "ip", "endpoint"
+    Scope            string        `json:"scope"` // "user", "tenant", "ip", "endpoint", "api_key"
 }
 
 type RateLimitResult struct {
@@ -159,6 +160,22 @@ func (rls *RateLimitService) MultiDimensionalRateLimit(
     return rls.getMostRestrictiveResult(results), nil
 }
 
+// APIKeyRateLimit applies the key's own per-minute limit. Rotated keys get
+// a fresh budget; the overlap window is short and bounded.
+func (rls *RateLimitService) APIKeyRateLimit(ctx context.Context, key *apikey.Key) (*RateLimitResult, error) {
+    limit := key.RateLimitPerMinute
+    if limit <= 0 {
+        limit = apikey.DefaultRateLimitPerMinute
+    }
+    return rls.CheckRateLimit(ctx,
+        fmt.Sprintf("api_key:%s:%s", key.TenantID, key.ID),
+        &RateLimitConfig{
+            RequestsPerMinute: limit,
+            Window: time.Minute,
+            Scope: "api_key",
+        })
+}
+
 // AdaptiveRateLimit adjusts limits based on client behavior
 func (rls *RateLimitService) AdaptiveRateLimit(
     ctx context.Context,
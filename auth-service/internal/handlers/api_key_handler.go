@@ -0,0 +1,81 @@
+// auth-service/internal/handlers/api_key_handler.go
+package handlers
+
+import (
+    "net/http"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "secure-iran-intel/pkg/apikey"
+)
+
+type APIKeyHandler struct {
+    keys *apikey.Service
+}
+
+func NewAPIKeyHandler(keys *apikey.Service) *APIKeyHandler {
+    return &APIKeyHandler{keys: keys}
+}
+
+// RotateRequest sets how long the old key keeps working
+type RotateRequest struct {
+    OverlapHours int `json:"overlap_hours"`
+}
+
+// CreateKey issues a key owned by the caller. The secret is in this
+// response only.
+func (h *APIKeyHandler) CreateKey(c *gin.Context) {
+    var req apikey.CreateRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    issued, err := h.keys.Create(c.Request.Context(), req)
+    if err != nil {
+        keyError(c, err)
+        return
+    }
+    c.JSON(http.StatusCreated, issued)
+}
+
+// ListKeys returns the keys the caller may manage, without secrets
+func (h *APIKeyHandler) ListKeys(c *gin.Context) {
+    keys, err := h.keys.List(c.Request.Context())
+    if err != nil {
+        keyError(c, err)
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"keys": keys})
+}
+
+// RotateKey issues a replacement; the old key expires after the overlap
+func (h *APIKeyHandler) RotateKey(c *gin.Context) {
+    var req RotateRequest
+    if c.Request.ContentLength > 0 {
+        if err := c.ShouldBindJSON(&req); err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+            return
+        }
+    }
+
+    issued, err := h.keys.Rotate(c.Request.Context(), c.Param("id"), time.Duration(req.OverlapHours)*time.Hour)
+    if err != nil {
+        keyError(c, err)
+        return
+    }
+    c.JSON(http.StatusCreated, issued)
+}
+
+// RevokeKey stops a key working immediately
+func (h *APIKeyHandler) RevokeKey(c *gin.Context) {
+    if err := h.keys.Revoke(c.Request.Context(), c.Param("id")); err != nil {
+        keyError(c, err)
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"id": c.Param("id"), "revoked": true})
+}
+
+func keyError(c *gin.Context, err error) {
+    refusalError(c, err, "API key request failed")
+}
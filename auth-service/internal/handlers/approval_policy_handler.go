@@ -0,0 +1,61 @@
+// auth-service/internal/handlers/approval_policy_handler.go
+package handlers
+
+import (
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+    "secure-iran-intel/pkg/approval"
+)
+
+type ApprovalPolicyHandler struct {
+    approvals *approval.Workflow
+}
+
+func NewApprovalPolicyHandler(approvals *approval.Workflow) *ApprovalPolicyHandler {
+    return &ApprovalPolicyHandler{approvals: approvals}
+}
+
+type ApprovalPolicyRequest struct {
+    MaxUnapprovedSize *int     `json:"max_unapproved_size" binding:"required"`
+    FlaggedCategories []string `json:"flagged_categories"`
+    DeadlineHours     *int     `json:"deadline_hours" binding:"required"`
+}
+
+// GetPolicy returns which of the tenant's jobs wait for a second user
+//
+//    GET /api/v1/admin/approval-policy
+func (h *ApprovalPolicyHandler) GetPolicy(c *gin.Context) {
+    policy, err := h.approvals.Policy(c.Request.Context())
+    if err != nil {
+        approvalPolicyError(c, err)
+        return
+    }
+    c.JSON(http.StatusOK, policy)
+}
+
+// SetPolicy sets which of the tenant's jobs wait for a second user, and
+// for how long
+//
+//    PUT /api/v1/admin/approval-policy
+func (h *ApprovalPolicyHandler) SetPolicy(c *gin.Context) {
+    var req ApprovalPolicyRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    policy, err := h.approvals.SetPolicy(c.Request.Context(), approval.Policy{
+        MaxUnapprovedSize: *req.MaxUnapprovedSize,
+        FlaggedCategories: req.FlaggedCategories,
+        DeadlineHours:     *req.DeadlineHours,
+    })
+    if err != nil {
+        approvalPolicyError(c, err)
+        return
+    }
+    c.JSON(http.StatusOK, policy)
+}
+
+func approvalPolicyError(c *gin.Context, err error) {
+    refusalError(c, err, "Approval policy change failed")
+}
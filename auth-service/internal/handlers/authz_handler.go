@@ -0,0 +1,63 @@
+// auth-service/internal/handlers/authz_handler.go
+package handlers
+
+import (
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+    "secure-iran-intel/pkg/audit"
+    "secure-iran-intel/pkg/rbac"
+)
+
+type AuthzHandler struct {
+    policy *rbac.Engine
+}
+
+func NewAuthzHandler(policy *rbac.Engine) *AuthzHandler {
+    return &AuthzHandler{policy: policy}
+}
+
+// Explain says whether a request would be allowed, and which rule of which
+// role decided it. Callers explain their own access; explaining another
+// user's needs roles:read.
+//
+//    GET /authz/explain?permission=jobs:read&owner_id=...&case_reference=...
+func (h *AuthzHandler) Explain(c *gin.Context) {
+    permission := c.Query("permission")
+    if permission == "" {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "permission is required"})
+        return
+    }
+
+    ctx := c.Request.Context()
+    caller := audit.ActorFromContext(ctx)
+    actor := caller
+    if userID := c.Query("user_id"); userID != "" && userID != caller.ID {
+        if err := h.policy.Require(ctx, caller, rbac.PermRolesRead, nil); err != nil {
+            c.JSON(http.StatusForbidden, gin.H{"error": "Explaining another user's access needs " + rbac.PermRolesRead})
+            return
+        }
+        actor = audit.Actor{TenantID: caller.TenantID, Type: audit.ActorUser, ID: userID}
+    }
+
+    // Without resource attributes this explains the route-level check
+    var resource *rbac.Resource
+    if c.Query("resource_type") != "" || c.Query("owner_id") != "" || c.Query("team_id") != "" || c.Query("case_reference") != "" {
+        resource = &rbac.Resource{
+            Type:          c.Query("resource_type"),
+            ID:            c.Query("resource_id"),
+            TenantID:      caller.TenantID,
+            OwnerID:       c.Query("owner_id"),
+            TeamID:        c.Query("team_id"),
+            CaseReference: c.Query("case_reference"),
+        }
+    }
+
+    decision, err := h.policy.AuthorizeActor(ctx, actor, permission, resource)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to evaluate policy"})
+        return
+    }
+
+    c.JSON(http.StatusOK, decision)
+}
@@ -0,0 +1,62 @@
+// auth-service/internal/handlers/case_handler.go
+package handlers
+
+import (
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+    "secure-iran-intel/pkg/purpose"
+)
+
+type CaseHandler struct {
+    cases *purpose.CaseService
+}
+
+func NewCaseHandler(service *purpose.CaseService) *CaseHandler {
+    return &CaseHandler{cases: service}
+}
+
+// ListCases returns the cases jobs can currently be run under
+//
+//    GET /api/v1/cases
+func (h *CaseHandler) ListCases(c *gin.Context) {
+    cases, err := h.cases.ListOpen(c.Request.Context())
+    if err != nil {
+        caseError(c, err)
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"cases": cases})
+}
+
+// OpenCase registers a case jobs can cite under its legal bases
+//
+//    POST /api/v1/cases
+func (h *CaseHandler) OpenCase(c *gin.Context) {
+    var req purpose.NewCase
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    opened, err := h.cases.Open(c.Request.Context(), req)
+    if err != nil {
+        caseError(c, err)
+        return
+    }
+    c.JSON(http.StatusCreated, opened)
+}
+
+// CloseCase stops new jobs citing the case
+//
+//    POST /api/v1/cases/:reference/close
+func (h *CaseHandler) CloseCase(c *gin.Context) {
+    reference := c.Param("reference")
+    if err := h.cases.Close(c.Request.Context(), reference); err != nil {
+        caseError(c, err)
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"reference": reference, "status": "closed"})
+}
+
+func caseError(c *gin.Context, err error) {
+    refusalError(c, err, "Case change failed")
+}
@@ -0,0 +1,26 @@
+// auth-service/internal/handlers/errors.go
+package handlers
+
+import (
+    "log"
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+    "secure-iran-intel/pkg/refusal"
+)
+
+// refusalError answers with the status and code of the refusal err wraps.
+// Other errors can describe internals, so they are logged and answered
+// with message instead.
+func refusalError(c *gin.Context, err error, message string) {
+    status := refusal.Status(err)
+    body := gin.H{"error": err.Error()}
+    if status == http.StatusInternalServerError {
+        log.Printf("⚠️ %s: %v", message, err)
+        body["error"] = message
+    }
+    if code := refusal.Code(err); code != "" {
+        body["code"] = code
+    }
+    c.JSON(status, body)
+}
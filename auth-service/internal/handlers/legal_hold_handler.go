@@ -0,0 +1,67 @@
+// auth-service/internal/handlers/legal_hold_handler.go
+package handlers
+
+import (
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+    "secure-iran-intel/pkg/retention"
+)
+
+type LegalHoldHandler struct {
+    retention *retention.Service
+}
+
+func NewLegalHoldHandler(service *retention.Service) *LegalHoldHandler {
+    return &LegalHoldHandler{retention: service}
+}
+
+type PlaceHoldRequest struct {
+    CaseReference string `json:"case_reference" binding:"required"`
+    Reason        string `json:"reason" binding:"required"`
+}
+
+// ListHolds returns the tenant's legal holds, released ones included
+//
+//    GET /api/v1/legal-holds
+func (h *LegalHoldHandler) ListHolds(c *gin.Context) {
+    holds, err := h.retention.ListHolds(c.Request.Context())
+    if err != nil {
+        retentionError(c, err)
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"legal_holds": holds})
+}
+
+// PlaceHold pauses retention for everything recorded under a case
+//
+//    POST /api/v1/legal-holds
+func (h *LegalHoldHandler) PlaceHold(c *gin.Context) {
+    var req PlaceHoldRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    hold, err := h.retention.PlaceHold(c.Request.Context(), req.CaseReference, req.Reason)
+    if err != nil {
+        retentionError(c, err)
+        return
+    }
+    c.JSON(http.StatusCreated, hold)
+}
+
+// ReleaseHold lets retention resume for the hold's case
+//
+//    POST /api/v1/legal-holds/:id/release
+func (h *LegalHoldHandler) ReleaseHold(c *gin.Context) {
+    hold, err := h.retention.ReleaseHold(c.Request.Context(), c.Param("id"))
+    if err != nil {
+        retentionError(c, err)
+        return
+    }
+    c.JSON(http.StatusOK, hold)
+}
+
+func retentionError(c *gin.Context, err error) {
+    refusalError(c, err, "Legal hold change failed")
+}
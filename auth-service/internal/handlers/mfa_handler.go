@@ -0,0 +1,249 @@
+// auth-service/internal/handlers/mfa_handler.go
+package handlers
+
+import (
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+    "secure-iran-intel/auth-service/internal/middleware"
+    "secure-iran-intel/auth-service/internal/services"
+    "secure-iran-intel/pkg/audit"
+    "secure-iran-intel/pkg/mfa"
+    "secure-iran-intel/pkg/token"
+)
+
+type MFAHandler struct {
+    mfa           *mfa.Service
+    tenantService *services.TenantService
+}
+
+func NewMFAHandler(service *mfa.Service, tenantService *services.TenantService) *MFAHandler {
+    return &MFAHandler{mfa: service, tenantService: tenantService}
+}
+
+type EnrollRequest struct {
+    Name string `json:"name"`
+}
+
+type ConfirmRequest struct {
+    Code string `json:"code" binding:"required"`
+}
+
+type VerifyRequest struct {
+    Method string `json:"method" binding:"required"` // mfa.MethodTOTP or mfa.MethodRecoveryCode
+    Code   string `json:"code" binding:"required"`
+}
+
+type MFAPolicyRequest struct {
+    Required *bool `json:"required" binding:"required"`
+}
+
+// ListFactors lists the caller's second factors
+//
+//    GET /api/v1/auth/mfa/factors
+func (h *MFAHandler) ListFactors(c *gin.Context) {
+    claims, ok := mfaClaims(c)
+    if !ok {
+        return
+    }
+    factors, err := h.mfa.Factors(c.Request.Context(), claims)
+    if err != nil {
+        mfaError(c, err)
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"factors": factors})
+}
+
+// EnrollTOTP starts enrolling an authenticator app. The response holds the
+// secret and otpauth URL, which are not shown again.
+//
+//    POST /api/v1/auth/mfa/totp
+func (h *MFAHandler) EnrollTOTP(c *gin.Context) {
+    claims, ok := mfaClaims(c)
+    if !ok {
+        return
+    }
+    var req EnrollRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    enrollment, err := h.mfa.EnrollTOTP(c.Request.Context(), claims, req.Name)
+    if err != nil {
+        mfaError(c, err)
+        return
+    }
+    c.JSON(http.StatusCreated, enrollment)
+}
+
+// ConfirmTOTP confirms an authenticator app with its first code
+//
+//    POST /api/v1/auth/mfa/totp/:id/confirm
+func (h *MFAHandler) ConfirmTOTP(c *gin.Context) {
+    claims, ok := mfaClaims(c)
+    if !ok {
+        return
+    }
+    var req ConfirmRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    verification, err := h.mfa.ConfirmTOTP(c.Request.Context(), claims, c.Param("id"), req.Code)
+    if err != nil {
+        mfaError(c, err)
+        return
+    }
+    c.JSON(http.StatusOK, verification)
+}
+
+// BeginWebAuthnRegistration returns the options for
+// navigator.credentials.create()
+//
+//    POST /api/v1/auth/mfa/webauthn/register/begin
+func (h *MFAHandler) BeginWebAuthnRegistration(c *gin.Context) {
+    claims, ok := mfaClaims(c)
+    if !ok {
+        return
+    }
+    creation, err := h.mfa.BeginWebAuthnRegistration(c.Request.Context(), claims)
+    if err != nil {
+        mfaError(c, err)
+        return
+    }
+    c.JSON(http.StatusOK, creation)
+}
+
+// FinishWebAuthnRegistration enrolls the credential the browser created.
+// The body is the PublicKeyCredential; ?name= labels the factor.
+//
+//    POST /api/v1/auth/mfa/webauthn/register/finish
+func (h *MFAHandler) FinishWebAuthnRegistration(c *gin.Context) {
+    claims, ok := mfaClaims(c)
+    if !ok {
+        return
+    }
+    verification, err := h.mfa.FinishWebAuthnRegistration(c.Request.Context(), claims, c.Query("name"), c.Request.Body)
+    if err != nil {
+        mfaError(c, err)
+        return
+    }
+    c.JSON(http.StatusCreated, verification)
+}
+
+// BeginWebAuthn returns the options for navigator.credentials.get()
+//
+//    POST /api/v1/auth/mfa/webauthn/challenge
+func (h *MFAHandler) BeginWebAuthn(c *gin.Context) {
+    claims, ok := mfaClaims(c)
+    if !ok {
+        return
+    }
+    assertion, err := h.mfa.BeginWebAuthn(c.Request.Context(), claims)
+    if err != nil {
+        mfaError(c, err)
+        return
+    }
+    c.JSON(http.StatusOK, assertion)
+}
+
+// VerifyWebAuthn checks the browser's assertion and returns a stepped-up
+// token
+//
+//    POST /api/v1/auth/mfa/webauthn/verify
+func (h *MFAHandler) VerifyWebAuthn(c *gin.Context) {
+    claims, ok := mfaClaims(c)
+    if !ok {
+        return
+    }
+    verification, err := h.mfa.VerifyWebAuthn(c.Request.Context(), claims, c.Request.Body)
+    if err != nil {
+        mfaError(c, err)
+        return
+    }
+    c.JSON(http.StatusOK, verification)
+}
+
+// Verify checks a TOTP or recovery code and returns a stepped-up token
+//
+//    POST /api/v1/auth/mfa/verify
+func (h *MFAHandler) Verify(c *gin.Context) {
+    claims, ok := mfaClaims(c)
+    if !ok {
+        return
+    }
+    var req VerifyRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    verification, err := h.mfa.Verify(c.Request.Context(), claims, req.Method, req.Code)
+    if err != nil {
+        mfaError(c, err)
+        return
+    }
+    c.JSON(http.StatusOK, verification)
+}
+
+// RemoveFactor deletes one of the caller's factors; needs a step-up
+//
+//    DELETE /api/v1/auth/mfa/factors/:id
+func (h *MFAHandler) RemoveFactor(c *gin.Context) {
+    claims, ok := mfaClaims(c)
+    if !ok {
+        return
+    }
+    if err := h.mfa.RemoveFactor(c.Request.Context(), claims, c.Param("id")); err != nil {
+        mfaError(c, err)
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"id": c.Param("id"), "deleted": true})
+}
+
+// RegenerateRecoveryCodes replaces the caller's recovery codes; needs a
+// step-up. The codes are not shown again.
+//
+//    POST /api/v1/auth/mfa/recovery-codes
+func (h *MFAHandler) RegenerateRecoveryCodes(c *gin.Context) {
+    claims, ok := mfaClaims(c)
+    if !ok {
+        return
+    }
+    codes, err := h.mfa.RegenerateRecoveryCodes(c.Request.Context(), claims)
+    if err != nil {
+        mfaError(c, err)
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+// SetPolicy sets whether the tenant's members must use MFA
+//
+//    PUT /api/v1/admin/mfa-policy
+func (h *MFAHandler) SetPolicy(c *gin.Context) {
+    var req MFAPolicyRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    tenantID := audit.ActorFromContext(c.Request.Context()).TenantID
+    if err := h.tenantService.SetMFARequired(c.Request.Context(), tenantID, *req.Required); err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"mfa_required": *req.Required})
+}
+
+// MFA is for people; API keys cannot enroll or verify factors
+func mfaClaims(c *gin.Context) (*token.Claims, bool) {
+    claims, ok := c.Request.Context().Value(middleware.ClaimsKey).(*token.Claims)
+    if !ok {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated with a token"})
+        return nil, false
+    }
+    return claims, true
+}
+
+func mfaError(c *gin.Context, err error) {
+    refusalError(c, err, "Multi-factor authentication failed")
+}
@@ -0,0 +1,110 @@
+// auth-service/internal/handlers/scim_handler.go
+package handlers
+
+import (
+    "net/http"
+    "strconv"
+
+    "github.com/gin-gonic/gin"
+    "secure-iran-intel/pkg/refusal"
+    "secure-iran-intel/pkg/sso"
+)
+
+// SCIMHandler serves the SCIM 2.0 Users endpoint enterprise directories
+// provision and deprovision a tenant's users through. Directories
+// authenticate with an API key holding rbac.PermSCIM.
+type SCIMHandler struct {
+    sso *sso.Service
+}
+
+func NewSCIMHandler(service *sso.Service) *SCIMHandler {
+    return &SCIMHandler{sso: service}
+}
+
+const scimContentType = "application/scim+json"
+
+// ListUsers handles GET /scim/v2/Users?filter=userName eq "..."
+func (h *SCIMHandler) ListUsers(c *gin.Context) {
+    startIndex, _ := strconv.Atoi(c.Query("startIndex"))
+    count, _ := strconv.Atoi(c.Query("count"))
+    list, err := h.sso.SCIMListUsers(c.Request.Context(), c.Query("filter"), startIndex, count)
+    if err != nil {
+        scimError(c, err)
+        return
+    }
+    scimJSON(c, http.StatusOK, list)
+}
+
+// GetUser handles GET /scim/v2/Users/:id
+func (h *SCIMHandler) GetUser(c *gin.Context) {
+    user, err := h.sso.SCIMGetUser(c.Request.Context(), c.Param("id"))
+    if err != nil {
+        scimError(c, err)
+        return
+    }
+    scimJSON(c, http.StatusOK, user)
+}
+
+// CreateUser handles POST /scim/v2/Users
+func (h *SCIMHandler) CreateUser(c *gin.Context) {
+    var req sso.SCIMUser
+    if err := c.ShouldBindJSON(&req); err != nil {
+        scimError(c, &refusal.Error{Reason: sso.ErrInvalidRequest, Detail: err.Error()})
+        return
+    }
+    user, err := h.sso.SCIMCreateUser(c.Request.Context(), &req)
+    if err != nil {
+        scimError(c, err)
+        return
+    }
+    scimJSON(c, http.StatusCreated, user)
+}
+
+// ReplaceUser handles PUT /scim/v2/Users/:id
+func (h *SCIMHandler) ReplaceUser(c *gin.Context) {
+    var req sso.SCIMUser
+    if err := c.ShouldBindJSON(&req); err != nil {
+        scimError(c, &refusal.Error{Reason: sso.ErrInvalidRequest, Detail: err.Error()})
+        return
+    }
+    user, err := h.sso.SCIMReplaceUser(c.Request.Context(), c.Param("id"), &req)
+    if err != nil {
+        scimError(c, err)
+        return
+    }
+    scimJSON(c, http.StatusOK, user)
+}
+
+// PatchUser handles PATCH /scim/v2/Users/:id, typically active=false
+func (h *SCIMHandler) PatchUser(c *gin.Context) {
+    var req sso.SCIMPatch
+    if err := c.ShouldBindJSON(&req); err != nil {
+        scimError(c, &refusal.Error{Reason: sso.ErrInvalidRequest, Detail: err.Error()})
+        return
+    }
+    user, err := h.sso.SCIMPatchUser(c.Request.Context(), c.Param("id"), &req)
+    if err != nil {
+        scimError(c, err)
+        return
+    }
+    scimJSON(c, http.StatusOK, user)
+}
+
+// DeleteUser handles DELETE /scim/v2/Users/:id by deactivating the user
+func (h *SCIMHandler) DeleteUser(c *gin.Context) {
+    if err := h.sso.SCIMDeleteUser(c.Request.Context(), c.Param("id")); err != nil {
+        scimError(c, err)
+        return
+    }
+    c.Status(http.StatusNoContent)
+}
+
+func scimJSON(c *gin.Context, status int, body interface{}) {
+    c.Header("Content-Type", scimContentType)
+    c.JSON(status, body)
+}
+
+func scimError(c *gin.Context, err error) {
+    status := refusal.Status(err)
+    scimJSON(c, status, sso.NewSCIMError(err, status))
+}
@@ -0,0 +1,134 @@
+// auth-service/internal/handlers/sso_handler.go
+package handlers
+
+import (
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+    "secure-iran-intel/pkg/sso"
+)
+
+type SSOHandler struct {
+    sso *sso.Service
+}
+
+func NewSSOHandler(service *sso.Service) *SSOHandler {
+    return &SSOHandler{sso: service}
+}
+
+// ProviderRequest creates or updates an identity provider. The client
+// secret is write-only, so it is taken here rather than from sso.Provider.
+type ProviderRequest struct {
+    sso.Provider
+    ClientSecret string `json:"client_secret"`
+}
+
+// Login redirects the user to their tenant's identity provider
+//
+//    GET /api/v1/auth/sso/providers/:provider/login
+func (h *SSOHandler) Login(c *gin.Context) {
+    redirect, err := h.sso.Begin(c.Request.Context(), c.Param("provider"))
+    if err != nil {
+        ssoError(c, err)
+        return
+    }
+    c.Redirect(http.StatusFound, redirect)
+}
+
+// OIDCCallback finishes an OIDC sign-in and returns an access token
+//
+//    GET /api/v1/auth/sso/oidc/callback
+func (h *SSOHandler) OIDCCallback(c *gin.Context) {
+    if msg := c.Query("error"); msg != "" {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "Sign-in failed at the identity provider: " + msg, "code": sso.ErrAssertion.Code()})
+        return
+    }
+    signIn, err := h.sso.CompleteOIDC(c.Request.Context(), c.Query("state"), c.Query("code"))
+    if err != nil {
+        ssoError(c, err)
+        return
+    }
+    c.JSON(http.StatusOK, signIn)
+}
+
+// SAMLACS finishes a SAML sign-in and returns an access token
+//
+//    POST /api/v1/auth/sso/saml/acs
+func (h *SSOHandler) SAMLACS(c *gin.Context) {
+    signIn, err := h.sso.CompleteSAML(c.Request.Context(), c.Request)
+    if err != nil {
+        ssoError(c, err)
+        return
+    }
+    c.JSON(http.StatusOK, signIn)
+}
+
+// SAMLMetadata serves the service provider metadata to register at the IdP
+//
+//    GET /api/v1/auth/sso/providers/:provider/saml/metadata
+func (h *SSOHandler) SAMLMetadata(c *gin.Context) {
+    metadata, err := h.sso.SAMLMetadata(c.Request.Context(), c.Param("provider"))
+    if err != nil {
+        ssoError(c, err)
+        return
+    }
+    c.Data(http.StatusOK, "application/samlmetadata+xml", metadata)
+}
+
+// ListProviders lists the tenant's identity providers, without secrets
+func (h *SSOHandler) ListProviders(c *gin.Context) {
+    providers, err := h.sso.ListProviders(c.Request.Context())
+    if err != nil {
+        ssoError(c, err)
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"providers": providers})
+}
+
+// CreateProvider adds an identity provider to the tenant
+func (h *SSOHandler) CreateProvider(c *gin.Context) {
+    var req ProviderRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    p := req.Provider
+    p.ID = ""
+    p.ClientSecret = req.ClientSecret
+    if err := h.sso.SaveProvider(c.Request.Context(), &p); err != nil {
+        ssoError(c, err)
+        return
+    }
+    c.JSON(http.StatusCreated, p)
+}
+
+// UpdateProvider replaces an identity provider's configuration. Leaving
+// client_secret out keeps the current one.
+func (h *SSOHandler) UpdateProvider(c *gin.Context) {
+    var req ProviderRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    p := req.Provider
+    p.ID = c.Param("id")
+    p.ClientSecret = req.ClientSecret
+    if err := h.sso.SaveProvider(c.Request.Context(), &p); err != nil {
+        ssoError(c, err)
+        return
+    }
+    c.JSON(http.StatusOK, p)
+}
+
+// DeleteProvider removes an identity provider
+func (h *SSOHandler) DeleteProvider(c *gin.Context) {
+    if err := h.sso.DeleteProvider(c.Request.Context(), c.Param("id")); err != nil {
+        ssoError(c, err)
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"id": c.Param("id"), "deleted": true})
+}
+
+func ssoError(c *gin.Context, err error) {
+    refusalError(c, err, "Single sign-on failed")
+}
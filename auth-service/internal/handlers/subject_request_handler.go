@@ -0,0 +1,137 @@
+// auth-service/internal/handlers/subject_request_handler.go
+package handlers
+
+import (
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+    "secure-iran-intel/pkg/subject"
+)
+
+type SubjectRequestHandler struct {
+    subjects *subject.Service
+}
+
+func NewSubjectRequestHandler(service *subject.Service) *SubjectRequestHandler {
+    return &SubjectRequestHandler{subjects: service}
+}
+
+type RejectSubjectRequest struct {
+    Reason string `json:"reason" binding:"required"`
+}
+
+type ExtendSubjectRequest struct {
+    Days   int    `json:"days" binding:"required"`
+    Reason string `json:"reason" binding:"required"`
+}
+
+// ListRequests returns subject requests, soonest deadline first; ?status=
+// narrows them to open, completed or rejected
+//
+//    GET /api/v1/admin/subject-requests
+func (h *SubjectRequestHandler) ListRequests(c *gin.Context) {
+    requests, err := h.subjects.List(c.Request.Context(), c.Query("status"))
+    if err != nil {
+        subjectError(c, err)
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"subject_requests": requests})
+}
+
+// OpenRequest records a verified subject's access, rectification or
+// erasure request and starts its deadline
+//
+//    POST /api/v1/admin/subject-requests
+func (h *SubjectRequestHandler) OpenRequest(c *gin.Context) {
+    var req subject.NewRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    r, err := h.subjects.Open(c.Request.Context(), req)
+    if err != nil {
+        subjectError(c, err)
+        return
+    }
+    c.JSON(http.StatusCreated, r)
+}
+
+// GetRequest returns a subject request
+//
+//    GET /api/v1/admin/subject-requests/:id
+func (h *SubjectRequestHandler) GetRequest(c *gin.Context) {
+    r, err := h.subjects.Get(c.Request.Context(), c.Param("id"))
+    if err != nil {
+        subjectError(c, err)
+        return
+    }
+    c.JSON(http.StatusOK, r)
+}
+
+// Search lists everything every tenant holds about an open request's
+// subject, without acting on it
+//
+//    GET /api/v1/admin/subject-requests/:id/records
+func (h *SubjectRequestHandler) Search(c *gin.Context) {
+    export, err := h.subjects.Search(c.Request.Context(), c.Param("id"))
+    if err != nil {
+        subjectError(c, err)
+        return
+    }
+    c.JSON(http.StatusOK, export)
+}
+
+// Fulfil carries out the request and closes it. Access requests respond
+// with the export; erasures and rectifications with what was removed.
+//
+//    POST /api/v1/admin/subject-requests/:id/fulfil
+func (h *SubjectRequestHandler) Fulfil(c *gin.Context) {
+    r, export, err := h.subjects.Fulfil(c.Request.Context(), c.Param("id"))
+    if err != nil {
+        subjectError(c, err)
+        return
+    }
+    if export != nil {
+        c.JSON(http.StatusOK, gin.H{"subject_request": r, "export": export})
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"subject_request": r})
+}
+
+// Reject closes a request without acting on it
+//
+//    POST /api/v1/admin/subject-requests/:id/reject
+func (h *SubjectRequestHandler) Reject(c *gin.Context) {
+    var req RejectSubjectRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    r, err := h.subjects.Reject(c.Request.Context(), c.Param("id"), req.Reason)
+    if err != nil {
+        subjectError(c, err)
+        return
+    }
+    c.JSON(http.StatusOK, r)
+}
+
+// Extend moves a request's deadline back, once, by up to 60 days
+//
+//    POST /api/v1/admin/subject-requests/:id/extend
+func (h *SubjectRequestHandler) Extend(c *gin.Context) {
+    var req ExtendSubjectRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    r, err := h.subjects.Extend(c.Request.Context(), c.Param("id"), req.Days, req.Reason)
+    if err != nil {
+        subjectError(c, err)
+        return
+    }
+    c.JSON(http.StatusOK, r)
+}
+
+func subjectError(c *gin.Context, err error) {
+    refusalError(c, err, "Subject request failed")
+}
@@ -0,0 +1,64 @@
+// auth-service/internal/handlers/suppression_handler.go
+package handlers
+
+import (
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+    "secure-iran-intel/pkg/suppression"
+)
+
+type SuppressionHandler struct {
+    suppressions *suppression.Service
+}
+
+func NewSuppressionHandler(service *suppression.Service) *SuppressionHandler {
+    return &SuppressionHandler{suppressions: service}
+}
+
+// ListEntries returns the tenant's suppression list, removed entries
+// included. Platform operators see the global list.
+//
+//    GET /api/v1/suppressions
+func (h *SuppressionHandler) ListEntries(c *gin.Context) {
+    entries, err := h.suppressions.List(c.Request.Context())
+    if err != nil {
+        suppressionError(c, err)
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"suppressions": entries})
+}
+
+// AddEntry stops the tenant, or every tenant when added by a platform
+// operator, processing a number
+//
+//    POST /api/v1/suppressions
+func (h *SuppressionHandler) AddEntry(c *gin.Context) {
+    var req suppression.NewEntry
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    entry, err := h.suppressions.Add(c.Request.Context(), req)
+    if err != nil {
+        suppressionError(c, err)
+        return
+    }
+    c.JSON(http.StatusCreated, entry)
+}
+
+// RemoveEntry lets the tenant process the entry's number again
+//
+//    POST /api/v1/suppressions/:id/remove
+func (h *SuppressionHandler) RemoveEntry(c *gin.Context) {
+    entry, err := h.suppressions.Remove(c.Request.Context(), c.Param("id"))
+    if err != nil {
+        suppressionError(c, err)
+        return
+    }
+    c.JSON(http.StatusOK, entry)
+}
+
+func suppressionError(c *gin.Context, err error) {
+    refusalError(c, err, "Suppression list change failed")
+}
@@ -0,0 +1,90 @@
+// auth-service/internal/handlers/tenant_lifecycle_handler.go
+package handlers
+
+import (
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+    "secure-iran-intel/pkg/lifecycle"
+)
+
+type TenantLifecycleHandler struct {
+    lifecycle *lifecycle.Service
+}
+
+func NewTenantLifecycleHandler(service *lifecycle.Service) *TenantLifecycleHandler {
+    return &TenantLifecycleHandler{lifecycle: service}
+}
+
+type SuspendRequest struct {
+    Reason string `json:"reason" binding:"required"`
+}
+
+// GetTenant returns a tenant's lifecycle state
+//
+//    GET /api/v1/admin/tenants/:id/lifecycle
+func (h *TenantLifecycleHandler) GetTenant(c *gin.Context) {
+    tenant, err := h.lifecycle.Get(c.Request.Context(), c.Param("id"))
+    if err != nil {
+        lifecycleError(c, err)
+        return
+    }
+    c.JSON(http.StatusOK, tenant)
+}
+
+// Suspend blocks every user and key of a tenant until it is resumed
+//
+//    POST /api/v1/admin/tenants/:id/suspend
+func (h *TenantLifecycleHandler) Suspend(c *gin.Context) {
+    var req SuspendRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    if err := h.lifecycle.Suspend(c.Request.Context(), c.Param("id"), req.Reason); err != nil {
+        lifecycleError(c, err)
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"id": c.Param("id"), "status": lifecycle.StatusSuspended})
+}
+
+// Resume reactivates a suspended tenant
+//
+//    POST /api/v1/admin/tenants/:id/resume
+func (h *TenantLifecycleHandler) Resume(c *gin.Context) {
+    if err := h.lifecycle.Resume(c.Request.Context(), c.Param("id")); err != nil {
+        lifecycleError(c, err)
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"id": c.Param("id"), "status": lifecycle.StatusActive})
+}
+
+// Offboard exports the tenant's audit log, deletes all their data and
+// returns the signed deletion certificate. It runs to completion before
+// responding; a failed run can be retried.
+//
+//    POST /api/v1/admin/tenants/:id/offboard
+func (h *TenantLifecycleHandler) Offboard(c *gin.Context) {
+    cert, err := h.lifecycle.Offboard(c.Request.Context(), c.Param("id"))
+    if err != nil {
+        lifecycleError(c, err)
+        return
+    }
+    c.JSON(http.StatusOK, cert)
+}
+
+// Certificate returns an offboarded tenant's deletion certificate
+//
+//    GET /api/v1/admin/tenants/:id/deletion-certificate
+func (h *TenantLifecycleHandler) Certificate(c *gin.Context) {
+    cert, err := h.lifecycle.Certificate(c.Request.Context(), c.Param("id"))
+    if err != nil {
+        lifecycleError(c, err)
+        return
+    }
+    c.JSON(http.StatusOK, cert)
+}
+
+func lifecycleError(c *gin.Context, err error) {
+    refusalError(c, err, "Tenant lifecycle change failed")
+}
@@ -0,0 +1,73 @@
+// auth-service/internal/handlers/token_handler.go
+package handlers
+
+import (
+    "fmt"
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+    "secure-iran-intel/auth-service/internal/middleware"
+    "secure-iran-intel/auth-service/internal/services"
+    "secure-iran-intel/pkg/audit"
+    "secure-iran-intel/pkg/token"
+)
+
+type TokenHandler struct {
+    issuer        *token.Issuer
+    revocations   *token.Revocations
+    tenantService *services.TenantService
+}
+
+func NewTokenHandler(issuer *token.Issuer, revocations *token.Revocations, tenantService *services.TenantService) *TokenHandler {
+    return &TokenHandler{issuer: issuer, revocations: revocations, tenantService: tenantService}
+}
+
+// JWKS publishes the keys tokens are signed with. Gateways cache it for
+// token.JWKSCacheTTL.
+//
+//    GET /.well-known/jwks.json
+func (h *TokenHandler) JWKS(c *gin.Context) {
+    c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(token.JWKSCacheTTL.Seconds())))
+    c.JSON(http.StatusOK, h.issuer.JWKS())
+}
+
+// Logout revokes the token the request was made with, or with ?all=true
+// every token the user holds
+func (h *TokenHandler) Logout(c *gin.Context) {
+    ctx := c.Request.Context()
+    claims, ok := ctx.Value(middleware.ClaimsKey).(*token.Claims)
+    if !ok {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated with a token"})
+        return
+    }
+
+    var err error
+    if c.Query("all") == "true" {
+        err = h.revocations.RevokeUser(ctx, claims.TenantID, claims.UserID)
+    } else {
+        err = h.revocations.RevokeToken(ctx, claims)
+    }
+    if err != nil {
+        c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Failed to revoke token"})
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"logged_out": true})
+}
+
+// DisableUser deactivates a member of the caller's tenant and revokes
+// every token they hold
+func (h *TokenHandler) DisableUser(c *gin.Context) {
+    ctx := c.Request.Context()
+    tenantID := audit.ActorFromContext(ctx).TenantID
+    userID := c.Param("id")
+
+    if err := h.tenantService.DisableUser(ctx, tenantID, userID); err != nil {
+        c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+        return
+    }
+    if err := h.revocations.RevokeUser(ctx, tenantID, userID); err != nil {
+        c.JSON(http.StatusServiceUnavailable, gin.H{"error": "User disabled, but their tokens could not be revoked; retry"})
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"id": userID, "disabled": true})
+}
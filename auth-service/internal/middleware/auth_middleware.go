@@ -3,17 +3,24 @@ package middleware
 
 import (
     "context"
+    "errors"
     "net/http"
     "strings"
+    "time"
 
     "github.com/gin-gonic/gin"
-    "github.com/golang-jwt/jwt/v4"
+    "secure-iran-intel/pkg/apikey"
+    "secure-iran-intel/pkg/audit"
+    "secure-iran-intel/pkg/mfa"
+    "secure-iran-intel/pkg/rbac"
+    "secure-iran-intel/pkg/token"
 )
 
 type AuthMiddleware struct {
-    jwtSecret        string
+    tokens           *token.Verifier
     tenantService    *services.TenantService
-    userService      *services.UserService
+    keys             *apikey.Service
+    policy           *rbac.Engine
 }
 
 type ContextKey string
@@ -22,17 +29,22 @@ const (
     TenantIDKey ContextKey = "tenant_id"
     UserIDKey   ContextKey = "user_id"
     RoleKey     ContextKey = "role"
+    APIKeyKey   ContextKey = "api_key" // The *apikey.Key a request authenticated with
+    ClaimsKey   ContextKey = "claims"  // The *token.Claims of the access token
+    TenantKey   ContextKey = "tenant"  // The caller's *services.Tenant
 )
 
-func NewAuthMiddleware(jwtSecret string, tenantService *services.TenantService, userService *services.UserService) *AuthMiddleware {
+func NewAuthMiddleware(tokens *token.Verifier, tenantService *services.TenantService, keys *apikey.Service, policy *rbac.Engine) *AuthMiddleware {
     return &AuthMiddleware{
-        jwtSecret:     jwtSecret,
+        tokens:        tokens,
         tenantService: tenantService,
-        userService:   userService,
+        keys:          keys,
+        policy:        policy,
     }
 }
 
-// JWTAuthMiddleware validates JWT tokens and sets context
+// JWTAuthMiddleware validates access tokens and sets context. Only tokens
+// signed by the auth-service's published keys are accepted.
 func (am *AuthMiddleware) JWTAuthMiddleware() gin.HandlerFunc {
     return func(c *gin.Context) {
         authHeader := c.GetHeader("Authorization")
@@ -50,45 +62,25 @@ func (am *AuthMiddleware) JWTAuthMiddleware() gin.HandlerFunc {
             return
         }
 
-        tokenString := parts[1]
-
-        // Parse and validate token
-        token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-            if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-                return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-            }
-            return []byte(am.jwtSecret), nil
-        })
-
-        if err != nil || !token.Valid {
-            c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
-            c.Abort()
-            return
-        }
-
-        claims, ok := token.Claims.(jwt.MapClaims)
-        if !ok {
-            c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+        // Signature, issuer, audience, expiry and revocation
+        claims, err := am.tokens.Verify(c.Request.Context(), parts[1])
+        if errors.Is(err, token.ErrRevoked) {
+            c.JSON(http.StatusUnauthorized, gin.H{"error": "Token revoked"})
             c.Abort()
             return
         }
-
-        // Extract claims
-        tenantID, ok := claims["tenant_id"].(string)
-        if !ok {
-            c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid tenant ID in token"})
+        if errors.Is(err, token.ErrInvalidToken) {
+            c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
             c.Abort()
             return
         }
-
-        userID, ok := claims["user_id"].(string)
-        if !ok {
-            c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID in token"})
+        if err != nil {
+            // Fail closed when revocation cannot be checked
+            c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Token verification unavailable"})
             c.Abort()
             return
         }
-
-        role, _ := claims["role"].(string)
+        tenantID, userID, role := claims.TenantID, claims.UserID, claims.Role
 
         // Verify tenant is active
         tenant, err := am.tenantService.GetTenantByID(c.Request.Context(), tenantID)
@@ -102,6 +94,9 @@ func (am *AuthMiddleware) JWTAuthMiddleware() gin.HandlerFunc {
         ctx := context.WithValue(c.Request.Context(), TenantIDKey, tenantID)
         ctx = context.WithValue(ctx, UserIDKey, userID)
         ctx = context.WithValue(ctx, RoleKey, role)
+        ctx = context.WithValue(ctx, ClaimsKey, claims)
+        ctx = context.WithValue(ctx, TenantKey, tenant)
+        ctx = audit.WithActor(ctx, audit.Actor{TenantID: tenantID, Type: audit.ActorUser, ID: userID})
         
         c.Request = c.Request.WithContext(ctx)
         c.Next()
@@ -118,59 +113,112 @@ func (am *AuthMiddleware) APIKeyAuthMiddleware() gin.HandlerFunc {
             return
         }
 
-        // Hash the provided API key
-        hashedKey := am.hashAPIKey(apiKey)
-
-        // Look up API key in database
-        apiKeyRecord, err := am.userService.GetAPIKeyByHash(c.Request.Context(), hashedKey)
-        if err != nil || !apiKeyRecord.IsActive {
+        // Revoked, expired and ownerless keys are refused; use is recorded
+        // in the background by the key service
+        key, err := am.keys.Authenticate(c.Request.Context(), apiKey)
+        if errors.Is(err, apikey.ErrExpired) {
+            c.JSON(http.StatusUnauthorized, gin.H{"error": "API key expired", "code": apikey.ErrExpired.Code()})
+            c.Abort()
+            return
+        }
+        if err != nil {
             c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid API key"})
             c.Abort()
             return
         }
 
-        // Check if API key has expired
-        if apiKeyRecord.ExpiresAt != nil && apiKeyRecord.ExpiresAt.Before(time.Now()) {
-            c.JSON(http.StatusUnauthorized, gin.H{"error": "API key expired"})
+        // Verify tenant is active
+        tenant, err := am.tenantService.GetTenantByID(c.Request.Context(), key.TenantID)
+        if err != nil || tenant.Status != "active" {
+            c.JSON(http.StatusUnauthorized, gin.H{"error": "Tenant not active"})
             c.Abort()
             return
         }
 
-        // Update last used timestamp
-        go am.userService.UpdateAPIKeyLastUsed(c.Request.Context(), apiKeyRecord.ID)
-
-        // Set context
-        ctx := context.WithValue(c.Request.Context(), TenantIDKey, apiKeyRecord.TenantID)
-        ctx = context.WithValue(ctx, UserIDKey, "") // No user for API keys
+        // Keys act for their owner, within their scopes
+        ctx := context.WithValue(c.Request.Context(), TenantIDKey, key.TenantID)
+        ctx = context.WithValue(ctx, UserIDKey, key.OwnerID)
         ctx = context.WithValue(ctx, RoleKey, "api_key")
+        ctx = context.WithValue(ctx, APIKeyKey, key)
+        ctx = context.WithValue(ctx, TenantKey, tenant)
+        ctx = audit.WithActor(ctx, audit.Actor{TenantID: key.TenantID, Type: audit.ActorAPIKey, ID: key.ID, OnBehalfOf: key.OwnerID})
         
         c.Request = c.Request.WithContext(ctx)
         c.Next()
     }
 }
 
-// PermissionMiddleware checks if user has required permissions
+// MFAPolicyMiddleware refuses access tokens without a verified second
+// factor when the caller's tenant requires MFA. It runs after
+// JWTAuthMiddleware; the MFA enrollment routes must not use it.
+func (am *AuthMiddleware) MFAPolicyMiddleware() gin.HandlerFunc {
+    return func(c *gin.Context) {
+        ctx := c.Request.Context()
+        tenant, _ := ctx.Value(TenantKey).(*services.Tenant)
+        claims, _ := ctx.Value(ClaimsKey).(*token.Claims)
+        if tenant != nil && claims != nil && tenant.MFARequired() && !claims.HasMFA() {
+            c.JSON(http.StatusForbidden, gin.H{
+                "error": "Your organization requires multi-factor authentication",
+                "code":  mfa.ErrMFARequired.Code(),
+            })
+            c.Abort()
+            return
+        }
+
+        c.Next()
+    }
+}
+
+// PermissionMiddleware checks the caller's tenant roles grant the required
+// permission on at least some resources. Handlers check the resource itself
+// once it is loaded. API keys also need the permission in their scopes.
+// Sensitive permissions (rbac.RequiresStepUp) also need a recent step-up.
 func (am *AuthMiddleware) PermissionMiddleware(requiredPermission string) gin.HandlerFunc {
     return func(c *gin.Context) {
         ctx := c.Request.Context()
-        role, ok := ctx.Value(RoleKey).(string)
-        if !ok {
-            c.JSON(http.StatusForbidden, gin.H{"error": "Role not found in context"})
+        actor := audit.ActorFromContext(ctx)
+        if actor.Type != audit.ActorUser && actor.Type != audit.ActorAPIKey {
+            c.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
             c.Abort()
             return
         }
 
-        // Get role permissions
-        permissions, err := am.userService.GetRolePermissions(ctx, role)
+        if rbac.RequiresStepUp(requiredPermission) {
+            // Keys cannot re-authenticate, so they cannot be used for these
+            claims, ok := ctx.Value(ClaimsKey).(*token.Claims)
+            if !ok || actor.Type != audit.ActorUser {
+                c.JSON(http.StatusForbidden, gin.H{"error": requiredPermission + " needs a signed-in user"})
+                c.Abort()
+                return
+            }
+            if !claims.SteppedUp(time.Now()) {
+                c.JSON(http.StatusForbidden, gin.H{
+                    "error": "Verify your second factor again to continue",
+                    "code":  mfa.ErrStepUpRequired.Code(),
+                })
+                c.Abort()
+                return
+            }
+        }
+
+        if actor.Type == audit.ActorAPIKey {
+            key, ok := ctx.Value(APIKeyKey).(*apikey.Key)
+            if !ok || !key.Allows(requiredPermission) {
+                c.JSON(http.StatusForbidden, gin.H{"error": "API key is not scoped for " + requiredPermission})
+                c.Abort()
+                return
+            }
+        }
+
+        decision, err := am.policy.AuthorizeActor(ctx, actor, requiredPermission, nil)
         if err != nil {
             c.JSON(http.StatusForbidden, gin.H{"error": "Failed to get permissions"})
             c.Abort()
             return
         }
 
-        // Check if role has required permission
-        if !am.hasPermission(permissions, requiredPermission) {
-            c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+        if !decision.Allowed {
+            c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions", "reason": decision.Reason})
             c.Abort()
             return
         }
@@ -178,18 +226,3 @@ func (am *AuthMiddleware) PermissionMiddleware(requiredPermission string) gin.Ha
         c.Next()
     }
 }
-
-func (am *AuthMiddleware) hasPermission(permissions map[string]bool, required string) bool {
-    // Check for wildcard permission
-    if permissions["*"] {
-        return true
-    }
-
-    // Check specific permission
-    return permissions[required]
-}
-
-func (am *AuthMiddleware) hashAPIKey(apiKey string) string {
-    hash := sha256.Sum256([]byte(apiKey))
-    return hex.EncodeToString(hash[:])
-}
@@ -7,6 +7,8 @@ import (
     "time"
 
     "gorm.io/gorm"
+    "secure-iran-intel/pkg/rbac"
+    "secure-iran-intel/pkg/tenancy"
 )
 
 type TenantService struct {
@@ -77,35 +79,31 @@ func (ts *TenantService) CreateTenant(ctx context.Context, req *CreateTenantRequ
 }
 
 func (ts *TenantService) createDefaultRoles(ctx context.Context, tenantID string) error {
-    defaultRoles := []Role{
-        {
-            TenantID:      tenantID,
-            Name:          "admin",
-            Permissions:   JSONMap{"*": true},
-            IsSystemRole:  true,
-        },
-        {
-            TenantID:     tenantID,
-            Name:         "user",
-            Permissions:  JSONMap{
-                "phone_lookup:execute": true,
-                "reports:read":         true,
-                "exports:read":         true,
-            },
-            IsSystemRole: true,
-        },
-        {
-            TenantID:     tenantID,
-            Name:         "viewer", 
-            Permissions:  JSONMap{
-                "reports:read": true,
-                "exports:read": true,
-            },
-            IsSystemRole: true,
-        },
-    }
+    return rbac.NewGormStore(ts.db).SaveRoles(ctx, rbac.DefaultRoles(tenantID))
+}
 
-    return ts.db.WithContext(ctx).Create(&defaultRoles).Error
+// MFARequired reports whether the tenant's members must verify a second
+// factor before using the API
+func (t *Tenant) MFARequired() bool {
+    required, _ := t.Settings["mfa_required"].(bool)
+    return required
+}
+
+// SetMFARequired sets the tenant's MFA policy
+func (ts *TenantService) SetMFARequired(ctx context.Context, tenantID string, required bool) error {
+    result := ts.db.WithContext(ctx).Model(&Tenant{}).
+        Where("id = ?", tenantID).
+        Updates(map[string]interface{}{
+            "settings":   gorm.Expr("jsonb_set(COALESCE(settings, '{}'::jsonb), '{mfa_required}', to_jsonb(?::boolean))", required),
+            "updated_at": time.Now(),
+        })
+    if result.Error != nil {
+        return fmt.Errorf("failed to update MFA policy: %w", result.Error)
+    }
+    if result.RowsAffected == 0 {
+        return fmt.Errorf("tenant not found")
+    }
+    return nil
 }
 
 // GetTenantBySlug retrieves tenant by slug
@@ -117,24 +115,45 @@ func (ts *TenantService) GetTenantBySlug(ctx context.Context, slug string) (*Ten
     return &tenant, nil
 }
 
+// DisableUser stops a member signing in or acting through their API keys.
+// Tokens already issued must be revoked as well.
+func (ts *TenantService) DisableUser(ctx context.Context, tenantID, userID string) error {
+    var disabled int64
+    err := tenancy.Transaction(ctx, ts.db, tenantID, func(tx *gorm.DB) error {
+        result := tx.Table("users").
+            Where("id = ? AND tenant_id = ?", userID, tenantID).
+            Updates(map[string]interface{}{"is_active": false, "updated_at": time.Now()})
+        disabled = result.RowsAffected
+        return result.Error
+    })
+    if err != nil {
+        return fmt.Errorf("failed to disable user: %w", err)
+    }
+    if disabled == 0 {
+        return fmt.Errorf("user not found")
+    }
+    return nil
+}
+
 // GetTenantUsage returns current usage for a tenant
 func (ts *TenantService) GetTenantUsage(ctx context.Context, tenantID string) (*TenantUsage, error) {
     currentMonth := time.Now().UTC().Format("2006-01-01")
     
     var usage TenantUsage
-    err := ts.db.WithContext(ctx).
-        Where("tenant_id = ? AND month = ?", tenantID, currentMonth).
-        First(&usage).Error
-
-    if err != nil {
-        // Create usage record if it doesn't exist
-        usage = TenantUsage{
-            TenantID:   tenantID,
-            Month:      currentMonth,
-        }
-        if createErr := ts.db.WithContext(ctx).Create(&usage).Error; createErr != nil {
-            return nil, createErr
+    err := tenancy.Transaction(ctx, ts.db, tenantID, func(tx *gorm.DB) error {
+        err := tx.Where("tenant_id = ? AND month = ?", tenantID, currentMonth).First(&usage).Error
+        if err != nil {
+            // Create usage record if it doesn't exist
+            usage = TenantUsage{
+                TenantID:   tenantID,
+                Month:      currentMonth,
+            }
+            return tx.Create(&usage).Error
         }
+        return nil
+    })
+    if err != nil {
+        return nil, err
     }
 
     return &usage, nil
@@ -174,10 +193,12 @@ func (ts *TenantService) CheckRateLimit(ctx context.Context, tenantID string, en
 func (ts *TenantService) IncrementUsage(ctx context.Context, tenantID string, usageType string, count int64) error {
     currentMonth := time.Now().UTC().Format("2006-01-01")
     
-    return ts.db.WithContext(ctx).Model(&TenantUsage{}).
-        Where("tenant_id = ? AND month = ?", tenantID, currentMonth).
-        UpdateColumn(usageType, gorm.Expr(fmt.Sprintf("%s + ?", usageType), count)).
-        Error
+    return tenancy.Transaction(ctx, ts.db, tenantID, func(tx *gorm.DB) error {
+        return tx.Model(&TenantUsage{}).
+            Where("tenant_id = ? AND month = ?", tenantID, currentMonth).
+            UpdateColumn(usageType, gorm.Expr(fmt.Sprintf("%s + ?", usageType), count)).
+            Error
+    })
 }
 
 func getAllowedPlatformsForPlan(planType string) []string {
@@ -206,6 +227,22 @@ func getMaxConcurrentJobsForPlan(planType string) int {
     }
 }
 
+// getDataRetentionForPlan is how many days jobs, results and reports are
+// kept before pkg/retention removes them. Migration 019 applies the same
+// periods to tenants created before the setting existed.
+func getDataRetentionForPlan(planType string) int {
+    switch planType {
+    case "enterprise":
+        return 365
+    case "professional":
+        return 180
+    case "starter":
+        return 90
+    default:
+        return 30
+    }
+}
+
 func getRateLimitsForPlan(planType string) map[string]int {
     switch planType {
     case "enterprise":
@@ -0,0 +1,69 @@
+// cmd/audit-verify/main.go
+package main
+
+import (
+    "context"
+    "encoding/json"
+    "flag"
+    "fmt"
+    "log"
+    "os"
+    "strconv"
+    "strings"
+
+    "secure-iran-intel/pkg/audit"
+)
+
+// Verifies the audit log hash chain. Exits 1 if any record was edited,
+// removed or relinked, printing the problems and the current head as JSON.
+//
+//   audit-verify -store postgres://... -anchor 1200:3f9a... -anchor 5400:77c1...
+func main() {
+    storeURL := flag.String("store", os.Getenv("AUDIT_STORE_URL"), "audit store URL (postgres://... or file:///path)")
+    var anchors anchorFlags
+    flag.Var(&anchors, "anchor", "previously published head as seq:hash (repeatable)")
+    flag.Parse()
+
+    if *storeURL == "" {
+        log.Fatal("No audit store given; set -store or AUDIT_STORE_URL")
+    }
+
+    store, err := audit.Open(*storeURL)
+    if err != nil {
+        log.Fatalf("Failed to open audit store: %v", err)
+    }
+
+    result, err := audit.Verify(context.Background(), store, anchors...)
+    if err != nil {
+        log.Fatalf("Failed to read audit log: %v", err)
+    }
+
+    encoder := json.NewEncoder(os.Stdout)
+    encoder.SetIndent("", "  ")
+    encoder.Encode(result)
+
+    if !result.OK() {
+        log.Printf("❌ Audit log verification failed: %d problem(s)", len(result.Problems))
+        os.Exit(1)
+    }
+    log.Printf("✅ Audit log intact: %d records, head %d %s", result.Records, result.HeadSeq, result.HeadHash)
+}
+
+type anchorFlags []audit.Anchor
+
+func (af *anchorFlags) String() string {
+    return ""
+}
+
+func (af *anchorFlags) Set(value string) error {
+    seq, hash, ok := strings.Cut(value, ":")
+    if !ok {
+        return fmt.Errorf("anchor %q is not seq:hash", value)
+    }
+    n, err := strconv.ParseInt(seq, 10, 64)
+    if err != nil {
+        return err
+    }
+    *af = append(*af, audit.Anchor{Seq: n, Hash: hash})
+    return nil
+}
@@ -0,0 +1,64 @@
+// cmd/keys-rotate/main.go
+package main
+
+import (
+    "context"
+    "flag"
+    "log"
+    "os"
+
+    "gorm.io/driver/postgres"
+    "gorm.io/gorm"
+
+    "secure-iran-intel/pkg/envelope"
+)
+
+// Rotates the master key that wraps tenant data keys. A new master key is
+// made current, every data key is re-wrapped under it, and with -retire
+// the old master keys are deleted once nothing is wrapped under them.
+// Encrypted rows are not rewritten. Run it with the services stopped or
+// restart them afterwards, so they load the new key file.
+//
+//   keys-rotate -db postgres://... -master /etc/secure-iran-intel/master-keys.json -retire
+func main() {
+    dbURL := flag.String("db", os.Getenv("DATABASE_URL"), "Postgres URL, connecting as a member of intel_system")
+    masterPath := flag.String("master", os.Getenv("MASTER_KEY_FILE"), "master key file")
+    retire := flag.Bool("retire", false, "delete the old master keys after re-wrapping")
+    flag.Parse()
+
+    if *dbURL == "" || *masterPath == "" {
+        log.Fatal("Set -db and -master, or DATABASE_URL and MASTER_KEY_FILE")
+    }
+
+    db, err := gorm.Open(postgres.Open(*dbURL), &gorm.Config{})
+    if err != nil {
+        log.Fatalf("Failed to connect to database: %v", err)
+    }
+    kms, err := envelope.NewFileKMS(*masterPath)
+    if err != nil {
+        log.Fatalf("Failed to load master keys: %v", err)
+    }
+
+    previous := kms.KeyIDs()
+    current, err := kms.Rotate()
+    if err != nil {
+        log.Fatalf("Failed to create master key: %v", err)
+    }
+    log.Printf("🔑 New master key %s", current)
+
+    moved, err := envelope.NewKeyring(envelope.NewGormKeyStore(db), kms).Rewrap(context.Background())
+    if err != nil {
+        // The old keys stay in the file; running again finishes the job
+        log.Fatalf("❌ Re-wrapped %d data keys before failing: %v", moved, err)
+    }
+    log.Printf("✅ Re-wrapped %d data keys", moved)
+
+    if *retire {
+        for _, id := range previous {
+            if err := kms.Retire(id); err != nil {
+                log.Fatalf("Failed to retire master key %s: %v", id, err)
+            }
+            log.Printf("🗑️ Retired master key %s", id)
+        }
+    }
+}
@@ -0,0 +1,153 @@
+// cmd/seal-backfill/main.go
+package main
+
+import (
+    "context"
+    "flag"
+    "log"
+    "os"
+
+    "gorm.io/driver/postgres"
+    "gorm.io/gorm"
+
+    "secure-iran-intel/pkg/envelope"
+    "secure-iran-intel/pkg/results"
+    "secure-iran-intel/pkg/tenancy"
+)
+
+// tables are the columns sealed since migration 020. Redacted rows hold
+// placeholders only and are left alone.
+var tables = []envelope.Table{
+    {
+        Name: "intelligence_reports",
+        Columns: []envelope.Column{
+            {Name: "phone_number", Index: "phone_number_index"},
+            {Name: "report_data"},
+            {Name: "executive_summary"},
+        },
+        Where: "redacted_at IS NULL",
+    },
+    {
+        Name: "email_discovery_results",
+        Columns: []envelope.Column{
+            {Name: "phone_number", Index: "phone_number_index"},
+            {Name: "email", Index: "email_index", Normalize: results.NormalizeEmail},
+            {Name: "social_profiles"},
+        },
+        Where: "redacted_at IS NULL",
+    },
+    {
+        Name: "social_graphs",
+        Columns: []envelope.Column{
+            {Name: "phone_number", Index: "phone_number_index"},
+            {Name: "graph_data"},
+            {Name: "central_node"},
+        },
+        Where: "redacted_at IS NULL",
+    },
+    {
+        Name: "behavioral_patterns",
+        Columns: []envelope.Column{
+            {Name: "user_identifier", Index: "user_identifier_index"},
+            {Name: "pattern_data"},
+        },
+        Where: "redacted_at IS NULL",
+    },
+    {
+        Name: "risk_assessments",
+        Columns: []envelope.Column{
+            {Name: "phone_number", Index: "phone_number_index"},
+            {Name: "factors"},
+        },
+        Where: "redacted_at IS NULL",
+    },
+    {
+        Name:    "bulk_jobs",
+        Columns: []envelope.Column{{Name: "name"}, {Name: "justification"}},
+        Where:   "redacted_at IS NULL",
+    },
+    {
+        Name:    "mfa_factors",
+        Columns: []envelope.Column{{Name: "secret"}},
+    },
+    {
+        Name:    "signing_keys",
+        Columns: []envelope.Column{{Name: "private_key"}},
+        Tenant:  tenancy.PlatformTenantID,
+    },
+}
+
+// plaintextIndexes are what migration 020 kept for rows written before
+// encryption
+var plaintextIndexes = []string{
+    "DROP INDEX IF EXISTS idx_intelligence_reports_phone",
+    "DROP INDEX IF EXISTS idx_email_discovery_phone",
+    "DROP INDEX IF EXISTS idx_email_discovery_email",
+    "DROP INDEX IF EXISTS idx_social_graphs_phone",
+    "DROP INDEX IF EXISTS idx_risk_assessments_phone",
+    "DROP INDEX IF EXISTS idx_behavioral_patterns_user",
+    "ALTER TABLE email_discovery_results DROP CONSTRAINT IF EXISTS email_discovery_results_tenant_id_phone_number_email_key",
+}
+
+// Seals the rows written before field encryption (migration 020) and fills
+// in their blind indexes, then drops the indexes on plaintext values. Rows
+// are sealed with their tenant's data key, which is created if need be.
+// Run it once after upgrading; the services read plaintext rows meanwhile,
+// and running it again picks up where it stopped. Once it finishes it
+// records so (migration 023), and the services refuse plaintext from their
+// next start. It drops indexes, so it connects as the owner of the tables,
+// the user the migrations ran as.
+//
+//   seal-backfill -db postgres://... -master /etc/secure-iran-intel/master-keys.json
+func main() {
+    dbURL := flag.String("db", os.Getenv("DATABASE_URL"), "Postgres URL, connecting as the owner of the tables")
+    masterPath := flag.String("master", os.Getenv("MASTER_KEY_FILE"), "master key file")
+    batch := flag.Int("batch", 500, "rows sealed per transaction")
+    flag.Parse()
+
+    if *dbURL == "" || *masterPath == "" {
+        log.Fatal("Set -db and -master, or DATABASE_URL and MASTER_KEY_FILE")
+    }
+
+    db, err := gorm.Open(postgres.Open(*dbURL), &gorm.Config{})
+    if err != nil {
+        log.Fatalf("Failed to connect to database: %v", err)
+    }
+    kms, err := envelope.NewFileKMS(*masterPath)
+    if err != nil {
+        log.Fatalf("Failed to load master keys: %v", err)
+    }
+    keyring := envelope.NewKeyring(envelope.NewGormKeyStore(db), kms)
+
+    ctx := context.Background()
+    var skipped int64
+    for _, t := range tables {
+        result, err := keyring.Backfill(ctx, db, t, *batch)
+        if err != nil {
+            // Sealed batches stay sealed; running again finishes the job
+            log.Fatalf("❌ Sealed %d rows of %s before failing: %v", result.Sealed, t.Name, err)
+        }
+        log.Printf("✅ %s: sealed %d rows, removed %d duplicates, skipped %d of shredded tenants",
+            t.Name, result.Sealed, result.Duplicates, result.Skipped)
+        skipped += result.Skipped
+    }
+
+    // Rows of shredded tenants stay in plaintext but are refused all the
+    // same, as their sealed rows are
+    if err := envelope.MarkBackfilled(ctx, db); err != nil {
+        log.Fatalf("Failed to record the backfill: %v", err)
+    }
+
+    // Rows of shredded tenants cannot be sealed any more and still use the
+    // plaintext indexes
+    if skipped > 0 {
+        log.Printf("⚠️ Left the plaintext indexes in place for %d rows of shredded tenants", skipped)
+        return
+    }
+    for _, statement := range plaintextIndexes {
+        if err := db.Exec(statement).Error; err != nil {
+            log.Fatalf("Failed to drop plaintext index: %v", err)
+        }
+    }
+    log.Printf("🗑️ Dropped %d plaintext indexes", len(plaintextIndexes))
+}
@@ -0,0 +1,39 @@
+-- database/migrations/009_audit_log.up.sql
+
+-- Append-only, hash-chained audit log (see pkg/audit). seq is assigned by
+-- the writer under an advisory lock so it has no gaps; a gap means rows
+-- were removed.
+CREATE TABLE audit_log (
+    seq BIGINT PRIMARY KEY,
+    recorded_at TIMESTAMPTZ NOT NULL,
+    tenant_id VARCHAR(64) NOT NULL DEFAULT '', -- Empty for system actions
+    actor_type VARCHAR(20) NOT NULL, -- user, api_key, system
+    actor_id VARCHAR(255) NOT NULL DEFAULT '',
+    action VARCHAR(255) NOT NULL,
+    target_type VARCHAR(50) NOT NULL,
+    target_hash CHAR(64) NOT NULL, -- SHA-256 of target_type:value, never the value itself
+    justification TEXT NOT NULL DEFAULT '',
+    metadata JSONB,
+    prev_hash CHAR(64) NOT NULL,
+    hash CHAR(64) NOT NULL UNIQUE
+);
+
+CREATE INDEX idx_audit_log_tenant ON audit_log(tenant_id, recorded_at);
+CREATE INDEX idx_audit_log_target ON audit_log(target_hash);
+CREATE INDEX idx_audit_log_actor ON audit_log(actor_type, actor_id);
+
+-- Rows can only be added. Verification still catches changes made by
+-- anyone able to drop these triggers.
+CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_no_update
+    BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
+
+CREATE TRIGGER audit_log_no_truncate
+    BEFORE TRUNCATE ON audit_log
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_log_append_only();
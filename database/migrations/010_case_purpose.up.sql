@@ -0,0 +1,25 @@
+-- database/migrations/010_case_purpose.up.sql
+
+-- Cases a tenant has opened; every job must cite one (see pkg/purpose)
+CREATE TABLE cases (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL,
+    reference VARCHAR(100) NOT NULL,
+    title VARCHAR(255),
+    legal_bases JSONB NOT NULL DEFAULT '[]', -- court_order, criminal_investigation, ...
+    status VARCHAR(20) DEFAULT 'open', -- open, closed
+    opened_by VARCHAR(255),
+    expires_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    UNIQUE(tenant_id, reference)
+);
+
+CREATE INDEX idx_cases_tenant_status ON cases(tenant_id, status);
+
+-- Bulk jobs record the purpose they were run under
+ALTER TABLE bulk_jobs
+    ADD COLUMN case_reference VARCHAR(100),
+    ADD COLUMN legal_basis VARCHAR(50),
+    ADD COLUMN justification TEXT;
@@ -0,0 +1,43 @@
+-- database/migrations/011_approvals.up.sql
+
+-- Per-tenant four-eyes policy (see pkg/approval); tenants without a row get
+-- the defaults: more than 100 numbers needs approval, 48h to decide
+CREATE TABLE approval_policies (
+    tenant_id UUID PRIMARY KEY,
+    max_unapproved_size INTEGER NOT NULL DEFAULT 100,
+    flagged_categories JSONB NOT NULL DEFAULT '[]', -- platforms, legal bases
+    deadline_hours INTEGER NOT NULL DEFAULT 48,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE
+);
+
+-- Jobs held for a second user's decision
+CREATE TABLE approval_requests (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL,
+    job_id VARCHAR(100) NOT NULL,
+    job_type VARCHAR(50) NOT NULL, -- intelligence_job, bulk_job
+    requested_by_type VARCHAR(20) NOT NULL,
+    requested_by VARCHAR(255) NOT NULL,
+    trigger TEXT NOT NULL,
+    size INTEGER NOT NULL,
+    categories JSONB NOT NULL DEFAULT '[]',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending', -- pending, approved, rejected, expired
+    decided_by VARCHAR(255),
+    decision_reason TEXT,
+    decided_at TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    -- A requester can never approve their own job
+    CHECK (status <> 'approved' OR decided_by <> requested_by),
+    CHECK (status NOT IN ('approved', 'rejected') OR decision_reason <> '')
+);
+
+CREATE INDEX idx_approval_requests_job ON approval_requests(tenant_id, job_id);
+CREATE INDEX idx_approval_requests_pending ON approval_requests(job_type, expires_at) WHERE status = 'pending';
+
+-- Tenant admins can approve; plain users cannot
+UPDATE roles
+SET permissions = permissions || '["approvals:grant"]'::jsonb
+WHERE tenant_id = '00000000-0000-0000-0000-000000000000' AND name = 'admin';
@@ -0,0 +1,83 @@
+-- database/migrations/012_rbac.up.sql
+
+-- Teams, for rules scoped to a team's jobs
+CREATE TABLE teams (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    UNIQUE(tenant_id, name)
+);
+
+ALTER TABLE users ADD COLUMN team_id UUID REFERENCES teams(id) ON DELETE SET NULL;
+
+-- Roles become rule lists with inheritance (see pkg/rbac)
+ALTER TABLE roles
+    ADD COLUMN inherits JSONB NOT NULL DEFAULT '[]',
+    ADD COLUMN rules JSONB NOT NULL DEFAULT '[]';
+
+-- Flat permissions become tenant-wide allow rules. Migration 007 stored
+-- arrays; roles created by the auth service stored {"permission": true}.
+UPDATE roles SET rules = COALESCE((
+    SELECT jsonb_agg(jsonb_build_object('effect', 'allow', 'permission', p, 'scope', 'tenant'))
+    FROM (
+        SELECT jsonb_array_elements_text(CASE WHEN jsonb_typeof(permissions) = 'array' THEN permissions ELSE '[]' END) AS p
+        UNION ALL
+        SELECT key FROM jsonb_each(CASE WHEN jsonb_typeof(permissions) = 'object' THEN permissions ELSE '{}' END)
+        WHERE value = 'true'::jsonb
+    ) flat
+), '[]');
+
+-- The system roles take the rules of rbac.DefaultRoles. Their flat
+-- permissions predate jobs:create, so users could not create jobs and
+-- admins could not do most of what the admin role now covers.
+INSERT INTO roles (tenant_id, name, permissions, is_system_role)
+VALUES ('00000000-0000-0000-0000-000000000000', 'viewer', '[]', TRUE)
+ON CONFLICT (tenant_id, name) DO NOTHING;
+
+UPDATE roles SET inherits = '[]', rules = '[
+    {"effect": "allow", "permission": "reports:read"},
+    {"effect": "allow", "permission": "exports:read"}
+]'
+WHERE tenant_id = '00000000-0000-0000-0000-000000000000' AND name = 'viewer';
+
+UPDATE roles SET inherits = '["viewer"]', rules = '[
+    {"effect": "allow", "permission": "phone_lookup:execute"},
+    {"effect": "allow", "permission": "jobs:create"},
+    {"effect": "allow", "permission": "jobs:bulk"},
+    {"effect": "allow", "permission": "jobs:read", "scope": "own"},
+    {"effect": "allow", "permission": "api_keys:manage", "scope": "own"}
+]'
+WHERE tenant_id = '00000000-0000-0000-0000-000000000000' AND name = 'user';
+
+UPDATE roles SET inherits = '["user"]', rules = '[
+    {"effect": "allow", "permission": "*"}
+]'
+WHERE tenant_id = '00000000-0000-0000-0000-000000000000' AND name = 'admin';
+
+-- Roles were looked up by name alone, so every tenant shared the system
+-- tenant's "admin". Give each tenant its own copy; lookups no longer fall
+-- back to the system tenant.
+INSERT INTO roles (tenant_id, name, permissions, inherits, rules, is_system_role)
+SELECT t.id, r.name, r.permissions, r.inherits, r.rules, TRUE
+FROM tenants t
+CROSS JOIN roles r
+WHERE r.tenant_id = '00000000-0000-0000-0000-000000000000'
+ON CONFLICT (tenant_id, name) DO NOTHING;
+
+-- Tenants that already had their own copies of the system roles get the
+-- same rules
+UPDATE roles r SET inherits = s.inherits, rules = s.rules
+FROM roles s
+WHERE s.tenant_id = '00000000-0000-0000-0000-000000000000'
+  AND s.name IN ('viewer', 'user', 'admin')
+  AND r.name = s.name
+  AND r.is_system_role
+  AND r.tenant_id <> s.tenant_id;
+
+-- The flat column is kept for rollback only
+ALTER TABLE roles ALTER COLUMN permissions DROP NOT NULL;
+COMMENT ON COLUMN roles.permissions IS 'Deprecated: superseded by rules (migration 012)';
+
+CREATE INDEX idx_users_team ON users(team_id);
@@ -0,0 +1,97 @@
+-- database/migrations/013_row_level_security.up.sql
+
+-- Tenant isolation enforced by Postgres rather than by each query
+-- remembering WHERE tenant_id = ?. Services scope every transaction with
+-- SET LOCAL app.tenant_id (see pkg/tenancy); a transaction with no tenant
+-- sees no tenant-owned rows at all.
+--
+-- Not covered, deliberately: tenants (the registry sign-in resolves tenants
+-- from), audit_log (one hash chain across tenants, verified as a whole) and
+-- the platform-wide cost_analytics, performance_metrics and
+-- proxy_acquisitions tables. 008_advanced_intelligence creates no tables.
+
+CREATE OR REPLACE FUNCTION app_current_tenant() RETURNS UUID
+LANGUAGE sql STABLE AS $$
+    SELECT NULLIF(current_setting('app.tenant_id', true), '')::uuid
+$$;
+
+-- Cross-tenant work (sweepers, sign-in) sets app.bypass_rls, which only
+-- counts for database users granted intel_system
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'intel_system') THEN
+        CREATE ROLE intel_system NOLOGIN;
+    END IF;
+END
+$$;
+
+-- Deployments where services run migrations under their own login need
+-- it to be a member. Superusers are skipped: row-level security never
+-- applies to them, so services must not connect as one.
+DO $$
+BEGIN
+    IF NOT (SELECT rolsuper FROM pg_roles WHERE rolname = current_user) THEN
+        EXECUTE format('GRANT intel_system TO %I', current_user);
+    END IF;
+END
+$$;
+
+CREATE OR REPLACE FUNCTION app_rls_bypassed() RETURNS BOOLEAN
+LANGUAGE sql STABLE AS $$
+    SELECT COALESCE(current_setting('app.bypass_rls', true), '') = 'on'
+       AND pg_has_role(current_user, 'intel_system', 'MEMBER')
+$$;
+
+-- Bulk jobs belonged to a user; record the tenant directly
+ALTER TABLE bulk_jobs ADD COLUMN tenant_id UUID REFERENCES tenants(id) ON DELETE CASCADE;
+UPDATE bulk_jobs b SET tenant_id = u.tenant_id FROM users u WHERE u.id = b.user_id;
+ALTER TABLE bulk_jobs
+    ALTER COLUMN tenant_id SET NOT NULL,
+    ALTER COLUMN tenant_id SET DEFAULT app_current_tenant();
+CREATE INDEX idx_bulk_jobs_tenant ON bulk_jobs(tenant_id);
+
+-- Intelligence results had no owner. Existing rows cannot be attributed and
+-- stay visible only to intel_system; new rows take the tenant in scope.
+ALTER TABLE email_discovery_results ADD COLUMN tenant_id UUID DEFAULT app_current_tenant() REFERENCES tenants(id) ON DELETE CASCADE;
+ALTER TABLE social_graphs ADD COLUMN tenant_id UUID DEFAULT app_current_tenant() REFERENCES tenants(id) ON DELETE CASCADE;
+ALTER TABLE behavioral_patterns ADD COLUMN tenant_id UUID DEFAULT app_current_tenant() REFERENCES tenants(id) ON DELETE CASCADE;
+ALTER TABLE risk_assessments ADD COLUMN tenant_id UUID DEFAULT app_current_tenant() REFERENCES tenants(id) ON DELETE CASCADE;
+ALTER TABLE intelligence_reports ADD COLUMN tenant_id UUID DEFAULT app_current_tenant() REFERENCES tenants(id) ON DELETE CASCADE;
+
+-- A global unique key would let one tenant detect another's results
+ALTER TABLE email_discovery_results DROP CONSTRAINT email_discovery_results_phone_number_email_key;
+ALTER TABLE email_discovery_results ADD UNIQUE (tenant_id, phone_number, email);
+
+CREATE INDEX idx_email_discovery_tenant ON email_discovery_results(tenant_id);
+CREATE INDEX idx_social_graphs_tenant ON social_graphs(tenant_id);
+CREATE INDEX idx_behavioral_patterns_tenant ON behavioral_patterns(tenant_id);
+CREATE INDEX idx_risk_assessments_tenant ON risk_assessments(tenant_id);
+CREATE INDEX idx_intelligence_reports_tenant ON intelligence_reports(tenant_id);
+
+-- One policy per tenant-owned table. FORCE applies it to the table owner
+-- too, so services connecting as the owner are not exempt.
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY[
+        -- 007_multi_tenant
+        'users', 'roles', 'api_keys', 'tenant_usage', 'billing_records',
+        -- 003_admin_features
+        'bulk_jobs',
+        -- 006_intelligence_engine
+        'email_discovery_results', 'social_graphs', 'behavioral_patterns',
+        'risk_assessments', 'intelligence_reports',
+        -- 010 to 012
+        'cases', 'approval_policies', 'approval_requests', 'teams'
+    ] LOOP
+        EXECUTE format('ALTER TABLE %I ENABLE ROW LEVEL SECURITY', t);
+        EXECUTE format('ALTER TABLE %I FORCE ROW LEVEL SECURITY', t);
+        EXECUTE format(
+            'CREATE POLICY tenant_isolation ON %I
+                USING (tenant_id = app_current_tenant() OR app_rls_bypassed())
+                WITH CHECK (tenant_id = app_current_tenant() OR app_rls_bypassed())',
+            t);
+    END LOOP;
+END
+$$;
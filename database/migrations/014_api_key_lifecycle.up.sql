@@ -0,0 +1,40 @@
+-- database/migrations/014_api_key_lifecycle.up.sql
+
+-- Existing keys span tenants; see 013 for who may bypass row-level security
+SELECT set_config('app.bypass_rls', 'on', false);
+
+-- Every key belongs to a user and acts with that user's roles (see pkg/apikey)
+ALTER TABLE api_keys
+    ADD COLUMN owner_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    ADD COLUMN prefix VARCHAR(16),
+    ADD COLUMN replaced_by UUID REFERENCES api_keys(id),
+    ADD COLUMN revoked_at TIMESTAMP,
+    ADD COLUMN revoked_by UUID;
+
+-- Keys created before owners were tracked go to the tenant's first admin
+UPDATE api_keys k SET owner_id = (
+    SELECT u.id FROM users u
+    WHERE u.tenant_id = k.tenant_id AND u.role = 'admin' AND u.is_active
+    ORDER BY u.created_at
+    LIMIT 1
+);
+
+-- Keys nobody can be held accountable for stop working
+UPDATE api_keys SET is_active = FALSE, revoked_at = CURRENT_TIMESTAMP
+WHERE owner_id IS NULL AND revoked_at IS NULL;
+
+UPDATE api_keys SET rate_limit_per_minute = LEAST(GREATEST(COALESCE(rate_limit_per_minute, 60), 1), 6000);
+
+ALTER TABLE api_keys
+    ADD CONSTRAINT api_keys_owned CHECK (owner_id IS NOT NULL OR NOT is_active),
+    ALTER COLUMN rate_limit_per_minute SET NOT NULL,
+    ADD CONSTRAINT api_keys_rate_limit CHECK (rate_limit_per_minute BETWEEN 1 AND 6000);
+
+CREATE INDEX idx_api_keys_owner ON api_keys(owner_id);
+
+-- Users manage their own keys; admins hold "*"
+UPDATE roles
+SET rules = rules || '[{"effect": "allow", "permission": "api_keys:manage", "scope": "own"}]'::jsonb
+WHERE name = 'user' AND is_system_role;
+
+RESET app.bypass_rls;
@@ -0,0 +1,17 @@
+-- database/migrations/015_signing_keys.up.sql
+
+-- Access token signing keys (see pkg/token). Platform-wide, so not under
+-- row-level security. A key is published from created_at, signs from
+-- activates_at until the next key activates, and is dropped from the JWKS
+-- at expires_at.
+CREATE TABLE signing_keys (
+    id VARCHAR(32) PRIMARY KEY, -- The JWT kid
+    algorithm VARCHAR(10) NOT NULL CHECK (algorithm IN ('RS256', 'EdDSA')),
+    private_key TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    activates_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP,
+    CHECK (expires_at IS NULL OR expires_at > activates_at)
+);
+
+CREATE INDEX idx_signing_keys_expires ON signing_keys(expires_at);
@@ -0,0 +1,56 @@
+-- database/migrations/016_sso_scim.up.sql
+
+-- Per-tenant identity providers for single sign-on (see pkg/sso)
+CREATE TABLE identity_providers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    protocol VARCHAR(10) NOT NULL CHECK (protocol IN ('oidc', 'saml')),
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    issuer_url TEXT,
+    client_id VARCHAR(255),
+    client_secret TEXT,
+    idp_metadata_xml TEXT,
+    groups_claim VARCHAR(255),
+    role_mappings JSONB NOT NULL DEFAULT '[]',
+    default_role VARCHAR(50),
+    jit_provisioning BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (tenant_id, name)
+);
+
+-- Which IdP subject signs in as which user. Keyed by subject, not email,
+-- so a changed email at the IdP cannot move a sign-in to another account.
+CREATE TABLE user_identities (
+    provider_id UUID NOT NULL REFERENCES identity_providers(id) ON DELETE CASCADE,
+    subject VARCHAR(255) NOT NULL,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (provider_id, subject)
+);
+
+CREATE INDEX idx_user_identities_user ON user_identities(user_id);
+
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY['identity_providers', 'user_identities'] LOOP
+        EXECUTE format('ALTER TABLE %I ENABLE ROW LEVEL SECURITY', t);
+        EXECUTE format('ALTER TABLE %I FORCE ROW LEVEL SECURITY', t);
+        EXECUTE format(
+            'CREATE POLICY tenant_isolation ON %I
+                USING (tenant_id = app_current_tenant() OR app_rls_bypassed())
+                WITH CHECK (tenant_id = app_current_tenant() OR app_rls_bypassed())',
+            t);
+    END LOOP;
+END $$;
+
+-- Users who only sign in through an IdP have no password
+ALTER TABLE users ALTER COLUMN password_hash DROP NOT NULL;
+
+-- The directory's ID for users provisioned over SCIM
+ALTER TABLE users ADD COLUMN external_id VARCHAR(255);
+CREATE UNIQUE INDEX idx_users_external_id ON users(tenant_id, external_id) WHERE external_id IS NOT NULL;
@@ -0,0 +1,55 @@
+-- database/migrations/017_mfa.up.sql
+
+-- Role rules are tenant-scoped; update every tenant's system roles
+SELECT set_config('app.bypass_rls', 'on', false);
+
+-- Enrolled second factors (see pkg/mfa)
+CREATE TABLE mfa_factors (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('totp', 'webauthn')),
+    name VARCHAR(100),
+    secret TEXT,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    credential JSONB,
+    confirmed_at TIMESTAMP,
+    last_used TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_mfa_factors_user ON mfa_factors(tenant_id, user_id);
+
+-- One-time recovery codes, stored hashed
+CREATE TABLE mfa_recovery_codes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_mfa_recovery_codes_user ON mfa_recovery_codes(tenant_id, user_id);
+
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY['mfa_factors', 'mfa_recovery_codes'] LOOP
+        EXECUTE format('ALTER TABLE %I ENABLE ROW LEVEL SECURITY', t);
+        EXECUTE format('ALTER TABLE %I FORCE ROW LEVEL SECURITY', t);
+        EXECUTE format(
+            'CREATE POLICY tenant_isolation ON %I
+                USING (tenant_id = app_current_tenant() OR app_rls_bypassed())
+                WITH CHECK (tenant_id = app_current_tenant() OR app_rls_bypassed())',
+            t);
+    END LOOP;
+END $$;
+
+-- Bulk jobs now have their own permission, which needs a step-up
+UPDATE roles
+SET rules = rules || '[{"effect": "allow", "permission": "jobs:bulk"}]'::jsonb
+WHERE name = 'user' AND is_system_role;
+
+RESET app.bypass_rls;
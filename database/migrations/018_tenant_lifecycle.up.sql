@@ -0,0 +1,30 @@
+-- database/migrations/018_tenant_lifecycle.up.sql
+
+-- Tenants move active -> suspended -> active, or on to offboarding and
+-- offboarded (see pkg/lifecycle). Offboarded tenants keep their row, with
+-- the name and slug only, as a tombstone.
+ALTER TABLE tenants
+    ADD COLUMN suspended_at TIMESTAMP,
+    ADD COLUMN suspension_reason TEXT,
+    ADD COLUMN offboarded_at TIMESTAMP,
+    ADD CONSTRAINT tenants_status CHECK (status IN ('active', 'suspended', 'offboarding', 'offboarded'));
+
+-- Signed certificates of what offboarding removed. Not tied to the tenant
+-- by a foreign key and not under row-level security: they outlive the
+-- tenant's data and are read by platform operators.
+CREATE TABLE tenant_deletion_certificates (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    tenant_name VARCHAR(255) NOT NULL,
+    tenant_slug VARCHAR(100) NOT NULL,
+    requested_by VARCHAR(255) NOT NULL,
+    audit_export JSONB NOT NULL,
+    removed JSONB NOT NULL,
+    retained JSONB NOT NULL,
+    verified BOOLEAN NOT NULL,
+    completed_at TIMESTAMP NOT NULL,
+    signature TEXT NOT NULL, -- Compact JWS over the certificate
+    public_key JSONB NOT NULL -- The JWK that signed it
+);
+
+CREATE INDEX idx_tenant_deletion_certificates_tenant ON tenant_deletion_certificates(tenant_id, completed_at);
@@ -0,0 +1,61 @@
+-- database/migrations/019_data_retention.up.sql
+
+-- Legal holds pause retention for one case of a tenant (see pkg/retention).
+-- Released holds are kept as the record of when deletion resumed.
+CREATE TABLE legal_holds (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL DEFAULT app_current_tenant() REFERENCES tenants(id) ON DELETE CASCADE,
+    case_reference VARCHAR(100) NOT NULL,
+    reason TEXT NOT NULL,
+    placed_by VARCHAR(255) NOT NULL,
+    placed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    released_by VARCHAR(255),
+    released_at TIMESTAMP
+);
+
+-- At most one active hold per case
+CREATE UNIQUE INDEX idx_legal_holds_active ON legal_holds(tenant_id, case_reference) WHERE released_at IS NULL;
+
+ALTER TABLE legal_holds ENABLE ROW LEVEL SECURITY;
+ALTER TABLE legal_holds FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON legal_holds
+    USING (tenant_id = app_current_tenant() OR app_rls_bypassed())
+    WITH CHECK (tenant_id = app_current_tenant() OR app_rls_bypassed());
+
+-- Results and reports carry the case of the job they came from, so a hold
+-- on the case covers them. Rows written before this migration have none
+-- and cannot be held.
+ALTER TABLE email_discovery_results ADD COLUMN case_reference VARCHAR(100);
+ALTER TABLE social_graphs ADD COLUMN case_reference VARCHAR(100);
+ALTER TABLE behavioral_patterns ADD COLUMN case_reference VARCHAR(100);
+ALTER TABLE risk_assessments ADD COLUMN case_reference VARCHAR(100);
+ALTER TABLE intelligence_reports ADD COLUMN case_reference VARCHAR(100);
+
+-- Tenants with retention_mode = redact keep expired rows with every
+-- identifier overwritten; redacted_at marks them as done
+ALTER TABLE bulk_jobs ADD COLUMN redacted_at TIMESTAMP;
+ALTER TABLE email_discovery_results ADD COLUMN redacted_at TIMESTAMP;
+ALTER TABLE social_graphs ADD COLUMN redacted_at TIMESTAMP;
+ALTER TABLE behavioral_patterns ADD COLUMN redacted_at TIMESTAMP;
+ALTER TABLE risk_assessments ADD COLUMN redacted_at TIMESTAMP;
+ALTER TABLE intelligence_reports ADD COLUMN redacted_at TIMESTAMP;
+
+-- The sweeper selects by tenant and age
+CREATE INDEX idx_bulk_jobs_retention ON bulk_jobs(tenant_id, created_at);
+CREATE INDEX idx_email_discovery_retention ON email_discovery_results(tenant_id, discovered_at);
+CREATE INDEX idx_social_graphs_retention ON social_graphs(tenant_id, generated_at);
+CREATE INDEX idx_behavioral_patterns_retention ON behavioral_patterns(tenant_id, detected_at);
+CREATE INDEX idx_risk_assessments_retention ON risk_assessments(tenant_id, assessed_at);
+CREATE INDEX idx_intelligence_reports_retention ON intelligence_reports(tenant_id, generated_at);
+
+-- Tenants created before the setting was written get their plan's period
+UPDATE tenants
+SET settings = COALESCE(settings, '{}'::jsonb) || jsonb_build_object('data_retention_days',
+    CASE plan_type
+        WHEN 'enterprise' THEN 365
+        WHEN 'professional' THEN 180
+        WHEN 'starter' THEN 90
+        ELSE 30
+    END)
+WHERE settings->>'data_retention_days' IS NULL
+  AND status IN ('active', 'suspended');
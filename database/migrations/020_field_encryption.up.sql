@@ -0,0 +1,69 @@
+-- database/migrations/020_field_encryption.up.sql
+
+-- One data key per tenant, wrapped by a KMS master key (see pkg/envelope).
+-- Rotating the master key rewrites these rows only. Shredding a tenant
+-- clears wrapped_key and sets destroyed_at; the row stays so nothing is
+-- sealed for the tenant again.
+CREATE TABLE tenant_data_keys (
+    tenant_id UUID PRIMARY KEY DEFAULT app_current_tenant() REFERENCES tenants(id) ON DELETE CASCADE,
+    master_key_id VARCHAR(64) NOT NULL,
+    wrapped_key BYTEA,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    rewrapped_at TIMESTAMP,
+    destroyed_at TIMESTAMP,
+    CHECK ((wrapped_key IS NULL) = (destroyed_at IS NOT NULL))
+);
+
+ALTER TABLE tenant_data_keys ENABLE ROW LEVEL SECURITY;
+ALTER TABLE tenant_data_keys FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON tenant_data_keys
+    USING (tenant_id = app_current_tenant() OR app_rls_bypassed())
+    WITH CHECK (tenant_id = app_current_tenant() OR app_rls_bypassed());
+
+-- Sealed values are text (enc:v1:<tenant>:<base64>) and longer than the
+-- plaintext. Rows already stored stay readable as plaintext until
+-- cmd/seal-backfill seals them.
+ALTER TABLE intelligence_reports
+    ALTER COLUMN phone_number TYPE TEXT,
+    ALTER COLUMN report_data TYPE TEXT USING report_data::text,
+    ALTER COLUMN executive_summary TYPE TEXT USING executive_summary::text;
+ALTER TABLE email_discovery_results
+    ALTER COLUMN phone_number TYPE TEXT,
+    ALTER COLUMN email TYPE TEXT,
+    ALTER COLUMN social_profiles TYPE TEXT USING social_profiles::text;
+ALTER TABLE social_graphs
+    ALTER COLUMN phone_number TYPE TEXT,
+    ALTER COLUMN graph_data TYPE TEXT USING graph_data::text,
+    ALTER COLUMN central_node TYPE TEXT;
+ALTER TABLE risk_assessments
+    ALTER COLUMN phone_number TYPE TEXT,
+    ALTER COLUMN factors TYPE TEXT USING factors::text;
+ALTER TABLE behavioral_patterns
+    ALTER COLUMN user_identifier TYPE TEXT,
+    ALTER COLUMN pattern_data TYPE TEXT USING pattern_data::text;
+-- Job names and justifications often name the subject
+ALTER TABLE bulk_jobs ALTER COLUMN name TYPE TEXT;
+
+-- Blind indexes: HMAC-SHA256 of the normalized value under a key derived
+-- from the tenant's data key. An index on ciphertext would match nothing.
+ALTER TABLE intelligence_reports ADD COLUMN phone_number_index VARCHAR(64);
+ALTER TABLE email_discovery_results ADD COLUMN phone_number_index VARCHAR(64);
+ALTER TABLE email_discovery_results ADD COLUMN email_index VARCHAR(64);
+ALTER TABLE social_graphs ADD COLUMN phone_number_index VARCHAR(64);
+ALTER TABLE risk_assessments ADD COLUMN phone_number_index VARCHAR(64);
+ALTER TABLE behavioral_patterns ADD COLUMN user_identifier_index VARCHAR(64);
+
+CREATE INDEX idx_intelligence_reports_phone_index ON intelligence_reports(tenant_id, phone_number_index);
+CREATE INDEX idx_email_discovery_phone_index ON email_discovery_results(tenant_id, phone_number_index);
+CREATE INDEX idx_email_discovery_email_index ON email_discovery_results(tenant_id, email_index);
+CREATE INDEX idx_social_graphs_phone_index ON social_graphs(tenant_id, phone_number_index);
+CREATE INDEX idx_risk_assessments_phone_index ON risk_assessments(tenant_id, phone_number_index);
+CREATE INDEX idx_behavioral_patterns_user_index ON behavioral_patterns(tenant_id, user_identifier_index);
+
+-- Sealing uses a fresh nonce each time, so uniqueness moves to the indexes
+CREATE UNIQUE INDEX idx_email_discovery_unique ON email_discovery_results(tenant_id, phone_number_index, email_index)
+    WHERE phone_number_index IS NOT NULL AND email_index IS NOT NULL;
+
+-- The plaintext indexes, and the (tenant_id, phone_number, email)
+-- constraint, still serve the rows written before encryption. Once every
+-- row is sealed cmd/seal-backfill drops them.
@@ -0,0 +1,41 @@
+-- database/migrations/021_subject_requests.up.sql
+
+-- Data-subject requests (see pkg/subject). They span tenants and are
+-- handled by platform operators, so neither table is under row-level
+-- security. phone_number is sealed under the platform tenant's data key
+-- and emptied once an erasure is done; subject_hash is the number hashed
+-- the way audit_log hashes phone targets.
+-- The platform tenant needs a row for its data key
+INSERT INTO tenants (id, name, slug, plan_type)
+VALUES ('00000000-0000-0000-0000-000000000000', 'Platform', 'platform', 'enterprise')
+ON CONFLICT (id) DO NOTHING;
+
+CREATE TABLE subject_requests (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('access', 'rectification', 'erasure')),
+    phone_number TEXT NOT NULL DEFAULT '',
+    subject_hash VARCHAR(64) NOT NULL,
+    verification_method VARCHAR(50) NOT NULL,
+    verification_reference VARCHAR(255) NOT NULL,
+    records JSONB, -- Record IDs a rectification removes
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'completed', 'rejected')),
+    received_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    due_at TIMESTAMP NOT NULL,
+    extended_at TIMESTAMP,
+    extension_reason TEXT,
+    opened_by VARCHAR(255) NOT NULL,
+    closed_by VARCHAR(255),
+    closed_at TIMESTAMP,
+    outcome JSONB
+);
+
+CREATE INDEX idx_subject_requests_due ON subject_requests(status, due_at);
+CREATE INDEX idx_subject_requests_subject ON subject_requests(subject_hash);
+
+-- Erased subjects. Jobs drop these numbers before anything is queued, so
+-- nothing about the subject is collected again.
+CREATE TABLE subject_tombstones (
+    subject_hash VARCHAR(64) PRIMARY KEY,
+    request_id UUID NOT NULL REFERENCES subject_requests(id),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
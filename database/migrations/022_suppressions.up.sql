@@ -0,0 +1,31 @@
+-- database/migrations/022_suppressions.up.sql
+
+-- Numbers that must never be processed (see pkg/suppression): opt-outs,
+-- minors flagged by reviewers and protected categories. Only the number's
+-- hash is kept, as audit_log hashes phone targets. Entries of the platform
+-- tenant apply to every tenant. Removed entries are kept as the record of
+-- when processing resumed.
+CREATE TABLE suppressions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL DEFAULT app_current_tenant() REFERENCES tenants(id) ON DELETE CASCADE,
+    identifier_hash VARCHAR(64) NOT NULL,
+    category VARCHAR(20) NOT NULL CHECK (category IN ('opt_out', 'minor', 'protected')),
+    reason TEXT NOT NULL,
+    added_by VARCHAR(255) NOT NULL,
+    added_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    removed_by VARCHAR(255),
+    removed_at TIMESTAMP
+);
+
+-- At most one active entry per number and tenant; jobs look numbers up
+-- by hash
+CREATE UNIQUE INDEX idx_suppressions_active ON suppressions(tenant_id, identifier_hash) WHERE removed_at IS NULL;
+CREATE INDEX idx_suppressions_hash ON suppressions(identifier_hash) WHERE removed_at IS NULL;
+
+-- Tenants see only their own list. Screening jobs against the global one
+-- runs as intel_system and returns matches, not entries.
+ALTER TABLE suppressions ENABLE ROW LEVEL SECURITY;
+ALTER TABLE suppressions FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON suppressions
+    USING (tenant_id = app_current_tenant() OR app_rls_bypassed())
+    WITH CHECK (tenant_id = app_current_tenant() OR app_rls_bypassed());
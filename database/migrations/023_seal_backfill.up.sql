@@ -0,0 +1,10 @@
+-- database/migrations/023_seal_backfill.up.sql
+
+-- One row once cmd/seal-backfill has sealed every value written before
+-- field encryption (020). From then on the services refuse plaintext in a
+-- sealed column rather than reading it as a row from before encryption.
+-- Platform-wide, so not under row-level security.
+CREATE TABLE seal_backfill (
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    completed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,24 @@
+#!/bin/sh
+# database/service_login.sh
+#
+# Run by the postgres image on first start. Services connect as intel_app
+# rather than as POSTGRES_USER: row-level security (migration 013) never
+# applies to superusers. intel_app is a member of intel_system, so the
+# cross-tenant sweepers can still bypass it (see pkg/tenancy).
+set -e
+
+psql -v ON_ERROR_STOP=1 --username "$POSTGRES_USER" --dbname "$POSTGRES_DB" \
+    -v owner="$POSTGRES_USER" -v password="$APP_DB_PASSWORD" <<'SQL'
+CREATE ROLE intel_system NOLOGIN;
+CREATE ROLE intel_app LOGIN NOSUPERUSER NOBYPASSRLS PASSWORD :'password';
+GRANT intel_system TO intel_app;
+
+-- Migrations run as the owner; intel_app may use what they create
+GRANT USAGE ON SCHEMA public TO intel_app;
+ALTER DEFAULT PRIVILEGES FOR ROLE :"owner" IN SCHEMA public
+    GRANT SELECT, INSERT, UPDATE, DELETE ON TABLES TO intel_app;
+ALTER DEFAULT PRIVILEGES FOR ROLE :"owner" IN SCHEMA public
+    GRANT USAGE, SELECT ON SEQUENCES TO intel_app;
+ALTER DEFAULT PRIVILEGES FOR ROLE :"owner" IN SCHEMA public
+    GRANT EXECUTE ON FUNCTIONS TO intel_app;
+SQL
@@ -2,18 +2,30 @@
 package report_generator
 
 import (
+    "context"
     "encoding/json"
+    "errors"
     "fmt"
     "html/template"
     "os"
     "path/filepath"
+    "strconv"
+    "strings"
     "time"
+
+    "secure-iran-intel/pkg/audit"
+    "secure-iran-intel/pkg/purpose"
+    "secure-iran-intel/pkg/rbac"
+    "secure-iran-intel/pkg/redaction"
 )
 
 type ReportGenerator struct {
     templates    map[string]*template.Template
     exportFormats []ExportFormat
     reportDB     *ReportDatabase
+    auditLog     *audit.Logger
+    policy       *rbac.Engine
+    redactions   *redaction.Set
 }
 
 type IntelligenceReport struct {
@@ -21,6 +33,8 @@ type IntelligenceReport struct {
     PhoneNumber   string                 `json:"phone_number"`
     GeneratedAt   time.Time              `json:"generated_at"`
     ReportType    string                 `json:"report_type"`
+    CaseReference string                 `json:"case_reference"`
+    LegalBasis    string                 `json:"legal_basis"`
     ExecutiveSummary *ExecutiveSummary     `json:"executive_summary"`
     EmailDiscovery *EmailDiscoveryReport  `json:"email_discovery"`
     SocialGraph   *SocialGraphReport      `json:"social_graph"`
@@ -29,6 +43,9 @@ type IntelligenceReport struct {
     Recommendations []Recommendation       `json:"recommendations"`
     RawData       map[string]interface{} `json:"raw_data"`
     Confidence    float64                `json:"confidence"`
+    // Redactions says what was withheld from a rendered report and why;
+    // stored reports have none
+    Redactions    *redaction.Manifest    `json:"redactions,omitempty"`
 }
 
 type ExecutiveSummary struct {
@@ -38,24 +55,46 @@ type ExecutiveSummary struct {
     Recommendations []string `json:"recommendations"`
 }
 
-func NewReportGenerator() *ReportGenerator {
+// NewReportGenerator renders reports with redactions, e.g.
+// redaction.Default(), according to each viewer's role under policy
+func NewReportGenerator(auditLog *audit.Logger, reportDB *ReportDatabase, policy *rbac.Engine, redactions *redaction.Set) *ReportGenerator {
     rg := &ReportGenerator{
         templates:    make(map[string]*template.Template),
         exportFormats: []ExportFormat{PDF, HTML, JSON, CSV},
-        reportDB:     NewReportDatabase(),
+        reportDB:     reportDB,
+        auditLog:     auditLog,
+        policy:       policy,
+        redactions:   redactions,
     }
     rg.loadTemplates()
     return rg
 }
 
-// GenerateComprehensiveReport creates a complete intelligence report
-func (rg *ReportGenerator) GenerateComprehensiveReport(phoneNumber string, intelligence *PhoneIntelligence) (*IntelligenceReport, error) {
+// GenerateComprehensiveReport creates a complete intelligence report for
+// the job run under jobPurpose and stores it whole. The caller gets it
+// rendered like any other view.
+func (rg *ReportGenerator) GenerateComprehensiveReport(ctx context.Context, phoneNumber string, jobPurpose purpose.Purpose, intelligence *PhoneIntelligence) (*IntelligenceReport, error) {
+    if err := rg.auditLog.Log(ctx, audit.Entry{
+        Action:     audit.ActionReportCreate,
+        TargetType: "phone",
+        Target:     phoneNumber,
+        Metadata: map[string]string{
+            "report_type":    "comprehensive",
+            "case_reference": jobPurpose.CaseReference,
+            "legal_basis":    jobPurpose.LegalBasis,
+        },
+    }); err != nil {
+        return nil, err
+    }
+
     report := &IntelligenceReport{
-        ReportID:    rg.generateReportID(),
-        PhoneNumber: phoneNumber,
-        GeneratedAt: time.Now(),
-        ReportType:  "comprehensive",
-        RawData:     make(map[string]interface{}),
+        ReportID:      rg.generateReportID(),
+        PhoneNumber:   phoneNumber,
+        GeneratedAt:   time.Now(),
+        ReportType:    "comprehensive",
+        CaseReference: jobPurpose.CaseReference,
+        LegalBasis:    jobPurpose.LegalBasis,
+        RawData:       make(map[string]interface{}),
     }
 
     // Generate all report sections
@@ -131,15 +170,60 @@ func (rg *ReportGenerator) GenerateComprehensiveReport(phoneNumber string, intel
     }
 
     if len(errorList) > 0 {
-        return report, fmt.Errorf("report generation completed with errors: %v", errorList)
+        return rg.renderFailed(ctx, report, fmt.Errorf("report generation completed with errors: %v", errorList))
     }
 
     // Store report in database
-    if err := rg.reportDB.StoreReport(report); err != nil {
-        return report, fmt.Errorf("failed to store report: %w", err)
+    if err := rg.reportDB.StoreReport(ctx, audit.ActorFromContext(ctx).TenantID, report); err != nil {
+        return rg.renderFailed(ctx, report, fmt.Errorf("failed to store report: %w", err))
+    }
+
+    return rg.render(ctx, report)
+}
+
+// render returns the copy of report the caller may see, carrying the
+// manifest of what the redaction policies for its legal basis and the
+// caller's role withheld. The report itself is left whole.
+func (rg *ReportGenerator) render(ctx context.Context, report *IntelligenceReport) (*IntelligenceReport, error) {
+    role, err := rg.viewerRole(ctx)
+    if err != nil {
+        return nil, err
+    }
+    rendered := &IntelligenceReport{}
+    manifest, err := rg.redactions.Render(report, rendered, report.LegalBasis, role)
+    if err != nil {
+        return nil, err
+    }
+    rendered.Redactions = manifest
+
+    // The stored summary is of the whole report; the viewer's is rebuilt
+    // from what they may see, so it repeats nothing from a withheld section
+    if rendered.ExecutiveSummary != nil {
+        rendered.ExecutiveSummary = rg.generateExecutiveSummary(rendered)
+    }
+    return rendered, nil
+}
+
+// renderFailed renders a partial report returned alongside err
+func (rg *ReportGenerator) renderFailed(ctx context.Context, report *IntelligenceReport, err error) (*IntelligenceReport, error) {
+    rendered, renderErr := rg.render(ctx, report)
+    if renderErr != nil {
+        return nil, renderErr
     }
+    return rendered, err
+}
 
-    return report, nil
+// viewerRole is the role the caller's user holds. Callers that act as no
+// user, internal services included, hold none and get the strictest view.
+func (rg *ReportGenerator) viewerRole(ctx context.Context) (string, error) {
+    subject, err := rg.policy.SubjectFor(ctx, audit.ActorFromContext(ctx))
+    if errors.Is(err, rbac.ErrUnknownSubject) {
+        return "", nil
+    }
+    if err != nil {
+        return "", err
+    }
+    return subject.Role, nil
 }
 
 func (rg *ReportGenerator) generateExecutiveSummary(report *IntelligenceReport) *ExecutiveSummary {
@@ -149,13 +233,16 @@ func (rg *ReportGenerator) generateExecutiveSummary(report *IntelligenceReport)
         Recommendations: make([]string, 0),
     }
 
-    // Determine overall risk level
-    if report.RiskAssessment.OverallScore >= 0.8 {
-        summary.OverallRisk = "HIGH"
-    } else if report.RiskAssessment.OverallScore >= 0.5 {
-        summary.OverallRisk = "MEDIUM" 
-    } else {
-        summary.OverallRisk = "LOW"
+    // Determine overall risk level. A withheld risk assessment leaves it
+    // unset rather than guessed.
+    if report.RiskAssessment != nil {
+        if report.RiskAssessment.OverallScore >= 0.8 {
+            summary.OverallRisk = "HIGH"
+        } else if report.RiskAssessment.OverallScore >= 0.5 {
+            summary.OverallRisk = "MEDIUM" 
+        } else {
+            summary.OverallRisk = "LOW"
+        }
     }
 
     // Extract key findings
@@ -170,10 +257,12 @@ func (rg *ReportGenerator) generateExecutiveSummary(report *IntelligenceReport)
     }
 
     // Add critical alerts from risk assessment
-    for _, factor := range report.RiskAssessment.Factors {
-        if factor.Score >= 0.8 {
-            summary.CriticalAlerts = append(summary.CriticalAlerts,
-                fmt.Sprintf("High risk: %s", factor.Description))
+    if report.RiskAssessment != nil {
+        for _, factor := range report.RiskAssessment.Factors {
+            if factor.Score >= 0.8 {
+                summary.CriticalAlerts = append(summary.CriticalAlerts,
+                    fmt.Sprintf("High risk: %s", factor.Description))
+            }
         }
     }
 
@@ -183,17 +272,92 @@ func (rg *ReportGenerator) generateExecutiveSummary(report *IntelligenceReport)
     return summary
 }
 
-// Export report in multiple formats
-func (rg *ReportGenerator) ExportReport(report *IntelligenceReport, format ExportFormat) ([]byte, error) {
+// GetReport loads a stored report and renders it for viewing
+func (rg *ReportGenerator) GetReport(ctx context.Context, reportID string) (*IntelligenceReport, error) {
+    report, err := rg.reportDB.GetReport(ctx, audit.ActorFromContext(ctx).TenantID, reportID)
+    if err != nil {
+        return nil, err
+    }
+    rendered, err := rg.render(ctx, report)
+    if err != nil {
+        return nil, err
+    }
+
+    if err := rg.recordAccess(ctx, audit.ActionReportView, report, withheld(rendered, nil)); err != nil {
+        return nil, err
+    }
+    return rendered, nil
+}
+
+// withheld adds to metadata how much of a rendered report was withheld and
+// under which policies
+func withheld(rendered *IntelligenceReport, metadata map[string]string) map[string]string {
+    if metadata == nil {
+        metadata = make(map[string]string)
+    }
+    if m := rendered.Redactions; m != nil {
+        count := 0
+        for _, w := range m.Withheld {
+            count += w.Count
+        }
+        metadata["redaction_policies"] = strings.Join(m.Policies, ",")
+        metadata["withheld"] = strconv.Itoa(count)
+    }
+    return metadata
+}
+
+// recordAccess audits a view or export of a report. The target is the
+// subject's number so compliance can find every access to one person.
+func (rg *ReportGenerator) recordAccess(ctx context.Context, action string, report *IntelligenceReport, metadata map[string]string) error {
+    if metadata == nil {
+        metadata = make(map[string]string)
+    }
+    metadata["report_id"] = report.ReportID
+
+    return rg.auditLog.Log(ctx, audit.Entry{
+        Action:     action,
+        TargetType: "phone",
+        Target:     report.PhoneNumber,
+        Metadata:   metadata,
+    })
+}
+
+// prepareExport returns the whole report behind report, which may already
+// have been rendered, e.g. by GetReport, and its rendering for the caller
+func (rg *ReportGenerator) prepareExport(ctx context.Context, report *IntelligenceReport) (*IntelligenceReport, *IntelligenceReport, error) {
+    if report.Redactions != nil {
+        stored, err := rg.reportDB.GetReport(ctx, audit.ActorFromContext(ctx).TenantID, report.ReportID)
+        if err != nil {
+            return nil, nil, err
+        }
+        report = stored
+    }
+    rendered, err := rg.render(ctx, report)
+    if err != nil {
+        return nil, nil, err
+    }
+    return report, rendered, nil
+}
+
+// Export report in multiple formats, rendered for the caller
+func (rg *ReportGenerator) ExportReport(ctx context.Context, report *IntelligenceReport, format ExportFormat) ([]byte, error) {
+    report, rendered, err := rg.prepareExport(ctx, report)
+    if err != nil {
+        return nil, err
+    }
+    if err := rg.recordAccess(ctx, audit.ActionReportExport, report, withheld(rendered, map[string]string{"format": fmt.Sprint(format)})); err != nil {
+        return nil, err
+    }
+
     switch format {
     case JSON:
-        return json.MarshalIndent(report, "", "  ")
+        return json.MarshalIndent(rendered, "", "  ")
     case HTML:
-        return rg.exportHTML(report)
+        return rg.exportHTML(rendered)
     case PDF:
-        return rg.exportPDF(report)
+        return rg.exportPDF(rendered)
     case CSV:
-        return rg.exportCSV(report)
+        return rg.exportCSV(rendered)
     default:
         return nil, fmt.Errorf("unsupported export format: %s", format)
     }
@@ -468,17 +632,38 @@ func (rg *ReportGenerator) generateReportID() string {
     return fmt.Sprintf("INTEL-%s-%d", time.Now().Format("20060102"), rand.Intn(1000))
 }
 
-// Export methods
-func (rg *ReportGenerator) ExportToPDF(report *IntelligenceReport, filePath string) error {
-    return rg.exporter.ExportToPDF(report, filePath)
+// Export methods; each writes the report rendered for the caller
+func (rg *ReportGenerator) ExportToPDF(ctx context.Context, report *IntelligenceReport, filePath string) error {
+    report, rendered, err := rg.prepareExport(ctx, report)
+    if err != nil {
+        return err
+    }
+    if err := rg.recordAccess(ctx, audit.ActionReportExport, report, withheld(rendered, map[string]string{"format": "pdf", "file": filePath})); err != nil {
+        return err
+    }
+    return rg.exporter.ExportToPDF(rendered, filePath)
 }
 
-func (rg *ReportGenerator) ExportToHTML(report *IntelligenceReport, filePath string) error {
-    return rg.exporter.ExportToHTML(report, filePath)
+func (rg *ReportGenerator) ExportToHTML(ctx context.Context, report *IntelligenceReport, filePath string) error {
+    report, rendered, err := rg.prepareExport(ctx, report)
+    if err != nil {
+        return err
+    }
+    if err := rg.recordAccess(ctx, audit.ActionReportExport, report, withheld(rendered, map[string]string{"format": "html", "file": filePath})); err != nil {
+        return err
+    }
+    return rg.exporter.ExportToHTML(rendered, filePath)
 }
 
-func (rg *ReportGenerator) ExportToJSON(report *IntelligenceReport, filePath string) error {
-    return rg.exporter.ExportToJSON(report, filePath)
+func (rg *ReportGenerator) ExportToJSON(ctx context.Context, report *IntelligenceReport, filePath string) error {
+    report, rendered, err := rg.prepareExport(ctx, report)
+    if err != nil {
+        return err
+    }
+    if err := rg.recordAccess(ctx, audit.ActionReportExport, report, withheld(rendered, map[string]string{"format": "json", "file": filePath})); err != nil {
+        return err
+    }
+    return rg.exporter.ExportToJSON(rendered, filePath)
 }
 
 // Additional helper methods would be implemented here...
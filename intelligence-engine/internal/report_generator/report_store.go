@@ -0,0 +1,124 @@
+// intelligence-engine/internal/report_generator/report_store.go
+package report_generator
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "time"
+
+    "gorm.io/gorm"
+    "secure-iran-intel/pkg/envelope"
+    "secure-iran-intel/pkg/tenancy"
+)
+
+// ErrReportNotFound is returned for a report the tenant does not have
+var ErrReportNotFound = errors.New("report not found")
+
+// storedReport is a row of intelligence_reports. The phone number and the
+// whole report, RawData included, are sealed with the tenant's data key;
+// PhoneNumberIndex is the blind index lookups by number go through.
+type storedReport struct {
+    ID               string `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+    TenantID         string `gorm:"type:uuid"`
+    ReportID         string `gorm:"not null"`
+    PhoneNumber      string `gorm:"serializer:encrypted"`
+    PhoneNumberIndex string
+    ReportType       string
+    CaseReference    string              // Lets a legal hold on the case cover the report
+    ReportData       *IntelligenceReport `gorm:"serializer:encrypted"`
+    ExecutiveSummary *ExecutiveSummary   `gorm:"serializer:encrypted"`
+    GeneratedAt      time.Time
+    ExpiresAt        *time.Time
+}
+
+func (storedReport) TableName() string {
+    return "intelligence_reports"
+}
+
+// ReportDatabase keeps reports in the intelligence_reports table
+// (migrations 006 and 020). The encrypted serializer needs envelope.Register
+// to have been called with keys.
+type ReportDatabase struct {
+    db   *gorm.DB
+    keys *envelope.Keyring
+}
+
+func NewReportDatabase(db *gorm.DB, keys *envelope.Keyring) *ReportDatabase {
+    return &ReportDatabase{db: db, keys: keys}
+}
+
+func (r *ReportDatabase) StoreReport(ctx context.Context, tenantID string, report *IntelligenceReport) error {
+    index, err := r.keys.BlindIndex(ctx, tenantID, report.PhoneNumber)
+    if err != nil {
+        return err
+    }
+    row := &storedReport{
+        TenantID:         tenantID,
+        ReportID:         report.ReportID,
+        PhoneNumber:      report.PhoneNumber,
+        PhoneNumberIndex: index,
+        ReportType:       report.ReportType,
+        CaseReference:    report.CaseReference,
+        ReportData:       report,
+        ExecutiveSummary: report.ExecutiveSummary,
+        GeneratedAt:      report.GeneratedAt,
+    }
+    return tenancy.Transaction(ctx, r.db, tenantID, func(tx *gorm.DB) error {
+        return tx.Create(row).Error
+    })
+}
+
+func (r *ReportDatabase) GetReport(ctx context.Context, tenantID, reportID string) (*IntelligenceReport, error) {
+    var row storedReport
+    err := tenancy.Transaction(ctx, r.db, tenantID, func(tx *gorm.DB) error {
+        return tx.Where("tenant_id = ? AND report_id = ?", tenantID, reportID).First(&row).Error
+    })
+    if errors.Is(err, gorm.ErrRecordNotFound) {
+        return nil, ErrReportNotFound
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to load report: %w", err)
+    }
+    return row.report(), nil
+}
+
+// FindByPhone returns the tenant's reports on a number, newest first. The
+// number must be normalized the way it was when the reports were stored.
+// Rows cmd/seal-backfill has not sealed yet are matched in plaintext.
+func (r *ReportDatabase) FindByPhone(ctx context.Context, tenantID, phoneNumber string) ([]*IntelligenceReport, error) {
+    index, err := r.keys.BlindIndex(ctx, tenantID, phoneNumber)
+    if err != nil {
+        return nil, err
+    }
+    var rows []storedReport
+    err = tenancy.Transaction(ctx, r.db, tenantID, func(tx *gorm.DB) error {
+        return tx.Where("tenant_id = ? AND (phone_number_index = ? OR (phone_number_index IS NULL AND phone_number = ?))", tenantID, index, phoneNumber).
+            Order("generated_at DESC").
+            Find(&rows).Error
+    })
+    if err != nil {
+        return nil, fmt.Errorf("failed to find reports: %w", err)
+    }
+    reports := make([]*IntelligenceReport, 0, len(rows))
+    for i := range rows {
+        reports = append(reports, rows[i].report())
+    }
+    return reports, nil
+}
+
+// report returns the stored report. Rows written before reports were
+// stored whole have only the columns, and redacted rows have nothing.
+func (s *storedReport) report() *IntelligenceReport {
+    if s.ReportData != nil && s.ReportData.ReportID != "" {
+        return s.ReportData
+    }
+    return &IntelligenceReport{
+        ReportID:         s.ReportID,
+        PhoneNumber:      s.PhoneNumber,
+        GeneratedAt:      s.GeneratedAt,
+        ReportType:       s.ReportType,
+        CaseReference:    s.CaseReference,
+        ExecutiveSummary: s.ExecutiveSummary,
+    }
+}
@@ -0,0 +1,104 @@
+// pkg/apikey/apikey.go
+package apikey
+
+import (
+    "crypto/rand"
+    "crypto/sha256"
+    "encoding/hex"
+    "fmt"
+    "net/http"
+    "time"
+
+    "secure-iran-intel/pkg/rbac"
+    "secure-iran-intel/pkg/refusal"
+)
+
+// Keys look like "sii_<64 hex>". The prefix is stored in the clear so
+// owners can tell their keys apart; only the hash of the whole key is kept.
+const (
+    secretPrefix = "sii_"
+    prefixLength = len(secretPrefix) + 8
+)
+
+// Limits on what a key can be created with
+const (
+    DefaultRateLimitPerMinute = 60
+    MaxRateLimitPerMinute     = 6000
+    MaxRotationOverlap        = 7 * 24 * time.Hour
+)
+
+// Key is a tenant API key. It acts for its owner, limited to its scopes.
+type Key struct {
+    ID                 string     `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+    TenantID           string     `json:"tenant_id" gorm:"type:uuid;not null"`
+    OwnerID            string     `json:"owner_id" gorm:"type:uuid"` // The user accountable for the key
+    Name               string     `json:"name" gorm:"not null"`
+    Prefix             string     `json:"prefix"`
+    KeyHash            string     `json:"-" gorm:"not null"`
+    Scopes             []string   `json:"scopes" gorm:"serializer:json;type:jsonb;not null"` // Permissions, as in rbac rules
+    RateLimitPerMinute int        `json:"rate_limit_per_minute"`
+    IsActive           bool       `json:"is_active"`
+    ExpiresAt          *time.Time `json:"expires_at,omitempty"`
+    LastUsed           *time.Time `json:"last_used,omitempty"`
+    ReplacedBy         string     `json:"replaced_by,omitempty" gorm:"type:uuid;default:null"`
+    RevokedAt          *time.Time `json:"revoked_at,omitempty"`
+    RevokedBy          string     `json:"revoked_by,omitempty" gorm:"type:uuid;default:null"`
+    CreatedAt          time.Time  `json:"created_at"`
+}
+
+func (Key) TableName() string {
+    return "api_keys"
+}
+
+// Allows reports whether the key's scopes cover permission. Scopes use the
+// same wildcards as rbac rules ("jobs:*").
+func (k *Key) Allows(permission string) bool {
+    for _, scope := range k.Scopes {
+        if (rbac.Rule{Effect: rbac.EffectAllow, Permission: scope}).Grants(permission) {
+            return true
+        }
+    }
+    return false
+}
+
+// Usable returns nil when the key may authenticate at now
+func (k *Key) Usable(now time.Time) error {
+    switch {
+    case !k.IsActive || k.RevokedAt != nil:
+        return &refusal.Error{Reason: ErrRevoked, ID: k.ID}
+    case k.ExpiresAt != nil && !now.Before(*k.ExpiresAt):
+        return &refusal.Error{Reason: ErrExpired, ID: k.ID}
+    case k.OwnerID == "":
+        return &refusal.Error{Reason: ErrNoOwner, ID: k.ID}
+    }
+    return nil
+}
+
+// Hash is how keys are looked up; the key itself is never stored
+func Hash(secret string) string {
+    sum := sha256.Sum256([]byte(secret))
+    return hex.EncodeToString(sum[:])
+}
+
+func newSecret() (secret, prefix string, err error) {
+    b := make([]byte, 32)
+    if _, err := rand.Read(b); err != nil {
+        return "", "", fmt.Errorf("failed to generate API key: %w", err)
+    }
+    secret = secretPrefix + hex.EncodeToString(b)
+    return secret, secret[:prefixLength], nil
+}
+
+// Refusal reasons. Match them with errors.Is.
+var (
+    ErrUnknownKey     = refusal.New("API_KEY_NOT_FOUND", http.StatusNotFound, "API key not found")
+    ErrRevoked        = refusal.New("API_KEY_REVOKED", http.StatusConflict, "API key has been revoked")
+    ErrExpired        = refusal.New("API_KEY_EXPIRED", http.StatusConflict, "API key has expired")
+    ErrNoOwner        = refusal.New("API_KEY_NO_OWNER", http.StatusForbidden, "API keys must belong to a user")
+    ErrNameRequired   = refusal.New("API_KEY_INVALID", http.StatusBadRequest, "a key name is required")
+    ErrScopeRequired  = refusal.New("API_KEY_INVALID", http.StatusBadRequest, "at least one scope is required")
+    ErrScopeNotHeld   = refusal.New("API_KEY_SCOPE_NOT_HELD", http.StatusForbidden, "keys cannot be scoped beyond their owner's permissions")
+    ErrInvalidLimit   = refusal.New("API_KEY_INVALID", http.StatusBadRequest, "rate limit out of range")
+    ErrInvalidOverlap = refusal.New("API_KEY_INVALID", http.StatusBadRequest, "rotation overlap out of range")
+    ErrInvalidExpiry  = refusal.New("API_KEY_INVALID", http.StatusBadRequest, "expiry must be in the future")
+)
@@ -0,0 +1,304 @@
+// pkg/apikey/service.go
+package apikey
+
+import (
+    "context"
+    "crypto/rand"
+    "fmt"
+    "log"
+    "strings"
+    "time"
+
+    "secure-iran-intel/pkg/audit"
+    "secure-iran-intel/pkg/rbac"
+    "secure-iran-intel/pkg/refusal"
+)
+
+// Last-used updates waiting to be written. When the writer falls this far
+// behind, updates are dropped; last_used is informational.
+const usageBuffer = 1024
+
+type usage struct {
+    tenantID string
+    keyID    string
+    at       time.Time
+}
+
+// Service creates, rotates and revokes keys, and authenticates them
+type Service struct {
+    store    Store
+    policy   *rbac.Engine
+    auditLog *audit.Logger
+    used     chan usage
+}
+
+func NewService(store Store, policy *rbac.Engine, auditLog *audit.Logger) *Service {
+    return &Service{store: store, policy: policy, auditLog: auditLog, used: make(chan usage, usageBuffer)}
+}
+
+// CreateRequest describes a new key. A zero rate limit means the default.
+type CreateRequest struct {
+    Name               string     `json:"name"`
+    Scopes             []string   `json:"scopes"`
+    RateLimitPerMinute int        `json:"rate_limit_per_minute"`
+    ExpiresAt          *time.Time `json:"expires_at"`
+}
+
+// Issued is a key together with its secret, which is only ever shown once
+type Issued struct {
+    Key    *Key   `json:"key"`
+    Secret string `json:"secret"`
+}
+
+// Create issues a key owned by the user attached to ctx. Its scopes must
+// be permissions the owner holds, so keys cannot escalate.
+func (s *Service) Create(ctx context.Context, req CreateRequest) (*Issued, error) {
+    owner := audit.ActorFromContext(ctx)
+    if owner.Type != audit.ActorUser {
+        return nil, &refusal.Error{Reason: ErrNoOwner, Detail: "keys can only be created by users"}
+    }
+
+    req.Name = strings.TrimSpace(req.Name)
+    if req.Name == "" {
+        return nil, &refusal.Error{Reason: ErrNameRequired}
+    }
+    if len(req.Scopes) == 0 {
+        return nil, &refusal.Error{Reason: ErrScopeRequired}
+    }
+    if req.RateLimitPerMinute == 0 {
+        req.RateLimitPerMinute = DefaultRateLimitPerMinute
+    }
+    if req.RateLimitPerMinute < 0 || req.RateLimitPerMinute > MaxRateLimitPerMinute {
+        return nil, &refusal.Error{Reason: ErrInvalidLimit, Detail: fmt.Sprintf("must be between 1 and %d", MaxRateLimitPerMinute)}
+    }
+    if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+        return nil, &refusal.Error{Reason: ErrInvalidExpiry}
+    }
+
+    err := s.policy.Require(ctx, owner, rbac.PermAPIKeys, &rbac.Resource{Type: "api_key", TenantID: owner.TenantID, OwnerID: owner.ID})
+    if err != nil {
+        return nil, err
+    }
+    for _, scope := range req.Scopes {
+        d, err := s.policy.AuthorizeActor(ctx, owner, scope, nil)
+        if err != nil {
+            return nil, err
+        }
+        if !d.Allowed {
+            return nil, &refusal.Error{Reason: ErrScopeNotHeld, Detail: scope}
+        }
+    }
+
+    issued, err := newKey(owner.TenantID, owner.ID, req.Name, req.Scopes, req.RateLimitPerMinute, req.ExpiresAt)
+    if err != nil {
+        return nil, err
+    }
+
+    err = s.auditLog.Log(ctx, audit.Entry{
+        Action:     audit.ActionAPIKeyCreate,
+        TargetType: "api_key",
+        Target:     issued.Key.ID,
+        Metadata:   map[string]string{"name": req.Name, "scopes": strings.Join(req.Scopes, ",")},
+    })
+    if err != nil {
+        return nil, err
+    }
+    if err := s.store.Create(ctx, issued.Key); err != nil {
+        return nil, err
+    }
+    return issued, nil
+}
+
+// Rotate issues a replacement for a key with the same owner, scopes and
+// limit. The old key keeps working until overlap has passed so clients
+// can switch over.
+func (s *Service) Rotate(ctx context.Context, id string, overlap time.Duration) (*Issued, error) {
+    actor := audit.ActorFromContext(ctx)
+    if overlap < 0 || overlap > MaxRotationOverlap {
+        return nil, &refusal.Error{Reason: ErrInvalidOverlap, ID: id, Detail: fmt.Sprintf("must be at most %s", MaxRotationOverlap)}
+    }
+
+    old, err := s.manageable(ctx, actor, id)
+    if err != nil {
+        return nil, err
+    }
+    now := time.Now()
+    if err := old.Usable(now); err != nil {
+        return nil, err
+    }
+
+    next, err := newKey(old.TenantID, old.OwnerID, old.Name, old.Scopes, old.RateLimitPerMinute, old.ExpiresAt)
+    if err != nil {
+        return nil, err
+    }
+    overlapEnd := now.Add(overlap)
+
+    err = s.auditLog.Log(ctx, audit.Entry{
+        Action:     audit.ActionAPIKeyRotate,
+        TargetType: "api_key",
+        Target:     old.ID,
+        Metadata:   map[string]string{"replaced_by": next.Key.ID, "overlap_ends": overlapEnd.UTC().Format(time.RFC3339)},
+    })
+    if err != nil {
+        return nil, err
+    }
+    if err := s.store.Rotate(ctx, old, next.Key, overlapEnd); err != nil {
+        return nil, err
+    }
+    return next, nil
+}
+
+// Revoke stops a key working immediately
+func (s *Service) Revoke(ctx context.Context, id string) error {
+    actor := audit.ActorFromContext(ctx)
+    k, err := s.manageable(ctx, actor, id)
+    if err != nil {
+        return err
+    }
+
+    err = s.auditLog.Log(ctx, audit.Entry{
+        Action:     audit.ActionAPIKeyRevoke,
+        TargetType: "api_key",
+        Target:     k.ID,
+        Metadata:   map[string]string{"owner_id": k.OwnerID},
+    })
+    if err != nil {
+        return err
+    }
+    return s.store.Revoke(ctx, k.TenantID, k.ID, actor.UserID(), time.Now())
+}
+
+// List returns the tenant's keys the actor may manage: their own, or all
+// of them for tenant-wide managers
+func (s *Service) List(ctx context.Context) ([]Key, error) {
+    actor := audit.ActorFromContext(ctx)
+    keys, err := s.store.List(ctx, actor.TenantID)
+    if err != nil {
+        return nil, err
+    }
+
+    visible := make([]Key, 0, len(keys))
+    for _, k := range keys {
+        d, err := s.policy.AuthorizeActor(ctx, actor, rbac.PermAPIKeys, resourceFor(&k))
+        if err != nil {
+            return nil, err
+        }
+        if d.Allowed {
+            visible = append(visible, k)
+        }
+    }
+    return visible, nil
+}
+
+// Authenticate returns the key for a secret presented by a client, and
+// records that it was used
+func (s *Service) Authenticate(ctx context.Context, secret string) (*Key, error) {
+    if !strings.HasPrefix(secret, secretPrefix) {
+        return nil, ErrUnknownKey
+    }
+    k, err := s.store.ByHash(ctx, Hash(secret))
+    if err != nil {
+        return nil, err
+    }
+    now := time.Now()
+    if err := k.Usable(now); err != nil {
+        return nil, err
+    }
+
+    select {
+    case s.used <- usage{tenantID: k.TenantID, keyID: k.ID, at: now}:
+    default:
+    }
+    return k, nil
+}
+
+// Run writes last-used times in batches until ctx is cancelled, so
+// authenticating never waits on, or outlives, a database write
+func (s *Service) Run(ctx context.Context, interval time.Duration) {
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+
+    pending := map[string]usage{}
+    flush := func(ctx context.Context) {
+        for id, u := range pending {
+            if err := s.store.TouchLastUsed(ctx, u.tenantID, u.keyID, u.at); err != nil {
+                log.Printf("⚠️ Failed to record API key use for %s: %v", u.keyID, err)
+            }
+            delete(pending, id)
+        }
+    }
+
+    for {
+        select {
+        case u := <-s.used:
+            pending[u.keyID] = u
+        case <-ticker.C:
+            flush(ctx)
+        case <-ctx.Done():
+            // Keep what has been collected; ctx is already cancelled
+            flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+            flush(flushCtx)
+            cancel()
+            return
+        }
+    }
+}
+
+// manageable loads a key the actor may manage. Keys the actor may not
+// manage are reported as missing.
+func (s *Service) manageable(ctx context.Context, actor audit.Actor, id string) (*Key, error) {
+    k, err := s.store.Get(ctx, actor.TenantID, id)
+    if err != nil {
+        return nil, err
+    }
+    d, err := s.policy.AuthorizeActor(ctx, actor, rbac.PermAPIKeys, resourceFor(k))
+    if err != nil {
+        return nil, err
+    }
+    if !d.Allowed {
+        return nil, &refusal.Error{Reason: ErrUnknownKey, ID: id}
+    }
+    return k, nil
+}
+
+func resourceFor(k *Key) *rbac.Resource {
+    return &rbac.Resource{Type: "api_key", ID: k.ID, TenantID: k.TenantID, OwnerID: k.OwnerID}
+}
+
+func newKey(tenantID, ownerID, name string, scopes []string, limit int, expiresAt *time.Time) (*Issued, error) {
+    id, err := newID()
+    if err != nil {
+        return nil, err
+    }
+    secret, prefix, err := newSecret()
+    if err != nil {
+        return nil, err
+    }
+    return &Issued{
+        Key: &Key{
+            ID:                 id,
+            TenantID:           tenantID,
+            OwnerID:            ownerID,
+            Name:               name,
+            Prefix:             prefix,
+            KeyHash:            Hash(secret),
+            Scopes:             scopes,
+            RateLimitPerMinute: limit,
+            IsActive:           true,
+            ExpiresAt:          expiresAt,
+        },
+        Secret: secret,
+    }, nil
+}
+
+// newID returns a random (version 4) UUID, so the key can be audited
+// before it is stored
+func newID() (string, error) {
+    b := make([]byte, 16)
+    if _, err := rand.Read(b); err != nil {
+        return "", fmt.Errorf("failed to generate API key ID: %w", err)
+    }
+    b[6] = (b[6] & 0x0f) | 0x40
+    b[8] = (b[8] & 0x3f) | 0x80
+    return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
@@ -0,0 +1,140 @@
+// pkg/apikey/store.go
+package apikey
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "time"
+
+    "gorm.io/gorm"
+    "secure-iran-intel/pkg/refusal"
+    "secure-iran-intel/pkg/tenancy"
+)
+
+// Store persists keys. Everything but ByHash is scoped to one tenant.
+type Store interface {
+    Create(ctx context.Context, k *Key) error
+    // Get returns ErrUnknownKey when the tenant has no such key
+    Get(ctx context.Context, tenantID, id string) (*Key, error)
+    // ByHash finds a key before its tenant is known, to authenticate it
+    ByHash(ctx context.Context, hash string) (*Key, error)
+    List(ctx context.Context, tenantID string) ([]Key, error)
+    // Rotate stores next and makes old expire at overlapEnd, atomically
+    Rotate(ctx context.Context, old *Key, next *Key, overlapEnd time.Time) error
+    Revoke(ctx context.Context, tenantID, id, revokedBy string, at time.Time) error
+    TouchLastUsed(ctx context.Context, tenantID, id string, at time.Time) error
+}
+
+// GormStore keeps keys in the api_keys table (migrations 007 and 014)
+type GormStore struct {
+    db *gorm.DB
+}
+
+func NewGormStore(db *gorm.DB) *GormStore {
+    return &GormStore{db: db}
+}
+
+func (s *GormStore) Create(ctx context.Context, k *Key) error {
+    err := tenancy.Transaction(ctx, s.db, k.TenantID, func(tx *gorm.DB) error {
+        return tx.Create(k).Error
+    })
+    if err != nil {
+        return fmt.Errorf("failed to create API key: %w", err)
+    }
+    return nil
+}
+
+func (s *GormStore) Get(ctx context.Context, tenantID, id string) (*Key, error) {
+    var k Key
+    err := tenancy.Transaction(ctx, s.db, tenantID, func(tx *gorm.DB) error {
+        return tx.Where("tenant_id = ? AND id = ?", tenantID, id).First(&k).Error
+    })
+    if errors.Is(err, gorm.ErrRecordNotFound) {
+        return nil, &refusal.Error{Reason: ErrUnknownKey, ID: id}
+    }
+    if err != nil {
+        return nil, err
+    }
+    return &k, nil
+}
+
+func (s *GormStore) ByHash(ctx context.Context, hash string) (*Key, error) {
+    // The key is what tells us the tenant
+    var k Key
+    err := tenancy.SystemTransaction(ctx, s.db, func(tx *gorm.DB) error {
+        return tx.Where("key_hash = ?", hash).First(&k).Error
+    })
+    if errors.Is(err, gorm.ErrRecordNotFound) {
+        return nil, ErrUnknownKey
+    }
+    if err != nil {
+        return nil, err
+    }
+    return &k, nil
+}
+
+func (s *GormStore) List(ctx context.Context, tenantID string) ([]Key, error) {
+    var keys []Key
+    err := tenancy.Transaction(ctx, s.db, tenantID, func(tx *gorm.DB) error {
+        return tx.Where("tenant_id = ?", tenantID).Order("created_at DESC").Find(&keys).Error
+    })
+    return keys, err
+}
+
+func (s *GormStore) Rotate(ctx context.Context, old *Key, next *Key, overlapEnd time.Time) error {
+    err := tenancy.Transaction(ctx, s.db, old.TenantID, func(tx *gorm.DB) error {
+        if err := tx.Create(next).Error; err != nil {
+            return err
+        }
+        // Only an unrotated, unrevoked key can be rotated, once
+        result := tx.Model(&Key{}).
+            Where("id = ? AND is_active AND revoked_at IS NULL AND replaced_by IS NULL", old.ID).
+            Where("(expires_at IS NULL OR expires_at > ?)", overlapEnd).
+            Updates(map[string]interface{}{"replaced_by": next.ID, "expires_at": overlapEnd})
+        if result.Error != nil {
+            return result.Error
+        }
+        if result.RowsAffected == 0 {
+            // The key may already expire before the overlap ends
+            result = tx.Model(&Key{}).
+                Where("id = ? AND is_active AND revoked_at IS NULL AND replaced_by IS NULL", old.ID).
+                Update("replaced_by", next.ID)
+            if result.Error != nil {
+                return result.Error
+            }
+            if result.RowsAffected == 0 {
+                return &refusal.Error{Reason: ErrRevoked, ID: old.ID, Detail: "already rotated or revoked"}
+            }
+        }
+        return nil
+    })
+    if err != nil {
+        return fmt.Errorf("failed to rotate API key: %w", err)
+    }
+    return nil
+}
+
+func (s *GormStore) Revoke(ctx context.Context, tenantID, id, revokedBy string, at time.Time) error {
+    var revoked int64
+    err := tenancy.Transaction(ctx, s.db, tenantID, func(tx *gorm.DB) error {
+        result := tx.Model(&Key{}).
+            Where("tenant_id = ? AND id = ? AND revoked_at IS NULL", tenantID, id).
+            Updates(map[string]interface{}{"is_active": false, "revoked_at": at, "revoked_by": revokedBy})
+        revoked = result.RowsAffected
+        return result.Error
+    })
+    if err != nil {
+        return fmt.Errorf("failed to revoke API key: %w", err)
+    }
+    if revoked == 0 {
+        return &refusal.Error{Reason: ErrRevoked, ID: id, Detail: "already revoked"}
+    }
+    return nil
+}
+
+func (s *GormStore) TouchLastUsed(ctx context.Context, tenantID, id string, at time.Time) error {
+    return tenancy.Transaction(ctx, s.db, tenantID, func(tx *gorm.DB) error {
+        return tx.Model(&Key{}).Where("id = ?", id).UpdateColumn("last_used", at).Error
+    })
+}
@@ -0,0 +1,131 @@
+// pkg/approval/approval.go
+package approval
+
+import (
+    "fmt"
+    "net/http"
+    "time"
+
+    "secure-iran-intel/pkg/refusal"
+)
+
+// PermissionGrant lets a user approve or reject other users' jobs
+const PermissionGrant = "approvals:grant"
+
+// Kinds of job a request can hold; each service expires its own
+const (
+    JobTypeIntelligence = "intelligence_job"
+    JobTypeBulk         = "bulk_job"
+)
+
+// Request states. Pending is the only state a request can leave.
+const (
+    StatusPending  = "pending"
+    StatusApproved = "approved"
+    StatusRejected = "rejected"
+    StatusExpired  = "expired"
+)
+
+var transitions = map[string][]string{
+    StatusPending: {StatusApproved, StatusRejected, StatusExpired},
+}
+
+// CanTransition reports whether a request may move from one state to another
+func CanTransition(from, to string) bool {
+    for _, next := range transitions[from] {
+        if next == to {
+            return true
+        }
+    }
+    return false
+}
+
+// Subject is what a job would do, as far as approval policy is concerned
+type Subject struct {
+    Size       int      // Distinct phone numbers
+    Categories []string // Platforms, legal basis, ...
+}
+
+// Policy is a tenant's approval configuration
+type Policy struct {
+    TenantID          string   `json:"tenant_id" gorm:"type:uuid;primary_key"`
+    MaxUnapprovedSize int      `json:"max_unapproved_size"` // Larger jobs need approval
+    FlaggedCategories []string `json:"flagged_categories" gorm:"serializer:json;type:jsonb"`
+    DeadlineHours     int      `json:"deadline_hours"` // Undecided requests expire after this
+}
+
+func (Policy) TableName() string {
+    return "approval_policies"
+}
+
+// DefaultPolicy applies to tenants that have not configured one
+func DefaultPolicy(tenantID string) *Policy {
+    return &Policy{
+        TenantID:          tenantID,
+        MaxUnapprovedSize: 100,
+        DeadlineHours:     48,
+    }
+}
+
+// Requires reports whether a job needs a second user's approval, and why
+func (p *Policy) Requires(s Subject) (bool, string) {
+    if p.MaxUnapprovedSize > 0 && s.Size > p.MaxUnapprovedSize {
+        return true, fmt.Sprintf("%d numbers exceeds the limit of %d", s.Size, p.MaxUnapprovedSize)
+    }
+    for _, category := range s.Categories {
+        for _, flagged := range p.FlaggedCategories {
+            if category == flagged {
+                return true, fmt.Sprintf("flagged category %q", category)
+            }
+        }
+    }
+    return false, ""
+}
+
+// Deadline is how long a request may wait for a decision
+func (p *Policy) Deadline() time.Duration {
+    if p.DeadlineHours <= 0 {
+        return 48 * time.Hour
+    }
+    return time.Duration(p.DeadlineHours) * time.Hour
+}
+
+// Request is a job waiting on, or decided by, a second user
+type Request struct {
+    ID              string     `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+    TenantID        string     `json:"tenant_id" gorm:"type:uuid;not null"`
+    JobID           string     `json:"job_id" gorm:"not null"`
+    JobType         string     `json:"job_type"` // JobTypeIntelligence, JobTypeBulk
+    RequestedByType string     `json:"requested_by_type"`
+    RequestedBy     string     `json:"requested_by"`
+    Trigger         string     `json:"trigger"` // Why approval was required
+    Size            int        `json:"size"`
+    Categories      []string   `json:"categories" gorm:"serializer:json;type:jsonb"`
+    Status          string     `json:"status" gorm:"default:'pending'"`
+    DecidedBy       string     `json:"decided_by,omitempty"`
+    DecisionReason  string     `json:"decision_reason,omitempty"`
+    DecidedAt       *time.Time `json:"decided_at,omitempty"`
+    ExpiresAt       time.Time  `json:"expires_at"`
+    CreatedAt       time.Time  `json:"created_at"`
+    UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+func (Request) TableName() string {
+    return "approval_requests"
+}
+
+// Overdue reports whether a pending request has passed its deadline
+func (r *Request) Overdue(now time.Time) bool {
+    return r.Status == StatusPending && !now.Before(r.ExpiresAt)
+}
+
+// Rejection reasons. Match them with errors.Is.
+var (
+    ErrUnknownRequest = refusal.New("APPROVAL_NOT_FOUND", http.StatusNotFound, "approval request not found")
+    ErrNotPermitted   = refusal.New("APPROVAL_NOT_PERMITTED", http.StatusForbidden, "approvals:grant permission required")
+    ErrSelfApproval   = refusal.New("SELF_APPROVAL", http.StatusForbidden, "requesters cannot approve their own jobs")
+    ErrReasonRequired = refusal.New("APPROVAL_REASON_REQUIRED", http.StatusBadRequest, "a reason is required")
+    ErrNotPending     = refusal.New("APPROVAL_ALREADY_DECIDED", http.StatusConflict, "approval request has already been decided")
+    ErrExpired        = refusal.New("APPROVAL_EXPIRED", http.StatusConflict, "approval request has expired")
+    ErrInvalidPolicy  = refusal.New("APPROVAL_POLICY_INVALID", http.StatusBadRequest, "size limit and deadline cannot be negative")
+)
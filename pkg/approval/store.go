@@ -0,0 +1,145 @@
+// pkg/approval/store.go
+package approval
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "time"
+
+    "gorm.io/gorm"
+    "gorm.io/gorm/clause"
+    "secure-iran-intel/pkg/tenancy"
+)
+
+// Store persists policies and requests. Decide must only move a request
+// that is still pending, so two approvers cannot both decide it.
+type Store interface {
+    // GetPolicy returns DefaultPolicy when the tenant has not configured one
+    GetPolicy(ctx context.Context, tenantID string) (*Policy, error)
+    // SetPolicy creates or replaces the tenant's policy
+    SetPolicy(ctx context.Context, p *Policy) error
+    Create(ctx context.Context, r *Request) error
+    // ForJob returns ErrUnknownRequest when the job has no request
+    ForJob(ctx context.Context, tenantID, jobID string) (*Request, error)
+    // Decide returns ErrNotPending when the request has left pending
+    Decide(ctx context.Context, r *Request) error
+    ListPending(ctx context.Context, tenantID string) ([]Request, error)
+    // ExpireOverdue moves pending requests of jobType past their deadline
+    // to expired
+    ExpireOverdue(ctx context.Context, jobType string, now time.Time) ([]Request, error)
+}
+
+// GormStore keeps approvals in the approval_policies and approval_requests
+// tables (migration 011)
+type GormStore struct {
+    db *gorm.DB
+}
+
+func NewGormStore(db *gorm.DB) *GormStore {
+    return &GormStore{db: db}
+}
+
+func (s *GormStore) GetPolicy(ctx context.Context, tenantID string) (*Policy, error) {
+    var p Policy
+    err := tenancy.Transaction(ctx, s.db, tenantID, func(tx *gorm.DB) error {
+        return tx.Where("tenant_id = ?", tenantID).First(&p).Error
+    })
+    if errors.Is(err, gorm.ErrRecordNotFound) {
+        return DefaultPolicy(tenantID), nil
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to load approval policy: %w", err)
+    }
+    return &p, nil
+}
+
+func (s *GormStore) SetPolicy(ctx context.Context, p *Policy) error {
+    err := tenancy.Transaction(ctx, s.db, p.TenantID, func(tx *gorm.DB) error {
+        return tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(p).Error
+    })
+    if err != nil {
+        return fmt.Errorf("failed to save approval policy: %w", err)
+    }
+    return nil
+}
+
+func (s *GormStore) Create(ctx context.Context, r *Request) error {
+    err := tenancy.Transaction(ctx, s.db, r.TenantID, func(tx *gorm.DB) error {
+        return tx.Create(r).Error
+    })
+    if err != nil {
+        return fmt.Errorf("failed to create approval request: %w", err)
+    }
+    return nil
+}
+
+func (s *GormStore) ForJob(ctx context.Context, tenantID, jobID string) (*Request, error) {
+    var r Request
+    err := tenancy.Transaction(ctx, s.db, tenantID, func(tx *gorm.DB) error {
+        return tx.Where("tenant_id = ? AND job_id = ?", tenantID, jobID).
+            Order("created_at DESC").
+            First(&r).Error
+    })
+    if errors.Is(err, gorm.ErrRecordNotFound) {
+        return nil, ErrUnknownRequest
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to load approval request: %w", err)
+    }
+    return &r, nil
+}
+
+func (s *GormStore) Decide(ctx context.Context, r *Request) error {
+    var decided int64
+    err := tenancy.Transaction(ctx, s.db, r.TenantID, func(tx *gorm.DB) error {
+        result := tx.Model(&Request{}).
+            Where("id = ? AND status = ?", r.ID, StatusPending).
+            Updates(map[string]interface{}{
+                "status":          r.Status,
+                "decided_by":      r.DecidedBy,
+                "decision_reason": r.DecisionReason,
+                "decided_at":      r.DecidedAt,
+                "updated_at":      time.Now(),
+            })
+        decided = result.RowsAffected
+        return result.Error
+    })
+    if err != nil {
+        return fmt.Errorf("failed to record approval decision: %w", err)
+    }
+    if decided == 0 {
+        return ErrNotPending
+    }
+    return nil
+}
+
+func (s *GormStore) ListPending(ctx context.Context, tenantID string) ([]Request, error) {
+    var requests []Request
+    err := tenancy.Transaction(ctx, s.db, tenantID, func(tx *gorm.DB) error {
+        return tx.Where("tenant_id = ? AND status = ? AND expires_at > ?", tenantID, StatusPending, time.Now()).
+            Order("expires_at").
+            Find(&requests).Error
+    })
+    return requests, err
+}
+
+func (s *GormStore) ExpireOverdue(ctx context.Context, jobType string, now time.Time) ([]Request, error) {
+    // The sweeper serves every tenant
+    var expired []Request
+    err := tenancy.SystemTransaction(ctx, s.db, func(tx *gorm.DB) error {
+        return tx.Model(&expired).
+            Clauses(clause.Returning{}).
+            Where("job_type = ? AND status = ? AND expires_at <= ?", jobType, StatusPending, now).
+            Updates(map[string]interface{}{"status": StatusExpired, "updated_at": now}).Error
+    })
+    if err != nil {
+        return nil, fmt.Errorf("failed to expire approval requests: %w", err)
+    }
+    return expired, nil
+}
+
+// Authorizer decides whether a user holds a permission; rbac.Engine is one
+type Authorizer interface {
+    HasPermission(ctx context.Context, tenantID, userID, permission string) (bool, error)
+}
@@ -0,0 +1,232 @@
+// pkg/approval/workflow.go
+package approval
+
+import (
+    "context"
+    "errors"
+    "log"
+    "strconv"
+    "strings"
+    "time"
+
+    "secure-iran-intel/pkg/audit"
+    "secure-iran-intel/pkg/refusal"
+)
+
+// Workflow holds large and sensitive jobs until a second user decides them
+type Workflow struct {
+    store    Store
+    authz    Authorizer
+    auditLog *audit.Logger
+}
+
+func NewWorkflow(store Store, authz Authorizer, auditLog *audit.Logger) *Workflow {
+    return &Workflow{store: store, authz: authz, auditLog: auditLog}
+}
+
+// Gate opens a pending request for the job when the tenant's policy needs
+// one, and returns nil when the job may be queued straight away. The
+// requester is the actor attached to ctx.
+func (w *Workflow) Gate(ctx context.Context, jobType, jobID string, subject Subject) (*Request, error) {
+    requester := audit.ActorFromContext(ctx)
+    policy, err := w.store.GetPolicy(ctx, requester.TenantID)
+    if err != nil {
+        return nil, err
+    }
+
+    required, trigger := policy.Requires(subject)
+    if !required {
+        return nil, nil
+    }
+
+    // A key's jobs count as its owner's, so the owner cannot approve them
+    requestedByType, requestedBy := requester.Type, requester.ID
+    if requester.Type == audit.ActorAPIKey {
+        requestedByType, requestedBy = audit.ActorUser, requester.UserID()
+    }
+
+    r := &Request{
+        TenantID:        requester.TenantID,
+        JobID:           jobID,
+        JobType:         jobType,
+        RequestedByType: requestedByType,
+        RequestedBy:     requestedBy,
+        Trigger:         trigger,
+        Size:            subject.Size,
+        Categories:      subject.Categories,
+        Status:          StatusPending,
+        ExpiresAt:       time.Now().Add(policy.Deadline()),
+    }
+
+    err = w.auditLog.Log(ctx, audit.Entry{
+        Action:     audit.ActionApprovalRequest,
+        TargetType: "job",
+        Target:     jobID,
+        Metadata:   map[string]string{"trigger": trigger, "expires_at": r.ExpiresAt.UTC().Format(time.RFC3339)},
+    })
+    if err != nil {
+        return nil, err
+    }
+    if err := w.store.Create(ctx, r); err != nil {
+        return nil, err
+    }
+    return r, nil
+}
+
+// Approve lets the job run. The approver is the actor attached to ctx and
+// must be a different user holding PermissionGrant.
+func (w *Workflow) Approve(ctx context.Context, jobID, reason string) (*Request, error) {
+    return w.decide(ctx, jobID, StatusApproved, reason)
+}
+
+// Reject stops the job from running
+func (w *Workflow) Reject(ctx context.Context, jobID, reason string) (*Request, error) {
+    return w.decide(ctx, jobID, StatusRejected, reason)
+}
+
+func (w *Workflow) decide(ctx context.Context, jobID, status, reason string) (*Request, error) {
+    reason = strings.TrimSpace(reason)
+    if reason == "" {
+        return nil, &refusal.Error{Reason: ErrReasonRequired}
+    }
+
+    // Only people approve: API keys and services never hold the grant
+    approver := audit.ActorFromContext(ctx)
+    if approver.Type != audit.ActorUser || approver.ID == "" {
+        return nil, &refusal.Error{Reason: ErrNotPermitted, Detail: approver.Type}
+    }
+    granted, err := w.authz.HasPermission(ctx, approver.TenantID, approver.ID, PermissionGrant)
+    if err != nil {
+        return nil, err
+    }
+    if !granted {
+        return nil, &refusal.Error{Reason: ErrNotPermitted}
+    }
+
+    r, err := w.store.ForJob(ctx, approver.TenantID, jobID)
+    if err != nil {
+        if errors.Is(err, ErrUnknownRequest) {
+            return nil, &refusal.Error{Reason: ErrUnknownRequest, Detail: jobID}
+        }
+        return nil, err
+    }
+    // The sweeper in Run moves it to expired and cancels the job
+    if r.Overdue(time.Now()) {
+        return nil, &refusal.Error{Reason: ErrExpired, ID: r.ID}
+    }
+    if !CanTransition(r.Status, status) {
+        return nil, &refusal.Error{Reason: ErrNotPending, ID: r.ID, Detail: r.Status}
+    }
+    if status == StatusApproved && r.RequestedByType == approver.Type && r.RequestedBy == approver.ID {
+        return nil, &refusal.Error{Reason: ErrSelfApproval, ID: r.ID}
+    }
+
+    action := audit.ActionApprovalGrant
+    if status == StatusRejected {
+        action = audit.ActionApprovalReject
+    }
+    err = w.auditLog.Log(ctx, audit.Entry{
+        Action:        action,
+        TargetType:    "job",
+        Target:        jobID,
+        Justification: reason,
+        Metadata:      map[string]string{"approval_id": r.ID, "requested_by": r.RequestedBy},
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    now := time.Now()
+    r.Status = status
+    r.DecidedBy = approver.ID
+    r.DecisionReason = reason
+    r.DecidedAt = &now
+    if err := w.store.Decide(ctx, r); err != nil {
+        if errors.Is(err, ErrNotPending) {
+            return nil, &refusal.Error{Reason: ErrNotPending, ID: r.ID}
+        }
+        return nil, err
+    }
+    return r, nil
+}
+
+// Policy returns the actor's tenant's approval policy
+func (w *Workflow) Policy(ctx context.Context) (*Policy, error) {
+    return w.store.GetPolicy(ctx, audit.ActorFromContext(ctx).TenantID)
+}
+
+// SetPolicy replaces the actor's tenant's approval policy. Requests
+// already pending keep the deadline they were opened with.
+func (w *Workflow) SetPolicy(ctx context.Context, p Policy) (*Policy, error) {
+    actor := audit.ActorFromContext(ctx)
+    if p.MaxUnapprovedSize < 0 || p.DeadlineHours < 0 {
+        return nil, &refusal.Error{Reason: ErrInvalidPolicy, ID: actor.TenantID}
+    }
+    p.TenantID = actor.TenantID
+
+    err := w.auditLog.Log(ctx, audit.Entry{
+        Action:     audit.ActionApprovalPolicy,
+        TargetType: "tenant",
+        Target:     actor.TenantID,
+        Metadata: map[string]string{
+            "max_unapproved_size": strconv.Itoa(p.MaxUnapprovedSize),
+            "flagged_categories":  strings.Join(p.FlaggedCategories, ","),
+            "deadline_hours":      strconv.Itoa(p.DeadlineHours),
+        },
+    })
+    if err != nil {
+        return nil, err
+    }
+    if err := w.store.SetPolicy(ctx, &p); err != nil {
+        return nil, err
+    }
+    return &p, nil
+}
+
+// Pending lists the tenant's requests still awaiting a decision
+func (w *Workflow) Pending(ctx context.Context, tenantID string) ([]Request, error) {
+    return w.store.ListPending(ctx, tenantID)
+}
+
+// ExpireOverdue expires requests of jobType past their deadline and
+// returns them
+func (w *Workflow) ExpireOverdue(ctx context.Context, jobType string) ([]Request, error) {
+    expired, err := w.store.ExpireOverdue(ctx, jobType, time.Now())
+    if err != nil || len(expired) == 0 {
+        return expired, err
+    }
+
+    entries := make([]audit.Entry, 0, len(expired))
+    for _, r := range expired {
+        entries = append(entries, audit.Entry{
+            Action:     audit.ActionApprovalExpire,
+            TargetType: "job",
+            Target:     r.JobID,
+            Actor:      &audit.Actor{TenantID: r.TenantID, Type: audit.ActorSystem},
+            Metadata:   map[string]string{"approval_id": r.ID},
+        })
+    }
+    return expired, w.auditLog.Log(ctx, entries...)
+}
+
+// Run expires overdue requests of jobType every interval until ctx is
+// cancelled, passing each one to onExpired so its job can be cancelled
+func (w *Workflow) Run(ctx context.Context, interval time.Duration, jobType string, onExpired func(Request)) {
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+            expired, err := w.ExpireOverdue(ctx, jobType)
+            if err != nil {
+                log.Printf("⚠️ Approval expiry failed: %v", err)
+            }
+            for _, r := range expired {
+                onExpired(r)
+            }
+        }
+    }
+}
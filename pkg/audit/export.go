@@ -0,0 +1,25 @@
+// pkg/audit/export.go
+package audit
+
+import (
+    "context"
+    "encoding/json"
+    "io"
+)
+
+// Export writes the tenant's records as JSON lines, in sequence order, and
+// returns how many it wrote. The chain spans every tenant, so an export
+// cannot be verified on its own; each record keeps its hash, which can be
+// checked against the full log.
+func Export(ctx context.Context, store Store, tenantID string, w io.Writer) (int64, error) {
+    var n int64
+    encoder := json.NewEncoder(w)
+    err := store.Scan(ctx, func(r *Record) error {
+        if r.TenantID != tenantID {
+            return nil
+        }
+        n++
+        return encoder.Encode(r)
+    })
+    return n, err
+}
@@ -0,0 +1,90 @@
+// pkg/audit/file_store.go
+package audit
+
+import (
+    "bufio"
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "os"
+    "sync"
+)
+
+// FileStore keeps the chain as JSON lines in a single file, for services
+// without a database. Only one process may append to a file.
+type FileStore struct {
+    path string
+    mu   sync.Mutex
+    head *Record
+    read bool // head has been loaded from the file
+}
+
+func NewFileStore(path string) *FileStore {
+    return &FileStore{path: path}
+}
+
+func (s *FileStore) Append(ctx context.Context, records []*Record) error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    if !s.read {
+        if err := s.Scan(ctx, func(r *Record) error {
+            s.head = r
+            return nil
+        }); err != nil && !errors.Is(err, os.ErrNotExist) {
+            return err
+        }
+        s.read = true
+    }
+
+    link(s.head, records)
+
+    f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+    if err != nil {
+        return err
+    }
+    defer f.Close()
+
+    w := bufio.NewWriter(f)
+    encoder := json.NewEncoder(w)
+    for _, r := range records {
+        if err := encoder.Encode(r); err != nil {
+            return err
+        }
+    }
+    if err := w.Flush(); err != nil {
+        return err
+    }
+    if err := f.Sync(); err != nil {
+        return err
+    }
+
+    s.head = records[len(records)-1]
+    return nil
+}
+
+func (s *FileStore) Scan(ctx context.Context, fn func(*Record) error) error {
+    f, err := os.Open(s.path)
+    if err != nil {
+        return err
+    }
+    defer f.Close()
+
+    scanner := bufio.NewScanner(f)
+    scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+    for line := 1; scanner.Scan(); line++ {
+        if err := ctx.Err(); err != nil {
+            return err
+        }
+
+        var r Record
+        if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+            return fmt.Errorf("line %d: %w", line, err)
+        }
+        if err := fn(&r); err != nil {
+            return err
+        }
+    }
+    return scanner.Err()
+}
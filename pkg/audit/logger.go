@@ -0,0 +1,76 @@
+// pkg/audit/logger.go
+package audit
+
+import (
+    "context"
+    "fmt"
+    "time"
+)
+
+// Entry describes one action to record. Tenant, actor and justification
+// default to those attached to the context.
+type Entry struct {
+    Action        string
+    TargetType    string // e.g. "phone", "job", "report"
+    Target        string // Hashed before it is stored
+    Justification string
+    Actor         *Actor
+    Metadata      map[string]string
+}
+
+// Logger appends entries to the hash-chained log. Callers treat a failed
+// write as a failed action: an access that cannot be recorded must not
+// happen.
+type Logger struct {
+    store Store
+}
+
+func NewLogger(store Store) *Logger {
+    return &Logger{store: store}
+}
+
+// Log records entries atomically and in order
+func (l *Logger) Log(ctx context.Context, entries ...Entry) error {
+    if len(entries) == 0 {
+        return nil
+    }
+
+    // Postgres keeps microseconds; hash what will be read back
+    now := time.Now().UTC().Truncate(time.Microsecond)
+    actor := ActorFromContext(ctx)
+    justification := JustificationFromContext(ctx)
+
+    records := make([]*Record, 0, len(entries))
+    for _, entry := range entries {
+        who := actor
+        if entry.Actor != nil {
+            who = *entry.Actor
+        }
+        why := justification
+        if entry.Justification != "" {
+            why = entry.Justification
+        }
+
+        targetHash, err := HashTarget(entry.TargetType, entry.Target)
+        if err != nil {
+            return fmt.Errorf("audit log append failed: %w", err)
+        }
+
+        records = append(records, &Record{
+            RecordedAt:    now,
+            TenantID:      who.TenantID,
+            ActorType:     who.Type,
+            ActorID:       who.ID,
+            Action:        entry.Action,
+            TargetType:    entry.TargetType,
+            TargetHash:    targetHash,
+            Justification: why,
+            Metadata:      entry.Metadata,
+        })
+    }
+
+    if err := l.store.Append(ctx, records); err != nil {
+        return fmt.Errorf("audit log append failed: %w", err)
+    }
+    return nil
+}
@@ -0,0 +1,237 @@
+// pkg/audit/record.go
+package audit
+
+import (
+    "context"
+    "crypto/hmac"
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "sync"
+    "time"
+)
+
+// Actions recorded by the services
+const (
+    ActionJobCreate            = "job.create"
+    ActionJobView              = "job.view"
+    ActionJobList              = "job.list"
+    ActionPhoneLookup          = "phone.lookup"
+    ActionReportCreate         = "report.generate"
+    ActionReportView           = "report.view"
+    ActionReportExport         = "report.export"
+    ActionApprovalRequest      = "approval.request"
+    ActionApprovalGrant        = "approval.grant"
+    ActionApprovalReject       = "approval.reject"
+    ActionApprovalExpire       = "approval.expire"
+    ActionApprovalPolicy       = "approval.policy"
+    ActionAPIKeyCreate         = "api_key.create"
+    ActionAPIKeyRotate         = "api_key.rotate"
+    ActionAPIKeyRevoke         = "api_key.revoke"
+    ActionSSOLogin             = "sso.login"
+    ActionSCIMProvision        = "scim.provision"
+    ActionSCIMUpdate           = "scim.update"
+    ActionSCIMDeprovision      = "scim.deprovision"
+    ActionMFAEnroll            = "mfa.enroll"
+    ActionMFARemove            = "mfa.remove"
+    ActionMFAVerify            = "mfa.verify"
+    ActionMFAFailure           = "mfa.verify_failed"
+    ActionMFARecoveryCodes     = "mfa.recovery_codes"
+    ActionTenantSuspend        = "tenant.suspend"
+    ActionTenantResume         = "tenant.resume"
+    ActionTenantOffboard       = "tenant.offboard"
+    ActionTenantPurge          = "tenant.purge"
+    ActionRetentionPurge       = "retention.purge"
+    ActionLegalHoldPlace       = "legal_hold.place"
+    ActionLegalHoldRelease     = "legal_hold.release"
+    ActionSubjectRequestOpen   = "subject_request.open"
+    ActionSubjectRequestSearch = "subject_request.search"
+    ActionSubjectRequestFulfil = "subject_request.fulfil"
+    ActionSubjectRequestReject = "subject_request.reject"
+    ActionSubjectRequestExtend = "subject_request.extend"
+    ActionSuppressionAdd       = "suppression.add"
+    ActionSuppressionRemove    = "suppression.remove"
+    ActionSuppressionBlock     = "suppression.block"
+    ActionCaseOpen             = "case.open"
+    ActionCaseClose            = "case.close"
+    ActionAdmin                = "admin" // Suffixed with the HTTP method and route
+)
+
+// Actor types. ActorNone is reported for contexts nobody authenticated;
+// it is never authorized. Background work sets ActorSystem explicitly.
+const (
+    ActorUser   = "user"
+    ActorAPIKey = "api_key"
+    ActorSystem = "system"
+    ActorNone   = "none"
+)
+
+// genesisHash is the PrevHash of the first record
+const genesisHash = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// Record is one entry of the append-only log. Hash covers every other field,
+// PrevHash included, so editing, removing or reordering any record breaks
+// the chain from that point on.
+type Record struct {
+    Seq           int64             `json:"seq" gorm:"primaryKey;autoIncrement:false"`
+    RecordedAt    time.Time         `json:"recorded_at"`
+    TenantID      string            `json:"tenant_id"`
+    ActorType     string            `json:"actor_type"`
+    ActorID       string            `json:"actor_id"`
+    Action        string            `json:"action"`
+    TargetType    string            `json:"target_type"`
+    TargetHash    string            `json:"target_hash"`
+    Justification string            `json:"justification"`
+    Metadata      map[string]string `json:"metadata,omitempty" gorm:"serializer:json"`
+    PrevHash      string            `json:"prev_hash"`
+    Hash          string            `json:"hash"`
+}
+
+func (Record) TableName() string {
+    return "audit_log"
+}
+
+// ComputeHash returns the SHA-256 chain hash of the record's contents
+func (r *Record) ComputeHash() string {
+    // Field order is fixed by the struct and map keys are sorted by
+    // encoding/json, so the encoding is canonical
+    canonical, _ := json.Marshal(struct {
+        Seq           int64             `json:"seq"`
+        RecordedAt    string            `json:"recorded_at"`
+        TenantID      string            `json:"tenant_id"`
+        ActorType     string            `json:"actor_type"`
+        ActorID       string            `json:"actor_id"`
+        Action        string            `json:"action"`
+        TargetType    string            `json:"target_type"`
+        TargetHash    string            `json:"target_hash"`
+        Justification string            `json:"justification"`
+        Metadata      map[string]string `json:"metadata"`
+        PrevHash      string            `json:"prev_hash"`
+    }{
+        Seq:           r.Seq,
+        RecordedAt:    r.RecordedAt.UTC().Format(time.RFC3339Nano),
+        TenantID:      r.TenantID,
+        ActorType:     r.ActorType,
+        ActorID:       r.ActorID,
+        Action:        r.Action,
+        TargetType:    r.TargetType,
+        TargetHash:    r.TargetHash,
+        Justification: r.Justification,
+        Metadata:      r.Metadata,
+        PrevHash:      r.PrevHash,
+    })
+
+    sum := sha256.Sum256(canonical)
+    return hex.EncodeToString(sum[:])
+}
+
+// link chains records onto head (nil for an empty log), assigning sequence
+// numbers and hashes. Stores call it while holding their append lock.
+func link(head *Record, records []*Record) {
+    seq, prev := int64(0), genesisHash
+    if head != nil {
+        seq, prev = head.Seq, head.Hash
+    }
+
+    for _, r := range records {
+        seq++
+        r.Seq = seq
+        r.PrevHash = prev
+        r.Hash = r.ComputeHash()
+        prev = r.Hash
+    }
+}
+
+// Target keys shorter than this are refused
+const minTargetKeyBytes = 32
+
+// ErrNoTargetKey is returned by HashTarget before SetTargetKey was called
+var ErrNoTargetKey = errors.New("no audit target key configured")
+
+var (
+    targetKeyMu sync.RWMutex
+    targetKey   []byte
+)
+
+// SetTargetKey sets the secret target hashes are keyed with. Every process
+// writing to or searching the log must use the same key.
+func SetTargetKey(key []byte) error {
+    if len(key) < minTargetKeyBytes {
+        return fmt.Errorf("audit target key must be at least %d bytes, got %d", minTargetKeyBytes, len(key))
+    }
+    targetKeyMu.Lock()
+    targetKey = append([]byte(nil), key...)
+    targetKeyMu.Unlock()
+    return nil
+}
+
+// HashTarget identifies what was accessed without storing it. It is an
+// HMAC under the target key, so a copy of the log cannot be used to confirm
+// a guessed phone number. Compliance queries hash the value they are asking
+// about and search for it.
+func HashTarget(targetType, value string) (string, error) {
+    targetKeyMu.RLock()
+    defer targetKeyMu.RUnlock()
+    if targetKey == nil {
+        return "", ErrNoTargetKey
+    }
+
+    mac := hmac.New(sha256.New, targetKey)
+    mac.Write([]byte(targetType + ":" + value))
+    return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// Actor is who performed an action, on behalf of which tenant
+type Actor struct {
+    TenantID string
+    Type     string // ActorUser, ActorAPIKey or ActorSystem
+    ID       string
+    // OnBehalfOf is the user an API key belongs to; keys act with their
+    // owner's roles
+    OnBehalfOf string
+}
+
+// UserID is the user accountable for what the actor does: the user itself
+// or an API key's owner. It is empty for the system actor.
+func (a Actor) UserID() string {
+    switch a.Type {
+    case ActorUser:
+        return a.ID
+    case ActorAPIKey:
+        return a.OnBehalfOf
+    default:
+        return ""
+    }
+}
+
+type contextKey string
+
+const (
+    actorKey         contextKey = "audit_actor"
+    justificationKey contextKey = "audit_justification"
+)
+
+// WithActor attaches the authenticated actor to ctx
+func WithActor(ctx context.Context, actor Actor) context.Context {
+    return context.WithValue(ctx, actorKey, actor)
+}
+
+// ActorFromContext returns the actor attached to ctx, or ActorNone
+func ActorFromContext(ctx context.Context) Actor {
+    if actor, ok := ctx.Value(actorKey).(Actor); ok {
+        return actor
+    }
+    return Actor{Type: ActorNone}
+}
+
+// WithJustification attaches the caller's stated reason for an access
+func WithJustification(ctx context.Context, justification string) context.Context {
+    return context.WithValue(ctx, justificationKey, justification)
+}
+
+func JustificationFromContext(ctx context.Context) string {
+    justification, _ := ctx.Value(justificationKey).(string)
+    return justification
+}
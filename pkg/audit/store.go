@@ -0,0 +1,90 @@
+// pkg/audit/store.go
+package audit
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "strings"
+
+    "gorm.io/driver/postgres"
+    "gorm.io/gorm"
+)
+
+// Store persists the chain. Append must link records onto the current head
+// and persist them atomically, with appends from every process serialized.
+type Store interface {
+    Append(ctx context.Context, records []*Record) error
+    // Scan calls fn for every record in sequence order
+    Scan(ctx context.Context, fn func(*Record) error) error
+}
+
+// Open returns the store for a URL: "postgres://..." or "file:///path"
+func Open(url string) (Store, error) {
+    switch {
+    case strings.HasPrefix(url, "postgres://"), strings.HasPrefix(url, "postgresql://"):
+        db, err := gorm.Open(postgres.Open(url), &gorm.Config{})
+        if err != nil {
+            return nil, fmt.Errorf("failed to connect to audit database: %w", err)
+        }
+        return NewGormStore(db), nil
+    case strings.HasPrefix(url, "file://"):
+        return NewFileStore(strings.TrimPrefix(url, "file://")), nil
+    default:
+        return nil, fmt.Errorf("unsupported audit store URL: %q", url)
+    }
+}
+
+// GormStore keeps the chain in the audit_log table (migration 009), which
+// rejects UPDATE, DELETE and TRUNCATE
+type GormStore struct {
+    db *gorm.DB
+}
+
+func NewGormStore(db *gorm.DB) *GormStore {
+    return &GormStore{db: db}
+}
+
+// Arbitrary key for the transaction-scoped advisory lock that serializes
+// appends across every service writing to the log
+const appendLockKey = 0x61756469
+
+func (s *GormStore) Append(ctx context.Context, records []*Record) error {
+    return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+        if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", appendLockKey).Error; err != nil {
+            return err
+        }
+
+        var head Record
+        err := tx.Order("seq DESC").Take(&head).Error
+        switch {
+        case errors.Is(err, gorm.ErrRecordNotFound):
+            link(nil, records)
+        case err != nil:
+            return err
+        default:
+            link(&head, records)
+        }
+
+        return tx.Create(records).Error
+    })
+}
+
+func (s *GormStore) Scan(ctx context.Context, fn func(*Record) error) error {
+    rows, err := s.db.WithContext(ctx).Model(&Record{}).Order("seq").Rows()
+    if err != nil {
+        return err
+    }
+    defer rows.Close()
+
+    for rows.Next() {
+        var r Record
+        if err := s.db.ScanRows(rows, &r); err != nil {
+            return err
+        }
+        if err := fn(&r); err != nil {
+            return err
+        }
+    }
+    return rows.Err()
+}
@@ -0,0 +1,99 @@
+// pkg/audit/verify.go
+package audit
+
+import (
+    "context"
+    "fmt"
+)
+
+// Problem is one inconsistency found while verifying the chain
+type Problem struct {
+    Seq    int64  `json:"seq"`
+    Kind   string `json:"kind"` // "edited", "missing", "relinked" or "anchor"
+    Detail string `json:"detail"`
+}
+
+// Anchor is a head previously published outside the log (a ticket, a
+// signed email). Without one, removing records from the end of the log
+// cannot be told apart from those records never having been written.
+type Anchor struct {
+    Seq  int64
+    Hash string
+}
+
+// VerifyResult summarises a verification run
+type VerifyResult struct {
+    Records  int64     `json:"records"`
+    HeadSeq  int64     `json:"head_seq"`
+    HeadHash string    `json:"head_hash"`
+    Problems []Problem `json:"problems"`
+}
+
+func (vr *VerifyResult) OK() bool {
+    return len(vr.Problems) == 0
+}
+
+// Verify walks the whole chain and reports every record whose contents no
+// longer match its hash, every gap in the sequence and every broken link.
+func Verify(ctx context.Context, store Store, anchors ...Anchor) (*VerifyResult, error) {
+    result := &VerifyResult{}
+    expectSeq, expectPrev := int64(1), genesisHash
+
+    wanted := make(map[int64]string, len(anchors))
+    for _, anchor := range anchors {
+        wanted[anchor.Seq] = anchor.Hash
+    }
+
+    err := store.Scan(ctx, func(r *Record) error {
+        result.Records++
+
+        if r.Seq != expectSeq {
+            result.Problems = append(result.Problems, Problem{
+                Seq:    r.Seq,
+                Kind:   "missing",
+                Detail: fmt.Sprintf("expected seq %d, records %d-%d are missing", expectSeq, expectSeq, r.Seq-1),
+            })
+        }
+        if r.PrevHash != expectPrev {
+            result.Problems = append(result.Problems, Problem{
+                Seq:    r.Seq,
+                Kind:   "relinked",
+                Detail: "prev_hash does not match the preceding record",
+            })
+        }
+        if computed := r.ComputeHash(); computed != r.Hash {
+            result.Problems = append(result.Problems, Problem{
+                Seq:    r.Seq,
+                Kind:   "edited",
+                Detail: fmt.Sprintf("stored hash %s, contents hash to %s", r.Hash, computed),
+            })
+        }
+        if hash, ok := wanted[r.Seq]; ok {
+            if hash != r.Hash {
+                result.Problems = append(result.Problems, Problem{
+                    Seq:    r.Seq,
+                    Kind:   "anchor",
+                    Detail: fmt.Sprintf("anchored hash %s, log has %s", hash, r.Hash),
+                })
+            }
+            delete(wanted, r.Seq)
+        }
+
+        expectSeq, expectPrev = r.Seq+1, r.Hash
+        result.HeadSeq, result.HeadHash = r.Seq, r.Hash
+        return nil
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    for seq := range wanted {
+        result.Problems = append(result.Problems, Problem{
+            Seq:    seq,
+            Kind:   "anchor",
+            Detail: fmt.Sprintf("anchored record is beyond the head at %d; the log was truncated", result.HeadSeq),
+        })
+    }
+
+    return result, nil
+}
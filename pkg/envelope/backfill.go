@@ -0,0 +1,226 @@
+// pkg/envelope/backfill.go
+package envelope
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+    "strings"
+
+    "gorm.io/gorm"
+    "secure-iran-intel/pkg/tenancy"
+)
+
+// Column is a column the encrypted serializer seals. Index names the
+// column holding its blind index, if it has one; Normalize, when set, is
+// applied to the value before indexing, as the column's writers do.
+type Column struct {
+    Name      string
+    Index     string
+    Normalize func(string) string
+}
+
+// Table lists the sealed columns of a table keyed by id. Rows are sealed
+// for their tenant_id, or for Tenant when it is set. Where, when set,
+// limits which rows are sealed.
+type Table struct {
+    Name    string
+    Columns []Column
+    Tenant  string
+    Where   string
+}
+
+// BackfillResult counts what Backfill did to one table
+type BackfillResult struct {
+    Sealed     int64 // Rows whose values were sealed or indexed
+    Duplicates int64 // Rows deleted because an equal row was already sealed
+    Skipped    int64 // Rows of shredded tenants, left as they were
+}
+
+// Backfill seals the values of t written before encryption and fills in
+// their blind indexes, batch rows per transaction. It reads every tenant,
+// so db must connect as a member of intel_system. Running it again visits
+// only the rows it has not finished.
+func (k *Keyring) Backfill(ctx context.Context, db *gorm.DB, t Table, batch int) (BackfillResult, error) {
+    var total BackfillResult
+    after := ""
+    for {
+        var rows []pendingRow
+        var done BackfillResult
+        err := tenancy.SystemTransaction(ctx, db, func(tx *gorm.DB) error {
+            var err error
+            if rows, err = t.pending(tx, after, batch); err != nil {
+                return err
+            }
+            for _, row := range rows {
+                if err := k.backfillRow(ctx, tx, t, row, &done); err != nil {
+                    return fmt.Errorf("%s %s: %w", t.Name, row.id, err)
+                }
+            }
+            return nil
+        })
+        if err != nil {
+            return total, err
+        }
+        total.Sealed += done.Sealed
+        total.Duplicates += done.Duplicates
+        total.Skipped += done.Skipped
+        if len(rows) < batch {
+            return total, nil
+        }
+        after = rows[len(rows)-1].id
+    }
+}
+
+// pendingRow is a row with a value still in plaintext or not indexed
+type pendingRow struct {
+    id       string
+    tenantID string
+    values   []sql.NullString // In the order of Table.Columns
+}
+
+// pending returns the next batch rows after the one with ID after
+func (t Table) pending(tx *gorm.DB, after string, batch int) ([]pendingRow, error) {
+    selects := []string{"id::text", "tenant_id::text"}
+    var args []interface{}
+    if t.Tenant != "" {
+        selects[1] = "?"
+        args = append(args, t.Tenant)
+    }
+    var todo []string
+    for _, c := range t.Columns {
+        selects = append(selects, c.Name+"::text")
+        set := fmt.Sprintf("%s IS NOT NULL AND %s::text <> ''", c.Name, c.Name)
+        todo = append(todo, fmt.Sprintf("(%s AND %s::text NOT LIKE '%s%%')", set, c.Name, sealedPrefix))
+        if c.Index != "" {
+            todo = append(todo, fmt.Sprintf("(%s AND %s IS NULL)", set, c.Index))
+        }
+    }
+
+    where := "(" + strings.Join(todo, " OR ") + ") AND id::text > ?"
+    args = append(args, after)
+    if t.Tenant == "" {
+        where += " AND tenant_id IS NOT NULL"
+    }
+    if t.Where != "" {
+        where += " AND (" + t.Where + ")"
+    }
+    query := fmt.Sprintf("SELECT %s FROM %s WHERE %s ORDER BY id::text LIMIT ?", strings.Join(selects, ", "), t.Name, where)
+    args = append(args, batch)
+
+    result, err := tx.Raw(query, args...).Rows()
+    if err != nil {
+        return nil, fmt.Errorf("%s: %w", t.Name, err)
+    }
+    defer result.Close()
+
+    var rows []pendingRow
+    for result.Next() {
+        row := pendingRow{values: make([]sql.NullString, len(t.Columns))}
+        dest := []interface{}{&row.id, &row.tenantID}
+        for i := range row.values {
+            dest = append(dest, &row.values[i])
+        }
+        if err := result.Scan(dest...); err != nil {
+            return nil, fmt.Errorf("%s: %w", t.Name, err)
+        }
+        rows = append(rows, row)
+    }
+    return rows, result.Err()
+}
+
+// backfillRow seals and indexes the values of one row
+func (k *Keyring) backfillRow(ctx context.Context, tx *gorm.DB, t Table, row pendingRow, done *BackfillResult) error {
+    updates := make(map[string]interface{})
+    for i, c := range t.Columns {
+        value := row.values[i]
+        if !value.Valid || value.String == "" {
+            continue
+        }
+        plaintext := value.String
+        if IsSealed(plaintext) {
+            if c.Index == "" {
+                continue
+            }
+            opened, err := k.OpenString(ctx, plaintext)
+            if errors.Is(err, ErrKeyDestroyed) {
+                done.Skipped++
+                return nil
+            }
+            if err != nil {
+                return err
+            }
+            plaintext = opened
+        } else {
+            sealed, err := k.SealString(ctx, row.tenantID, plaintext)
+            if errors.Is(err, ErrKeyDestroyed) {
+                done.Skipped++
+                return nil
+            }
+            if err != nil {
+                return err
+            }
+            updates[c.Name] = sealed
+        }
+
+        if c.Index != "" {
+            if c.Normalize != nil {
+                plaintext = c.Normalize(plaintext)
+            }
+            index, err := k.BlindIndex(ctx, row.tenantID, plaintext)
+            if err != nil {
+                return err
+            }
+            updates[c.Index] = index
+        }
+    }
+    if len(updates) == 0 {
+        return nil
+    }
+
+    // A savepoint, so a collision leaves the batch's transaction usable
+    err := tx.Transaction(func(sp *gorm.DB) error {
+        return sp.Table(t.Name).Where("id::text = ?", row.id).Updates(updates).Error
+    })
+    if duplicated(tx, err) {
+        if err := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE id::text = ?", t.Name), row.id).Error; err != nil {
+            return err
+        }
+        done.Duplicates++
+        return nil
+    }
+    if err != nil {
+        return err
+    }
+    done.Sealed++
+    return nil
+}
+
+// duplicated reports whether err is a unique violation, whether or not db
+// was opened with TranslateError
+func duplicated(db *gorm.DB, err error) bool {
+    if err == nil {
+        return false
+    }
+    if translator, ok := db.Dialector.(gorm.ErrorTranslator); ok {
+        err = translator.Translate(err)
+    }
+    return errors.Is(err, gorm.ErrDuplicatedKey)
+}
+
+// MarkBackfilled records that every stored value has been sealed, so the
+// services refuse plaintext from their next start (Backfilled)
+func MarkBackfilled(ctx context.Context, db *gorm.DB) error {
+    return db.WithContext(ctx).Exec("INSERT INTO seal_backfill DEFAULT VALUES ON CONFLICT (id) DO NOTHING").Error
+}
+
+// Backfilled reports whether cmd/seal-backfill has finished (MarkBackfilled)
+func Backfilled(ctx context.Context, db *gorm.DB) (bool, error) {
+    var done bool
+    err := db.WithContext(ctx).Raw("SELECT EXISTS (SELECT 1 FROM seal_backfill)").Scan(&done).Error
+    if err != nil {
+        return false, fmt.Errorf("failed to check seal backfill: %w", err)
+    }
+    return done, nil
+}
@@ -0,0 +1,63 @@
+// pkg/envelope/envelope.go
+package envelope
+
+import (
+    "context"
+    "errors"
+    "time"
+)
+
+// Every tenant has one data key. Column values are sealed with it, and the
+// data key itself is stored wrapped by a master key the KMS holds, so:
+//
+//   - rotating the master key re-wraps one small row per tenant and never
+//     touches the encrypted rows (Keyring.Rewrap)
+//   - destroying a tenant's data key leaves every copy of their rows,
+//     backups included, unreadable (Keyring.Shred)
+//
+// Sealed values look like enc:v1:<tenant ID>:<base64 nonce and ciphertext>;
+// the tenant ID is authenticated as associated data, so a value copied into
+// another tenant's row does not decrypt, and one read in another tenant's
+// transaction is refused before it is. Values without the prefix were
+// written before encryption and are read as they are, until
+// cmd/seal-backfill has sealed them all (Keyring.RejectPlaintext).
+
+// KMS wraps data keys under a master key it never releases. FileKMS keeps
+// master keys in a local file; a cloud KMS can stand in for it.
+type KMS interface {
+    // CurrentKeyID is the master key Wrap uses
+    CurrentKeyID() string
+    Wrap(ctx context.Context, dataKey []byte) (masterKeyID string, wrapped []byte, err error)
+    Unwrap(ctx context.Context, masterKeyID string, wrapped []byte) ([]byte, error)
+}
+
+// DataKey is a tenant's wrapped data key. A destroyed key keeps its row,
+// without the key, so that nothing is sealed for the tenant again.
+type DataKey struct {
+    TenantID    string     `json:"tenant_id" gorm:"type:uuid;primary_key"`
+    MasterKeyID string     `json:"master_key_id"`
+    WrappedKey  []byte     `json:"-"`
+    CreatedAt   time.Time  `json:"created_at"`
+    RewrappedAt *time.Time `json:"rewrapped_at,omitempty"`
+    DestroyedAt *time.Time `json:"destroyed_at,omitempty"`
+}
+
+func (DataKey) TableName() string {
+    return "tenant_data_keys"
+}
+
+// Destroyed reports whether the tenant has been crypto-shredded
+func (k *DataKey) Destroyed() bool {
+    return k.DestroyedAt != nil
+}
+
+// Failure reasons. Match them with errors.Is.
+var (
+    ErrNoKey            = errors.New("tenant has no data key")
+    ErrKeyDestroyed     = errors.New("tenant data key has been destroyed")
+    ErrUnknownMasterKey = errors.New("master key not found")
+    ErrMalformed        = errors.New("malformed sealed value")
+    ErrNoKeyring        = errors.New("no keyring registered for encrypted columns")
+    ErrTenantMismatch   = errors.New("value is sealed for another tenant")
+    ErrNotSealed        = errors.New("value is not sealed")
+)
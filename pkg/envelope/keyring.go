@@ -0,0 +1,324 @@
+// pkg/envelope/keyring.go
+package envelope
+
+import (
+    "context"
+    "crypto/cipher"
+    "crypto/hmac"
+    "crypto/rand"
+    "crypto/sha256"
+    "encoding/base64"
+    "encoding/hex"
+    "errors"
+    "fmt"
+    "strings"
+    "sync"
+    "time"
+
+    "secure-iran-intel/pkg/tenancy"
+)
+
+// sealedPrefix marks a value sealed by a Keyring
+const sealedPrefix = "enc:v1:"
+
+// Unwrapped keys are kept this long, which bounds how long another process
+// can go on using a key after it was shredded
+const cacheTTL = 5 * time.Minute
+
+// Labels separating the keys derived from one data key
+const (
+    labelSeal  = "secure-iran-intel/envelope/seal"
+    labelIndex = "secure-iran-intel/envelope/blind-index"
+)
+
+type tenantKeys struct {
+    aead    cipher.AEAD
+    index   []byte
+    expires time.Time
+}
+
+// Keyring seals and opens column values with each tenant's data key,
+// creating the key the first time something is sealed for the tenant
+type Keyring struct {
+    store KeyStore
+    kms   KMS
+
+    mu              sync.Mutex
+    cache           map[string]*tenantKeys
+    rejectPlaintext bool
+}
+
+func NewKeyring(store KeyStore, kms KMS) *Keyring {
+    return &Keyring{store: store, kms: kms, cache: make(map[string]*tenantKeys)}
+}
+
+// Seal encrypts plaintext for tenantID
+func (k *Keyring) Seal(ctx context.Context, tenantID string, plaintext []byte) (string, error) {
+    keys, err := k.keys(ctx, tenantID, true)
+    if err != nil {
+        return "", err
+    }
+    nonce := make([]byte, keys.aead.NonceSize())
+    if _, err := rand.Read(nonce); err != nil {
+        return "", fmt.Errorf("failed to generate nonce: %w", err)
+    }
+    sealed := keys.aead.Seal(nonce, nonce, plaintext, []byte(tenantID))
+    return sealedPrefix + tenantID + ":" + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a value from Seal. A value sealed for a tenant other than
+// the one tenancy.Transaction scoped ctx to is refused. Values Seal did not
+// produce were stored before encryption and are returned unchanged, until
+// RejectPlaintext is called; empty values and redaction placeholders
+// always are.
+func (k *Keyring) Open(ctx context.Context, value string) ([]byte, error) {
+    if !IsSealed(value) {
+        if value == "" || redacted(value) || !k.rejectsPlaintext() {
+            return []byte(value), nil
+        }
+        return nil, ErrNotSealed
+    }
+    tenantID, encoded, ok := strings.Cut(strings.TrimPrefix(value, sealedPrefix), ":")
+    if !ok {
+        return nil, ErrMalformed
+    }
+    if scoped := tenancy.FromContext(ctx); scoped != "" && scoped != tenantID {
+        return nil, ErrTenantMismatch
+    }
+    sealed, err := base64.RawStdEncoding.DecodeString(encoded)
+    if err != nil {
+        return nil, ErrMalformed
+    }
+
+    keys, err := k.keys(ctx, tenantID, false)
+    if err != nil {
+        return nil, err
+    }
+    if len(sealed) < keys.aead.NonceSize() {
+        return nil, ErrMalformed
+    }
+    nonce, ciphertext := sealed[:keys.aead.NonceSize()], sealed[keys.aead.NonceSize():]
+    plaintext, err := keys.aead.Open(nil, nonce, ciphertext, []byte(tenantID))
+    if err != nil {
+        return nil, fmt.Errorf("failed to open sealed value: %w", err)
+    }
+    return plaintext, nil
+}
+
+// RejectPlaintext makes Open refuse values that are not sealed. Call it
+// once cmd/seal-backfill has sealed every stored value (Backfilled); a
+// plaintext value after that was written around the keyring.
+func (k *Keyring) RejectPlaintext() {
+    k.mu.Lock()
+    k.rejectPlaintext = true
+    k.mu.Unlock()
+}
+
+func (k *Keyring) rejectsPlaintext() bool {
+    k.mu.Lock()
+    defer k.mu.Unlock()
+    return k.rejectPlaintext
+}
+
+// redacted reports whether value is a placeholder the retention sweep
+// wrote over a redacted value (pkg/retention). The sweep redacts in SQL,
+// so placeholders are never sealed; they hold nothing worth sealing.
+func redacted(value string) bool {
+    switch value {
+    case "{}", "[]", "[redacted]":
+        return true
+    }
+    return strings.HasPrefix(value, "redacted:")
+}
+
+// IsSealed reports whether value was produced by Seal
+func IsSealed(value string) bool {
+    return strings.HasPrefix(value, sealedPrefix)
+}
+
+// SealString seals value for tenantID, for stores the encrypted serializer
+// does not cover. Empty and already sealed values are returned unchanged.
+func (k *Keyring) SealString(ctx context.Context, tenantID, value string) (string, error) {
+    if value == "" || IsSealed(value) {
+        return value, nil
+    }
+    return k.Seal(ctx, tenantID, []byte(value))
+}
+
+// OpenString opens a value from SealString
+func (k *Keyring) OpenString(ctx context.Context, value string) (string, error) {
+    plaintext, err := k.Open(ctx, value)
+    if err != nil {
+        return "", err
+    }
+    return string(plaintext), nil
+}
+
+// BlindIndex is a keyed hash of value for equality lookups on a sealed
+// column. It is stable for a tenant and unrelated across tenants. Callers
+// normalize value first (phone numbers to E.164).
+func (k *Keyring) BlindIndex(ctx context.Context, tenantID, value string) (string, error) {
+    keys, err := k.keys(ctx, tenantID, true)
+    if err != nil {
+        return "", err
+    }
+    mac := hmac.New(sha256.New, keys.index)
+    mac.Write([]byte(value))
+    return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// BlindIndexes is value's blind index under every tenant with a live data
+// key, by tenant ID, for lookups that span tenants. No key is created.
+func (k *Keyring) BlindIndexes(ctx context.Context, value string) (map[string]string, error) {
+    live, err := k.store.Live(ctx)
+    if err != nil {
+        return nil, fmt.Errorf("failed to list data keys: %w", err)
+    }
+    indexes := make(map[string]string, len(live))
+    for _, dk := range live {
+        keys, err := k.keys(ctx, dk.TenantID, false)
+        if errors.Is(err, ErrKeyDestroyed) || errors.Is(err, ErrNoKey) {
+            // Shredded since it was listed
+            continue
+        }
+        if err != nil {
+            return nil, fmt.Errorf("tenant %s: %w", dk.TenantID, err)
+        }
+        mac := hmac.New(sha256.New, keys.index)
+        mac.Write([]byte(value))
+        indexes[dk.TenantID] = hex.EncodeToString(mac.Sum(nil))
+    }
+    return indexes, nil
+}
+
+// Rewrap moves every data key onto the KMS's current master key and
+// returns how many it moved. Sealed rows are not touched.
+func (k *Keyring) Rewrap(ctx context.Context) (int, error) {
+    live, err := k.store.Live(ctx)
+    if err != nil {
+        return 0, fmt.Errorf("failed to list data keys: %w", err)
+    }
+
+    current := k.kms.CurrentKeyID()
+    moved := 0
+    for _, dk := range live {
+        if dk.MasterKeyID == current {
+            continue
+        }
+        plain, err := k.kms.Unwrap(ctx, dk.MasterKeyID, dk.WrappedKey)
+        if err != nil {
+            return moved, fmt.Errorf("tenant %s: %w", dk.TenantID, err)
+        }
+        masterKeyID, wrapped, err := k.kms.Wrap(ctx, plain)
+        if err != nil {
+            return moved, fmt.Errorf("tenant %s: %w", dk.TenantID, err)
+        }
+        if err := k.store.Rewrap(ctx, dk.TenantID, dk.MasterKeyID, masterKeyID, wrapped); err != nil {
+            return moved, fmt.Errorf("tenant %s: %w", dk.TenantID, err)
+        }
+        moved++
+    }
+    return moved, nil
+}
+
+// Shred destroys the tenant's data key. Everything sealed for the tenant,
+// in the database and in every backup of it, can no longer be opened, and
+// nothing new can be sealed for them.
+func (k *Keyring) Shred(ctx context.Context, tenantID string) error {
+    if err := k.store.Destroy(ctx, tenantID); err != nil {
+        return err
+    }
+    k.mu.Lock()
+    delete(k.cache, tenantID)
+    k.mu.Unlock()
+    return nil
+}
+
+// HasKey reports whether the tenant has a live data key
+func (k *Keyring) HasKey(ctx context.Context, tenantID string) (bool, error) {
+    dk, err := k.store.Get(ctx, tenantID)
+    if errors.Is(err, ErrNoKey) {
+        return false, nil
+    }
+    if err != nil {
+        return false, err
+    }
+    return !dk.Destroyed(), nil
+}
+
+// keys returns the tenant's unwrapped keys, creating a data key when
+// create is set and the tenant has none
+func (k *Keyring) keys(ctx context.Context, tenantID string, create bool) (*tenantKeys, error) {
+    if tenantID == "" {
+        return nil, fmt.Errorf("%w: no tenant to seal for", ErrNoKey)
+    }
+    k.mu.Lock()
+    cached, ok := k.cache[tenantID]
+    k.mu.Unlock()
+    if ok && time.Now().Before(cached.expires) {
+        return cached, nil
+    }
+
+    dk, err := k.store.Get(ctx, tenantID)
+    if errors.Is(err, ErrNoKey) && create {
+        dk, err = k.create(ctx, tenantID)
+    }
+    if err != nil {
+        return nil, err
+    }
+    if dk.Destroyed() {
+        return nil, ErrKeyDestroyed
+    }
+
+    plain, err := k.kms.Unwrap(ctx, dk.MasterKeyID, dk.WrappedKey)
+    if err != nil {
+        return nil, err
+    }
+    keys, err := deriveKeys(plain)
+    if err != nil {
+        return nil, err
+    }
+
+    k.mu.Lock()
+    k.cache[tenantID] = keys
+    k.mu.Unlock()
+    return keys, nil
+}
+
+// create stores a new data key for the tenant. When another process got
+// there first its key wins and is returned.
+func (k *Keyring) create(ctx context.Context, tenantID string) (*DataKey, error) {
+    plain := make([]byte, 32)
+    if _, err := rand.Read(plain); err != nil {
+        return nil, fmt.Errorf("failed to generate data key: %w", err)
+    }
+    masterKeyID, wrapped, err := k.kms.Wrap(ctx, plain)
+    if err != nil {
+        return nil, err
+    }
+    err = k.store.Create(ctx, &DataKey{
+        TenantID:    tenantID,
+        MasterKeyID: masterKeyID,
+        WrappedKey:  wrapped,
+        CreatedAt:   time.Now(),
+    })
+    if err != nil {
+        return nil, err
+    }
+    return k.store.Get(ctx, tenantID)
+}
+
+// deriveKeys splits a data key into the sealing key and the blind index
+// key, so an index value reveals nothing about the sealing key
+func deriveKeys(dataKey []byte) (*tenantKeys, error) {
+    derive := func(label string) []byte {
+        mac := hmac.New(sha256.New, dataKey)
+        mac.Write([]byte(label))
+        return mac.Sum(nil)
+    }
+    aead, err := newAEAD(derive(labelSeal))
+    if err != nil {
+        return nil, err
+    }
+    return &tenantKeys{aead: aead, index: derive(labelIndex), expires: time.Now().Add(cacheTTL)}, nil
+}
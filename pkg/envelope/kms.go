@@ -0,0 +1,216 @@
+// pkg/envelope/kms.go
+package envelope
+
+import (
+    "context"
+    "crypto/aes"
+    "crypto/cipher"
+    "crypto/rand"
+    "encoding/hex"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "os"
+    "path/filepath"
+    "sync"
+    "time"
+)
+
+// masterKey is one entry of the key file
+type masterKey struct {
+    ID        string    `json:"id"`
+    Key       []byte    `json:"key"` // 32 bytes, base64 in the file
+    CreatedAt time.Time `json:"created_at"`
+}
+
+type keyFile struct {
+    Current string       `json:"current"`
+    Keys    []*masterKey `json:"keys"`
+}
+
+// FileKMS keeps AES-256 master keys in a JSON file readable only by its
+// owner. It stands in for a real KMS on single-host installs; the file
+// must not be backed up alongside the database.
+type FileKMS struct {
+    path string
+    mu   sync.RWMutex
+    file keyFile
+}
+
+// NewFileKMS loads the key file at path, creating it with a first master
+// key if it does not exist
+func NewFileKMS(path string) (*FileKMS, error) {
+    k := &FileKMS{path: path}
+    data, err := os.ReadFile(path)
+    if errors.Is(err, os.ErrNotExist) {
+        if _, err := k.Rotate(); err != nil {
+            return nil, err
+        }
+        return k, nil
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to read master keys: %w", err)
+    }
+    if err := json.Unmarshal(data, &k.file); err != nil {
+        return nil, fmt.Errorf("failed to parse master keys: %w", err)
+    }
+    if k.key(k.file.Current) == nil {
+        return nil, fmt.Errorf("%w: current key %q", ErrUnknownMasterKey, k.file.Current)
+    }
+    return k, nil
+}
+
+func (k *FileKMS) CurrentKeyID() string {
+    k.mu.RLock()
+    defer k.mu.RUnlock()
+    return k.file.Current
+}
+
+func (k *FileKMS) Wrap(ctx context.Context, dataKey []byte) (string, []byte, error) {
+    k.mu.RLock()
+    mk := k.key(k.file.Current)
+    k.mu.RUnlock()
+
+    aead, err := newAEAD(mk.Key)
+    if err != nil {
+        return "", nil, err
+    }
+    nonce := make([]byte, aead.NonceSize())
+    if _, err := rand.Read(nonce); err != nil {
+        return "", nil, fmt.Errorf("failed to generate nonce: %w", err)
+    }
+    return mk.ID, aead.Seal(nonce, nonce, dataKey, []byte(mk.ID)), nil
+}
+
+func (k *FileKMS) Unwrap(ctx context.Context, masterKeyID string, wrapped []byte) ([]byte, error) {
+    k.mu.RLock()
+    mk := k.key(masterKeyID)
+    k.mu.RUnlock()
+    if mk == nil {
+        return nil, fmt.Errorf("%w: %s", ErrUnknownMasterKey, masterKeyID)
+    }
+
+    aead, err := newAEAD(mk.Key)
+    if err != nil {
+        return nil, err
+    }
+    if len(wrapped) < aead.NonceSize() {
+        return nil, ErrMalformed
+    }
+    nonce, sealed := wrapped[:aead.NonceSize()], wrapped[aead.NonceSize():]
+    dataKey, err := aead.Open(nil, nonce, sealed, []byte(masterKeyID))
+    if err != nil {
+        return nil, fmt.Errorf("failed to unwrap data key: %w", err)
+    }
+    return dataKey, nil
+}
+
+// Rotate adds a new master key and makes it current. Keys wrapped under
+// the old one still unwrap until it is retired; Keyring.Rewrap moves them.
+func (k *FileKMS) Rotate() (string, error) {
+    key := make([]byte, 32)
+    id := make([]byte, 8)
+    if _, err := rand.Read(key); err != nil {
+        return "", fmt.Errorf("failed to generate master key: %w", err)
+    }
+    if _, err := rand.Read(id); err != nil {
+        return "", fmt.Errorf("failed to generate master key: %w", err)
+    }
+    mk := &masterKey{ID: "mk-" + hex.EncodeToString(id), Key: key, CreatedAt: time.Now().UTC()}
+
+    k.mu.Lock()
+    defer k.mu.Unlock()
+    next := keyFile{Current: mk.ID, Keys: append(append([]*masterKey{}, k.file.Keys...), mk)}
+    if err := k.save(next); err != nil {
+        return "", err
+    }
+    k.file = next
+    return mk.ID, nil
+}
+
+// Retire deletes a master key. Only retire a key once Rewrap has moved
+// every data key off it; anything still wrapped under it is lost.
+func (k *FileKMS) Retire(masterKeyID string) error {
+    k.mu.Lock()
+    defer k.mu.Unlock()
+    if masterKeyID == k.file.Current {
+        return fmt.Errorf("cannot retire the current master key")
+    }
+
+    next := keyFile{Current: k.file.Current}
+    for _, mk := range k.file.Keys {
+        if mk.ID != masterKeyID {
+            next.Keys = append(next.Keys, mk)
+        }
+    }
+    if len(next.Keys) == len(k.file.Keys) {
+        return fmt.Errorf("%w: %s", ErrUnknownMasterKey, masterKeyID)
+    }
+    if err := k.save(next); err != nil {
+        return err
+    }
+    k.file = next
+    return nil
+}
+
+// KeyIDs lists every master key still held, oldest first
+func (k *FileKMS) KeyIDs() []string {
+    k.mu.RLock()
+    defer k.mu.RUnlock()
+    ids := make([]string, 0, len(k.file.Keys))
+    for _, mk := range k.file.Keys {
+        ids = append(ids, mk.ID)
+    }
+    return ids
+}
+
+func (k *FileKMS) key(id string) *masterKey {
+    for _, mk := range k.file.Keys {
+        if mk.ID == id {
+            return mk
+        }
+    }
+    return nil
+}
+
+// save replaces the key file atomically, so a crash leaves the old or the
+// new set of keys and never half of one
+func (k *FileKMS) save(f keyFile) error {
+    data, err := json.MarshalIndent(f, "", "  ")
+    if err != nil {
+        return err
+    }
+    tmp, err := os.CreateTemp(filepath.Dir(k.path), ".master-keys-*")
+    if err != nil {
+        return fmt.Errorf("failed to write master keys: %w", err)
+    }
+    defer os.Remove(tmp.Name())
+
+    if err := tmp.Chmod(0600); err != nil {
+        tmp.Close()
+        return fmt.Errorf("failed to write master keys: %w", err)
+    }
+    if _, err := tmp.Write(data); err != nil {
+        tmp.Close()
+        return fmt.Errorf("failed to write master keys: %w", err)
+    }
+    if err := tmp.Sync(); err != nil {
+        tmp.Close()
+        return fmt.Errorf("failed to write master keys: %w", err)
+    }
+    if err := tmp.Close(); err != nil {
+        return fmt.Errorf("failed to write master keys: %w", err)
+    }
+    if err := os.Rename(tmp.Name(), k.path); err != nil {
+        return fmt.Errorf("failed to write master keys: %w", err)
+    }
+    return nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+    block, err := aes.NewCipher(key)
+    if err != nil {
+        return nil, err
+    }
+    return cipher.NewGCM(block)
+}
@@ -0,0 +1,124 @@
+// pkg/envelope/serializer.go
+package envelope
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "reflect"
+    "sync"
+
+    "gorm.io/gorm/schema"
+    "secure-iran-intel/pkg/tenancy"
+)
+
+// SerializerName is the GORM serializer sealing a column:
+//
+//    PhoneNumber string `gorm:"serializer:encrypted"`
+//
+// Strings are sealed as they are; anything else is sealed as JSON. The
+// tenant is the one tenancy.Transaction scoped the statement to, or else
+// the model's TenantID field.
+const SerializerName = "encrypted"
+
+var (
+    registeredMu sync.RWMutex
+    registered   *Keyring
+)
+
+// Register makes k the keyring of every encrypted column. GORM serializers
+// are process-wide, so there is one keyring per process.
+func Register(k *Keyring) {
+    registeredMu.Lock()
+    registered = k
+    registeredMu.Unlock()
+    schema.RegisterSerializer(SerializerName, Serializer{})
+}
+
+func keyring() (*Keyring, error) {
+    registeredMu.RLock()
+    defer registeredMu.RUnlock()
+    if registered == nil {
+        return nil, ErrNoKeyring
+    }
+    return registered, nil
+}
+
+// Serializer seals column values with the registered Keyring
+type Serializer struct{}
+
+func (Serializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+    fieldValue := reflect.New(field.FieldType)
+    if dbValue != nil {
+        var stored string
+        switch v := dbValue.(type) {
+        case []byte:
+            stored = string(v)
+        case string:
+            stored = v
+        default:
+            return fmt.Errorf("%w: unsupported column type %T", ErrMalformed, dbValue)
+        }
+
+        keys, err := keyring()
+        if err != nil {
+            return err
+        }
+        plaintext, err := keys.Open(ctx, stored)
+        if err != nil {
+            return fmt.Errorf("%s: %w", field.DBName, err)
+        }
+        if field.FieldType.Kind() == reflect.String {
+            fieldValue.Elem().SetString(string(plaintext))
+        } else if len(plaintext) > 0 {
+            if err := json.Unmarshal(plaintext, fieldValue.Interface()); err != nil {
+                return fmt.Errorf("%s: %w", field.DBName, err)
+            }
+        }
+    }
+    field.ReflectValueOf(ctx, dst).Set(fieldValue.Elem())
+    return nil
+}
+
+func (Serializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+    var plaintext []byte
+    switch v := fieldValue.(type) {
+    case string:
+        // Empty stays empty, so NOT NULL DEFAULT '' columns keep working
+        if v == "" {
+            return "", nil
+        }
+        plaintext = []byte(v)
+    default:
+        if fieldValue == nil || (reflect.ValueOf(fieldValue).Kind() == reflect.Ptr && reflect.ValueOf(fieldValue).IsNil()) {
+            return nil, nil
+        }
+        encoded, err := json.Marshal(fieldValue)
+        if err != nil {
+            return nil, err
+        }
+        plaintext = encoded
+    }
+
+    keys, err := keyring()
+    if err != nil {
+        return nil, err
+    }
+    return keys.Seal(ctx, sealingTenant(ctx, dst), plaintext)
+}
+
+// sealingTenant is the tenant in the statement's scope, or the model's own
+// TenantID outside one
+func sealingTenant(ctx context.Context, dst reflect.Value) string {
+    if tenantID := tenancy.FromContext(ctx); tenantID != "" {
+        return tenantID
+    }
+    model := reflect.Indirect(dst)
+    if model.Kind() != reflect.Struct {
+        return ""
+    }
+    if f := model.FieldByName("TenantID"); f.IsValid() && f.Kind() == reflect.String {
+        return f.String()
+    }
+    return ""
+}
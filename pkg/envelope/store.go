@@ -0,0 +1,94 @@
+// pkg/envelope/store.go
+package envelope
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "time"
+
+    "gorm.io/gorm"
+    "gorm.io/gorm/clause"
+    "secure-iran-intel/pkg/tenancy"
+)
+
+// KeyStore keeps the wrapped data keys. Create must not replace an
+// existing key, and Rewrap must only replace a key still wrapped under
+// fromMasterKey, so two processes racing each other cannot lose a key.
+type KeyStore interface {
+    // Get returns ErrNoKey when the tenant has none
+    Get(ctx context.Context, tenantID string) (*DataKey, error)
+    Create(ctx context.Context, k *DataKey) error
+    // Live lists every key that has not been destroyed
+    Live(ctx context.Context) ([]DataKey, error)
+    Rewrap(ctx context.Context, tenantID, fromMasterKey, toMasterKey string, wrapped []byte) error
+    // Destroy drops the key and leaves a tombstone in its place
+    Destroy(ctx context.Context, tenantID string) error
+}
+
+// GormKeyStore keeps keys in the tenant_data_keys table (migration 020)
+type GormKeyStore struct {
+    db *gorm.DB
+}
+
+func NewGormKeyStore(db *gorm.DB) *GormKeyStore {
+    return &GormKeyStore{db: db}
+}
+
+func (s *GormKeyStore) Get(ctx context.Context, tenantID string) (*DataKey, error) {
+    var k DataKey
+    err := tenancy.Transaction(ctx, s.db, tenantID, func(tx *gorm.DB) error {
+        return tx.Where("tenant_id = ?", tenantID).First(&k).Error
+    })
+    if errors.Is(err, gorm.ErrRecordNotFound) {
+        return nil, ErrNoKey
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to load data key: %w", err)
+    }
+    return &k, nil
+}
+
+func (s *GormKeyStore) Create(ctx context.Context, k *DataKey) error {
+    err := tenancy.Transaction(ctx, s.db, k.TenantID, func(tx *gorm.DB) error {
+        return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(k).Error
+    })
+    if err != nil {
+        return fmt.Errorf("failed to store data key: %w", err)
+    }
+    return nil
+}
+
+func (s *GormKeyStore) Live(ctx context.Context) ([]DataKey, error) {
+    var keys []DataKey
+    err := tenancy.SystemTransaction(ctx, s.db, func(tx *gorm.DB) error {
+        return tx.Where("destroyed_at IS NULL").Find(&keys).Error
+    })
+    return keys, err
+}
+
+func (s *GormKeyStore) Rewrap(ctx context.Context, tenantID, fromMasterKey, toMasterKey string, wrapped []byte) error {
+    return tenancy.Transaction(ctx, s.db, tenantID, func(tx *gorm.DB) error {
+        return tx.Model(&DataKey{}).
+            Where("tenant_id = ? AND master_key_id = ? AND destroyed_at IS NULL", tenantID, fromMasterKey).
+            Updates(map[string]interface{}{
+                "master_key_id": toMasterKey,
+                "wrapped_key":   wrapped,
+                "rewrapped_at":  time.Now(),
+            }).Error
+    })
+}
+
+func (s *GormKeyStore) Destroy(ctx context.Context, tenantID string) error {
+    now := time.Now()
+    err := tenancy.Transaction(ctx, s.db, tenantID, func(tx *gorm.DB) error {
+        return tx.Clauses(clause.OnConflict{
+            Columns:   []clause.Column{{Name: "tenant_id"}},
+            DoUpdates: clause.Assignments(map[string]interface{}{"wrapped_key": nil, "master_key_id": "", "destroyed_at": now}),
+        }).Create(&DataKey{TenantID: tenantID, CreatedAt: now, DestroyedAt: &now}).Error
+    })
+    if err != nil {
+        return fmt.Errorf("failed to destroy data key: %w", err)
+    }
+    return nil
+}
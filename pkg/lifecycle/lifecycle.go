@@ -0,0 +1,104 @@
+// pkg/lifecycle/lifecycle.go
+package lifecycle
+
+import (
+    "net/http"
+    "time"
+
+    "secure-iran-intel/pkg/refusal"
+    "secure-iran-intel/pkg/token"
+)
+
+// Tenant statuses. Only active tenants can sign in or use their keys.
+const (
+    StatusActive      = "active"
+    StatusSuspended   = "suspended"
+    StatusOffboarding = "offboarding" // Export and purge under way
+    StatusOffboarded  = "offboarded"  // Data purged; the tenant row remains as a tombstone
+)
+
+// Tenant is the part of a tenant the lifecycle reads and changes
+type Tenant struct {
+    ID               string     `json:"id"`
+    Name             string     `json:"name"`
+    Slug             string     `json:"slug"`
+    Status           string     `json:"status"`
+    SuspendedAt      *time.Time `json:"suspended_at,omitempty"`
+    SuspensionReason string     `json:"suspension_reason,omitempty"`
+    OffboardedAt     *time.Time `json:"offboarded_at,omitempty"`
+}
+
+func (Tenant) TableName() string {
+    return "tenants"
+}
+
+// Removal is what one purger deleted of one kind of data
+type Removal struct {
+    Store string `json:"store"` // postgres, redis, rabbitmq, kms
+    Kind  string `json:"kind"`  // Table, key class or queue
+    Count int64  `json:"count"`
+}
+
+// Retention is data deliberately kept after offboarding, and why
+type Retention struct {
+    Kind   string `json:"kind"`
+    Reason string `json:"reason"`
+}
+
+// AuditExport describes the copy of the tenant's audit records handed over
+// before anything was deleted
+type AuditExport struct {
+    File    string `json:"file"`
+    SHA256  string `json:"sha256"`
+    Records int64  `json:"records"`
+}
+
+// Certificate attests that a tenant's data was removed. Signature is a
+// compact JWS over the other fields, signed by the token issuer's key
+// ring; PublicKey is that key, kept so the certificate can be verified
+// after the key has left the JWKS.
+type Certificate struct {
+    ID          string      `json:"id" gorm:"type:uuid;primary_key"`
+    TenantID    string      `json:"tenant_id" gorm:"type:uuid;not null"`
+    TenantName  string      `json:"tenant_name"`
+    TenantSlug  string      `json:"tenant_slug"`
+    RequestedBy string      `json:"requested_by"`
+    AuditExport AuditExport `json:"audit_export" gorm:"serializer:json;type:jsonb"`
+    Removed     []Removal   `json:"removed" gorm:"serializer:json;type:jsonb"`
+    Retained    []Retention `json:"retained" gorm:"serializer:json;type:jsonb"`
+    // Verified is set when every store reported nothing left for the
+    // tenant after the purge
+    Verified    bool       `json:"verified"`
+    CompletedAt time.Time  `json:"completed_at"`
+    Signature   string     `json:"signature,omitempty"`
+    PublicKey   *token.JWK `json:"public_key,omitempty" gorm:"serializer:json;type:jsonb"`
+}
+
+func (Certificate) TableName() string {
+    return "tenant_deletion_certificates"
+}
+
+// Total is the number of items removed across every store
+func (c *Certificate) Total() int64 {
+    var total int64
+    for _, r := range c.Removed {
+        total += r.Count
+    }
+    return total
+}
+
+// retained is what offboarding keeps, for every tenant
+var retained = []Retention{
+    {Kind: "audit_log", Reason: "append-only and hash-chained across tenants; targets are stored hashed, and the tenant's records were exported"},
+    {Kind: "billing_records", Reason: "kept for statutory accounting"},
+    {Kind: "tenants", Reason: "kept as a tombstone with status offboarded so the slug and certificate stay resolvable"},
+}
+
+// Refusal reasons. Match them with errors.Is.
+var (
+    ErrUnknownTenant   = refusal.New("TENANT_NOT_FOUND", http.StatusNotFound, "tenant not found")
+    ErrInvalidState    = refusal.New("TENANT_INVALID_STATE", http.StatusConflict, "tenant is not in a state that allows this")
+    ErrNotPlatform     = refusal.New("TENANT_NOT_PLATFORM_OPERATOR", http.StatusForbidden, "only platform operators can change another tenant's lifecycle")
+    ErrReasonRequired  = refusal.New("TENANT_LIFECYCLE_INVALID", http.StatusBadRequest, "a reason is required")
+    ErrPurgeIncomplete = refusal.New("TENANT_PURGE_INCOMPLETE", http.StatusInternalServerError, "data was left behind after the purge")
+)
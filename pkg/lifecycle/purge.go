@@ -0,0 +1,292 @@
+// pkg/lifecycle/purge.go
+package lifecycle
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+
+    "github.com/go-redis/redis/v8"
+    "github.com/streadway/amqp"
+    "gorm.io/gorm"
+    "secure-iran-intel/pkg/tenancy"
+)
+
+// Purger hard-deletes everything one store holds for a tenant
+type Purger interface {
+    Purge(ctx context.Context, tenantID string) ([]Removal, error)
+    // Remaining counts what the store still holds for the tenant
+    Remaining(ctx context.Context, tenantID string) (int64, error)
+}
+
+// purgedTables are the tenant-owned tables, children before the rows they
+// reference. Foreign keys from bulk_jobs to users do not cascade, and
+// api_keys reference each other, so the order matters.
+var purgedTables = []string{
+    // 016_sso_scim, 017_mfa
+    "user_identities", "identity_providers", "mfa_recovery_codes", "mfa_factors",
+    // 011_approvals
+    "approval_requests", "approval_policies",
+    // Jobs, their results and the reports built from them
+    "bulk_jobs", "email_discovery_results", "social_graphs", "behavioral_patterns",
+    "risk_assessments", "intelligence_reports",
+    // 019_data_retention, 010_case_purpose
+    "legal_holds", "cases",
+    // 022_suppressions: the tenant's own list only; entries of the platform
+    // tenant are the global list
+    "suppressions",
+    // 007_multi_tenant, 012_rbac
+    "api_keys", "tenant_usage", "users", "teams", "roles",
+}
+
+// PostgresPurger deletes the tenant's rows from every tenant-owned table
+// in one transaction
+type PostgresPurger struct {
+    db *gorm.DB
+}
+
+func NewPostgresPurger(db *gorm.DB) *PostgresPurger {
+    return &PostgresPurger{db: db}
+}
+
+func (p *PostgresPurger) Purge(ctx context.Context, tenantID string) ([]Removal, error) {
+    var removed []Removal
+    err := tenancy.Transaction(ctx, p.db, tenantID, func(tx *gorm.DB) error {
+        // api_keys reference their replacements
+        if err := tx.Exec("UPDATE api_keys SET replaced_by = NULL WHERE tenant_id = ?", tenantID).Error; err != nil {
+            return err
+        }
+        for _, table := range purgedTables {
+            result := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE tenant_id = ?", table), tenantID)
+            if result.Error != nil {
+                return fmt.Errorf("%s: %w", table, result.Error)
+            }
+            removed = append(removed, Removal{Store: "postgres", Kind: table, Count: result.RowsAffected})
+        }
+        // The tombstone keeps the name and slug only
+        return tx.Exec("UPDATE tenants SET billing_email = NULL, settings = '{}'::jsonb WHERE id = ?", tenantID).Error
+    })
+    if err != nil {
+        return nil, fmt.Errorf("failed to purge tenant rows: %w", err)
+    }
+    return removed, nil
+}
+
+func (p *PostgresPurger) Remaining(ctx context.Context, tenantID string) (int64, error) {
+    var total int64
+    // Bypass row-level security so rows written under another scope count
+    err := tenancy.SystemTransaction(ctx, p.db, func(tx *gorm.DB) error {
+        for _, table := range purgedTables {
+            var n int64
+            if err := tx.Table(table).Where("tenant_id = ?", tenantID).Count(&n).Error; err != nil {
+                return fmt.Errorf("%s: %w", table, err)
+            }
+            total += n
+        }
+        return nil
+    })
+    return total, err
+}
+
+// Keys deleted per round trip
+const redisBatch = 500
+
+// RedisPurger deletes every key naming the tenant: rate limit counters,
+// token revocations, MFA challenges and cached state all embed the tenant
+// ID in their key
+type RedisPurger struct {
+    redis *redis.Client
+}
+
+func NewRedisPurger(client *redis.Client) *RedisPurger {
+    return &RedisPurger{redis: client}
+}
+
+func tenantKeys(tenantID string) string {
+    return "*" + tenantID + "*"
+}
+
+func (p *RedisPurger) Purge(ctx context.Context, tenantID string) ([]Removal, error) {
+    var deleted int64
+    iter := p.redis.Scan(ctx, 0, tenantKeys(tenantID), redisBatch).Iterator()
+    batch := make([]string, 0, redisBatch)
+    flush := func() error {
+        if len(batch) == 0 {
+            return nil
+        }
+        n, err := p.redis.Del(ctx, batch...).Result()
+        deleted += n
+        batch = batch[:0]
+        return err
+    }
+    for iter.Next(ctx) {
+        batch = append(batch, iter.Val())
+        if len(batch) == redisBatch {
+            if err := flush(); err != nil {
+                return nil, fmt.Errorf("failed to delete tenant keys: %w", err)
+            }
+        }
+    }
+    if err := iter.Err(); err != nil {
+        return nil, fmt.Errorf("failed to scan tenant keys: %w", err)
+    }
+    if err := flush(); err != nil {
+        return nil, fmt.Errorf("failed to delete tenant keys: %w", err)
+    }
+    return []Removal{{Store: "redis", Kind: "keys", Count: deleted}}, nil
+}
+
+func (p *RedisPurger) Remaining(ctx context.Context, tenantID string) (int64, error) {
+    var n int64
+    iter := p.redis.Scan(ctx, 0, tenantKeys(tenantID), redisBatch).Iterator()
+    for iter.Next(ctx) {
+        n++
+    }
+    return n, iter.Err()
+}
+
+// QueuePurger removes the tenant's messages still waiting in RabbitMQ
+// queues. Messages name their tenant in a tenant_id header or a top-level
+// tenant_id field of their JSON body; anything else is left alone.
+type QueuePurger struct {
+    conn   *amqp.Connection
+    queues []string
+}
+
+func NewQueuePurger(conn *amqp.Connection, queues ...string) *QueuePurger {
+    return &QueuePurger{conn: conn, queues: queues}
+}
+
+func (p *QueuePurger) Purge(ctx context.Context, tenantID string) ([]Removal, error) {
+    var removed []Removal
+    for _, queue := range p.queues {
+        matched, err := SweepQueue(ctx, p.conn, queue, tenantMessages(tenantID), true)
+        if err != nil {
+            return nil, fmt.Errorf("failed to purge queue %s: %w", queue, err)
+        }
+        removed = append(removed, Removal{Store: "rabbitmq", Kind: queue, Count: int64(len(matched))})
+    }
+    return removed, nil
+}
+
+func (p *QueuePurger) Remaining(ctx context.Context, tenantID string) (int64, error) {
+    var total int64
+    for _, queue := range p.queues {
+        matched, err := SweepQueue(ctx, p.conn, queue, tenantMessages(tenantID), false)
+        if err != nil {
+            return 0, fmt.Errorf("failed to inspect queue %s: %w", queue, err)
+        }
+        total += int64(len(matched))
+    }
+    return total, nil
+}
+
+// SweepQueue takes every message waiting in queue without acknowledging
+// it, so none is delivered twice, then acknowledges (deleting) those match
+// selects when remove is set and returns the rest to the queue in their
+// original order. It returns the messages match selected.
+func SweepQueue(ctx context.Context, conn *amqp.Connection, queue string, match func(amqp.Delivery) bool, remove bool) ([]amqp.Delivery, error) {
+    ch, err := conn.Channel()
+    if err != nil {
+        return nil, err
+    }
+    // Closing the channel requeues anything not yet settled
+    defer ch.Close()
+
+    state, err := ch.QueueInspect(queue)
+    if err != nil {
+        return nil, err
+    }
+
+    var matched, others []amqp.Delivery
+    for i := 0; i < state.Messages; i++ {
+        if err := ctx.Err(); err != nil {
+            return nil, err
+        }
+        d, ok, err := ch.Get(queue, false)
+        if err != nil {
+            return nil, err
+        }
+        if !ok {
+            break
+        }
+        if !match(d) {
+            others = append(others, d)
+            continue
+        }
+        matched = append(matched, d)
+        if remove {
+            if err := d.Ack(false); err != nil {
+                return nil, err
+            }
+        } else {
+            others = append(others, d)
+        }
+    }
+
+    for _, d := range others {
+        if err := d.Nack(false, true); err != nil {
+            return nil, err
+        }
+    }
+    return matched, nil
+}
+
+func tenantMessages(tenantID string) func(amqp.Delivery) bool {
+    return func(d amqp.Delivery) bool {
+        return messageTenant(d) == tenantID
+    }
+}
+
+func messageTenant(d amqp.Delivery) string {
+    if tenantID, ok := d.Headers["tenant_id"].(string); ok {
+        return tenantID
+    }
+    var body struct {
+        TenantID string `json:"tenant_id"`
+    }
+    if err := json.Unmarshal(d.Body, &body); err != nil {
+        return ""
+    }
+    return body.TenantID
+}
+
+// Shredder destroys a tenant's data key; *envelope.Keyring implements it
+type Shredder interface {
+    Shred(ctx context.Context, tenantID string) error
+    HasKey(ctx context.Context, tenantID string) (bool, error)
+}
+
+// KeyPurger crypto-shreds the tenant, so sealed values that outlive the
+// purge, in backups or replicas, can no longer be opened. Put it last:
+// the other purgers may need the key.
+type KeyPurger struct {
+    keys Shredder
+}
+
+func NewKeyPurger(keys Shredder) *KeyPurger {
+    return &KeyPurger{keys: keys}
+}
+
+func (p *KeyPurger) Purge(ctx context.Context, tenantID string) ([]Removal, error) {
+    had, err := p.keys.HasKey(ctx, tenantID)
+    if err != nil {
+        return nil, fmt.Errorf("failed to look up data key: %w", err)
+    }
+    if err := p.keys.Shred(ctx, tenantID); err != nil {
+        return nil, err
+    }
+    removal := Removal{Store: "kms", Kind: "data_keys"}
+    if had {
+        removal.Count = 1
+    }
+    return []Removal{removal}, nil
+}
+
+func (p *KeyPurger) Remaining(ctx context.Context, tenantID string) (int64, error) {
+    has, err := p.keys.HasKey(ctx, tenantID)
+    if err != nil || !has {
+        return 0, err
+    }
+    return 1, nil
+}
@@ -0,0 +1,269 @@
+// pkg/lifecycle/service.go
+package lifecycle
+
+import (
+    "context"
+    "crypto/rand"
+    "crypto/sha256"
+    "encoding/hex"
+    "fmt"
+    "io"
+    "os"
+    "path/filepath"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/golang-jwt/jwt/v4"
+    "secure-iran-intel/pkg/audit"
+    "secure-iran-intel/pkg/rbac"
+    "secure-iran-intel/pkg/refusal"
+    "secure-iran-intel/pkg/tenancy"
+    "secure-iran-intel/pkg/token"
+)
+
+// Signer signs deletion certificates; *token.Issuer implements it
+type Signer interface {
+    SignStatement(claims jwt.Claims) (string, token.JWK, error)
+}
+
+// certificateClaims is the signed form of a certificate
+type certificateClaims struct {
+    Certificate *Certificate `json:"certificate"`
+    jwt.RegisteredClaims
+}
+
+// Service suspends, resumes and offboards tenants. Only operators of the
+// platform tenant may use it, since a suspended tenant cannot act at all.
+type Service struct {
+    store      Store
+    purgers    []Purger
+    auditStore audit.Store
+    auditLog   *audit.Logger
+    policy     *rbac.Engine
+    signer     Signer
+    exportDir  string
+}
+
+// NewService returns a service that writes audit exports to exportDir and
+// purges offboarded tenants from every store in purgers
+func NewService(store Store, auditStore audit.Store, auditLog *audit.Logger, policy *rbac.Engine, signer Signer, exportDir string, purgers ...Purger) *Service {
+    return &Service{
+        store:      store,
+        purgers:    purgers,
+        auditStore: auditStore,
+        auditLog:   auditLog,
+        policy:     policy,
+        signer:     signer,
+        exportDir:  exportDir,
+    }
+}
+
+// Get returns a tenant's lifecycle state
+func (s *Service) Get(ctx context.Context, tenantID string) (*Tenant, error) {
+    if err := s.authorize(ctx); err != nil {
+        return nil, err
+    }
+    return s.store.Get(ctx, tenantID)
+}
+
+// Suspend stops the tenant's users and keys working at once. Their data is
+// kept and Resume restores access.
+func (s *Service) Suspend(ctx context.Context, tenantID, reason string) error {
+    if err := s.authorize(ctx); err != nil {
+        return err
+    }
+    reason = strings.TrimSpace(reason)
+    if reason == "" {
+        return &refusal.Error{Reason: ErrReasonRequired, ID: tenantID}
+    }
+
+    if err := s.log(ctx, audit.ActionTenantSuspend, tenantID, map[string]string{"reason": reason}); err != nil {
+        return err
+    }
+    return s.store.Transition(ctx, tenantID, []string{StatusActive}, StatusSuspended, map[string]interface{}{
+        "suspended_at":      time.Now(),
+        "suspension_reason": reason,
+    })
+}
+
+// Resume reactivates a suspended tenant
+func (s *Service) Resume(ctx context.Context, tenantID string) error {
+    if err := s.authorize(ctx); err != nil {
+        return err
+    }
+    if err := s.log(ctx, audit.ActionTenantResume, tenantID, nil); err != nil {
+        return err
+    }
+    return s.store.Transition(ctx, tenantID, []string{StatusSuspended}, StatusActive, map[string]interface{}{
+        "suspended_at":      nil,
+        "suspension_reason": nil,
+    })
+}
+
+// Offboard shuts a tenant down for good. It exports the tenant's audit
+// records, hard-deletes their data from every store, checks nothing is
+// left, and returns a signed certificate listing what was removed. A
+// failed offboarding leaves the tenant in StatusOffboarding and can be
+// run again.
+func (s *Service) Offboard(ctx context.Context, tenantID string) (*Certificate, error) {
+    if err := s.authorize(ctx); err != nil {
+        return nil, err
+    }
+    tenant, err := s.store.Get(ctx, tenantID)
+    if err != nil {
+        return nil, err
+    }
+
+    if err := s.log(ctx, audit.ActionTenantOffboard, tenantID, map[string]string{"previous_status": tenant.Status}); err != nil {
+        return nil, err
+    }
+    err = s.store.Transition(ctx, tenantID, []string{StatusActive, StatusSuspended, StatusOffboarding}, StatusOffboarding, nil)
+    if err != nil {
+        return nil, err
+    }
+
+    // The tenant gets their records before anything is deleted
+    export, err := s.exportAudit(ctx, tenantID)
+    if err != nil {
+        return nil, err
+    }
+
+    cert := &Certificate{
+        TenantID:    tenant.ID,
+        TenantName:  tenant.Name,
+        TenantSlug:  tenant.Slug,
+        RequestedBy: audit.ActorFromContext(ctx).ID,
+        AuditExport: *export,
+        Retained:    retained,
+    }
+    for _, p := range s.purgers {
+        removed, err := p.Purge(ctx, tenantID)
+        if err != nil {
+            return nil, err
+        }
+        cert.Removed = append(cert.Removed, removed...)
+    }
+
+    var remaining int64
+    for _, p := range s.purgers {
+        n, err := p.Remaining(ctx, tenantID)
+        if err != nil {
+            return nil, fmt.Errorf("failed to verify purge: %w", err)
+        }
+        remaining += n
+    }
+    if remaining > 0 {
+        return nil, &refusal.Error{Reason: ErrPurgeIncomplete, ID: tenantID, Detail: fmt.Sprintf("%d items remain; run offboarding again", remaining)}
+    }
+    cert.Verified = true
+
+    if err := s.sign(cert); err != nil {
+        return nil, err
+    }
+    err = s.log(ctx, audit.ActionTenantPurge, tenantID, map[string]string{
+        "certificate_id": cert.ID,
+        "removed":        strconv.FormatInt(cert.Total(), 10),
+        "export_sha256":  export.SHA256,
+        "export_records": strconv.FormatInt(export.Records, 10),
+    })
+    if err != nil {
+        return nil, err
+    }
+    if err := s.store.SaveCertificate(ctx, cert); err != nil {
+        return nil, err
+    }
+
+    err = s.store.Transition(ctx, tenantID, []string{StatusOffboarding}, StatusOffboarded, map[string]interface{}{
+        "offboarded_at": cert.CompletedAt,
+    })
+    if err != nil {
+        return nil, err
+    }
+    return cert, nil
+}
+
+// Certificate returns the deletion certificate of an offboarded tenant
+func (s *Service) Certificate(ctx context.Context, tenantID string) (*Certificate, error) {
+    if err := s.authorize(ctx); err != nil {
+        return nil, err
+    }
+    return s.store.Certificate(ctx, tenantID)
+}
+
+// authorize lets the platform's operators, and internal services, through
+func (s *Service) authorize(ctx context.Context) error {
+    actor := audit.ActorFromContext(ctx)
+    if actor.Type == audit.ActorSystem {
+        return nil
+    }
+    if actor.TenantID != tenancy.PlatformTenantID {
+        return &refusal.Error{Reason: ErrNotPlatform}
+    }
+    return s.policy.Require(ctx, actor, rbac.PermTenantsManage, nil)
+}
+
+func (s *Service) log(ctx context.Context, action, tenantID string, metadata map[string]string) error {
+    if metadata == nil {
+        metadata = map[string]string{}
+    }
+    metadata["tenant_id"] = tenantID
+    return s.auditLog.Log(ctx, audit.Entry{Action: action, TargetType: "tenant", Target: tenantID, Metadata: metadata})
+}
+
+// exportAudit writes the tenant's audit records to a new file in the
+// export directory, hashing them as they are written
+func (s *Service) exportAudit(ctx context.Context, tenantID string) (*AuditExport, error) {
+    name := fmt.Sprintf("%s-audit-%s.jsonl", tenantID, time.Now().UTC().Format("20060102T150405Z"))
+    path := filepath.Join(s.exportDir, name)
+    f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+    if err != nil {
+        return nil, fmt.Errorf("failed to create audit export: %w", err)
+    }
+    defer f.Close()
+
+    hash := sha256.New()
+    records, err := audit.Export(ctx, s.auditStore, tenantID, io.MultiWriter(f, hash))
+    if err != nil {
+        return nil, fmt.Errorf("failed to export audit log: %w", err)
+    }
+    if err := f.Sync(); err != nil {
+        return nil, fmt.Errorf("failed to write audit export: %w", err)
+    }
+    return &AuditExport{File: name, SHA256: hex.EncodeToString(hash.Sum(nil)), Records: records}, nil
+}
+
+func (s *Service) sign(cert *Certificate) error {
+    id, err := newID()
+    if err != nil {
+        return err
+    }
+    cert.ID = id
+    // Postgres keeps microseconds; sign what will be read back
+    cert.CompletedAt = time.Now().UTC().Truncate(time.Microsecond)
+
+    signed, key, err := s.signer.SignStatement(&certificateClaims{
+        Certificate: cert,
+        RegisteredClaims: jwt.RegisteredClaims{
+            ID:       cert.ID,
+            Subject:  cert.TenantID,
+            IssuedAt: jwt.NewNumericDate(cert.CompletedAt),
+        },
+    })
+    if err != nil {
+        return fmt.Errorf("failed to sign deletion certificate: %w", err)
+    }
+    cert.Signature, cert.PublicKey = signed, &key
+    return nil
+}
+
+// newID returns a random (version 4) UUID
+func newID() (string, error) {
+    b := make([]byte, 16)
+    if _, err := rand.Read(b); err != nil {
+        return "", fmt.Errorf("failed to generate certificate ID: %w", err)
+    }
+    b[6] = (b[6] & 0x0f) | 0x40
+    b[8] = (b[8] & 0x3f) | 0x80
+    return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
@@ -0,0 +1,89 @@
+// pkg/lifecycle/store.go
+package lifecycle
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "time"
+
+    "gorm.io/gorm"
+
+    "secure-iran-intel/pkg/refusal"
+)
+
+// Store persists tenant statuses and deletion certificates. Transition
+// must only move a tenant that is in one of from, so two operators cannot
+// both act on it.
+type Store interface {
+    // Get returns ErrUnknownTenant when there is no such tenant
+    Get(ctx context.Context, tenantID string) (*Tenant, error)
+    // Transition moves the tenant to to and applies changes; it returns
+    // ErrInvalidState when the tenant is not in one of from
+    Transition(ctx context.Context, tenantID string, from []string, to string, changes map[string]interface{}) error
+    SaveCertificate(ctx context.Context, c *Certificate) error
+    // Certificate returns ErrUnknownTenant when the tenant has none
+    Certificate(ctx context.Context, tenantID string) (*Certificate, error)
+}
+
+// GormStore reads and writes the tenants and tenant_deletion_certificates
+// tables (migrations 007 and 018). Neither is under row-level security.
+type GormStore struct {
+    db *gorm.DB
+}
+
+func NewGormStore(db *gorm.DB) *GormStore {
+    return &GormStore{db: db}
+}
+
+func (s *GormStore) Get(ctx context.Context, tenantID string) (*Tenant, error) {
+    var t Tenant
+    err := s.db.WithContext(ctx).Where("id = ?", tenantID).First(&t).Error
+    if errors.Is(err, gorm.ErrRecordNotFound) {
+        return nil, &refusal.Error{Reason: ErrUnknownTenant, ID: tenantID}
+    }
+    if err != nil {
+        return nil, err
+    }
+    return &t, nil
+}
+
+func (s *GormStore) Transition(ctx context.Context, tenantID string, from []string, to string, changes map[string]interface{}) error {
+    updates := map[string]interface{}{"status": to, "updated_at": time.Now()}
+    for column, value := range changes {
+        updates[column] = value
+    }
+
+    result := s.db.WithContext(ctx).Model(&Tenant{}).
+        Where("id = ? AND status IN ?", tenantID, from).
+        Updates(updates)
+    if result.Error != nil {
+        return fmt.Errorf("failed to update tenant status: %w", result.Error)
+    }
+    if result.RowsAffected == 0 {
+        if _, err := s.Get(ctx, tenantID); err != nil {
+            return err
+        }
+        return &refusal.Error{Reason: ErrInvalidState, ID: tenantID, Detail: fmt.Sprintf("cannot move to %s", to)}
+    }
+    return nil
+}
+
+func (s *GormStore) SaveCertificate(ctx context.Context, c *Certificate) error {
+    if err := s.db.WithContext(ctx).Create(c).Error; err != nil {
+        return fmt.Errorf("failed to store deletion certificate: %w", err)
+    }
+    return nil
+}
+
+func (s *GormStore) Certificate(ctx context.Context, tenantID string) (*Certificate, error) {
+    var c Certificate
+    err := s.db.WithContext(ctx).Where("tenant_id = ?", tenantID).Order("completed_at DESC").First(&c).Error
+    if errors.Is(err, gorm.ErrRecordNotFound) {
+        return nil, &refusal.Error{Reason: ErrUnknownTenant, ID: tenantID, Detail: "no deletion certificate"}
+    }
+    if err != nil {
+        return nil, err
+    }
+    return &c, nil
+}
@@ -0,0 +1,90 @@
+// pkg/mfa/challenges.go
+package mfa
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "time"
+
+    "github.com/go-redis/redis/v8"
+    "github.com/go-webauthn/webauthn/webauthn"
+
+    "secure-iran-intel/pkg/refusal"
+)
+
+// How long a WebAuthn ceremony may take
+const challengeTTL = 5 * time.Minute
+
+// Challenges keeps WebAuthn ceremonies in progress and counts failed
+// verifications, in Redis so any instance can finish a ceremony
+type Challenges struct {
+    redis *redis.Client
+}
+
+func NewChallenges(client *redis.Client) *Challenges {
+    return &Challenges{redis: client}
+}
+
+func challengeKey(kind, tenantID, userID string) string {
+    return fmt.Sprintf("mfa_challenge:%s:%s:%s", kind, tenantID, userID)
+}
+
+func attemptsKey(tenantID, userID string) string {
+    return fmt.Sprintf("mfa_attempts:%s:%s", tenantID, userID)
+}
+
+// Put stores a ceremony's session. A user has at most one of each kind in
+// progress; starting another replaces it.
+func (c *Challenges) Put(ctx context.Context, kind, tenantID, userID string, session *webauthn.SessionData) error {
+    body, err := json.Marshal(session)
+    if err != nil {
+        return err
+    }
+    return c.redis.Set(ctx, challengeKey(kind, tenantID, userID), body, challengeTTL).Err()
+}
+
+// Take returns and forgets a ceremony's session, or ErrInvalidChallenge
+func (c *Challenges) Take(ctx context.Context, kind, tenantID, userID string) (*webauthn.SessionData, error) {
+    body, err := c.redis.GetDel(ctx, challengeKey(kind, tenantID, userID)).Bytes()
+    if errors.Is(err, redis.Nil) {
+        return nil, &refusal.Error{Reason: ErrInvalidChallenge}
+    }
+    if err != nil {
+        return nil, err
+    }
+    var session webauthn.SessionData
+    if err := json.Unmarshal(body, &session); err != nil {
+        return nil, err
+    }
+    return &session, nil
+}
+
+// Allow refuses verification once a user has failed MaxAttempts times
+// within AttemptWindow
+func (c *Challenges) Allow(ctx context.Context, tenantID, userID string) error {
+    failed, err := c.redis.Get(ctx, attemptsKey(tenantID, userID)).Int()
+    if err != nil && !errors.Is(err, redis.Nil) {
+        return err
+    }
+    if failed >= MaxAttempts {
+        return &refusal.Error{Reason: ErrTooManyAttempts}
+    }
+    return nil
+}
+
+// Failed counts a failed verification. The window starts at the first.
+func (c *Challenges) Failed(ctx context.Context, tenantID, userID string) error {
+    key := attemptsKey(tenantID, userID)
+    pipe := c.redis.TxPipeline()
+    pipe.Incr(ctx, key)
+    pipe.ExpireNX(ctx, key, AttemptWindow)
+    _, err := pipe.Exec(ctx)
+    return err
+}
+
+// Succeeded clears the failure count
+func (c *Challenges) Succeeded(ctx context.Context, tenantID, userID string) error {
+    return c.redis.Del(ctx, attemptsKey(tenantID, userID)).Err()
+}
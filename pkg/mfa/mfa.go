@@ -0,0 +1,82 @@
+// pkg/mfa/mfa.go
+package mfa
+
+import (
+    "net/http"
+    "time"
+
+    "github.com/go-webauthn/webauthn/webauthn"
+
+    "secure-iran-intel/pkg/refusal"
+)
+
+// Factor types
+const (
+    FactorTOTP     = "totp"
+    FactorWebAuthn = "webauthn"
+)
+
+// Methods a second factor can be proved with
+const (
+    MethodTOTP         = "totp"
+    MethodWebAuthn     = "webauthn"
+    MethodRecoveryCode = "recovery_code"
+)
+
+const (
+    // RecoveryCodeCount codes are issued at a time; each works once
+    RecoveryCodeCount = 10
+    // MaxAttempts failed verifications lock a user out for AttemptWindow
+    MaxAttempts   = 5
+    AttemptWindow = 15 * time.Minute
+)
+
+// Factor is an enrolled second factor. TOTP factors count once confirmed
+// with a first code; WebAuthn factors are confirmed by registration.
+type Factor struct {
+    ID       string `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+    TenantID string `json:"-" gorm:"type:uuid;not null"`
+    UserID   string `json:"-" gorm:"type:uuid;not null"`
+    Type     string `json:"type" gorm:"not null"` // FactorTOTP, FactorWebAuthn
+    Name     string `json:"name"`
+
+    Secret       string               `json:"-" gorm:"serializer:encrypted"` // TOTP, base32
+    LastUsedStep int64                `json:"-"`                             // TOTP, so a code works once
+    Credential   *webauthn.Credential `json:"-" gorm:"serializer:json;type:jsonb"`
+
+    ConfirmedAt *time.Time `json:"confirmed_at"`
+    LastUsed    *time.Time `json:"last_used"`
+    CreatedAt   time.Time  `json:"created_at"`
+}
+
+func (Factor) TableName() string {
+    return "mfa_factors"
+}
+
+// RecoveryCode is a one-time code for when the user's factors are lost.
+// Only its bcrypt hash is stored.
+type RecoveryCode struct {
+    ID        string `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+    TenantID  string `gorm:"type:uuid;not null"`
+    UserID    string `gorm:"type:uuid;not null"`
+    CodeHash  string `gorm:"not null"`
+    UsedAt    *time.Time
+    CreatedAt time.Time
+}
+
+func (RecoveryCode) TableName() string {
+    return "mfa_recovery_codes"
+}
+
+// Refusal reasons. Match them with errors.Is.
+var (
+    ErrUnknownFactor    = refusal.New("MFA_FACTOR_NOT_FOUND", http.StatusNotFound, "factor not found")
+    ErrNoFactor         = refusal.New("MFA_NOT_ENROLLED", http.StatusForbidden, "no second factor enrolled")
+    ErrInvalidCode      = refusal.New("MFA_INVALID_CODE", http.StatusUnauthorized, "invalid verification code")
+    ErrAlreadyConfirmed = refusal.New("MFA_ALREADY_CONFIRMED", http.StatusConflict, "factor already confirmed")
+    ErrInvalidChallenge = refusal.New("MFA_CHALLENGE_INVALID", http.StatusUnauthorized, "challenge expired or unknown")
+    ErrTooManyAttempts  = refusal.New("MFA_TOO_MANY_ATTEMPTS", http.StatusTooManyRequests, "too many failed attempts")
+    ErrStepUpRequired   = refusal.New("STEP_UP_REQUIRED", http.StatusForbidden, "re-authentication required")
+    ErrMFARequired      = refusal.New("MFA_REQUIRED", http.StatusForbidden, "the tenant requires a second factor")
+    ErrUnsupported      = refusal.New("MFA_UNSUPPORTED_METHOD", http.StatusBadRequest, "unsupported verification method")
+)
@@ -0,0 +1,570 @@
+// pkg/mfa/service.go
+package mfa
+
+import (
+    "bytes"
+    "context"
+    "crypto/rand"
+    "crypto/sha256"
+    "crypto/subtle"
+    "encoding/base32"
+    "encoding/hex"
+    "fmt"
+    "io"
+    "log"
+    "strings"
+    "time"
+
+    "github.com/go-webauthn/webauthn/protocol"
+    "github.com/go-webauthn/webauthn/webauthn"
+    "golang.org/x/crypto/bcrypt"
+    "secure-iran-intel/pkg/audit"
+    "secure-iran-intel/pkg/refusal"
+    "secure-iran-intel/pkg/token"
+)
+
+// WebAuthn ceremonies
+const (
+    ceremonyRegister = "register"
+    ceremonyLogin    = "login"
+)
+
+// WebAuthnConfig identifies this service to authenticators
+type WebAuthnConfig struct {
+    RPID          string   // The domain credentials are bound to
+    RPDisplayName string   // Also the issuer shown in TOTP apps
+    RPOrigins     []string // Origins the browser may run ceremonies from
+}
+
+// Service enrolls second factors and verifies them. A successful
+// verification reissues the caller's access token as multi-factor and
+// stepped up.
+type Service struct {
+    store      Store
+    challenges *Challenges
+    webauthn   *webauthn.WebAuthn
+    issuer     *token.Issuer
+    auditLog   *audit.Logger
+    appName    string
+}
+
+func NewService(store Store, challenges *Challenges, cfg WebAuthnConfig, issuer *token.Issuer, auditLog *audit.Logger) (*Service, error) {
+    wa, err := webauthn.New(&webauthn.Config{
+        RPID:          cfg.RPID,
+        RPDisplayName: cfg.RPDisplayName,
+        RPOrigins:     cfg.RPOrigins,
+    })
+    if err != nil {
+        return nil, fmt.Errorf("invalid WebAuthn configuration: %w", err)
+    }
+    return &Service{
+        store:      store,
+        challenges: challenges,
+        webauthn:   wa,
+        issuer:     issuer,
+        auditLog:   auditLog,
+        appName:    cfg.RPDisplayName,
+    }, nil
+}
+
+// TOTPEnrollment is a pending TOTP factor. The secret is only shown here.
+type TOTPEnrollment struct {
+    Factor *Factor `json:"factor"`
+    Secret string  `json:"secret"`
+    URL    string  `json:"otpauth_url"`
+}
+
+// Verification is the token a verified factor earns
+type Verification struct {
+    AccessToken string    `json:"access_token"`
+    TokenType   string    `json:"token_type"`
+    ExpiresAt   time.Time `json:"expires_at"`
+    StepUpUntil time.Time `json:"step_up_until"`
+    // RecoveryCodes are issued with the first factor, and shown only once
+    RecoveryCodes []string `json:"recovery_codes,omitempty"`
+}
+
+// Factors lists the caller's factors
+func (s *Service) Factors(ctx context.Context, claims *token.Claims) ([]Factor, error) {
+    return s.store.Factors(ctx, claims.TenantID, claims.UserID)
+}
+
+// Enrolled reports whether a user has a confirmed factor
+func (s *Service) Enrolled(ctx context.Context, tenantID, userID string) (bool, error) {
+    factors, err := s.confirmed(ctx, tenantID, userID, "")
+    return len(factors) > 0, err
+}
+
+// EnrollTOTP starts enrolling an authenticator app. The factor counts once
+// ConfirmTOTP has seen a code from it.
+func (s *Service) EnrollTOTP(ctx context.Context, claims *token.Claims, name string) (*TOTPEnrollment, error) {
+    if err := s.requireStepUpIfEnrolled(ctx, claims); err != nil {
+        return nil, err
+    }
+    account, err := s.store.UserName(ctx, claims.TenantID, claims.UserID)
+    if err != nil {
+        return nil, err
+    }
+    secret, err := newTOTPSecret()
+    if err != nil {
+        return nil, err
+    }
+    f := &Factor{
+        TenantID: claims.TenantID,
+        UserID:   claims.UserID,
+        Type:     FactorTOTP,
+        Name:     factorName(name, "Authenticator app"),
+        Secret:   secret,
+    }
+    if err := s.store.CreateFactor(ctx, f); err != nil {
+        return nil, err
+    }
+    return &TOTPEnrollment{Factor: f, Secret: secret, URL: totpURL(s.appName, account, secret)}, nil
+}
+
+// ConfirmTOTP confirms a pending TOTP factor with a code from it
+func (s *Service) ConfirmTOTP(ctx context.Context, claims *token.Claims, factorID, code string) (*Verification, error) {
+    f, err := s.store.Factor(ctx, claims.TenantID, claims.UserID, factorID)
+    if err != nil {
+        return nil, err
+    }
+    if f.Type != FactorTOTP {
+        return nil, &refusal.Error{Reason: ErrUnknownFactor, ID: factorID}
+    }
+    if f.ConfirmedAt != nil {
+        return nil, &refusal.Error{Reason: ErrAlreadyConfirmed, ID: factorID}
+    }
+    if err := s.challenges.Allow(ctx, claims.TenantID, claims.UserID); err != nil {
+        return nil, err
+    }
+    step, err := matchTOTP(f.Secret, code, time.Now())
+    if err != nil {
+        return nil, err
+    }
+    if step == 0 {
+        return nil, s.failed(ctx, claims, MethodTOTP)
+    }
+    return s.enrolled(ctx, claims, f, func() error { return s.store.ConfirmFactor(ctx, f, step) })
+}
+
+// BeginWebAuthnRegistration starts registering a security key or platform
+// authenticator. The options go to navigator.credentials.create().
+func (s *Service) BeginWebAuthnRegistration(ctx context.Context, claims *token.Claims) (*protocol.CredentialCreation, error) {
+    if err := s.requireStepUpIfEnrolled(ctx, claims); err != nil {
+        return nil, err
+    }
+    user, err := s.webauthnUser(ctx, claims)
+    if err != nil {
+        return nil, err
+    }
+    // Registering the same authenticator twice is refused by the browser
+    creation, session, err := s.webauthn.BeginRegistration(user,
+        webauthn.WithExclusions(webauthn.Credentials(user.credentials).CredentialDescriptors()))
+    if err != nil {
+        return nil, err
+    }
+    if err := s.challenges.Put(ctx, ceremonyRegister, claims.TenantID, claims.UserID, session); err != nil {
+        return nil, err
+    }
+    return creation, nil
+}
+
+// FinishWebAuthnRegistration checks the authenticator's attestation and
+// enrolls it. body is the PublicKeyCredential the browser returned.
+func (s *Service) FinishWebAuthnRegistration(ctx context.Context, claims *token.Claims, name string, body io.Reader) (*Verification, error) {
+    session, err := s.challenges.Take(ctx, ceremonyRegister, claims.TenantID, claims.UserID)
+    if err != nil {
+        return nil, err
+    }
+    user, err := s.webauthnUser(ctx, claims)
+    if err != nil {
+        return nil, err
+    }
+    parsed, err := protocol.ParseCredentialCreationResponseBody(body)
+    if err != nil {
+        return nil, &refusal.Error{Reason: ErrInvalidChallenge, Detail: err.Error()}
+    }
+    credential, err := s.webauthn.CreateCredential(user, *session, parsed)
+    if err != nil {
+        return nil, &refusal.Error{Reason: ErrInvalidChallenge, Detail: err.Error()}
+    }
+
+    now := time.Now()
+    f := &Factor{
+        TenantID:    claims.TenantID,
+        UserID:      claims.UserID,
+        Type:        FactorWebAuthn,
+        Name:        factorName(name, "Security key"),
+        Credential:  credential,
+        ConfirmedAt: &now,
+    }
+    return s.enrolled(ctx, claims, f, func() error { return s.store.CreateFactor(ctx, f) })
+}
+
+// enrolled audits and stores a newly confirmed factor, issues recovery
+// codes with the first one, and steps the caller up: they just proved
+// they hold the factor
+func (s *Service) enrolled(ctx context.Context, claims *token.Claims, f *Factor, store func() error) (*Verification, error) {
+    first, err := s.Enrolled(ctx, claims.TenantID, claims.UserID)
+    if err != nil {
+        return nil, err
+    }
+    first = !first
+
+    err = s.auditLog.Log(ctx, audit.Entry{
+        Action:     audit.ActionMFAEnroll,
+        TargetType: "user",
+        Target:     claims.UserID,
+        Metadata:   map[string]string{"factor": f.ID, "type": f.Type, "name": f.Name},
+    })
+    if err != nil {
+        return nil, err
+    }
+    if err := store(); err != nil {
+        return nil, err
+    }
+
+    var codes []string
+    if first {
+        if codes, err = s.newRecoveryCodes(ctx, claims); err != nil {
+            return nil, err
+        }
+    }
+    method := token.AMROTP
+    if f.Type == FactorWebAuthn {
+        method = token.AMRHardwareKey
+    }
+    v, err := s.stepUp(ctx, claims, method)
+    if err != nil {
+        return nil, err
+    }
+    v.RecoveryCodes = codes
+    return v, nil
+}
+
+// Verify checks a TOTP or recovery code and steps the caller up
+func (s *Service) Verify(ctx context.Context, claims *token.Claims, method, code string) (*Verification, error) {
+    if err := s.challenges.Allow(ctx, claims.TenantID, claims.UserID); err != nil {
+        return nil, err
+    }
+
+    switch method {
+    case MethodTOTP:
+        factors, err := s.confirmed(ctx, claims.TenantID, claims.UserID, FactorTOTP)
+        if err != nil {
+            return nil, err
+        }
+        if len(factors) == 0 {
+            return nil, &refusal.Error{Reason: ErrNoFactor, Detail: "no authenticator app enrolled"}
+        }
+        now := time.Now()
+        for i := range factors {
+            step, err := matchTOTP(factors[i].Secret, code, now)
+            if err != nil || step == 0 {
+                continue
+            }
+            // A code is spent once it has been accepted
+            ok, err := s.store.UseTOTPStep(ctx, &factors[i], step)
+            if err != nil {
+                return nil, err
+            }
+            if ok {
+                return s.verified(ctx, claims, method, factors[i].ID, token.AMROTP)
+            }
+        }
+    case MethodRecoveryCode:
+        codes, err := s.store.RecoveryCodes(ctx, claims.TenantID, claims.UserID)
+        if err != nil {
+            return nil, err
+        }
+        for _, c := range codes {
+            if !recoveryCodeMatches(c.CodeHash, code) {
+                continue
+            }
+            ok, err := s.store.UseRecoveryCode(ctx, claims.TenantID, claims.UserID, c.ID)
+            if err != nil {
+                return nil, err
+            }
+            if ok {
+                return s.verified(ctx, claims, method, "", token.AMRRecoveryCode)
+            }
+        }
+    default:
+        return nil, &refusal.Error{Reason: ErrUnsupported, Detail: method}
+    }
+    return nil, s.failed(ctx, claims, method)
+}
+
+// BeginWebAuthn starts an assertion with one of the caller's registered
+// authenticators. The options go to navigator.credentials.get().
+func (s *Service) BeginWebAuthn(ctx context.Context, claims *token.Claims) (*protocol.CredentialAssertion, error) {
+    user, err := s.webauthnUser(ctx, claims)
+    if err != nil {
+        return nil, err
+    }
+    if len(user.credentials) == 0 {
+        return nil, &refusal.Error{Reason: ErrNoFactor, Detail: "no security key enrolled"}
+    }
+    assertion, session, err := s.webauthn.BeginLogin(user)
+    if err != nil {
+        return nil, err
+    }
+    if err := s.challenges.Put(ctx, ceremonyLogin, claims.TenantID, claims.UserID, session); err != nil {
+        return nil, err
+    }
+    return assertion, nil
+}
+
+// VerifyWebAuthn checks an assertion and steps the caller up. body is the
+// PublicKeyCredential the browser returned.
+func (s *Service) VerifyWebAuthn(ctx context.Context, claims *token.Claims, body io.Reader) (*Verification, error) {
+    if err := s.challenges.Allow(ctx, claims.TenantID, claims.UserID); err != nil {
+        return nil, err
+    }
+    session, err := s.challenges.Take(ctx, ceremonyLogin, claims.TenantID, claims.UserID)
+    if err != nil {
+        return nil, err
+    }
+    user, err := s.webauthnUser(ctx, claims)
+    if err != nil {
+        return nil, err
+    }
+    parsed, err := protocol.ParseCredentialRequestResponseBody(body)
+    if err != nil {
+        return nil, s.failed(ctx, claims, MethodWebAuthn)
+    }
+    credential, err := s.webauthn.ValidateLogin(user, *session, parsed)
+    if err != nil {
+        return nil, s.failed(ctx, claims, MethodWebAuthn)
+    }
+    f := user.factor(credential.ID)
+    if f == nil {
+        return nil, s.failed(ctx, claims, MethodWebAuthn)
+    }
+    // A signature counter that went backwards means the key was copied
+    if credential.Authenticator.CloneWarning {
+        log.Printf("⚠️ WebAuthn factor %s of user %s may be cloned; refusing it", f.ID, claims.UserID)
+        return nil, s.failed(ctx, claims, MethodWebAuthn)
+    }
+    f.Credential = credential
+    if err := s.store.UpdateCredential(ctx, f); err != nil {
+        return nil, err
+    }
+    return s.verified(ctx, claims, MethodWebAuthn, f.ID, token.AMRHardwareKey)
+}
+
+// RemoveFactor deletes one of the caller's factors. It needs a fresh
+// step-up, so a stolen session cannot strip a user's MFA.
+func (s *Service) RemoveFactor(ctx context.Context, claims *token.Claims, id string) error {
+    if !claims.SteppedUp(time.Now()) {
+        return &refusal.Error{Reason: ErrStepUpRequired}
+    }
+    f, err := s.store.Factor(ctx, claims.TenantID, claims.UserID, id)
+    if err != nil {
+        return err
+    }
+    err = s.auditLog.Log(ctx, audit.Entry{
+        Action:     audit.ActionMFARemove,
+        TargetType: "user",
+        Target:     claims.UserID,
+        Metadata:   map[string]string{"factor": f.ID, "type": f.Type},
+    })
+    if err != nil {
+        return err
+    }
+    return s.store.DeleteFactor(ctx, claims.TenantID, claims.UserID, id)
+}
+
+// RegenerateRecoveryCodes replaces the caller's recovery codes
+func (s *Service) RegenerateRecoveryCodes(ctx context.Context, claims *token.Claims) ([]string, error) {
+    if !claims.SteppedUp(time.Now()) {
+        return nil, &refusal.Error{Reason: ErrStepUpRequired}
+    }
+    enrolled, err := s.Enrolled(ctx, claims.TenantID, claims.UserID)
+    if err != nil {
+        return nil, err
+    }
+    if !enrolled {
+        return nil, &refusal.Error{Reason: ErrNoFactor}
+    }
+    return s.newRecoveryCodes(ctx, claims)
+}
+
+func (s *Service) newRecoveryCodes(ctx context.Context, claims *token.Claims) ([]string, error) {
+    codes := make([]string, RecoveryCodeCount)
+    hashes := make([]string, RecoveryCodeCount)
+    for i := range codes {
+        b := make([]byte, recoveryCodeBytes)
+        if _, err := rand.Read(b); err != nil {
+            return nil, err
+        }
+        code := strings.ToLower(base32.StdEncoding.EncodeToString(b)) // 16 characters, no padding
+        codes[i] = code[:4] + "-" + code[4:8] + "-" + code[8:12] + "-" + code[12:]
+        hash, err := bcrypt.GenerateFromPassword([]byte(normalizeRecoveryCode(codes[i])), recoveryCodeCost)
+        if err != nil {
+            return nil, fmt.Errorf("failed to hash recovery code: %w", err)
+        }
+        hashes[i] = string(hash)
+    }
+
+    err := s.auditLog.Log(ctx, audit.Entry{
+        Action:     audit.ActionMFARecoveryCodes,
+        TargetType: "user",
+        Target:     claims.UserID,
+    })
+    if err != nil {
+        return nil, err
+    }
+    if err := s.store.ReplaceRecoveryCodes(ctx, claims.TenantID, claims.UserID, hashes); err != nil {
+        return nil, err
+    }
+    return codes, nil
+}
+
+// verified records a successful verification and issues the stepped-up
+// token
+func (s *Service) verified(ctx context.Context, claims *token.Claims, method, factorID, amr string) (*Verification, error) {
+    err := s.auditLog.Log(ctx, audit.Entry{
+        Action:     audit.ActionMFAVerify,
+        TargetType: "user",
+        Target:     claims.UserID,
+        Metadata:   map[string]string{"method": method, "factor": factorID},
+    })
+    if err != nil {
+        return nil, err
+    }
+    if err := s.challenges.Succeeded(ctx, claims.TenantID, claims.UserID); err != nil {
+        log.Printf("⚠️ Failed to reset MFA attempts for user %s: %v", claims.UserID, err)
+    }
+    return s.stepUp(ctx, claims, amr)
+}
+
+func (s *Service) stepUp(ctx context.Context, claims *token.Claims, amr string) (*Verification, error) {
+    signed, stepped, err := s.issuer.StepUp(claims, amr)
+    if err != nil {
+        return nil, err
+    }
+    return &Verification{
+        AccessToken: signed,
+        TokenType:   "Bearer",
+        ExpiresAt:   stepped.ExpiresAt.Time,
+        StepUpUntil: stepped.StepUpUntil.Time,
+    }, nil
+}
+
+// failed counts and records a failed verification, and returns the error
+// for it
+func (s *Service) failed(ctx context.Context, claims *token.Claims, method string) error {
+    if err := s.challenges.Failed(ctx, claims.TenantID, claims.UserID); err != nil {
+        return err
+    }
+    err := s.auditLog.Log(ctx, audit.Entry{
+        Action:     audit.ActionMFAFailure,
+        TargetType: "user",
+        Target:     claims.UserID,
+        Metadata:   map[string]string{"method": method},
+    })
+    if err != nil {
+        log.Printf("⚠️ Failed to audit MFA failure for user %s: %v", claims.UserID, err)
+    }
+    return &refusal.Error{Reason: ErrInvalidCode}
+}
+
+// Adding a factor to an account that has one needs a step-up, or a stolen
+// session could enroll the thief's authenticator
+func (s *Service) requireStepUpIfEnrolled(ctx context.Context, claims *token.Claims) error {
+    enrolled, err := s.Enrolled(ctx, claims.TenantID, claims.UserID)
+    if err != nil {
+        return err
+    }
+    if enrolled && !claims.SteppedUp(time.Now()) {
+        return &refusal.Error{Reason: ErrStepUpRequired}
+    }
+    return nil
+}
+
+func (s *Service) confirmed(ctx context.Context, tenantID, userID, factorType string) ([]Factor, error) {
+    factors, err := s.store.Factors(ctx, tenantID, userID)
+    if err != nil {
+        return nil, err
+    }
+    var confirmed []Factor
+    for _, f := range factors {
+        if f.ConfirmedAt != nil && (factorType == "" || f.Type == factorType) {
+            confirmed = append(confirmed, f)
+        }
+    }
+    return confirmed, nil
+}
+
+// webauthnUser adapts a user and their registered authenticators to the
+// WebAuthn library
+type webauthnUser struct {
+    id          string
+    name        string
+    factors     []Factor
+    credentials []webauthn.Credential
+}
+
+func (u *webauthnUser) WebAuthnID() []byte                         { return []byte(u.id) }
+func (u *webauthnUser) WebAuthnName() string                       { return u.name }
+func (u *webauthnUser) WebAuthnDisplayName() string                { return u.name }
+func (u *webauthnUser) WebAuthnCredentials() []webauthn.Credential { return u.credentials }
+
+func (u *webauthnUser) factor(credentialID []byte) *Factor {
+    for i := range u.factors {
+        if bytes.Equal(u.factors[i].Credential.ID, credentialID) {
+            return &u.factors[i]
+        }
+    }
+    return nil
+}
+
+func (s *Service) webauthnUser(ctx context.Context, claims *token.Claims) (*webauthnUser, error) {
+    name, err := s.store.UserName(ctx, claims.TenantID, claims.UserID)
+    if err != nil {
+        return nil, err
+    }
+    factors, err := s.confirmed(ctx, claims.TenantID, claims.UserID, FactorWebAuthn)
+    if err != nil {
+        return nil, err
+    }
+    user := &webauthnUser{id: claims.UserID, name: name}
+    for _, f := range factors {
+        if f.Credential != nil {
+            user.factors = append(user.factors, f)
+            user.credentials = append(user.credentials, *f.Credential)
+        }
+    }
+    return user, nil
+}
+
+// Recovery codes are 80 random bits, hashed with bcrypt so a copy of the
+// table cannot be searched for them
+const (
+    recoveryCodeBytes = 10
+    recoveryCodeCost  = bcrypt.DefaultCost
+)
+
+func normalizeRecoveryCode(code string) string {
+    return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(code)))
+}
+
+// recoveryCodeMatches compares code with a stored hash. Codes issued before
+// bcrypt have an unsalted SHA-256 hash, which still works until the user
+// regenerates their codes.
+func recoveryCodeMatches(hash, code string) bool {
+    normalized := normalizeRecoveryCode(code)
+    if !strings.HasPrefix(hash, "$2") {
+        sum := sha256.Sum256([]byte(normalized))
+        return subtle.ConstantTimeCompare([]byte(hash), []byte(hex.EncodeToString(sum[:]))) == 1
+    }
+    return bcrypt.CompareHashAndPassword([]byte(hash), []byte(normalized)) == nil
+}
+
+func factorName(name, fallback string) string {
+    if name = strings.TrimSpace(name); name != "" {
+        return name
+    }
+    return fallback
+}
@@ -0,0 +1,199 @@
+// pkg/mfa/store.go
+package mfa
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "time"
+
+    "gorm.io/gorm"
+    "secure-iran-intel/pkg/refusal"
+    "secure-iran-intel/pkg/tenancy"
+)
+
+// Store persists factors and recovery codes. Everything is scoped to one
+// tenant's user.
+type Store interface {
+    CreateFactor(ctx context.Context, f *Factor) error
+    Factor(ctx context.Context, tenantID, userID, id string) (*Factor, error)
+    // Factors lists a user's factors, unconfirmed ones included
+    Factors(ctx context.Context, tenantID, userID string) ([]Factor, error)
+    ConfirmFactor(ctx context.Context, f *Factor, step int64) error
+    // UseTOTPStep records a TOTP code's step; false means the step, or a
+    // later one, was already used
+    UseTOTPStep(ctx context.Context, f *Factor, step int64) (bool, error)
+    UpdateCredential(ctx context.Context, f *Factor) error
+    // DeleteFactor removes a factor, and the recovery codes with the last
+    // confirmed one
+    DeleteFactor(ctx context.Context, tenantID, userID, id string) error
+
+    ReplaceRecoveryCodes(ctx context.Context, tenantID, userID string, hashes []string) error
+    // RecoveryCodes lists a user's unused codes
+    RecoveryCodes(ctx context.Context, tenantID, userID string) ([]RecoveryCode, error)
+    // UseRecoveryCode spends an unused code; false when it was spent already
+    UseRecoveryCode(ctx context.Context, tenantID, userID, id string) (bool, error)
+
+    // UserName is what authenticators show for the account, the email
+    UserName(ctx context.Context, tenantID, userID string) (string, error)
+}
+
+// GormStore keeps factors in mfa_factors and mfa_recovery_codes
+// (migration 017)
+type GormStore struct {
+    db *gorm.DB
+}
+
+func NewGormStore(db *gorm.DB) *GormStore {
+    return &GormStore{db: db}
+}
+
+func (s *GormStore) CreateFactor(ctx context.Context, f *Factor) error {
+    err := tenancy.Transaction(ctx, s.db, f.TenantID, func(tx *gorm.DB) error {
+        return tx.Create(f).Error
+    })
+    if err != nil {
+        return fmt.Errorf("failed to create factor: %w", err)
+    }
+    return nil
+}
+
+func (s *GormStore) Factor(ctx context.Context, tenantID, userID, id string) (*Factor, error) {
+    var f Factor
+    err := tenancy.Transaction(ctx, s.db, tenantID, func(tx *gorm.DB) error {
+        return tx.Where("tenant_id = ? AND user_id = ? AND id = ?", tenantID, userID, id).First(&f).Error
+    })
+    if errors.Is(err, gorm.ErrRecordNotFound) {
+        return nil, &refusal.Error{Reason: ErrUnknownFactor, ID: id}
+    }
+    if err != nil {
+        return nil, err
+    }
+    return &f, nil
+}
+
+func (s *GormStore) Factors(ctx context.Context, tenantID, userID string) ([]Factor, error) {
+    var factors []Factor
+    err := tenancy.Transaction(ctx, s.db, tenantID, func(tx *gorm.DB) error {
+        return tx.Where("tenant_id = ? AND user_id = ?", tenantID, userID).Order("created_at").Find(&factors).Error
+    })
+    return factors, err
+}
+
+func (s *GormStore) ConfirmFactor(ctx context.Context, f *Factor, step int64) error {
+    var confirmed int64
+    err := tenancy.Transaction(ctx, s.db, f.TenantID, func(tx *gorm.DB) error {
+        result := tx.Model(&Factor{}).
+            Where("tenant_id = ? AND id = ? AND confirmed_at IS NULL", f.TenantID, f.ID).
+            Updates(map[string]interface{}{"confirmed_at": time.Now(), "last_used_step": step})
+        confirmed = result.RowsAffected
+        return result.Error
+    })
+    if err != nil {
+        return fmt.Errorf("failed to confirm factor: %w", err)
+    }
+    if confirmed == 0 {
+        return &refusal.Error{Reason: ErrAlreadyConfirmed, ID: f.ID}
+    }
+    return nil
+}
+
+func (s *GormStore) UseTOTPStep(ctx context.Context, f *Factor, step int64) (bool, error) {
+    var used int64
+    err := tenancy.Transaction(ctx, s.db, f.TenantID, func(tx *gorm.DB) error {
+        // Conditional, so two requests racing with one code cannot both win
+        result := tx.Model(&Factor{}).
+            Where("tenant_id = ? AND id = ? AND last_used_step < ?", f.TenantID, f.ID, step).
+            Updates(map[string]interface{}{"last_used_step": step, "last_used": time.Now()})
+        used = result.RowsAffected
+        return result.Error
+    })
+    return used == 1, err
+}
+
+func (s *GormStore) UpdateCredential(ctx context.Context, f *Factor) error {
+    now := time.Now()
+    f.LastUsed = &now
+    return tenancy.Transaction(ctx, s.db, f.TenantID, func(tx *gorm.DB) error {
+        // A struct update, so the credential goes through its serializer
+        return tx.Model(f).Select("credential", "last_used").Updates(f).Error
+    })
+}
+
+func (s *GormStore) DeleteFactor(ctx context.Context, tenantID, userID, id string) error {
+    var deleted int64
+    err := tenancy.Transaction(ctx, s.db, tenantID, func(tx *gorm.DB) error {
+        result := tx.Where("tenant_id = ? AND user_id = ? AND id = ?", tenantID, userID, id).Delete(&Factor{})
+        if result.Error != nil {
+            return result.Error
+        }
+        deleted = result.RowsAffected
+
+        var remaining int64
+        err := tx.Model(&Factor{}).
+            Where("tenant_id = ? AND user_id = ? AND confirmed_at IS NOT NULL", tenantID, userID).
+            Count(&remaining).Error
+        if err != nil || remaining > 0 {
+            return err
+        }
+        return tx.Where("tenant_id = ? AND user_id = ?", tenantID, userID).Delete(&RecoveryCode{}).Error
+    })
+    if err != nil {
+        return fmt.Errorf("failed to delete factor: %w", err)
+    }
+    if deleted == 0 {
+        return &refusal.Error{Reason: ErrUnknownFactor, ID: id}
+    }
+    return nil
+}
+
+func (s *GormStore) ReplaceRecoveryCodes(ctx context.Context, tenantID, userID string, hashes []string) error {
+    err := tenancy.Transaction(ctx, s.db, tenantID, func(tx *gorm.DB) error {
+        if err := tx.Where("tenant_id = ? AND user_id = ?", tenantID, userID).Delete(&RecoveryCode{}).Error; err != nil {
+            return err
+        }
+        codes := make([]RecoveryCode, len(hashes))
+        for i, hash := range hashes {
+            codes[i] = RecoveryCode{TenantID: tenantID, UserID: userID, CodeHash: hash}
+        }
+        return tx.Create(&codes).Error
+    })
+    if err != nil {
+        return fmt.Errorf("failed to store recovery codes: %w", err)
+    }
+    return nil
+}
+
+func (s *GormStore) RecoveryCodes(ctx context.Context, tenantID, userID string) ([]RecoveryCode, error) {
+    var codes []RecoveryCode
+    err := tenancy.Transaction(ctx, s.db, tenantID, func(tx *gorm.DB) error {
+        return tx.Where("tenant_id = ? AND user_id = ? AND used_at IS NULL", tenantID, userID).Find(&codes).Error
+    })
+    if err != nil {
+        return nil, fmt.Errorf("failed to load recovery codes: %w", err)
+    }
+    return codes, nil
+}
+
+func (s *GormStore) UseRecoveryCode(ctx context.Context, tenantID, userID, id string) (bool, error) {
+    var used int64
+    err := tenancy.Transaction(ctx, s.db, tenantID, func(tx *gorm.DB) error {
+        result := tx.Model(&RecoveryCode{}).
+            Where("tenant_id = ? AND user_id = ? AND id = ? AND used_at IS NULL", tenantID, userID, id).
+            Update("used_at", time.Now())
+        used = result.RowsAffected
+        return result.Error
+    })
+    return used > 0, err
+}
+
+func (s *GormStore) UserName(ctx context.Context, tenantID, userID string) (string, error) {
+    var email string
+    err := tenancy.Transaction(ctx, s.db, tenantID, func(tx *gorm.DB) error {
+        return tx.Table("users").Select("email").Where("tenant_id = ? AND id = ?", tenantID, userID).Row().Scan(&email)
+    })
+    if err != nil {
+        return "", fmt.Errorf("failed to load user: %w", err)
+    }
+    return email, nil
+}
@@ -0,0 +1,237 @@
+// pkg/normalizer/batch.go
+package normalizer
+
+import (
+    "bufio"
+    "context"
+    "io"
+    "runtime"
+    "strings"
+    "sync"
+)
+
+type BatchOptions struct {
+    CountryHint string
+    Workers     int // Defaults to runtime.NumCPU()
+}
+
+// BatchResult is the outcome for a single batch input. Results are emitted
+// in input order; FirstIndex points at the first input that normalized to
+// the same number and extension (equal to Index for the first occurrence).
+type BatchResult struct {
+    Index      int              `json:"index"`
+    Input      string           `json:"input"`
+    Phone      *NormalizedPhone `json:"result,omitempty"`
+    FirstIndex int              `json:"first_index"`
+    Err        error            `json:"-"`
+    Code       string           `json:"code,omitempty"` // See ErrorCode
+    Error      string           `json:"error,omitempty"`
+}
+
+// IsDuplicate reports whether an earlier input normalized to the same number
+func (br BatchResult) IsDuplicate() bool {
+    return br.Err == nil && br.FirstIndex != br.Index
+}
+
+// UniquePhone is one distinct E.164 number and every input index that produced it
+type UniquePhone struct {
+    Phone   *NormalizedPhone `json:"phone"`
+    Indices []int            `json:"indices"`
+}
+
+// BatchSummary is a fully collected batch
+type BatchSummary struct {
+    Results []BatchResult  `json:"results"` // Every input, in order
+    Unique  []*UniquePhone `json:"unique"`  // Distinct numbers, in first-seen order
+    Failed  []BatchResult  `json:"failed"`
+}
+
+func (bo BatchOptions) workers() int {
+    if bo.Workers > 0 {
+        return bo.Workers
+    }
+    return runtime.NumCPU()
+}
+
+// NormalizeStream normalizes inputs on a bounded worker pool and emits one
+// result per input, in input order. At most a small multiple of the worker
+// count is in flight, so slow items cannot grow the reorder buffer without
+// bound. The returned channel is closed when inputs is drained or ctx ends.
+func (pn *PhoneNormalizer) NormalizeStream(ctx context.Context, inputs <-chan string, opts BatchOptions) <-chan BatchResult {
+    workers := opts.workers()
+    window := workers * 4
+
+    jobs := make(chan BatchResult, workers)
+    done := make(chan BatchResult, workers)
+    slots := make(chan struct{}, window)
+    out := make(chan BatchResult, workers)
+
+    // Dispatcher: assign indices and hand out work within the window
+    go func() {
+        defer close(jobs)
+        index := 0
+        for {
+            var input string
+            var ok bool
+            select {
+            case input, ok = <-inputs:
+                if !ok {
+                    return
+                }
+            case <-ctx.Done():
+                return
+            }
+
+            select {
+            case slots <- struct{}{}:
+            case <-ctx.Done():
+                return
+            }
+
+            select {
+            case jobs <- BatchResult{Index: index, Input: input}:
+            case <-ctx.Done():
+                return
+            }
+            index++
+        }
+    }()
+
+    // Workers
+    var wg sync.WaitGroup
+    for i := 0; i < workers; i++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            for job := range jobs {
+                job.Phone, job.Err = pn.NormalizePhone(job.Input, opts.CountryHint)
+                select {
+                case done <- job:
+                case <-ctx.Done():
+                    return
+                }
+            }
+        }()
+    }
+
+    go func() {
+        wg.Wait()
+        close(done)
+    }()
+
+    // Reorder and dedupe by E.164
+    go func() {
+        defer close(out)
+        pending := make(map[int]BatchResult, window)
+        firstSeen := make(map[string]int)
+        next := 0
+
+        for result := range done {
+            pending[result.Index] = result
+            for {
+                r, ok := pending[next]
+                if !ok {
+                    break
+                }
+                delete(pending, next)
+                next++
+
+                r.FirstIndex = r.Index
+                if r.Err != nil {
+                    r.Code = ErrorCode(r.Err)
+                    r.Error = r.Err.Error()
+                } else if first, seen := firstSeen[dedupeKey(r.Phone)]; seen {
+                    r.FirstIndex = first
+                } else {
+                    firstSeen[dedupeKey(r.Phone)] = r.Index
+                }
+
+                select {
+                case out <- r:
+                case <-ctx.Done():
+                    return
+                }
+                <-slots
+            }
+        }
+    }()
+
+    return out
+}
+
+// dedupeKey identifies a distinct dialable target. Extensions of one line
+// are different targets, and short codes only mean something within their
+// country.
+func dedupeKey(phone *NormalizedPhone) string {
+    key := phone.Dialable()
+    if phone.Type == "SHORT_CODE" || phone.Type == "EMERGENCY" {
+        key = phone.Country + ":" + key
+    }
+    return key
+}
+
+// NormalizeReader streams newline-separated numbers from r. Each line is one
+// input, so Index is the zero-based line number. The returned function
+// reports any read error once the results channel has been drained.
+func (pn *PhoneNormalizer) NormalizeReader(ctx context.Context, r io.Reader, opts BatchOptions) (<-chan BatchResult, func() error) {
+    lines := make(chan string, opts.workers())
+    var readErr error
+
+    go func() {
+        defer close(lines)
+        scanner := bufio.NewScanner(r)
+        for scanner.Scan() {
+            select {
+            case lines <- strings.TrimSpace(scanner.Text()):
+            case <-ctx.Done():
+                return
+            }
+        }
+        readErr = scanner.Err()
+    }()
+
+    return pn.NormalizeStream(ctx, lines, opts), func() error { return readErr }
+}
+
+// NormalizeBatch normalizes a slice of numbers concurrently and collects the results
+func (pn *PhoneNormalizer) NormalizeBatch(inputs []string, countryHint string) *BatchSummary {
+    ctx, cancel := context.WithCancel(context.Background())
+    defer cancel()
+
+    feed := make(chan string)
+    go func() {
+        defer close(feed)
+        for _, input := range inputs {
+            select {
+            case feed <- input:
+            case <-ctx.Done():
+                return
+            }
+        }
+    }()
+
+    return CollectBatch(pn.NormalizeStream(ctx, feed, BatchOptions{CountryHint: countryHint}))
+}
+
+// CollectBatch drains a result stream into a summary
+func CollectBatch(results <-chan BatchResult) *BatchSummary {
+    summary := &BatchSummary{}
+    unique := make(map[int]*UniquePhone)
+
+    for r := range results {
+        summary.Results = append(summary.Results, r)
+
+        switch {
+        case r.Err != nil:
+            summary.Failed = append(summary.Failed, r)
+        case r.IsDuplicate():
+            unique[r.FirstIndex].Indices = append(unique[r.FirstIndex].Indices, r.Index)
+        default:
+            u := &UniquePhone{Phone: r.Phone, Indices: []int{r.Index}}
+            unique[r.Index] = u
+            summary.Unique = append(summary.Unique, u)
+        }
+    }
+
+    return summary
+}
@@ -0,0 +1,192 @@
+// pkg/normalizer/cache.go
+package normalizer
+
+import (
+    "container/list"
+    "reflect"
+    "strings"
+    "sync"
+    "sync/atomic"
+    "time"
+)
+
+// NormalizationCache is a bounded LRU cache of NormalizePhone results with
+// a per-entry TTL. It is safe for concurrent use and meant to be shared by
+// every normalizer in a process. Entries computed under one numbering plan
+// version are dropped as soon as a different version is seen.
+type NormalizationCache struct {
+    capacity int
+    ttl      time.Duration
+
+    mu       sync.Mutex
+    entries  map[cacheKey]*list.Element
+    order    *list.List // Front is most recently used
+    version  string
+    inflight map[cacheKey]*cacheCall
+
+    hits          atomic.Uint64
+    misses        atomic.Uint64
+    evictions     atomic.Uint64
+    invalidations atomic.Uint64
+}
+
+// CacheStats is a snapshot of cache counters
+type CacheStats struct {
+    Hits          uint64 `json:"hits"`
+    Misses        uint64 `json:"misses"`
+    Evictions     uint64 `json:"evictions"`     // Entries pushed out by the size bound
+    Invalidations uint64 `json:"invalidations"` // Full flushes on plan version change
+    Size          int    `json:"size"`
+}
+
+// Everything that changes a result
+type cacheKey struct {
+    input         string
+    countryHint   string
+    defaultRegion string
+    tenantRegion  string
+    strict        bool
+    detector      interface{} // See detectorIdentity
+}
+
+type cacheEntry struct {
+    key     cacheKey
+    phone   *NormalizedPhone
+    err     error
+    expires time.Time
+}
+
+// Concurrent misses on one key share a single computation
+type cacheCall struct {
+    done  chan struct{}
+    phone *NormalizedPhone
+    err   error
+}
+
+var (
+    defaultCacheOnce sync.Once
+    defaultCache     *NormalizationCache
+)
+
+// DefaultNormalizationCache returns the process-wide cache
+func DefaultNormalizationCache() *NormalizationCache {
+    defaultCacheOnce.Do(func() {
+        defaultCache = NewNormalizationCache(100000, time.Hour)
+    })
+    return defaultCache
+}
+
+func NewNormalizationCache(capacity int, ttl time.Duration) *NormalizationCache {
+    return &NormalizationCache{
+        capacity: capacity,
+        ttl:      ttl,
+        entries:  make(map[cacheKey]*list.Element),
+        order:    list.New(),
+        inflight: make(map[cacheKey]*cacheCall),
+    }
+}
+
+// Stats returns the current counters
+func (nc *NormalizationCache) Stats() CacheStats {
+    nc.mu.Lock()
+    size := nc.order.Len()
+    nc.mu.Unlock()
+
+    return CacheStats{
+        Hits:          nc.hits.Load(),
+        Misses:        nc.misses.Load(),
+        Evictions:     nc.evictions.Load(),
+        Invalidations: nc.invalidations.Load(),
+        Size:          size,
+    }
+}
+
+// get returns the cached result for key, computing and storing it on a miss.
+// Failures are cached too, so repeated bad inputs are as cheap as good ones.
+func (nc *NormalizationCache) get(key cacheKey, version string, compute func() (*NormalizedPhone, error)) (*NormalizedPhone, error) {
+    nc.mu.Lock()
+    if version != nc.version {
+        if nc.order.Len() > 0 {
+            nc.invalidations.Add(1)
+        }
+        nc.entries = make(map[cacheKey]*list.Element)
+        nc.order.Init()
+        nc.version = version
+    }
+
+    if elem, ok := nc.entries[key]; ok {
+        entry := elem.Value.(*cacheEntry)
+        if time.Now().Before(entry.expires) {
+            nc.order.MoveToFront(elem)
+            nc.mu.Unlock()
+            nc.hits.Add(1)
+            return clonePhone(entry.phone), entry.err
+        }
+        nc.remove(elem)
+    }
+
+    if call, ok := nc.inflight[key]; ok {
+        nc.mu.Unlock()
+        <-call.done
+        nc.hits.Add(1)
+        return clonePhone(call.phone), call.err
+    }
+
+    call := &cacheCall{done: make(chan struct{})}
+    nc.inflight[key] = call
+    nc.mu.Unlock()
+    nc.misses.Add(1)
+
+    call.phone, call.err = compute()
+
+    nc.mu.Lock()
+    delete(nc.inflight, key)
+    // A reload during compute makes this result stale; hand it back uncached
+    if version == nc.version {
+        nc.store(&cacheEntry{key: key, phone: call.phone, err: call.err, expires: time.Now().Add(nc.ttl)})
+    }
+    nc.mu.Unlock()
+    close(call.done)
+
+    return clonePhone(call.phone), call.err
+}
+
+// store adds an entry, evicting the least recently used ones beyond capacity.
+// Callers hold nc.mu.
+func (nc *NormalizationCache) store(entry *cacheEntry) {
+    nc.entries[entry.key] = nc.order.PushFront(entry)
+    for nc.order.Len() > nc.capacity {
+        nc.remove(nc.order.Back())
+        nc.evictions.Add(1)
+    }
+}
+
+func (nc *NormalizationCache) remove(elem *list.Element) {
+    nc.order.Remove(elem)
+    delete(nc.entries, elem.Value.(*cacheEntry).key)
+}
+
+// detectorIdentity is what a cache key records of a country detector.
+// Prefix detectors are identified by their regions, so normalizers built
+// alike share entries; other detectors by their value. Detectors whose
+// type cannot be compared have no identity and their results are not
+// cached.
+func detectorIdentity(detector CountryDetector) (interface{}, bool) {
+    if d, ok := detector.(*PrefixCountryDetector); ok {
+        return "prefix:" + strings.Join(d.regions, ","), true
+    }
+    if detector != nil && !reflect.TypeOf(detector).Comparable() {
+        return nil, false
+    }
+    return detector, true
+}
+
+// Callers may modify what they get back; the cached copy must not change
+func clonePhone(phone *NormalizedPhone) *NormalizedPhone {
+    if phone == nil {
+        return nil
+    }
+    clone := *phone
+    clone.Timezones = append([]string(nil), phone.Timezones...)
+    return &clone
+}
@@ -0,0 +1,39 @@
+// pkg/normalizer/cache_metrics.go
+package normalizer
+
+import (
+    "github.com/prometheus/client_golang/prometheus"
+)
+
+// RegisterCacheMetrics exposes the cache counters to Prometheus
+func RegisterCacheMetrics(reg prometheus.Registerer, cache *NormalizationCache) error {
+    collectors := []prometheus.Collector{
+        prometheus.NewCounterFunc(prometheus.CounterOpts{
+            Name: "phone_normalizer_cache_hits_total",
+            Help: "Normalization results served from cache.",
+        }, func() float64 { return float64(cache.hits.Load()) }),
+        prometheus.NewCounterFunc(prometheus.CounterOpts{
+            Name: "phone_normalizer_cache_misses_total",
+            Help: "Normalizations computed because no cached result existed.",
+        }, func() float64 { return float64(cache.misses.Load()) }),
+        prometheus.NewCounterFunc(prometheus.CounterOpts{
+            Name: "phone_normalizer_cache_evictions_total",
+            Help: "Cached results evicted to stay within the size bound.",
+        }, func() float64 { return float64(cache.evictions.Load()) }),
+        prometheus.NewCounterFunc(prometheus.CounterOpts{
+            Name: "phone_normalizer_cache_invalidations_total",
+            Help: "Full cache flushes caused by a numbering plan version change.",
+        }, func() float64 { return float64(cache.invalidations.Load()) }),
+        prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+            Name: "phone_normalizer_cache_entries",
+            Help: "Results currently cached.",
+        }, func() float64 { return float64(cache.Stats().Size) }),
+    }
+
+    for _, collector := range collectors {
+        if err := reg.Register(collector); err != nil {
+            return err
+        }
+    }
+    return nil
+}
@@ -0,0 +1,170 @@
+// pkg/normalizer/country_detector.go
+package normalizer
+
+import (
+    "fmt"
+    "sort"
+    "strings"
+
+    "github.com/nyaruka/phonenumbers"
+)
+
+// CountryCandidate is one region an input may belong to
+type CountryCandidate struct {
+    Region     string   `json:"region"`     // ISO 3166-1 alpha-2
+    Confidence float64  `json:"confidence"` // 0..1
+    Reasons    []string `json:"reasons"`
+}
+
+// DetectionContext carries caller defaults that influence detection
+type DetectionContext struct {
+    TenantDefault  string // Tenant's configured home region, if any
+    ServiceDefault string // The normalizer's default region
+}
+
+// CountryDetector ranks candidate regions for an input that came without a
+// country hint. Candidates are returned best first.
+type CountryDetector interface {
+    DetectCountry(cleaned string, dctx DetectionContext) []CountryCandidate
+}
+
+// CountryDetection records how NormalizePhone picked the region it parsed with
+type CountryDetection struct {
+    Source     string             `json:"source"` // "hint" or "detected"
+    Region     string             `json:"region"`
+    Confidence float64            `json:"confidence"`
+    Reasons    []string           `json:"reasons"`
+    Candidates []CountryCandidate `json:"candidates,omitempty"`
+}
+
+const (
+    // Minimum lead the best candidate needs over the runner-up
+    ambiguityMargin = 0.15
+    // Candidates below this are not considered competitive
+    minCandidateConfidence = 0.3
+)
+
+// PrefixCountryDetector is the default CountryDetector. International
+// inputs are resolved from their calling code; national inputs are scored
+// against the tenant default, the service default and any extra regions by
+// trunk prefix, length and range validity.
+type PrefixCountryDetector struct {
+    regions []string
+}
+
+func NewPrefixCountryDetector(extraRegions ...string) *PrefixCountryDetector {
+    return &PrefixCountryDetector{regions: extraRegions}
+}
+
+func (d *PrefixCountryDetector) DetectCountry(cleaned string, dctx DetectionContext) []CountryCandidate {
+    if strings.HasPrefix(cleaned, "+") {
+        return d.detectInternational(cleaned, "calling code")
+    }
+    if strings.HasPrefix(cleaned, "00") {
+        return d.detectInternational("+"+cleaned[2:], "international prefix 00")
+    }
+
+    var candidates []CountryCandidate
+    for _, region := range d.candidateRegions(dctx) {
+        if candidate, ok := d.scoreNational(cleaned, region, dctx); ok {
+            candidates = append(candidates, candidate)
+        }
+    }
+
+    sort.SliceStable(candidates, func(i, j int) bool {
+        return candidates[i].Confidence > candidates[j].Confidence
+    })
+
+    return candidates
+}
+
+func (d *PrefixCountryDetector) detectInternational(number, via string) []CountryCandidate {
+    num, err := phonenumbers.Parse(number, "")
+    if err != nil {
+        return nil
+    }
+
+    region := phonenumbers.GetRegionCodeForNumber(num)
+    confidence := 0.95
+    if region == "" || region == "ZZ" {
+        // Shared calling code and no valid range: use the code's main region
+        region = phonenumbers.GetRegionCodeForCountryCode(int(num.GetCountryCode()))
+        confidence = 0.7
+    }
+    if region == "ZZ" {
+        return nil
+    }
+
+    return []CountryCandidate{{
+        Region:     region,
+        Confidence: confidence,
+        Reasons:    []string{fmt.Sprintf("%s +%d", via, num.GetCountryCode())},
+    }}
+}
+
+func (d *PrefixCountryDetector) candidateRegions(dctx DetectionContext) []string {
+    seen := make(map[string]bool)
+    var regions []string
+    for _, region := range append([]string{dctx.TenantDefault, dctx.ServiceDefault}, d.regions...) {
+        region = strings.ToUpper(region)
+        if region != "" && !seen[region] {
+            seen[region] = true
+            regions = append(regions, region)
+        }
+    }
+    return regions
+}
+
+func (d *PrefixCountryDetector) scoreNational(cleaned, region string, dctx DetectionContext) (CountryCandidate, bool) {
+    num, err := phonenumbers.Parse(cleaned, region)
+    if err != nil {
+        return CountryCandidate{}, false
+    }
+
+    candidate := CountryCandidate{Region: region}
+    score := 0.0
+
+    switch {
+    case phonenumbers.IsValidNumberForRegion(num, region):
+        score += 0.6
+        candidate.Reasons = append(candidate.Reasons, "valid number range for "+region)
+    case phonenumbers.IsPossibleNumber(num):
+        score += 0.3
+        candidate.Reasons = append(candidate.Reasons, "possible length for "+region)
+    }
+
+    if ndd := phonenumbers.GetNddPrefixForRegion(region, true); ndd != "" && strings.HasPrefix(cleaned, ndd) {
+        score += 0.15
+        candidate.Reasons = append(candidate.Reasons, "trunk prefix "+ndd)
+    }
+
+    if strings.EqualFold(region, dctx.TenantDefault) {
+        score += 0.2
+        candidate.Reasons = append(candidate.Reasons, "tenant default region")
+    }
+    if strings.EqualFold(region, dctx.ServiceDefault) {
+        score += 0.1
+        candidate.Reasons = append(candidate.Reasons, "service default region")
+    }
+
+    if score > 1 {
+        score = 1
+    }
+    candidate.Confidence = score
+
+    return candidate, true
+}
+
+// chooseCountry picks the winning candidate, or reports ambiguity when the
+// runner-up is too close to call
+func chooseCountry(candidates []CountryCandidate) (CountryCandidate, bool) {
+    if len(candidates) == 0 {
+        return CountryCandidate{}, false
+    }
+    if len(candidates) > 1 &&
+        candidates[1].Confidence >= minCandidateConfidence &&
+        candidates[0].Confidence-candidates[1].Confidence < ambiguityMargin {
+        return CountryCandidate{}, false
+    }
+    return candidates[0], true
+}
@@ -0,0 +1,91 @@
+// pkg/normalizer/errors.go
+package normalizer
+
+import (
+    "errors"
+    "fmt"
+
+    "github.com/nyaruka/phonenumbers"
+)
+
+// Normalization failure reasons. Match them with errors.Is; every error
+// returned by NormalizePhone wraps exactly one of the first five, and
+// fallback results additionally wrap ErrFallbackUsed.
+var (
+    ErrTooShort           = errors.New("too short to be a phone number")
+    ErrTooLong            = errors.New("too long to be a phone number")
+    ErrInvalidCountryCode = errors.New("invalid country code")
+    ErrNotANumber         = errors.New("not a phone number")
+    ErrAmbiguousCountry   = errors.New("country could not be determined unambiguously")
+    ErrFallbackUsed       = errors.New("normalized by fallback rules, result is unverified")
+)
+
+// NormalizationError describes why an input could not be normalized
+type NormalizationError struct {
+    Reason     error  // One of the Err* sentinels
+    Input      string
+    Err        error  // Underlying cause, if any
+    Candidates []CountryCandidate // Competing regions, for ErrAmbiguousCountry
+}
+
+func (e *NormalizationError) Error() string {
+    if e.Err != nil {
+        return fmt.Sprintf("%s: %s", e.Reason, e.Err)
+    }
+    return e.Reason.Error()
+}
+
+func (e *NormalizationError) Unwrap() []error {
+    if e.Err != nil {
+        return []error{e.Reason, e.Err}
+    }
+    return []error{e.Reason}
+}
+
+// Stable, machine-readable codes for API responses
+const (
+    CodeTooShort           = "TOO_SHORT"
+    CodeTooLong            = "TOO_LONG"
+    CodeInvalidCountryCode = "INVALID_COUNTRY_CODE"
+    CodeNotANumber         = "NOT_A_NUMBER"
+    CodeAmbiguousCountry   = "AMBIGUOUS_COUNTRY"
+    CodeFallbackUsed       = "FALLBACK_USED"
+    CodeUnknown            = "NORMALIZATION_FAILED"
+)
+
+// ErrorCode maps a normalization error to its stable code
+func ErrorCode(err error) string {
+    switch {
+    case err == nil:
+        return ""
+    case errors.Is(err, ErrFallbackUsed):
+        return CodeFallbackUsed
+    case errors.Is(err, ErrAmbiguousCountry):
+        return CodeAmbiguousCountry
+    case errors.Is(err, ErrInvalidCountryCode):
+        return CodeInvalidCountryCode
+    case errors.Is(err, ErrTooShort):
+        return CodeTooShort
+    case errors.Is(err, ErrTooLong):
+        return CodeTooLong
+    case errors.Is(err, ErrNotANumber):
+        return CodeNotANumber
+    default:
+        return CodeUnknown
+    }
+}
+
+// parseError classifies a libphonenumber parse failure
+func parseError(input string, err error) *NormalizationError {
+    reason := ErrNotANumber
+    switch {
+    case errors.Is(err, phonenumbers.ErrInvalidCountryCode):
+        reason = ErrInvalidCountryCode
+    case errors.Is(err, phonenumbers.ErrTooShortNSN), errors.Is(err, phonenumbers.ErrTooShortAfterIDD):
+        reason = ErrTooShort
+    case errors.Is(err, phonenumbers.ErrNumTooLong):
+        reason = ErrTooLong
+    }
+
+    return &NormalizationError{Reason: reason, Input: input, Err: err}
+}
@@ -0,0 +1,251 @@
+// pkg/normalizer/extractor.go
+package normalizer
+
+import (
+    "regexp"
+    "strings"
+    "unicode"
+    "unicode/utf8"
+)
+
+// PhoneSpan is a phone number found in free text. Start and End are byte
+// offsets into the original text, so text[Start:End] == Raw.
+type PhoneSpan struct {
+    Start     int              `json:"start"`
+    End       int              `json:"end"`
+    Raw       string           `json:"raw"`
+    Extension string           `json:"extension,omitempty"`
+    Phone     *NormalizedPhone `json:"phone"`
+}
+
+// PhoneExtractor finds phone numbers in arbitrary UTF-8 text: any decimal
+// digit script, bidi control marks, common separators, tel: URIs and
+// extensions ("ext", "x", "#", "داخلی", ...).
+type PhoneExtractor struct {
+    normalizer *PhoneNormalizer
+    minDigits  int
+    maxDigits  int
+}
+
+func NewPhoneExtractor(normalizer *PhoneNormalizer) *PhoneExtractor {
+    return &PhoneExtractor{
+        normalizer: normalizer,
+        minDigits:  7,
+        maxDigits:  17,
+    }
+}
+
+// Extension markers, matched case-insensitively after a number
+var extensionKeywords = []string{
+    "extension", "ext.", "ext", "x", "#",
+    "داخلی", "داخلي", // Persian / Arabic "internal"
+    "تحويلة", // Arabic "extension"
+}
+
+// Dates look like numbers; skip the common shapes
+var dateLike = regexp.MustCompile(`^(\d{4}[-/.]\d{1,2}[-/.]\d{1,2}|\d{1,2}[-/.]\d{1,2}[-/.]\d{4})$`)
+
+// Extract returns every number in text that normalizes to a valid phone
+// number, in order of appearance. Free text is full of order numbers and
+// IDs, so merely possible numbers are not reported.
+func (pe *PhoneExtractor) Extract(text string, countryHint string) []PhoneSpan {
+    var spans []PhoneSpan
+
+    for i := 0; i < len(text); {
+        r, size := utf8.DecodeRuneInString(text[i:])
+
+        start, numberStart := i, i
+        if hasPrefixFold(text[i:], "tel:") {
+            numberStart = i + len("tel:")
+        } else if !isNumberStart(r) || precededByWordChar(text, i) {
+            i += size
+            continue
+        }
+
+        span, next, ok := pe.scanNumber(text, start, numberStart, countryHint)
+        if ok {
+            spans = append(spans, span)
+        }
+        if next <= i {
+            next = i + size
+        }
+        i = next
+    }
+
+    return spans
+}
+
+// scanNumber reads one candidate starting at numberStart and returns the
+// span (including any "tel:" prefix at start) and the offset to resume at
+func (pe *PhoneExtractor) scanNumber(text string, start, numberStart int, countryHint string) (PhoneSpan, int, bool) {
+    var digits strings.Builder
+    count := 0
+    end := numberStart
+
+    j := numberStart
+scan:
+    for j < len(text) {
+        r, size := utf8.DecodeRuneInString(text[j:])
+        switch {
+        case (r == '+' || r == '\uff0b') && count == 0:
+            digits.WriteByte('+')
+        case isDigitRune(r):
+            d, _ := digitValue(r)
+            digits.WriteRune(d)
+            count++
+            end = j + size
+        case isSeparator(r) || isBidiMark(r):
+        default:
+            break scan
+        }
+        j += size
+    }
+
+    if count < pe.minDigits || count > pe.maxDigits {
+        return PhoneSpan{}, end, false
+    }
+
+    // A number glued to letters is an identifier, not a phone number
+    if r, _ := utf8.DecodeRuneInString(text[end:]); unicode.IsLetter(r) && !startsExtension(text[end:]) {
+        return PhoneSpan{}, end, false
+    }
+
+    raw := text[start:end]
+    if dateLike.MatchString(stripBidiMarks(pe.normalizer.convertDigits(text[numberStart:end]))) {
+        return PhoneSpan{}, end, false
+    }
+
+    extension, extEnd := pe.scanExtension(text, end, start != numberStart)
+    if extension != "" {
+        end = extEnd
+        raw = text[start:end]
+    }
+
+    phone, err := pe.normalizer.NormalizePhone(digits.String(), countryHint)
+    if err != nil || !phone.IsValid {
+        return PhoneSpan{}, end, false
+    }
+    phone.Extension = extension
+
+    return PhoneSpan{
+        Start:     start,
+        End:       end,
+        Raw:       raw,
+        Extension: extension,
+        Phone:     phone,
+    }, end, true
+}
+
+// scanExtension looks for an extension right after a number. tel: URIs
+// carry it as an ";ext=" parameter instead of a keyword.
+func (pe *PhoneExtractor) scanExtension(text string, from int, telURI bool) (string, int) {
+    j := from
+    if telURI {
+        if !hasPrefixFold(text[j:], ";ext=") {
+            return "", from
+        }
+        j += len(";ext=")
+    } else {
+        j = skipRunes(text, j, func(r rune) bool { return r == ' ' || r == ',' || isBidiMark(r) })
+        matched := false
+        for _, keyword := range extensionKeywords {
+            if hasPrefixFold(text[j:], keyword) {
+                j += len(keyword)
+                matched = true
+                break
+            }
+        }
+        if !matched {
+            return "", from
+        }
+        j = skipRunes(text, j, func(r rune) bool { return r == ' ' || r == '.' || r == ':' || isBidiMark(r) })
+    }
+
+    var ext strings.Builder
+    for j < len(text) && ext.Len() < 7 {
+        r, size := utf8.DecodeRuneInString(text[j:])
+        d, ok := digitValue(r)
+        if !ok {
+            break
+        }
+        ext.WriteRune(d)
+        j += size
+    }
+
+    if ext.Len() == 0 {
+        return "", from
+    }
+    return ext.String(), j
+}
+
+func isNumberStart(r rune) bool {
+    return r == '+' || r == '\uff0b' || r == '(' || r == '\uff08' || isDigitRune(r)
+}
+
+func isDigitRune(r rune) bool {
+    _, ok := digitValue(r)
+    return ok
+}
+
+// Characters that may appear between digit groups. Newlines are excluded
+// so numbers on consecutive lines are never joined.
+func isSeparator(r rune) bool {
+    switch r {
+    case ' ', '\u00a0', '\u3000', '-', '.', '(', ')', '/',
+        '\u2010', '\u2011', '\u2012', '\u2013', '\u2014', '\u2212', // Dashes and minus
+        '\uff0d', '\uff0e', '\uff08', '\uff09', '\uff0f': // Full-width forms
+        return true
+    }
+    return false
+}
+
+// Bidi controls that RTL editors sprinkle around numbers
+func isBidiMark(r rune) bool {
+    switch {
+    case r == '\u200e', r == '\u200f', r == '\u061c': // LRM, RLM, ALM
+        return true
+    case r >= '\u202a' && r <= '\u202e': // Embeddings and overrides
+        return true
+    case r >= '\u2066' && r <= '\u2069': // Isolates
+        return true
+    }
+    return false
+}
+
+func stripBidiMarks(s string) string {
+    return strings.Map(func(r rune) rune {
+        if isBidiMark(r) {
+            return -1
+        }
+        return r
+    }, s)
+}
+
+func precededByWordChar(text string, i int) bool {
+    r, _ := utf8.DecodeLastRuneInString(text[:i])
+    return unicode.IsLetter(r) || isDigitRune(r)
+}
+
+func startsExtension(text string) bool {
+    for _, keyword := range extensionKeywords {
+        if hasPrefixFold(text, keyword) {
+            return true
+        }
+    }
+    return false
+}
+
+func hasPrefixFold(s, prefix string) bool {
+    return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
+
+func skipRunes(text string, j int, skip func(rune) bool) int {
+    for j < len(text) {
+        r, size := utf8.DecodeRuneInString(text[j:])
+        if !skip(r) {
+            break
+        }
+        j += size
+    }
+    return j
+}
@@ -6,113 +6,72 @@ import (
 )
 
 type GeoLocator struct {
-    countryMapping map[int32]string
-    regionMapping  map[string]string
+    plans *PlanStore
 }
 
 func NewGeoLocator() *GeoLocator {
-    return &GeoLocator{
-        countryMapping: map[int32]string{
-            98: "IR", // Iran
-            1:  "US", // United States
-            44: "GB", // United Kingdom
-            49: "DE", // Germany
-            33: "FR", // France
-            // Add all country codes...
-        },
-        regionMapping: loadRegionMapping(),
-    }
+    return NewGeoLocatorWithPlans(DefaultPlanStore())
 }
 
-func (gl *GeoLocator) GetRegion(num *phonenumbers.PhoneNumber) string {
-    countryCode := *num.CountryCode
-    
-    // For Iran, detect specific regions based on area codes
-    if countryCode == 98 {
-        return gl.getIranianRegion(num)
+func NewGeoLocatorWithPlans(plans *PlanStore) *GeoLocator {
+    return &GeoLocator{
+        plans: plans,
     }
-    
-    // Generic region detection for other countries
-    return gl.countryMapping[countryCode]
 }
 
-func (gl *GeoLocator) GetTimezone(num *phonenumbers.PhoneNumber) string {
+func (gl *GeoLocator) GetRegion(num *phonenumbers.PhoneNumber) PlanAnswer {
     countryCode := *num.CountryCode
+    nationalNumber := phonenumbers.GetNationalSignificantNumber(num)
     
-    // Map country codes to timezones
-    timezoneMap := map[int32]string{
-        98: "Asia/Tehran",    // Iran
-        1:  "America/New_York", // US
-        44: "Europe/London",  // UK
-        49: "Europe/Berlin",  // Germany
-        33: "Europe/Paris",   // France
-    }
-    
-    if tz, exists := timezoneMap[countryCode]; exists {
-        return tz
+    // Mobile ranges resolve to their home provinces (or "Nationwide"),
+    // fixed lines to the province owning the area code
+    r, version, ok := gl.plans.Lookup(countryCode, nationalNumber)
+    if ok && r.Region != "" {
+        return PlanAnswer{Value: r.Region, Version: version}
     }
     
-    return "Unknown"
+    // Fall back to the ISO region of the number
+    return PlanAnswer{Value: phonenumbers.GetRegionCodeForNumber(num), Version: version}
 }
 
-func (gl *GeoLocator) getIranianRegion(num *phonenumbers.PhoneNumber) string {
+// GetTimezones returns every candidate IANA zone for the number, primary
+// first. Plan ranges take precedence; otherwise libphonenumber's per-range
+// timezone metadata is used, which covers all ITU country codes (US area
+// codes, Russian ranges, ...).
+func (gl *GeoLocator) GetTimezones(num *phonenumbers.PhoneNumber) []string {
+    countryCode := *num.CountryCode
     nationalNumber := phonenumbers.GetNationalSignificantNumber(num)
     
-    if len(nationalNumber) < 3 {
-        return "Unknown"
+    if zones, _, ok := gl.plans.Timezones(countryCode, nationalNumber); ok {
+        return zones
     }
     
-    areaCode := nationalNumber[:3]
-    
-    // Iranian mobile operator regions
-    iranRegionMap := map[string]string{
-        "091": "Tehran",      // MCI
-        "0910": "Tehran",     // MCI
-        "0911": "Tehran",     // MCI
-        "0912": "Tehran",     // MCI
-        "0913": "Tehran",     // MCI
-        "0914": "Tehran",     // MCI
-        "0915": "Tehran",     // MCI
-        "0916": "Tehran",     // MCI
-        "0917": "Tehran",     // MCI
-        "0918": "Tehran",     // MCI
-        "0919": "Tehran",     // MCI
-        "093": "Tehran",      // MTN Irancell
-        "0930": "Tehran",     // MTN Irancell
-        "0931": "Tehran",     // MTN Irancell
-        "0932": "Tehran",     // MTN Irancell
-        "0933": "Tehran",     // MTN Irancell
-        "0934": "Tehran",     // MTN Irancell
-        "0935": "Tehran",     // MTN Irancell
-        "0936": "Tehran",     // MTN Irancell
-        "0937": "Tehran",     // MTN Irancell
-        "0938": "Tehran",     // MTN Irancell
-        "0939": "Tehran",     // MTN Irancell
-        "092": "Tehran",      // Rightel
-        "0920": "Tehran",     // Rightel
-        "0921": "Tehran",     // Rightel
-        "0922": "Tehran",     // Rightel
-        "099": "Tehran",      // Shatel
-        "0990": "Tehran",     // Shatel
-        "0991": "Tehran",     // Shatel
-        // Add more specific regional mappings
+    zones, err := phonenumbers.GetTimezonesForNumber(num)
+    if err != nil {
+        return nil
     }
     
-    if region, exists := iranRegionMap[areaCode]; exists {
-        return region
+    var candidates []string
+    for _, zone := range zones {
+        if zone != unknownTimezone {
+            candidates = append(candidates, zone)
+        }
     }
     
-    return "Iran"
+    return candidates
 }
 
-func loadRegionMapping() map[string]string {
-    // Load from configuration file
-    return map[string]string{
-        "091": "MCI",
-        "093": "MTN Irancell", 
-        "092": "Rightel",
-        "099": "Shatel",
-        "090": "Irancell",
-        "094": "Irancell",
+// GetTimezone returns the primary zone, or "Unknown"
+func (gl *GeoLocator) GetTimezone(num *phonenumbers.PhoneNumber) string {
+    return primaryTimezone(gl.GetTimezones(num))
+}
+
+// libphonenumber's placeholder for ranges without timezone data
+const unknownTimezone = "Etc/Unknown"
+
+func primaryTimezone(zones []string) string {
+    if len(zones) == 0 {
+        return "Unknown"
     }
+    return zones[0]
 }
@@ -0,0 +1,203 @@
+// pkg/normalizer/numbering_plan.go
+package normalizer
+
+import (
+    "embed"
+    "encoding/json"
+    "fmt"
+    "io/fs"
+    "strings"
+
+    "github.com/nyaruka/phonenumbers"
+)
+
+//go:embed data/numbering_plan_*.json
+var embeddedPlans embed.FS
+
+// PlanTables is the on-disk format read by PlanStore.LoadFile: a set of
+// numbering plans, at most one per country calling code.
+type PlanTables struct {
+    Version string          `json:"version"`
+    Plans   []NumberingPlan `json:"plans"`
+}
+
+// NumberingPlan is a versioned set of number ranges for a single country
+// calling code. Prefixes are national significant number prefixes, i.e.
+// without the trunk "0" ("912", not "0912").
+type NumberingPlan struct {
+    Version     string        `json:"version"`
+    CountryCode int32         `json:"country_code"`
+    Timezones   []string      `json:"timezones,omitempty"` // Default for ranges without their own
+    Ranges      []NumberRange `json:"ranges"`
+}
+
+type NumberRange struct {
+    Prefix    string   `json:"prefix"`
+    Type      string   `json:"type"`                // MOBILE, FIXED_LINE, MVNO
+    Carrier   string   `json:"carrier,omitempty"`
+    Region    string   `json:"region,omitempty"`
+    Timezones []string `json:"timezones,omitempty"` // IANA zones, primary first
+}
+
+// PlanAnswer is a carrier or region answer together with the version of
+// the numbering plan it came from.
+type PlanAnswer struct {
+    Value   string `json:"value"`
+    Version string `json:"version,omitempty"`
+}
+
+// planIndex is the compiled, read-only form of PlanTables
+type planIndex struct {
+    version string
+    plans   map[int32]*compiledPlan
+}
+
+type compiledPlan struct {
+    version   string
+    timezones []string
+    trie      *prefixTrie
+}
+
+// ParsePlanTables decodes a numbering plan tables document
+func ParsePlanTables(data []byte) (*PlanTables, error) {
+    var tables PlanTables
+    if err := json.Unmarshal(data, &tables); err != nil {
+        return nil, fmt.Errorf("failed to decode numbering plan tables: %w", err)
+    }
+    return &tables, nil
+}
+
+// embeddedPlanTables assembles the per-country plans shipped with the binary
+func embeddedPlanTables() (*PlanTables, error) {
+    files, err := fs.Glob(embeddedPlans, "data/numbering_plan_*.json")
+    if err != nil {
+        return nil, err
+    }
+
+    tables := &PlanTables{Version: "embedded"}
+    for _, file := range files {
+        data, err := embeddedPlans.ReadFile(file)
+        if err != nil {
+            return nil, err
+        }
+
+        var plan NumberingPlan
+        if err := json.Unmarshal(data, &plan); err != nil {
+            return nil, fmt.Errorf("failed to decode %s: %w", file, err)
+        }
+        tables.Plans = append(tables.Plans, plan)
+    }
+
+    return tables, nil
+}
+
+// buildPlanIndex validates tables and compiles one trie per country code.
+// Duplicate prefixes are ambiguous and rejected; a nested range that repeats
+// its enclosing range's data is treated as an overlap mistake.
+func buildPlanIndex(tables *PlanTables) (*planIndex, error) {
+    index := &planIndex{
+        version: tables.Version,
+        plans:   make(map[int32]*compiledPlan, len(tables.Plans)),
+    }
+
+    for i := range tables.Plans {
+        plan := &tables.Plans[i]
+
+        if plan.Version == "" {
+            return nil, fmt.Errorf("numbering plan for +%d has no version", plan.CountryCode)
+        }
+        if phonenumbers.GetRegionCodeForCountryCode(int(plan.CountryCode)) == "ZZ" {
+            return nil, fmt.Errorf("numbering plan %s: unknown country code +%d", plan.Version, plan.CountryCode)
+        }
+        if _, exists := index.plans[plan.CountryCode]; exists {
+            return nil, fmt.Errorf("numbering plan %s: country code +%d defined more than once", plan.Version, plan.CountryCode)
+        }
+
+        if err := validateTimezones(plan.Timezones); err != nil {
+            return nil, fmt.Errorf("numbering plan %s: %w", plan.Version, err)
+        }
+
+        trie := newPrefixTrie()
+        for j := range plan.Ranges {
+            r := &plan.Ranges[j]
+            if r.Prefix == "" || !isDigits(r.Prefix) {
+                return nil, fmt.Errorf("numbering plan %s: invalid prefix %q", plan.Version, r.Prefix)
+            }
+            if err := validateTimezones(r.Timezones); err != nil {
+                return nil, fmt.Errorf("numbering plan %s: range %q: %w", plan.Version, r.Prefix, err)
+            }
+            if existing := trie.insert(r.Prefix, r); existing != nil {
+                return nil, fmt.Errorf("numbering plan %s: ambiguous prefix %q defined more than once", plan.Version, r.Prefix)
+            }
+        }
+
+        var overlapErr error
+        trie.walk(func(r, parent *NumberRange) {
+            if overlapErr == nil && parent != nil && sameRangeData(r, parent) {
+                overlapErr = fmt.Errorf("numbering plan %s: range %q overlaps %q with identical data", plan.Version, r.Prefix, parent.Prefix)
+            }
+        })
+        if overlapErr != nil {
+            return nil, overlapErr
+        }
+
+        index.plans[plan.CountryCode] = &compiledPlan{
+            version:   plan.Version,
+            timezones: plan.Timezones,
+            trie:      trie,
+        }
+    }
+
+    return index, nil
+}
+
+func (pi *planIndex) lookup(countryCode int32, nationalNumber string) (*NumberRange, string, bool) {
+    plan, exists := pi.plans[countryCode]
+    if !exists {
+        return nil, "", false
+    }
+
+    r := plan.trie.longestMatch(nationalNumber)
+    return r, plan.version, r != nil
+}
+
+// timezones returns the zones of the most specific matching range that
+// declares any, falling back to the plan default
+func (pi *planIndex) timezones(countryCode int32, nationalNumber string) ([]string, string, bool) {
+    plan, exists := pi.plans[countryCode]
+    if !exists {
+        return nil, "", false
+    }
+
+    matches := plan.trie.matches(nationalNumber)
+    for i := len(matches) - 1; i >= 0; i-- {
+        if len(matches[i].Timezones) > 0 {
+            return matches[i].Timezones, plan.version, true
+        }
+    }
+
+    return plan.timezones, plan.version, len(plan.timezones) > 0
+}
+
+func sameRangeData(a, b *NumberRange) bool {
+    return a.Type == b.Type && a.Carrier == b.Carrier && a.Region == b.Region &&
+        strings.Join(a.Timezones, ",") == strings.Join(b.Timezones, ",")
+}
+
+func validateTimezones(zones []string) error {
+    for _, zone := range zones {
+        if zone == "" || strings.ContainsAny(zone, " \t") {
+            return fmt.Errorf("invalid timezone %q", zone)
+        }
+    }
+    return nil
+}
+
+func isDigits(s string) bool {
+    for _, c := range s {
+        if c < '0' || c > '9' {
+            return false
+        }
+    }
+    return true
+}
@@ -2,18 +2,21 @@
 package normalizer
 
 import (
-    "fmt"
     "regexp"
     "strings"
+    "unicode"
 
     "github.com/nyaruka/phonenumbers"
 )
 
 type PhoneNormalizer struct {
     defaultRegion string
+    tenantRegion  string
     strictMode    bool
     geoLocator    *GeoLocator
     carrierDetector *CarrierDetector
+    countryDetector CountryDetector
+    cache           *NormalizationCache
 }
 
 type NormalizedPhone struct {
@@ -27,8 +30,21 @@ type NormalizedPhone struct {
     Carrier        string `json:"carrier"`
     IsValid        bool   `json:"is_valid"`
     IsPossible     bool   `json:"is_possible"`
-    Type           string `json:"type"`           // MOBILE, FIXED_LINE, etc.
-    Timezone       string `json:"timezone"`
+    Type           string `json:"type"`           // MOBILE, FIXED_LINE, EMERGENCY, SHORT_CODE, etc.
+    Extension      string `json:"extension,omitempty"`
+    Timezone       string `json:"timezone"`       // Primary IANA zone
+    Timezones      []string `json:"timezones,omitempty"` // All candidate zones, primary first
+    PlanVersion    string `json:"plan_version,omitempty"` // Numbering plan behind Region/Carrier
+    Detection      *CountryDetection `json:"detection,omitempty"` // How Country was chosen
+}
+
+// Dialable is the E.164 number with any extension appended as an RFC 3966
+// ";ext=" parameter, which NormalizePhone parses back
+func (p *NormalizedPhone) Dialable() string {
+    if p.Extension == "" {
+        return p.Normalized
+    }
+    return p.Normalized + ";ext=" + p.Extension
 }
 
 func NewPhoneNormalizer(defaultRegion string) *PhoneNormalizer {
@@ -37,31 +53,98 @@ func NewPhoneNormalizer(defaultRegion string) *PhoneNormalizer {
         strictMode:    true,
         geoLocator:    NewGeoLocator(),
         carrierDetector: NewCarrierDetector(),
+        countryDetector: NewPrefixCountryDetector(),
     }
 }
 
+// WithCountryDetector returns a copy of the normalizer using detector.
+// Cached results are kept apart per detector.
+func (pn *PhoneNormalizer) WithCountryDetector(detector CountryDetector) *PhoneNormalizer {
+    clone := *pn
+    clone.countryDetector = detector
+    return &clone
+}
+
+// WithCache returns a copy of the normalizer that serves repeated inputs
+// from cache
+func (pn *PhoneNormalizer) WithCache(cache *NormalizationCache) *PhoneNormalizer {
+    clone := *pn
+    clone.cache = cache
+    return &clone
+}
+
+// WithStrictMode returns a copy of the normalizer that, when strict is
+// false, returns unparseable but digit-bearing inputs as-is alongside
+// ErrFallbackUsed instead of rejecting them
+func (pn *PhoneNormalizer) WithStrictMode(strict bool) *PhoneNormalizer {
+    clone := *pn
+    clone.strictMode = strict
+    return &clone
+}
+
+// WithTenantDefault returns a copy of the normalizer that favours region
+// when detecting the country of inputs without a hint
+func (pn *PhoneNormalizer) WithTenantDefault(region string) *PhoneNormalizer {
+    clone := *pn
+    clone.tenantRegion = region
+    return &clone
+}
+
 // NormalizePhone - Main normalization function using libphonenumber
 func (pn *PhoneNormalizer) NormalizePhone(input string, countryHint string) (*NormalizedPhone, error) {
-    // Step 1: Clean and preprocess input
-    cleaned := pn.cleanPhoneNumber(input)
+    detector, ok := detectorIdentity(pn.countryDetector)
+    if pn.cache == nil || !ok {
+        return pn.normalizePhone(input, countryHint)
+    }
+    
+    key := cacheKey{
+        input:         input,
+        countryHint:   countryHint,
+        defaultRegion: pn.defaultRegion,
+        tenantRegion:  pn.tenantRegion,
+        strict:        pn.strictMode,
+        detector:      detector,
+    }
+    return pn.cache.get(key, pn.planDataVersion(), func() (*NormalizedPhone, error) {
+        return pn.normalizePhone(input, countryHint)
+    })
+}
+
+// planDataVersion identifies the numbering plan data behind results
+func (pn *PhoneNormalizer) planDataVersion() string {
+    return pn.geoLocator.plans.Version() + "/" + pn.carrierDetector.plans.Version()
+}
+
+func (pn *PhoneNormalizer) normalizePhone(input string, countryHint string) (*NormalizedPhone, error) {
+    // Step 1: Split off any extension, then clean and preprocess input
+    number, extension := splitExtension(pn.convertDigits(input))
+    cleaned := pn.cleanPhoneNumber(number)
     
     // Step 2: Detect country if not provided
-    countryCode := countryHint
-    if countryCode == "" {
-        countryCode = pn.detectCountry(cleaned)
+    detection, err := pn.detectCountry(input, cleaned, countryHint)
+    if err != nil {
+        return nil, err
     }
+    countryCode := detection.Region
     
     // Step 3: Parse with libphonenumber
     num, err := phonenumbers.Parse(cleaned, countryCode)
     if err != nil {
+        parseErr := parseError(input, err)
         if pn.strictMode {
-            return nil, fmt.Errorf("failed to parse phone number: %w", err)
+            return nil, parseErr
         }
         // Fallback normalization
-        return pn.fallbackNormalize(cleaned, countryCode)
+        return pn.fallbackNormalize(input, cleaned, extension, countryCode, parseErr)
+    }
+    if extension != "" {
+        num.Extension = &extension
     }
     
     // Step 4: Validate number
+    if short, ok := pn.shortNumber(input, cleaned, countryCode, num, detection); ok {
+        return short, nil
+    }
     isValid := phonenumbers.IsValidNumber(num)
     isPossible := phonenumbers.IsPossibleNumber(num)
     
@@ -69,7 +152,13 @@ func (pn *PhoneNormalizer) NormalizePhone(input string, countryHint string) (*No
     region := pn.geoLocator.GetRegion(num)
     carrier := pn.carrierDetector.GetCarrier(num)
     numberType := pn.getNumberType(num)
-    timezone := pn.geoLocator.GetTimezone(num)
+    timezones := pn.geoLocator.GetTimezones(num)
+    
+    // The number's own region wins over the one used to parse it
+    country := phonenumbers.GetRegionCodeForNumber(num)
+    if country == "" || country == "ZZ" {
+        country = countryCode
+    }
     
     // Step 6: Format in different standards
     normalized := &NormalizedPhone{
@@ -78,40 +167,135 @@ func (pn *PhoneNormalizer) NormalizePhone(input string, countryHint string) (*No
         International: phonenumbers.Format(num, phonenumbers.INTERNATIONAL),
         National:      phonenumbers.Format(num, phonenumbers.NATIONAL),
         CountryCode:   *num.CountryCode,
-        Country:       countryCode,
-        Region:        region,
-        Carrier:       carrier,
+        Country:       country,
+        Region:        region.Value,
+        Carrier:       carrier.Value,
         IsValid:       isValid,
         IsPossible:    isPossible,
         Type:          numberType,
-        Timezone:      timezone,
+        Extension:     extension,
+        Timezone:      primaryTimezone(timezones),
+        Timezones:     timezones,
+        PlanVersion:   planVersion(carrier, region),
+        Detection:     detection,
     }
     
     return normalized, nil
 }
 
-// Batch normalization for multiple numbers
-func (pn *PhoneNormalizer) NormalizeBatch(inputs []string, countryHint string) (map[string]*NormalizedPhone, []error) {
-    results := make(map[string]*NormalizedPhone)
-    var errors []error
+// detectCountry resolves the region to parse with. A hint always wins;
+// otherwise the country detector's best candidate is used, and inputs whose
+// top candidates are too close to call are rejected rather than guessed.
+func (pn *PhoneNormalizer) detectCountry(input, cleaned, countryHint string) (*CountryDetection, error) {
+    if countryHint != "" {
+        return &CountryDetection{
+            Source:     "hint",
+            Region:     strings.ToUpper(countryHint),
+            Confidence: 1,
+            Reasons:    []string{"country hint supplied by caller"},
+        }, nil
+    }
+    
+    candidates := pn.countryDetector.DetectCountry(cleaned, DetectionContext{
+        TenantDefault:  pn.tenantRegion,
+        ServiceDefault: pn.defaultRegion,
+    })
+    
+    // Nothing even parses: let libphonenumber report the real failure
+    if len(candidates) == 0 {
+        return &CountryDetection{
+            Source:  "default",
+            Region:  pn.defaultRegion,
+            Reasons: []string{"no candidate region matched"},
+        }, nil
+    }
     
-    for _, input := range inputs {
-        normalized, err := pn.NormalizePhone(input, countryHint)
-        if err != nil {
-            errors = append(errors, fmt.Errorf("failed to normalize %s: %w", input, err))
-            continue
+    chosen, ok := chooseCountry(candidates)
+    if !ok {
+        return nil, &NormalizationError{
+            Reason:     ErrAmbiguousCountry,
+            Input:      input,
+            Candidates: candidates,
         }
-        results[input] = normalized
     }
     
-    return results, errors
+    return &CountryDetection{
+        Source:     "detected",
+        Region:     chosen.Region,
+        Confidence: chosen.Confidence,
+        Reasons:    chosen.Reasons,
+        Candidates: candidates,
+    }, nil
+}
+
+// shortNumber recognises emergency and service short codes (110, 115,
+// 1xx, ...). They are only dialable inside their country, so they have no
+// E.164 form: Normalized holds the national digits.
+func (pn *PhoneNormalizer) shortNumber(input, cleaned, region string, num *phonenumbers.PhoneNumber, detection *CountryDetection) (*NormalizedPhone, bool) {
+    if strings.HasPrefix(cleaned, "+") || phonenumbers.IsValidNumber(num) ||
+        !phonenumbers.IsValidShortNumberForRegion(num, region) {
+        return nil, false
+    }
+    
+    numberType := "SHORT_CODE"
+    if phonenumbers.IsEmergencyNumber(cleaned, region) {
+        numberType = "EMERGENCY"
+    }
+    
+    national := phonenumbers.GetNationalSignificantNumber(num)
+    timezones := pn.geoLocator.GetTimezones(num)
+    
+    return &NormalizedPhone{
+        Original:    input,
+        Normalized:  national,
+        National:    national,
+        CountryCode: num.GetCountryCode(),
+        Country:     region,
+        IsValid:     true,
+        IsPossible:  true,
+        Type:        numberType,
+        Timezone:    primaryTimezone(timezones),
+        Timezones:   timezones,
+        Detection:   detection,
+    }, true
+}
+
+// Extension markers at the end of an input, after the last digit of the
+// number itself. Shares its keywords with PhoneExtractor.
+var extensionSuffix = func() *regexp.Regexp {
+    keywords := []string{";ext="}
+    for _, keyword := range extensionKeywords {
+        keywords = append(keywords, regexp.QuoteMeta(keyword))
+    }
+    return regexp.MustCompile(`(?i)^(.*\d)[\s,]*(?:` + strings.Join(keywords, "|") + `)[\s.:]*(\d{1,7})\s*$`)
+}()
+
+// splitExtension separates a trailing extension from an input whose digits
+// have already been folded to ASCII
+func splitExtension(input string) (string, string) {
+    if matches := extensionSuffix.FindStringSubmatch(input); matches != nil {
+        return matches[1], matches[2]
+    }
+    return input, ""
+}
+
+func planVersion(answers ...PlanAnswer) string {
+    for _, answer := range answers {
+        if answer.Version != "" {
+            return answer.Version
+        }
+    }
+    return ""
 }
 
 // Clean phone number input
 func (pn *PhoneNormalizer) cleanPhoneNumber(input string) string {
+    // Fold other digit scripts first; \d only matches ASCII
+    cleaned := pn.convertDigits(input)
+    
     // Remove all non-digit characters except + and spaces
     re := regexp.MustCompile(`[^\d+\s]`)
-    cleaned := re.ReplaceAllString(input, "")
+    cleaned = re.ReplaceAllString(cleaned, "")
     
     // Remove spaces
     cleaned = strings.ReplaceAll(cleaned, " ", "")
@@ -124,14 +308,11 @@ func (pn *PhoneNormalizer) cleanPhoneNumber(input string) string {
 
 // Special handling for Iranian phone numbers
 func (pn *PhoneNormalizer) normalizeIranianFormats(phone string) string {
-    // Convert Persian digits to English
-    phone = pn.convertPersianDigits(phone)
-    
     // Handle common Iranian formats
     patterns := map[string]*regexp.Regexp{
         "with_zero":   regexp.MustCompile(`^0098(\d{10})$`),
         "with_plus":   regexp.MustCompile(`^\+98(\d{10})$`),
-        "without_country": regexp.MustCompile(`^0?9(\d{9})$`),
+        "without_country": regexp.MustCompile(`^0?(9\d{9})$`),
     }
     
     for format, pattern := range patterns {
@@ -150,19 +331,15 @@ func (pn *PhoneNormalizer) normalizeIranianFormats(phone string) string {
     return phone
 }
 
-// Convert Persian/Arabic digits to English
-func (pn *PhoneNormalizer) convertPersianDigits(input string) string {
-    persianToEnglish := map[rune]rune{
-        '۰': '0', '۱': '1', '۲': '2', '۳': '3', '۴': '4',
-        '۵': '5', '۶': '6', '۷': '7', '۸': '8', '۹': '9',
-        '٠': '0', '١': '1', '٢': '2', '٣': '3', '٤': '4',
-        '٥': '5', '٦': '6', '٧': '7', '٨': '8', '٩': '9',
-    }
-    
+// Convert digits from any script (Persian, Arabic-Indic, full-width, ...)
+// to ASCII and fold full-width plus signs
+func (pn *PhoneNormalizer) convertDigits(input string) string {
     var result strings.Builder
     for _, char := range input {
-        if replacement, exists := persianToEnglish[char]; exists {
-            result.WriteRune(replacement)
+        if digit, ok := digitValue(char); ok {
+            result.WriteRune(digit)
+        } else if char == '\uff0b' { // Full-width plus
+            result.WriteRune('+')
         } else {
             result.WriteRune(char)
         }
@@ -171,31 +348,60 @@ func (pn *PhoneNormalizer) convertPersianDigits(input string) string {
     return result.String()
 }
 
-// Fallback normalization when libphonenumber fails
-func (pn *PhoneNormalizer) fallbackNormalize(phone, country string) (*NormalizedPhone, error) {
-    // Simple regex-based normalization as fallback
-    re := regexp.MustCompile(`^(?:\+?(\d{1,3})?[-. ]?)?\(?(\d{3})\)?[-. ]?(\d{3})[-. ]?(\d{4})$`)
+// digitValue maps a Unicode decimal digit to its ASCII equivalent. Every
+// range in unicode.Nd starts at a zero and spans whole runs of ten.
+func digitValue(r rune) (rune, bool) {
+    if r >= '0' && r <= '9' {
+        return r, true
+    }
+    if r < 0x80 || !unicode.IsDigit(r) {
+        return 0, false
+    }
     
-    if matches := re.FindStringSubmatch(phone); matches != nil {
-        countryCode := matches[1]
-        if countryCode == "" {
-            countryCode = "98" // Default to Iran
+    for _, rg := range unicode.Nd.R16 {
+        if rune(rg.Lo) <= r && r <= rune(rg.Hi) {
+            return '0' + (r-rune(rg.Lo))%10, true
         }
-        
-        normalized := &NormalizedPhone{
-            Original:   phone,
-            Normalized: fmt.Sprintf("+%s%s%s%s", countryCode, matches[2], matches[3], matches[4]),
-            Country:    country,
-            IsValid:    false, // Mark as invalid since fallback was used
-            IsPossible: true,
+    }
+    for _, rg := range unicode.Nd.R32 {
+        if rune(rg.Lo) <= r && r <= rune(rg.Hi) {
+            return '0' + (r-rune(rg.Lo))%10, true
         }
-        
-        return normalized, nil
     }
     
-    return nil, fmt.Errorf("could not normalize phone number: %s", phone)
+    return 0, false
+}
+
+// Fallback normalization when libphonenumber fails. The cleaned digits are
+// kept exactly as given, never reshaped or given an invented country code,
+// so the input round-trips losslessly. A fallback result is returned
+// together with ErrFallbackUsed so callers must opt in to using it.
+func (pn *PhoneNormalizer) fallbackNormalize(input, cleaned, extension, country string, parseErr *NormalizationError) (*NormalizedPhone, error) {
+    digits := strings.TrimPrefix(cleaned, "+")
+    if len(digits) < minFallbackDigits || strings.Trim(digits, "0123456789") != "" {
+        return nil, parseErr
+    }
+    
+    normalized := &NormalizedPhone{
+        Original:   input,
+        Normalized: cleaned,
+        National:   digits,
+        Country:    country,
+        IsValid:    false, // Mark as invalid since fallback was used
+        IsPossible: false,
+        Type:       "UNKNOWN",
+        Extension:  extension,
+    }
+    if !strings.HasPrefix(cleaned, "+") {
+        normalized.CountryCode = int32(phonenumbers.GetCountryCodeForRegion(country))
+    }
+    
+    return normalized, &NormalizationError{Reason: ErrFallbackUsed, Input: input, Err: parseErr}
 }
 
+// Fewer digits than this carry no recognisable structure worth keeping
+const minFallbackDigits = 3
+
 func (pn *PhoneNormalizer) getNumberType(num *phonenumbers.PhoneNumber) string {
     switch phonenumbers.GetNumberType(num) {
     case phonenumbers.MOBILE:
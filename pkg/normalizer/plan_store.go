@@ -0,0 +1,143 @@
+// pkg/normalizer/plan_store.go
+package normalizer
+
+import (
+    "context"
+    "fmt"
+    "log"
+    "os"
+    "os/signal"
+    "sync"
+    "sync/atomic"
+    "syscall"
+    "time"
+)
+
+// PlanStore holds the active numbering plan index. Lookups are lock-free;
+// reloads build a new index off to the side and swap it in atomically, so
+// a bad file never replaces a good one.
+type PlanStore struct {
+    index  atomic.Pointer[planIndex]
+    loadMu sync.Mutex
+}
+
+var (
+    defaultPlanStoreOnce sync.Once
+    defaultPlanStore     *PlanStore
+)
+
+// DefaultPlanStore returns the process-wide store, seeded with the embedded plans
+func DefaultPlanStore() *PlanStore {
+    defaultPlanStoreOnce.Do(func() {
+        defaultPlanStore = &PlanStore{}
+        defaultPlanStore.index.Store(&planIndex{plans: map[int32]*compiledPlan{}})
+
+        tables, err := embeddedPlanTables()
+        if err == nil {
+            err = defaultPlanStore.Replace(tables)
+        }
+        if err != nil {
+            log.Printf("⚠️ Embedded numbering plans unavailable: %v", err)
+        }
+    })
+    return defaultPlanStore
+}
+
+// NewPlanStore creates a store serving the given tables
+func NewPlanStore(tables *PlanTables) (*PlanStore, error) {
+    ps := &PlanStore{}
+    if err := ps.Replace(tables); err != nil {
+        return nil, err
+    }
+    return ps, nil
+}
+
+// Lookup returns the longest matching range for a national significant
+// number, plus the version of the plan that answered
+func (ps *PlanStore) Lookup(countryCode int32, nationalNumber string) (*NumberRange, string, bool) {
+    return ps.index.Load().lookup(countryCode, nationalNumber)
+}
+
+// Timezones returns the IANA zones the plan assigns to a national
+// significant number, primary first
+func (ps *PlanStore) Timezones(countryCode int32, nationalNumber string) ([]string, string, bool) {
+    return ps.index.Load().timezones(countryCode, nationalNumber)
+}
+
+// Version returns the version of the active tables
+func (ps *PlanStore) Version() string {
+    return ps.index.Load().version
+}
+
+// Replace validates tables and makes them the active index
+func (ps *PlanStore) Replace(tables *PlanTables) error {
+    ps.loadMu.Lock()
+    defer ps.loadMu.Unlock()
+
+    index, err := buildPlanIndex(tables)
+    if err != nil {
+        return err
+    }
+    ps.index.Store(index)
+    return nil
+}
+
+// LoadFile reads a PlanTables document from path and swaps it in. The file
+// is authoritative: it replaces the embedded plans rather than merging.
+func (ps *PlanStore) LoadFile(path string) error {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return fmt.Errorf("failed to read numbering plan file: %w", err)
+    }
+
+    tables, err := ParsePlanTables(data)
+    if err != nil {
+        return err
+    }
+
+    return ps.Replace(tables)
+}
+
+// Watch reloads path on SIGHUP or whenever its modification time changes,
+// until ctx is cancelled. Failed reloads are logged and the previous index
+// stays active.
+func (ps *PlanStore) Watch(ctx context.Context, path string, interval time.Duration) {
+    hup := make(chan os.Signal, 1)
+    signal.Notify(hup, syscall.SIGHUP)
+    defer signal.Stop(hup)
+
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+
+    lastMod := fileModTime(path)
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case <-hup:
+            lastMod = fileModTime(path)
+            ps.reload(path, "SIGHUP")
+        case <-ticker.C:
+            if modTime := fileModTime(path); !modTime.Equal(lastMod) {
+                lastMod = modTime
+                ps.reload(path, "file change")
+            }
+        }
+    }
+}
+
+func (ps *PlanStore) reload(path, trigger string) {
+    if err := ps.LoadFile(path); err != nil {
+        log.Printf("⚠️ Numbering plan reload (%s) rejected, keeping %s: %v", trigger, ps.Version(), err)
+        return
+    }
+    log.Printf("📞 Numbering plans reloaded (%s): %s", trigger, ps.Version())
+}
+
+func fileModTime(path string) time.Time {
+    info, err := os.Stat(path)
+    if err != nil {
+        return time.Time{}
+    }
+    return info.ModTime()
+}
@@ -0,0 +1,91 @@
+// pkg/normalizer/prefix_trie.go
+package normalizer
+
+// prefixTrie is a decimal digit trie used for longest-prefix matching of
+// national significant numbers against numbering plan ranges.
+type prefixTrie struct {
+    root *trieNode
+    size int
+}
+
+type trieNode struct {
+    children [10]*trieNode
+    value    *NumberRange
+}
+
+func newPrefixTrie() *prefixTrie {
+    return &prefixTrie{root: &trieNode{}}
+}
+
+// insert stores r under prefix. If the prefix is already taken the existing
+// range is returned and the trie is left unchanged.
+func (t *prefixTrie) insert(prefix string, r *NumberRange) *NumberRange {
+    node := t.root
+    for i := 0; i < len(prefix); i++ {
+        d := prefix[i] - '0'
+        if node.children[d] == nil {
+            node.children[d] = &trieNode{}
+        }
+        node = node.children[d]
+    }
+
+    if node.value != nil {
+        return node.value
+    }
+
+    node.value = r
+    t.size++
+    return nil
+}
+
+// longestMatch returns the range with the longest prefix of digits
+func (t *prefixTrie) longestMatch(digits string) *NumberRange {
+    var best *NumberRange
+    node := t.root
+    for i := 0; i < len(digits); i++ {
+        d := digits[i] - '0'
+        if d > 9 || node.children[d] == nil {
+            break
+        }
+        node = node.children[d]
+        if node.value != nil {
+            best = node.value
+        }
+    }
+    return best
+}
+
+// matches returns every range whose prefix matches digits, shortest first
+func (t *prefixTrie) matches(digits string) []*NumberRange {
+    var found []*NumberRange
+    node := t.root
+    for i := 0; i < len(digits); i++ {
+        d := digits[i] - '0'
+        if d > 9 || node.children[d] == nil {
+            break
+        }
+        node = node.children[d]
+        if node.value != nil {
+            found = append(found, node.value)
+        }
+    }
+    return found
+}
+
+// walk visits every stored range in prefix order together with the nearest
+// enclosing range, if any
+func (t *prefixTrie) walk(fn func(r, parent *NumberRange)) {
+    var visit func(node *trieNode, parent *NumberRange)
+    visit = func(node *trieNode, parent *NumberRange) {
+        if node.value != nil {
+            fn(node.value, parent)
+            parent = node.value
+        }
+        for _, child := range node.children {
+            if child != nil {
+                visit(child, parent)
+            }
+        }
+    }
+    visit(t.root, nil)
+}
@@ -0,0 +1,131 @@
+// pkg/purpose/case_store.go
+package purpose
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "time"
+
+    "gorm.io/gorm"
+    "secure-iran-intel/pkg/refusal"
+    "secure-iran-intel/pkg/tenancy"
+)
+
+// Case is a tenant-registered investigation that jobs can be run under
+type Case struct {
+    ID          string     `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+    TenantID    string     `json:"tenant_id" gorm:"type:uuid;not null"`
+    Reference   string     `json:"reference" gorm:"not null"`
+    Title       string     `json:"title"`
+    LegalBases  []string   `json:"legal_bases" gorm:"serializer:json;type:jsonb"` // Bases jobs may cite
+    Status      string     `json:"status" gorm:"default:'open'"`                  // open, closed
+    OpenedBy    string     `json:"opened_by"`
+    ExpiresAt   *time.Time `json:"expires_at"`
+    CreatedAt   time.Time  `json:"created_at"`
+    UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+func (Case) TableName() string {
+    return "cases"
+}
+
+// IsOpen reports whether jobs may still be run under the case
+func (c *Case) IsOpen() bool {
+    return c.Status == "open" && (c.ExpiresAt == nil || c.ExpiresAt.After(time.Now()))
+}
+
+// Allows reports whether basis is authorised for the case
+func (c *Case) Allows(basis string) bool {
+    for _, allowed := range c.LegalBases {
+        if allowed == basis {
+            return true
+        }
+    }
+    return false
+}
+
+// CaseStore is the tenant's case register
+type CaseStore interface {
+    // GetCase returns ErrUnknownCase when the tenant has no such case
+    GetCase(ctx context.Context, tenantID, reference string) (*Case, error)
+}
+
+// CaseRegister is a CaseStore cases are also opened and closed in
+type CaseRegister interface {
+    CaseStore
+    OpenCase(ctx context.Context, c *Case) error
+    // CloseCase returns ErrUnknownCase when the tenant has no such case
+    CloseCase(ctx context.Context, tenantID, reference string) error
+    ListOpenCases(ctx context.Context, tenantID string) ([]Case, error)
+}
+
+// GormCaseStore keeps cases in the cases table (migration 010)
+type GormCaseStore struct {
+    db *gorm.DB
+}
+
+func NewGormCaseStore(db *gorm.DB) *GormCaseStore {
+    return &GormCaseStore{db: db}
+}
+
+func (s *GormCaseStore) GetCase(ctx context.Context, tenantID, reference string) (*Case, error) {
+    var c Case
+    err := tenancy.Transaction(ctx, s.db, tenantID, func(tx *gorm.DB) error {
+        return tx.Where("tenant_id = ? AND reference = ?", tenantID, reference).First(&c).Error
+    })
+    if errors.Is(err, gorm.ErrRecordNotFound) {
+        return nil, ErrUnknownCase
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to load case: %w", err)
+    }
+    return &c, nil
+}
+
+// OpenCase registers a case for the tenant
+func (s *GormCaseStore) OpenCase(ctx context.Context, c *Case) error {
+    for _, basis := range c.LegalBases {
+        if !IsLegalBasis(basis) {
+            return &refusal.Error{Reason: ErrInvalidLegalBasis, ID: c.Reference, Detail: basis}
+        }
+    }
+    c.Status = "open"
+    err := tenancy.Transaction(ctx, s.db, c.TenantID, func(tx *gorm.DB) error {
+        return tx.Create(c).Error
+    })
+    if err != nil {
+        return fmt.Errorf("failed to open case: %w", err)
+    }
+    return nil
+}
+
+// CloseCase stops new jobs from citing the case; existing jobs keep it
+func (s *GormCaseStore) CloseCase(ctx context.Context, tenantID, reference string) error {
+    var closed int64
+    err := tenancy.Transaction(ctx, s.db, tenantID, func(tx *gorm.DB) error {
+        result := tx.Model(&Case{}).
+            Where("tenant_id = ? AND reference = ?", tenantID, reference).
+            Updates(map[string]interface{}{"status": "closed", "updated_at": time.Now()})
+        closed = result.RowsAffected
+        return result.Error
+    })
+    if err != nil {
+        return fmt.Errorf("failed to close case: %w", err)
+    }
+    if closed == 0 {
+        return ErrUnknownCase
+    }
+    return nil
+}
+
+// ListOpenCases returns the cases jobs can currently be run under
+func (s *GormCaseStore) ListOpenCases(ctx context.Context, tenantID string) ([]Case, error) {
+    var cases []Case
+    err := tenancy.Transaction(ctx, s.db, tenantID, func(tx *gorm.DB) error {
+        return tx.Where("tenant_id = ? AND status = 'open' AND (expires_at IS NULL OR expires_at > ?)", tenantID, time.Now()).
+            Order("created_at DESC").
+            Find(&cases).Error
+    })
+    return cases, err
+}
@@ -0,0 +1,108 @@
+// pkg/purpose/purpose.go
+package purpose
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "net/http"
+    "strings"
+
+    "secure-iran-intel/pkg/refusal"
+)
+
+// Legal-basis categories a job can be run under
+const (
+    BasisCourtOrder            = "court_order"
+    BasisCriminalInvestigation = "criminal_investigation"
+    BasisLegalObligation       = "legal_obligation"
+    BasisVitalInterests        = "vital_interests"
+    BasisConsent               = "consent"
+    BasisLegitimateInterest    = "legitimate_interest"
+)
+
+var legalBases = map[string]bool{
+    BasisCourtOrder:            true,
+    BasisCriminalInvestigation: true,
+    BasisLegalObligation:       true,
+    BasisVitalInterests:        true,
+    BasisConsent:               true,
+    BasisLegitimateInterest:    true,
+}
+
+// IsLegalBasis reports whether basis is a known category
+func IsLegalBasis(basis string) bool {
+    return legalBases[basis]
+}
+
+// Short justifications ("test", "check") say nothing about purpose
+const minJustificationLength = 20
+
+// Purpose is why a job is run. Request types embed it so the fields sit at
+// the top level of the JSON body.
+type Purpose struct {
+    CaseReference string `json:"case_reference"`
+    LegalBasis    string `json:"legal_basis"`
+    Justification string `json:"justification"`
+}
+
+// Rejection reasons. Match them with errors.Is.
+var (
+    ErrMissingPurpose    = refusal.New("PURPOSE_REQUIRED", http.StatusBadRequest, "case reference, legal basis and justification are required")
+    ErrInvalidLegalBasis = refusal.New("INVALID_LEGAL_BASIS", http.StatusBadRequest, "unknown legal basis")
+    ErrUnknownCase       = refusal.New("UNKNOWN_CASE", http.StatusBadRequest, "case reference is not registered for this tenant")
+    ErrCaseClosed        = refusal.New("CASE_CLOSED", http.StatusBadRequest, "case is not open")
+    ErrBasisNotAllowed   = refusal.New("LEGAL_BASIS_NOT_ALLOWED", http.StatusBadRequest, "legal basis is not authorised for this case")
+    ErrNoLegalBases      = refusal.New("LEGAL_BASES_REQUIRED", http.StatusBadRequest, "a case must authorise at least one legal basis")
+    ErrCaseExists        = refusal.New("CASE_EXISTS", http.StatusConflict, "case reference is already registered for this tenant")
+)
+
+// Validator checks a job's purpose against the tenant's open cases
+type Validator struct {
+    cases CaseStore
+}
+
+func NewValidator(cases CaseStore) *Validator {
+    return &Validator{cases: cases}
+}
+
+// Validate returns the case the job is authorised under, or a *refusal.Error
+func (v *Validator) Validate(ctx context.Context, tenantID string, p Purpose) (*Case, error) {
+    p.CaseReference = strings.TrimSpace(p.CaseReference)
+    p.Justification = strings.TrimSpace(p.Justification)
+
+    var missing []string
+    if p.CaseReference == "" {
+        missing = append(missing, "case_reference")
+    }
+    if p.LegalBasis == "" {
+        missing = append(missing, "legal_basis")
+    }
+    if len([]rune(p.Justification)) < minJustificationLength {
+        missing = append(missing, fmt.Sprintf("justification (at least %d characters)", minJustificationLength))
+    }
+    if len(missing) > 0 {
+        return nil, &refusal.Error{Reason: ErrMissingPurpose, ID: p.CaseReference, Detail: strings.Join(missing, ", ")}
+    }
+
+    if !IsLegalBasis(p.LegalBasis) {
+        return nil, &refusal.Error{Reason: ErrInvalidLegalBasis, ID: p.CaseReference, Detail: p.LegalBasis}
+    }
+
+    c, err := v.cases.GetCase(ctx, tenantID, p.CaseReference)
+    if errors.Is(err, ErrUnknownCase) {
+        return nil, &refusal.Error{Reason: ErrUnknownCase, ID: p.CaseReference}
+    }
+    if err != nil {
+        return nil, err
+    }
+
+    if !c.IsOpen() {
+        return nil, &refusal.Error{Reason: ErrCaseClosed, ID: p.CaseReference, Detail: c.Status}
+    }
+    if !c.Allows(p.LegalBasis) {
+        return nil, &refusal.Error{Reason: ErrBasisNotAllowed, ID: p.CaseReference, Detail: p.LegalBasis}
+    }
+
+    return c, nil
+}
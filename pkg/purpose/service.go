@@ -0,0 +1,131 @@
+// pkg/purpose/service.go
+package purpose
+
+import (
+    "context"
+    "errors"
+    "strings"
+    "time"
+
+    "secure-iran-intel/pkg/audit"
+    "secure-iran-intel/pkg/rbac"
+    "secure-iran-intel/pkg/refusal"
+)
+
+// NewCase is a case to register for the actor's tenant
+type NewCase struct {
+    Reference  string     `json:"reference" binding:"required"`
+    Title      string     `json:"title"`
+    LegalBases []string   `json:"legal_bases"`
+    ExpiresAt  *time.Time `json:"expires_at"`
+}
+
+// CaseService opens and closes the cases jobs are run under. Every change
+// is recorded in the audit log before it is made.
+type CaseService struct {
+    cases    CaseRegister
+    auditLog *audit.Logger
+    policy   *rbac.Engine
+}
+
+func NewCaseService(cases CaseRegister, auditLog *audit.Logger, policy *rbac.Engine) *CaseService {
+    return &CaseService{
+        cases:    cases,
+        auditLog: auditLog,
+        policy:   policy,
+    }
+}
+
+// Open registers a case for the actor's tenant. Jobs can cite it, under
+// one of its legal bases, until it is closed or expires.
+func (s *CaseService) Open(ctx context.Context, req NewCase) (*Case, error) {
+    actor := audit.ActorFromContext(ctx)
+    if err := s.policy.Require(ctx, actor, rbac.PermCases, nil); err != nil {
+        return nil, err
+    }
+    reference := strings.TrimSpace(req.Reference)
+    if reference == "" {
+        return nil, &refusal.Error{Reason: ErrMissingPurpose, Detail: "reference"}
+    }
+    if len(req.LegalBases) == 0 {
+        return nil, &refusal.Error{Reason: ErrNoLegalBases, ID: reference}
+    }
+    for _, basis := range req.LegalBases {
+        if !IsLegalBasis(basis) {
+            return nil, &refusal.Error{Reason: ErrInvalidLegalBasis, ID: reference, Detail: basis}
+        }
+    }
+    if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+        return nil, &refusal.Error{Reason: ErrCaseClosed, ID: reference, Detail: "expires_at is in the past"}
+    }
+
+    _, err := s.cases.GetCase(ctx, actor.TenantID, reference)
+    if err == nil {
+        return nil, &refusal.Error{Reason: ErrCaseExists, ID: reference}
+    }
+    if !errors.Is(err, ErrUnknownCase) {
+        return nil, err
+    }
+
+    metadata := map[string]string{"legal_bases": strings.Join(req.LegalBases, ",")}
+    if req.ExpiresAt != nil {
+        metadata["expires_at"] = req.ExpiresAt.UTC().Format(time.RFC3339)
+    }
+    err = s.auditLog.Log(ctx, audit.Entry{
+        Action:     audit.ActionCaseOpen,
+        TargetType: "case",
+        Target:     reference,
+        Metadata:   metadata,
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    c := &Case{
+        TenantID:   actor.TenantID,
+        Reference:  reference,
+        Title:      strings.TrimSpace(req.Title),
+        LegalBases: req.LegalBases,
+        OpenedBy:   actor.ID,
+        ExpiresAt:  req.ExpiresAt,
+    }
+    if err := s.cases.OpenCase(ctx, c); err != nil {
+        return nil, err
+    }
+    return c, nil
+}
+
+// Close stops new jobs citing the case. Jobs already run under it, and
+// legal holds on it, are unaffected.
+func (s *CaseService) Close(ctx context.Context, reference string) error {
+    actor := audit.ActorFromContext(ctx)
+    if err := s.policy.Require(ctx, actor, rbac.PermCases, nil); err != nil {
+        return err
+    }
+    if _, err := s.cases.GetCase(ctx, actor.TenantID, reference); err != nil {
+        if errors.Is(err, ErrUnknownCase) {
+            return &refusal.Error{Reason: ErrUnknownCase, ID: reference}
+        }
+        return err
+    }
+
+    if err := s.auditLog.Log(ctx, audit.Entry{Action: audit.ActionCaseClose, TargetType: "case", Target: reference}); err != nil {
+        return err
+    }
+    if err := s.cases.CloseCase(ctx, actor.TenantID, reference); err != nil {
+        if errors.Is(err, ErrUnknownCase) {
+            return &refusal.Error{Reason: ErrUnknownCase, ID: reference}
+        }
+        return err
+    }
+    return nil
+}
+
+// ListOpen returns the cases the actor's tenant can currently run jobs under
+func (s *CaseService) ListOpen(ctx context.Context) ([]Case, error) {
+    actor := audit.ActorFromContext(ctx)
+    if err := s.policy.Require(ctx, actor, rbac.PermCases, nil); err != nil {
+        return nil, err
+    }
+    return s.cases.ListOpenCases(ctx, actor.TenantID)
+}
@@ -0,0 +1,73 @@
+// pkg/rbac/defaults.go
+package rbac
+
+// Permissions checked by the services
+const (
+    PermJobsCreate      = "jobs:create"
+    PermJobsRead        = "jobs:read"
+    PermReportsRead     = "reports:read"
+    PermExportsRead     = "exports:read"
+    PermBulkJobs        = "jobs:bulk"
+    PermDataDelete      = "data:delete"
+    PermRolesWrite      = "roles:write"
+    PermPhoneLookup     = "phone_lookup:execute"
+    PermRolesRead       = "roles:read"
+    PermAPIKeys         = "api_keys:manage"
+    PermSCIM            = "scim:provision" // Granted to the keys directories provision with
+    PermTenantsManage   = "tenants:manage" // Platform operators only; see pkg/lifecycle
+    PermLegalHolds      = "legal_holds:manage"
+    PermSubjectRequests = "subject_requests:manage" // Platform operators only; see pkg/subject
+    PermSuppressions    = "suppressions:manage"
+    PermCases           = "cases:manage"
+    PermAdmin           = "admin"
+)
+
+// RequiresStepUp reports whether a permission is sensitive enough that the
+// user must have re-authenticated with a second factor moments before
+// using it. API keys cannot use these permissions.
+func RequiresStepUp(permission string) bool {
+    switch permission {
+    case PermExportsRead, PermBulkJobs, PermDataDelete, PermRolesWrite, PermTenantsManage, PermLegalHolds, PermSubjectRequests, PermSuppressions, PermCases:
+        return true
+    default:
+        return false
+    }
+}
+
+// DefaultRoles are the roles every new tenant starts with. Users see their
+// own jobs; admins can do everything.
+func DefaultRoles(tenantID string) []Role {
+    return []Role{
+        {
+            TenantID: tenantID,
+            Name:     "viewer",
+            Rules: []Rule{
+                {Effect: EffectAllow, Permission: PermReportsRead},
+                {Effect: EffectAllow, Permission: PermExportsRead},
+            },
+            IsSystemRole: true,
+        },
+        {
+            TenantID: tenantID,
+            Name:     "user",
+            Inherits: []string{"viewer"},
+            Rules: []Rule{
+                {Effect: EffectAllow, Permission: PermPhoneLookup},
+                {Effect: EffectAllow, Permission: PermJobsCreate},
+                {Effect: EffectAllow, Permission: PermBulkJobs},
+                {Effect: EffectAllow, Permission: PermJobsRead, Scope: ScopeOwn},
+                {Effect: EffectAllow, Permission: PermAPIKeys, Scope: ScopeOwn},
+            },
+            IsSystemRole: true,
+        },
+        {
+            TenantID: tenantID,
+            Name:     "admin",
+            Inherits: []string{"user"},
+            Rules: []Rule{
+                {Effect: EffectAllow, Permission: "*"},
+            },
+            IsSystemRole: true,
+        },
+    }
+}
@@ -0,0 +1,211 @@
+// pkg/rbac/engine.go
+package rbac
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "net/http"
+
+    "secure-iran-intel/pkg/audit"
+    "secure-iran-intel/pkg/refusal"
+)
+
+// Inheritance chains deeper than this are cut off rather than followed
+const maxInheritanceDepth = 8
+
+var (
+    ErrUnknownRole    = errors.New("role is not defined for this tenant")
+    ErrUnknownSubject = errors.New("user is not an active member of this tenant")
+    ErrDenied         = refusal.New("PERMISSION_DENIED", http.StatusForbidden, "permission denied")
+)
+
+// DeniedError carries the decision behind a refusal so callers can explain it
+type DeniedError struct {
+    Decision *Decision
+}
+
+func (e *DeniedError) Error() string {
+    return fmt.Sprintf("%s: %s", ErrDenied, e.Decision.Reason)
+}
+
+func (e *DeniedError) Unwrap() error {
+    return ErrDenied
+}
+
+// Engine evaluates tenant-scoped roles. Everything not explicitly allowed
+// is denied, and a matching deny rule overrides any allow.
+type Engine struct {
+    store Store
+}
+
+func NewEngine(store Store) *Engine {
+    return &Engine{store: store}
+}
+
+// Authorize decides whether subject may use permission on resource. Pass a
+// nil resource for route-level checks made before the resource is loaded;
+// those pass when some scope could allow the permission.
+func (e *Engine) Authorize(ctx context.Context, subject Subject, permission string, resource *Resource) (*Decision, error) {
+    d := &Decision{Permission: permission, Subject: subject, Resource: resource}
+
+    if resource != nil && resource.TenantID != subject.TenantID {
+        d.Reason = "resource belongs to another tenant"
+        return d, nil
+    }
+
+    roles, err := e.resolve(ctx, subject.TenantID, subject.Role)
+    if errors.Is(err, ErrUnknownRole) {
+        d.Reason = fmt.Sprintf("role %q is not defined for this tenant", subject.Role)
+        return d, nil
+    }
+    if err != nil {
+        return nil, err
+    }
+    for _, role := range roles {
+        d.Roles = append(d.Roles, role.Name)
+    }
+
+    // Team scopes compare against the owner's team when the resource does
+    // not record one
+    if resource != nil && resource.TeamID == "" && resource.OwnerID != "" {
+        if owner, err := e.store.Subject(ctx, resource.TenantID, resource.OwnerID); err == nil {
+            scoped := *resource
+            scoped.TeamID = owner.TeamID
+            resource = &scoped
+        }
+    }
+
+    // Deny rules first. Without a resource only tenant-wide denies apply,
+    // since a narrower deny leaves other resources allowed.
+    for _, role := range roles {
+        for i, rule := range role.Rules {
+            if rule.Effect != EffectDeny || !rule.Grants(permission) {
+                continue
+            }
+            d.Evaluated = append(d.Evaluated, role.Name+": "+rule.String())
+            if (resource == nil && rule.scope() == ScopeTenant) || (resource != nil && rule.Covers(&subject, resource)) {
+                d.Rule, d.RuleRole = &role.Rules[i], role.Name
+                d.Reason = fmt.Sprintf("denied by role %q: %s", role.Name, rule)
+                return d, nil
+            }
+        }
+    }
+
+    for _, role := range roles {
+        for i, rule := range role.Rules {
+            if rule.Effect != EffectAllow || !rule.Grants(permission) {
+                continue
+            }
+            d.Evaluated = append(d.Evaluated, role.Name+": "+rule.String())
+            if rule.Covers(&subject, resource) {
+                d.Allowed = true
+                d.Rule, d.RuleRole = &role.Rules[i], role.Name
+                d.Reason = fmt.Sprintf("allowed by role %q: %s", role.Name, rule)
+                return d, nil
+            }
+        }
+    }
+
+    if len(d.Evaluated) > 0 {
+        d.Reason = fmt.Sprintf("no rule granting %s covers this resource", permission)
+    } else {
+        d.Reason = fmt.Sprintf("no rule grants %s", permission)
+    }
+    return d, nil
+}
+
+// SubjectFor maps an authenticated actor to the subject it acts as. API
+// keys act as the user who owns them.
+func (e *Engine) SubjectFor(ctx context.Context, actor audit.Actor) (*Subject, error) {
+    switch actor.Type {
+    case audit.ActorUser:
+        return e.store.Subject(ctx, actor.TenantID, actor.ID)
+    case audit.ActorAPIKey:
+        // A key's scopes are checked by the gateway before this
+        if actor.OnBehalfOf == "" {
+            return nil, ErrUnknownSubject
+        }
+        return e.store.Subject(ctx, actor.TenantID, actor.OnBehalfOf)
+    default:
+        return nil, ErrUnknownSubject
+    }
+}
+
+// AuthorizeActor is Authorize for the actor attached to a request. The
+// system actor, which only internal services run as, is always allowed;
+// a context without an actor never is.
+func (e *Engine) AuthorizeActor(ctx context.Context, actor audit.Actor, permission string, resource *Resource) (*Decision, error) {
+    switch actor.Type {
+    case audit.ActorSystem:
+        return &Decision{Allowed: true, Permission: permission, Resource: resource, Reason: "internal system actor"}, nil
+    case audit.ActorNone, "":
+        return &Decision{Permission: permission, Resource: resource, Reason: "not authenticated"}, nil
+    }
+
+    subject, err := e.SubjectFor(ctx, actor)
+    if errors.Is(err, ErrUnknownSubject) {
+        return &Decision{Permission: permission, Resource: resource, Subject: Subject{TenantID: actor.TenantID, UserID: actor.ID}, Reason: err.Error()}, nil
+    }
+    if err != nil {
+        return nil, err
+    }
+    return e.Authorize(ctx, *subject, permission, resource)
+}
+
+// Require returns a *DeniedError unless the actor is allowed
+func (e *Engine) Require(ctx context.Context, actor audit.Actor, permission string, resource *Resource) error {
+    d, err := e.AuthorizeActor(ctx, actor, permission, resource)
+    if err != nil {
+        return err
+    }
+    if !d.Allowed {
+        return &DeniedError{Decision: d}
+    }
+    return nil
+}
+
+// HasPermission reports whether a user holds permission on some resource
+func (e *Engine) HasPermission(ctx context.Context, tenantID, userID, permission string) (bool, error) {
+    d, err := e.AuthorizeActor(ctx, audit.Actor{TenantID: tenantID, Type: audit.ActorUser, ID: userID}, permission, nil)
+    if err != nil {
+        return false, err
+    }
+    return d.Allowed, nil
+}
+
+// resolve returns the named role followed by every role it inherits,
+// breadth first, each once
+func (e *Engine) resolve(ctx context.Context, tenantID, name string) ([]*Role, error) {
+    root, err := e.store.Role(ctx, tenantID, name)
+    if err != nil {
+        return nil, err
+    }
+
+    roles := []*Role{root}
+    seen := map[string]bool{root.Name: true}
+    level := []*Role{root}
+    for depth := 0; depth < maxInheritanceDepth && len(level) > 0; depth++ {
+        var next []*Role
+        for _, role := range level {
+            for _, parentName := range role.Inherits {
+                if seen[parentName] {
+                    continue
+                }
+                seen[parentName] = true
+
+                parent, err := e.store.Role(ctx, tenantID, parentName)
+                if errors.Is(err, ErrUnknownRole) {
+                    continue // A dangling parent grants nothing
+                }
+                if err != nil {
+                    return nil, err
+                }
+                roles = append(roles, parent)
+                next = append(next, parent)
+            }
+        }
+        level = next
+    }
+    return roles, nil
+}
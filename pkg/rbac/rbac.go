@@ -0,0 +1,128 @@
+// pkg/rbac/rbac.go
+package rbac
+
+import (
+    "fmt"
+    "strings"
+)
+
+// Rule effects. A matching deny always beats a matching allow.
+const (
+    EffectAllow = "allow"
+    EffectDeny  = "deny"
+)
+
+// Scopes limit which resources a rule covers. Case scopes are written
+// "case:<reference>".
+const (
+    ScopeTenant = "tenant" // Every resource of the tenant
+    ScopeTeam   = "team"   // Resources owned by the subject's team
+    ScopeOwn    = "own"    // Resources the subject owns
+    casePrefix  = "case:"
+)
+
+// ScopeCase scopes a rule to the resources of one case
+func ScopeCase(reference string) string {
+    return casePrefix + reference
+}
+
+// Rule grants or denies a permission ("jobs:read", "jobs:*", "*") over a scope
+type Rule struct {
+    Effect     string `json:"effect"`
+    Permission string `json:"permission"`
+    Scope      string `json:"scope,omitempty"` // Defaults to ScopeTenant
+}
+
+func (r Rule) String() string {
+    return fmt.Sprintf("%s %s on %s", r.Effect, r.Permission, r.scope())
+}
+
+func (r Rule) scope() string {
+    if r.Scope == "" {
+        return ScopeTenant
+    }
+    return r.Scope
+}
+
+// Grants reports whether the rule's permission covers permission
+func (r Rule) Grants(permission string) bool {
+    if r.Permission == "*" || r.Permission == permission {
+        return true
+    }
+    if prefix, ok := strings.CutSuffix(r.Permission, ":*"); ok {
+        return strings.HasPrefix(permission, prefix+":")
+    }
+    return false
+}
+
+// Covers reports whether the rule's scope includes resource for subject.
+// A nil resource asks whether the rule could cover any resource at all,
+// which is how route-level checks run before the resource is loaded.
+func (r Rule) Covers(subject *Subject, resource *Resource) bool {
+    if resource == nil {
+        return true
+    }
+
+    switch scope := r.scope(); {
+    case scope == ScopeTenant:
+        return true
+    case scope == ScopeOwn:
+        return subject.UserID != "" && resource.OwnerID == subject.UserID
+    case scope == ScopeTeam:
+        if subject.UserID != "" && resource.OwnerID == subject.UserID {
+            return true
+        }
+        return subject.TeamID != "" && resource.TeamID == subject.TeamID
+    case strings.HasPrefix(scope, casePrefix):
+        return resource.CaseReference != "" && resource.CaseReference == strings.TrimPrefix(scope, casePrefix)
+    default:
+        return false
+    }
+}
+
+// Role is a tenant's named set of rules. Roles inherit the rules of the
+// roles they name in Inherits, within the same tenant.
+type Role struct {
+    ID           string   `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+    TenantID     string   `json:"tenant_id" gorm:"type:uuid;not null"`
+    Name         string   `json:"name" gorm:"not null"`
+    Inherits     []string `json:"inherits" gorm:"serializer:json;type:jsonb"`
+    Rules        []Rule   `json:"rules" gorm:"serializer:json;type:jsonb"`
+    IsSystemRole bool     `json:"is_system_role"`
+}
+
+func (Role) TableName() string {
+    return "roles"
+}
+
+// Subject is who is asking
+type Subject struct {
+    TenantID string `json:"tenant_id"`
+    UserID   string `json:"user_id,omitempty"`
+    TeamID   string `json:"team_id,omitempty"`
+    Role     string `json:"role"`
+}
+
+// Resource is what is being acted on. Fields the resource lacks stay empty
+// and are never matched.
+type Resource struct {
+    Type          string `json:"type"` // job, report, bulk_job, ...
+    ID            string `json:"id,omitempty"`
+    TenantID      string `json:"tenant_id"`
+    OwnerID       string `json:"owner_id,omitempty"`
+    TeamID        string `json:"team_id,omitempty"`
+    CaseReference string `json:"case_reference,omitempty"`
+}
+
+// Decision is the engine's answer, with enough detail to explain it
+type Decision struct {
+    Allowed    bool      `json:"allowed"`
+    Permission string    `json:"permission"`
+    Subject    Subject   `json:"subject"`
+    Resource   *Resource `json:"resource,omitempty"`
+    Reason     string    `json:"reason"`
+    Rule       *Rule     `json:"rule,omitempty"`      // The rule that decided
+    RuleRole   string    `json:"rule_role,omitempty"` // The role that rule came from
+    Roles      []string  `json:"roles"`               // The role and all it inherits
+    Evaluated  []string  `json:"evaluated,omitempty"` // Rules considered, in order
+}
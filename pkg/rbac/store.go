@@ -0,0 +1,79 @@
+// pkg/rbac/store.go
+package rbac
+
+import (
+    "context"
+    "errors"
+    "fmt"
+
+    "gorm.io/gorm"
+    "gorm.io/gorm/clause"
+    "secure-iran-intel/pkg/tenancy"
+)
+
+// Store looks up roles and subjects within a tenant. Nothing is ever looked
+// up across tenants.
+type Store interface {
+    // Role returns ErrUnknownRole when the tenant has no such role
+    Role(ctx context.Context, tenantID, name string) (*Role, error)
+    // Subject returns ErrUnknownSubject for unknown or inactive users
+    Subject(ctx context.Context, tenantID, userID string) (*Subject, error)
+}
+
+// GormStore reads the roles and users tables (migrations 007 and 012)
+type GormStore struct {
+    db *gorm.DB
+}
+
+func NewGormStore(db *gorm.DB) *GormStore {
+    return &GormStore{db: db}
+}
+
+func (s *GormStore) Role(ctx context.Context, tenantID, name string) (*Role, error) {
+    var role Role
+    err := tenancy.Transaction(ctx, s.db, tenantID, func(tx *gorm.DB) error {
+        return tx.Where("tenant_id = ? AND name = ?", tenantID, name).First(&role).Error
+    })
+    if errors.Is(err, gorm.ErrRecordNotFound) {
+        return nil, ErrUnknownRole
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to load role: %w", err)
+    }
+    return &role, nil
+}
+
+func (s *GormStore) Subject(ctx context.Context, tenantID, userID string) (*Subject, error) {
+    var subject Subject
+    err := tenancy.Transaction(ctx, s.db, tenantID, func(tx *gorm.DB) error {
+        return tx.Table("users").
+            Select("tenant_id, id AS user_id, COALESCE(team_id::text, '') AS team_id, role").
+            Where("id = ? AND tenant_id = ? AND is_active", userID, tenantID).
+            Take(&subject).Error
+    })
+    if errors.Is(err, gorm.ErrRecordNotFound) {
+        return nil, ErrUnknownSubject
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to load user: %w", err)
+    }
+    return &subject, nil
+}
+
+// SaveRoles creates roles, replacing the rules of any the tenant already
+// has under the same name
+func (s *GormStore) SaveRoles(ctx context.Context, roles []Role) error {
+    for i := range roles {
+        role := &roles[i]
+        err := tenancy.Transaction(ctx, s.db, role.TenantID, func(tx *gorm.DB) error {
+            return tx.Clauses(clause.OnConflict{
+                Columns:   []clause.Column{{Name: "tenant_id"}, {Name: "name"}},
+                DoUpdates: clause.AssignmentColumns([]string{"inherits", "rules", "is_system_role"}),
+            }).Create(role).Error
+        })
+        if err != nil {
+            return fmt.Errorf("failed to save role %q: %w", role.Name, err)
+        }
+    }
+    return nil
+}
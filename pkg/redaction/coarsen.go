@@ -0,0 +1,86 @@
+// pkg/redaction/coarsen.go
+package redaction
+
+import (
+    "strings"
+    "time"
+)
+
+// Coarseners replace a value with a less precise one of the same JSON
+// type where they can, so the report still decodes. Values they do not
+// understand are emptied rather than passed through.
+var Coarseners = map[string]func(interface{}) interface{}{
+    "region": Region,
+    "year":   Year,
+    "domain": Domain,
+    "bucket": Bucket,
+}
+
+// Region keeps the province of an address. Address objects keep only
+// their region, province or state; address strings only their last
+// comma-separated part, which is where addresses here put it.
+func Region(value interface{}) interface{} {
+    switch v := value.(type) {
+    case map[string]interface{}:
+        for _, key := range []string{"region", "province", "state"} {
+            if region, ok := v[key]; ok {
+                return map[string]interface{}{key: region}
+            }
+        }
+        return map[string]interface{}{}
+    case string:
+        // A string with no parts may be anything, so it is not kept
+        at := strings.LastIndex(v, ",")
+        if at < 0 {
+            return nil
+        }
+        return strings.TrimSpace(v[at+1:])
+    default:
+        return nil
+    }
+}
+
+// Year keeps the year of a date. RFC 3339 timestamps become the first
+// instant of their year, so they still decode as times.
+func Year(value interface{}) interface{} {
+    s, ok := value.(string)
+    if !ok {
+        return nil
+    }
+    if t, err := time.Parse(time.RFC3339, s); err == nil {
+        return time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, time.UTC).Format(time.RFC3339)
+    }
+    if len(s) >= 4 {
+        return s[:4]
+    }
+    return nil
+}
+
+// Domain keeps the domain of an email address
+func Domain(value interface{}) interface{} {
+    s, ok := value.(string)
+    if !ok {
+        return nil
+    }
+    if at := strings.LastIndex(s, "@"); at >= 0 {
+        return "@" + s[at+1:]
+    }
+    return nil
+}
+
+// Bucket rounds a count down to a power of ten: counts under ten read 0,
+// 10 to 99 read 10, and so on
+func Bucket(value interface{}) interface{} {
+    n, ok := value.(float64)
+    if !ok {
+        return nil
+    }
+    if n < 10 {
+        return float64(0)
+    }
+    bucket := float64(10)
+    for bucket*10 <= n {
+        bucket *= 10
+    }
+    return bucket
+}
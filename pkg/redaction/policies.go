@@ -0,0 +1,81 @@
+// pkg/redaction/policies.go
+package redaction
+
+import (
+    "encoding/json"
+    "fmt"
+    "os"
+
+    "secure-iran-intel/pkg/purpose"
+)
+
+// DefaultPolicies limit what a report shows to what the job's purpose
+// needs. Administrators, of a tenant or of the platform, see raw data;
+// nobody else does.
+var DefaultPolicies = []Policy{
+    {
+        Name:   "raw-data-administrators-only",
+        Exempt: []string{"super_admin", "admin"},
+        Reason: "Raw collected data is only shown to administrators",
+        Rules: []Rule{
+            {Path: "raw_data", Action: ActionDrop},
+        },
+    },
+    {
+        Name:       "consent-and-legitimate-interest",
+        LegalBases: []string{purpose.BasisConsent, purpose.BasisLegitimateInterest},
+        Reason:     "Social and behavioural profiling is beyond the purpose of consent and legitimate-interest jobs",
+        Rules: []Rule{
+            {Path: "social_graph", Action: ActionDrop},
+            {Path: "behavioral_analysis", Action: ActionDrop},
+            {Path: "**.address", Action: ActionCoarsen, Coarsener: "region"},
+            {Path: "**.date_of_birth", Action: ActionCoarsen, Coarsener: "year"},
+        },
+    },
+    {
+        Name:       "no-recorded-legal-basis",
+        LegalBases: []string{UnknownBasis},
+        Reason:     "The report's job recorded no legal basis",
+        Rules: []Rule{
+            {Path: "social_graph", Action: ActionDrop},
+            {Path: "behavioral_analysis", Action: ActionDrop},
+            {Path: "email_discovery", Action: ActionDrop},
+            {Path: "**.address", Action: ActionCoarsen, Coarsener: "region"},
+        },
+    },
+    {
+        Name:   "viewers",
+        Exempt: []string{"super_admin", "admin", "user"},
+        Reason: "The viewer role reads findings, not identifiers",
+        Rules: []Rule{
+            {Path: "phone_number", Action: ActionMask},
+            {Path: "**.email", Action: ActionCoarsen, Coarsener: "domain"},
+            {Path: "**.emails.*", Action: ActionCoarsen, Coarsener: "domain"},
+            {Path: "**.address", Action: ActionCoarsen, Coarsener: "region"},
+            {Path: "**.username", Action: ActionMask},
+            {Path: "**.profile_url", Action: ActionDrop},
+        },
+    },
+}
+
+// Default returns the set of DefaultPolicies
+func Default() *Set {
+    set, err := NewSet(DefaultPolicies...)
+    if err != nil {
+        panic(err) // The defaults are fixed; a bad one is a programming error
+    }
+    return set
+}
+
+// LoadFile reads a JSON array of policies, replacing the defaults
+func LoadFile(path string) (*Set, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, fmt.Errorf("failed to read redaction policies: %w", err)
+    }
+    var policies []Policy
+    if err := json.Unmarshal(data, &policies); err != nil {
+        return nil, fmt.Errorf("failed to parse redaction policies: %w", err)
+    }
+    return NewSet(policies...)
+}
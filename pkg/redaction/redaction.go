@@ -0,0 +1,278 @@
+// pkg/redaction/redaction.go
+package redaction
+
+import (
+    "encoding/json"
+    "fmt"
+    "sort"
+    "strings"
+)
+
+// What a rule does to the values its path matches
+const (
+    ActionDrop    = "drop"    // The value is removed
+    ActionMask    = "mask"    // Strings keep their last characters; other values are emptied
+    ActionCoarsen = "coarsen" // The value is replaced by a coarser one; see Coarseners
+)
+
+// UnknownBasis is the legal basis of reports that recorded none. Policies
+// list it to cover them.
+const UnknownBasis = "unknown"
+
+// Rule withholds the values at Path. Paths are dot-separated JSON keys;
+// "*" matches every key or array element at one level and "**" any number
+// of levels, so "**.address" is every address in the document.
+type Rule struct {
+    Path      string `json:"path"`
+    Action    string `json:"action"`
+    Coarsener string `json:"coarsener,omitempty"` // For ActionCoarsen
+}
+
+// Policy is a set of rules for reports on jobs run under some legal bases.
+// It applies to every viewer except those holding one of the Exempt roles,
+// so custom roles are redacted until a policy says otherwise.
+type Policy struct {
+    Name       string   `json:"name"`
+    LegalBases []string `json:"legal_bases,omitempty"` // Empty for every basis
+    Exempt     []string `json:"exempt_roles,omitempty"`
+    Reason     string   `json:"reason"`
+    Rules      []Rule   `json:"rules"`
+}
+
+// AppliesTo reports whether the policy covers a report under legalBasis
+// shown to a viewer with role
+func (p *Policy) AppliesTo(legalBasis, role string) bool {
+    for _, exempt := range p.Exempt {
+        if exempt == role {
+            return false
+        }
+    }
+    if len(p.LegalBases) == 0 {
+        return true
+    }
+    for _, basis := range p.LegalBases {
+        if basis == legalBasis {
+            return true
+        }
+    }
+    return false
+}
+
+// Validate checks every rule names a known action and coarsener
+func (p *Policy) Validate() error {
+    if p.Name == "" || p.Reason == "" {
+        return fmt.Errorf("redaction policy needs a name and a reason")
+    }
+    for _, r := range p.Rules {
+        if r.Path == "" {
+            return fmt.Errorf("redaction policy %s: rule without a path", p.Name)
+        }
+        switch r.Action {
+        case ActionDrop, ActionMask:
+        case ActionCoarsen:
+            if _, ok := Coarseners[r.Coarsener]; !ok {
+                return fmt.Errorf("redaction policy %s: unknown coarsener %q", p.Name, r.Coarsener)
+            }
+        default:
+            return fmt.Errorf("redaction policy %s: unknown action %q", p.Name, r.Action)
+        }
+    }
+    return nil
+}
+
+// Withheld is what one rule of one policy did to a rendered report
+type Withheld struct {
+    Path   string `json:"path"`
+    Action string `json:"action"`
+    Count  int    `json:"count"` // Values matched
+    Policy string `json:"policy"`
+    Reason string `json:"reason"`
+}
+
+// Manifest travels with a rendered report and says what was withheld from
+// it and why. Rules that matched nothing are not listed.
+type Manifest struct {
+    LegalBasis string     `json:"legal_basis"`
+    Role       string     `json:"viewer_role"`
+    Policies   []string   `json:"policies"`
+    Withheld   []Withheld `json:"withheld"`
+}
+
+// Set is the policies a service renders with
+type Set struct {
+    policies []Policy
+}
+
+// NewSet returns a set of policies, refusing any that do not validate
+func NewSet(policies ...Policy) (*Set, error) {
+    for i := range policies {
+        if err := policies[i].Validate(); err != nil {
+            return nil, err
+        }
+    }
+    return &Set{policies: policies}, nil
+}
+
+// Apply withholds from doc, a report decoded from JSON, what the policies
+// covering legalBasis and role call for, and returns the manifest
+func (s *Set) Apply(doc map[string]interface{}, legalBasis, role string) *Manifest {
+    if legalBasis == "" {
+        legalBasis = UnknownBasis
+    }
+    m := &Manifest{LegalBasis: legalBasis, Role: role, Policies: []string{}, Withheld: []Withheld{}}
+    for _, p := range s.policies {
+        if !p.AppliesTo(legalBasis, role) {
+            continue
+        }
+        m.Policies = append(m.Policies, p.Name)
+        for _, r := range p.Rules {
+            count := apply(doc, strings.Split(r.Path, "."), r)
+            if count > 0 {
+                m.Withheld = append(m.Withheld, Withheld{Path: r.Path, Action: r.Action, Count: count, Policy: p.Name, Reason: p.Reason})
+            }
+        }
+    }
+    return m
+}
+
+// Render applies the policies to v, which must encode as a JSON object,
+// and decodes the result into out. Dropped and emptied values decode as
+// zero values, so out may be the type v was.
+func (s *Set) Render(v interface{}, out interface{}, legalBasis, role string) (*Manifest, error) {
+    encoded, err := json.Marshal(v)
+    if err != nil {
+        return nil, err
+    }
+    var doc map[string]interface{}
+    if err := json.Unmarshal(encoded, &doc); err != nil {
+        return nil, fmt.Errorf("redaction needs a JSON object: %w", err)
+    }
+    m := s.Apply(doc, legalBasis, role)
+    if encoded, err = json.Marshal(doc); err != nil {
+        return nil, err
+    }
+    if err := json.Unmarshal(encoded, out); err != nil {
+        return nil, fmt.Errorf("failed to decode redacted report: %w", err)
+    }
+    return m, nil
+}
+
+// apply runs r on every value under node that path matches and returns
+// how many it changed
+func apply(node interface{}, path []string, r Rule) int {
+    if len(path) == 0 {
+        return 0
+    }
+    key, rest := path[0], path[1:]
+
+    if key == "**" {
+        // Match rest here, then at every level below
+        count := apply(node, rest, r)
+        for _, child := range children(node) {
+            count += apply(child, path, r)
+        }
+        return count
+    }
+
+    switch n := node.(type) {
+    case map[string]interface{}:
+        keys := []string{key}
+        if key == "*" {
+            keys = sortedKeys(n)
+        }
+        count := 0
+        for _, k := range keys {
+            value, ok := n[k]
+            if !ok {
+                continue
+            }
+            if len(rest) > 0 {
+                count += apply(value, rest, r)
+                continue
+            }
+            if value == nil {
+                continue
+            }
+            if r.Action == ActionDrop {
+                delete(n, k)
+            } else {
+                n[k] = redact(value, r)
+            }
+            count++
+        }
+        return count
+    case []interface{}:
+        if key != "*" {
+            return 0
+        }
+        count := 0
+        for i, value := range n {
+            if len(rest) > 0 {
+                count += apply(value, rest, r)
+                continue
+            }
+            if value == nil {
+                continue
+            }
+            // Elements cannot be removed without shifting the others
+            if r.Action == ActionDrop {
+                n[i] = nil
+            } else {
+                n[i] = redact(value, r)
+            }
+            count++
+        }
+        return count
+    default:
+        return 0
+    }
+}
+
+func redact(value interface{}, r Rule) interface{} {
+    switch r.Action {
+    case ActionMask:
+        return Mask(value)
+    case ActionCoarsen:
+        return Coarseners[r.Coarsener](value)
+    default:
+        return nil
+    }
+}
+
+// Mask hides a value. Strings keep their last four characters when they
+// are long enough to stay unrecognizable; everything else is emptied.
+func Mask(value interface{}) interface{} {
+    s, ok := value.(string)
+    if !ok {
+        return nil
+    }
+    runes := []rune(s)
+    if len(runes) <= 8 {
+        return strings.Repeat("•", len(runes))
+    }
+    return strings.Repeat("•", len(runes)-4) + string(runes[len(runes)-4:])
+}
+
+func children(node interface{}) []interface{} {
+    switch n := node.(type) {
+    case map[string]interface{}:
+        out := make([]interface{}, 0, len(n))
+        for _, k := range sortedKeys(n) {
+            out = append(out, n[k])
+        }
+        return out
+    case []interface{}:
+        return n
+    default:
+        return nil
+    }
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+    keys := make([]string, 0, len(m))
+    for k := range m {
+        keys = append(keys, k)
+    }
+    sort.Strings(keys)
+    return keys
+}
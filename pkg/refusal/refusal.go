@@ -0,0 +1,68 @@
+// pkg/refusal/refusal.go
+package refusal
+
+import (
+    "errors"
+    "fmt"
+    "net/http"
+)
+
+// Reason is a sentinel for one way a request can be refused. It carries
+// the stable, machine-readable code API responses report and the HTTP
+// status they are sent with. Match reasons with errors.Is.
+type Reason struct {
+    message string
+    code    string
+    status  int
+}
+
+// New returns a reason with its response code and status
+func New(code string, status int, message string) *Reason {
+    return &Reason{message: message, code: code, status: status}
+}
+
+func (r *Reason) Error() string {
+    return r.message
+}
+
+// Code is the reason's stable code
+func (r *Reason) Code() string {
+    return r.code
+}
+
+// Error describes one refusal: its reason, what was refused and why
+type Error struct {
+    Reason error  // A *Reason
+    ID     string // The key, case, request, tenant... that was refused
+    Detail string
+}
+
+func (e *Error) Error() string {
+    if e.Detail != "" {
+        return fmt.Sprintf("%s: %s", e.Reason, e.Detail)
+    }
+    return e.Reason.Error()
+}
+
+func (e *Error) Unwrap() error {
+    return e.Reason
+}
+
+// Code maps err to the code of the reason it wraps, or "" for other errors
+func Code(err error) string {
+    var r *Reason
+    if errors.As(err, &r) {
+        return r.code
+    }
+    return ""
+}
+
+// Status is the response status for err: its reason's, or 500 for errors
+// that wrap none
+func Status(err error) int {
+    var r *Reason
+    if errors.As(err, &r) {
+        return r.status
+    }
+    return http.StatusInternalServerError
+}
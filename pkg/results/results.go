@@ -0,0 +1,97 @@
+// pkg/results/results.go
+package results
+
+import (
+    "encoding/json"
+    "strings"
+    "time"
+)
+
+// Rows of the result tables the intelligence engine fills (migration 006).
+// Every value naming or describing the subject is sealed with the tenant's
+// data key (migration 020); the *Index fields are the blind indexes lookups
+// go through, filled in by the store. Data columns hold JSON the caller
+// encodes.
+
+// EmailDiscovery is an address found for a phone number
+type EmailDiscovery struct {
+    ID               string `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+    TenantID         string `gorm:"type:uuid"`
+    PhoneNumber      string `gorm:"serializer:encrypted"`
+    PhoneNumberIndex string
+    Email            string `gorm:"serializer:encrypted"`
+    EmailIndex       string
+    Confidence       float64
+    Source           string
+    PatternType      string
+    FoundInBreach    bool
+    SocialProfiles   json.RawMessage `gorm:"serializer:encrypted"`
+    Verified         bool
+    CaseReference    string // Lets a legal hold on the case cover the row
+    DiscoveredAt     time.Time
+}
+
+func (EmailDiscovery) TableName() string {
+    return "email_discovery_results"
+}
+
+// SocialGraph is the graph of accounts and people around a phone number
+type SocialGraph struct {
+    ID               string `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+    TenantID         string `gorm:"type:uuid"`
+    PhoneNumber      string `gorm:"serializer:encrypted"`
+    PhoneNumberIndex string
+    GraphData        json.RawMessage `gorm:"serializer:encrypted"`
+    NodeCount        int
+    EdgeCount        int
+    Density          float64
+    CentralNode      string `gorm:"serializer:encrypted"`
+    CaseReference    string
+    GeneratedAt      time.Time
+}
+
+func (SocialGraph) TableName() string {
+    return "social_graphs"
+}
+
+// BehavioralPattern is activity seen for an account on one platform
+type BehavioralPattern struct {
+    ID                  string `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+    TenantID            string `gorm:"type:uuid"`
+    UserIdentifier      string `gorm:"serializer:encrypted"`
+    UserIdentifierIndex string
+    Platform            string
+    ActivityType        string
+    PatternData         json.RawMessage `gorm:"serializer:encrypted"`
+    Confidence          float64
+    AnomalyScore        float64
+    CaseReference       string
+    DetectedAt          time.Time
+}
+
+func (BehavioralPattern) TableName() string {
+    return "behavioral_patterns"
+}
+
+// RiskAssessment scores a phone number
+type RiskAssessment struct {
+    ID               string `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+    TenantID         string `gorm:"type:uuid"`
+    PhoneNumber      string `gorm:"serializer:encrypted"`
+    PhoneNumberIndex string
+    OverallScore     float64
+    RiskLevel        string
+    Factors          json.RawMessage `gorm:"serializer:encrypted"`
+    Confidence       float64
+    CaseReference    string
+    AssessedAt       time.Time
+}
+
+func (RiskAssessment) TableName() string {
+    return "risk_assessments"
+}
+
+// NormalizeEmail is the form of an address its blind index is taken of
+func NormalizeEmail(email string) string {
+    return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,100 @@
+// pkg/results/store.go
+package results
+
+import (
+    "context"
+    "fmt"
+
+    "gorm.io/gorm"
+    "gorm.io/gorm/clause"
+    "secure-iran-intel/pkg/envelope"
+    "secure-iran-intel/pkg/tenancy"
+)
+
+// Result is a row of one of the result tables: *EmailDiscovery,
+// *SocialGraph, *BehavioralPattern or *RiskAssessment
+type Result interface {
+    // prepare sets the row's tenant and fills in its blind indexes
+    prepare(tenantID string, index func(value string) (string, error)) error
+}
+
+// rediscovered refreshes an address found again for the same number
+var rediscovered = clause.OnConflict{
+    Columns:     []clause.Column{{Name: "tenant_id"}, {Name: "phone_number_index"}, {Name: "email_index"}},
+    TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "phone_number_index IS NOT NULL AND email_index IS NOT NULL"}}},
+    DoUpdates: clause.AssignmentColumns([]string{
+        "confidence", "source", "pattern_type", "found_in_breach", "social_profiles", "verified", "case_reference", "discovered_at",
+    }),
+}
+
+// GormStore writes results to the tables of migration 006. The encrypted
+// serializer needs envelope.Register to have been called with keys.
+type GormStore struct {
+    db   *gorm.DB
+    keys *envelope.Keyring
+}
+
+func NewGormStore(db *gorm.DB, keys *envelope.Keyring) *GormStore {
+    return &GormStore{db: db, keys: keys}
+}
+
+// Save stores results for the tenant. Phone numbers must be normalized to
+// E.164, so the blind indexes match the ones lookups compute.
+func (s *GormStore) Save(ctx context.Context, tenantID string, results ...Result) error {
+    index := func(value string) (string, error) {
+        return s.keys.BlindIndex(ctx, tenantID, value)
+    }
+    for _, r := range results {
+        if err := r.prepare(tenantID, index); err != nil {
+            return fmt.Errorf("failed to index result: %w", err)
+        }
+    }
+
+    err := tenancy.Transaction(ctx, s.db, tenantID, func(tx *gorm.DB) error {
+        for _, r := range results {
+            create := tx
+            if _, ok := r.(*EmailDiscovery); ok {
+                create = tx.Clauses(rediscovered)
+            }
+            if err := create.Create(r).Error; err != nil {
+                return err
+            }
+        }
+        return nil
+    })
+    if err != nil {
+        return fmt.Errorf("failed to store results: %w", err)
+    }
+    return nil
+}
+
+func (r *EmailDiscovery) prepare(tenantID string, index func(string) (string, error)) error {
+    var err error
+    r.TenantID = tenantID
+    if r.PhoneNumberIndex, err = index(r.PhoneNumber); err != nil {
+        return err
+    }
+    r.EmailIndex, err = index(NormalizeEmail(r.Email))
+    return err
+}
+
+func (r *SocialGraph) prepare(tenantID string, index func(string) (string, error)) error {
+    var err error
+    r.TenantID = tenantID
+    r.PhoneNumberIndex, err = index(r.PhoneNumber)
+    return err
+}
+
+func (r *BehavioralPattern) prepare(tenantID string, index func(string) (string, error)) error {
+    var err error
+    r.TenantID = tenantID
+    r.UserIdentifierIndex, err = index(r.UserIdentifier)
+    return err
+}
+
+func (r *RiskAssessment) prepare(tenantID string, index func(string) (string, error)) error {
+    var err error
+    r.TenantID = tenantID
+    r.PhoneNumberIndex, err = index(r.PhoneNumber)
+    return err
+}
@@ -0,0 +1,73 @@
+// pkg/retention/retention.go
+package retention
+
+import (
+    "net/http"
+    "time"
+
+    "secure-iran-intel/pkg/refusal"
+)
+
+// What happens to data past a tenant's retention period, chosen by the
+// retention_mode tenant setting
+const (
+    ModeDelete = "delete" // Rows, files and keys are removed (the default)
+    ModeRedact = "redact" // Rows are kept for statistics with every identifier overwritten
+)
+
+// Policy is how long one tenant keeps data, from the data_retention_days
+// and retention_mode settings. Tenants without data_retention_days are not
+// swept.
+type Policy struct {
+    TenantID string
+    Days     int
+    Mode     string
+}
+
+// Period is the retention period as a duration
+func (p Policy) Period() time.Duration {
+    return time.Duration(p.Days) * 24 * time.Hour
+}
+
+// Cutoff is the time before which data has expired
+func (p Policy) Cutoff(now time.Time) time.Time {
+    return now.Add(-p.Period())
+}
+
+// LegalHold pauses retention for everything recorded under one case until
+// it is released
+type LegalHold struct {
+    ID            string     `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+    TenantID      string     `json:"tenant_id" gorm:"type:uuid;not null"`
+    CaseReference string     `json:"case_reference" gorm:"not null"`
+    Reason        string     `json:"reason"`
+    PlacedBy      string     `json:"placed_by"`
+    PlacedAt      time.Time  `json:"placed_at"`
+    ReleasedBy    string     `json:"released_by,omitempty"`
+    ReleasedAt    *time.Time `json:"released_at,omitempty"`
+}
+
+func (LegalHold) TableName() string {
+    return "legal_holds"
+}
+
+// Active reports whether the hold still pauses deletion
+func (h *LegalHold) Active() bool {
+    return h.ReleasedAt == nil
+}
+
+// Removal is what one sweeper did to one kind of expired data
+type Removal struct {
+    Store  string `json:"store"`  // postgres, files, redis
+    Kind   string `json:"kind"`   // Table, file class or key class
+    Action string `json:"action"` // deleted, redacted or expiry_capped
+    Count  int64  `json:"count"`
+}
+
+// Refusal reasons. Match them with errors.Is.
+var (
+    ErrUnknownHold    = refusal.New("LEGAL_HOLD_NOT_FOUND", http.StatusNotFound, "legal hold not found")
+    ErrHoldExists     = refusal.New("LEGAL_HOLD_EXISTS", http.StatusConflict, "the case is already under a legal hold")
+    ErrHoldReleased   = refusal.New("LEGAL_HOLD_RELEASED", http.StatusConflict, "legal hold has already been released")
+    ErrReasonRequired = refusal.New("RETENTION_INVALID", http.StatusBadRequest, "a reason is required")
+)
@@ -0,0 +1,191 @@
+// pkg/retention/service.go
+package retention
+
+import (
+    "context"
+    "errors"
+    "log"
+    "strconv"
+    "strings"
+    "time"
+
+    "secure-iran-intel/pkg/audit"
+    "secure-iran-intel/pkg/purpose"
+    "secure-iran-intel/pkg/rbac"
+    "secure-iran-intel/pkg/refusal"
+)
+
+// Service enforces each tenant's data_retention_days and keeps the legal
+// holds that pause it
+type Service struct {
+    store    Store
+    cases    purpose.CaseStore
+    sweepers []Sweeper
+    auditLog *audit.Logger
+    policy   *rbac.Engine
+}
+
+// NewService returns a service that sweeps every store in sweepers
+func NewService(store Store, cases purpose.CaseStore, auditLog *audit.Logger, policy *rbac.Engine, sweepers ...Sweeper) *Service {
+    return &Service{
+        store:    store,
+        cases:    cases,
+        sweepers: sweepers,
+        auditLog: auditLog,
+        policy:   policy,
+    }
+}
+
+// Run sweeps every tenant each interval until ctx is cancelled
+func (s *Service) Run(ctx context.Context, interval time.Duration) {
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+            if err := s.SweepAll(ctx); err != nil {
+                log.Printf("⚠️ Retention sweep failed: %v", err)
+            }
+        }
+    }
+}
+
+// SweepAll sweeps every tenant with a retention period. One tenant's
+// failure does not stop the others; the last error is returned.
+func (s *Service) SweepAll(ctx context.Context) error {
+    policies, err := s.store.Policies(ctx)
+    if err != nil {
+        return err
+    }
+
+    var last error
+    for _, p := range policies {
+        if _, err := s.Sweep(ctx, p, time.Now()); err != nil {
+            log.Printf("⚠️ Retention sweep of tenant %s failed: %v", p.TenantID, err)
+            last = err
+        }
+    }
+    return last
+}
+
+// Sweep deletes or redacts what the tenant recorded before the policy's
+// cutoff, except under cases on legal hold, and records the purge in the
+// audit log with what each store removed
+func (s *Service) Sweep(ctx context.Context, p Policy, now time.Time) ([]Removal, error) {
+    holds, err := s.store.ActiveHolds(ctx, p.TenantID)
+    if err != nil {
+        return nil, err
+    }
+    held := make([]string, 0, len(holds))
+    for _, h := range holds {
+        held = append(held, h.CaseReference)
+    }
+
+    cutoff := p.Cutoff(now)
+    var removed []Removal
+    var sweepErr error
+    for _, sw := range s.sweepers {
+        r, err := sw.Sweep(ctx, p, cutoff, held)
+        if err != nil {
+            // Record what the other stores did before giving up
+            sweepErr = err
+            continue
+        }
+        removed = append(removed, r...)
+    }
+
+    var total int64
+    metadata := map[string]string{
+        "tenant_id":      p.TenantID,
+        "mode":           p.Mode,
+        "retention_days": strconv.Itoa(p.Days),
+        "cutoff":         cutoff.UTC().Format(time.RFC3339),
+        "held_cases":     strconv.Itoa(len(held)),
+    }
+    for _, r := range removed {
+        if r.Count == 0 {
+            continue
+        }
+        total += r.Count
+        metadata[r.Store+"."+r.Kind+"."+r.Action] = strconv.FormatInt(r.Count, 10)
+    }
+    if total > 0 {
+        err := s.auditLog.Log(ctx, audit.Entry{
+            Action:     audit.ActionRetentionPurge,
+            TargetType: "tenant",
+            Target:     p.TenantID,
+            Actor:      &audit.Actor{TenantID: p.TenantID, Type: audit.ActorSystem, ID: "retention"},
+            Metadata:   metadata,
+        })
+        if err != nil {
+            return removed, err
+        }
+    }
+    return removed, sweepErr
+}
+
+// PlaceHold stops retention deleting anything recorded under the actor's
+// tenant's case until the hold is released
+func (s *Service) PlaceHold(ctx context.Context, caseReference, reason string) (*LegalHold, error) {
+    actor := audit.ActorFromContext(ctx)
+    if err := s.policy.Require(ctx, actor, rbac.PermLegalHolds, nil); err != nil {
+        return nil, err
+    }
+    reason = strings.TrimSpace(reason)
+    if reason == "" {
+        return nil, &refusal.Error{Reason: ErrReasonRequired, ID: caseReference}
+    }
+    // Closed cases can be held too; their data is what retention would take
+    if _, err := s.cases.GetCase(ctx, actor.TenantID, caseReference); err != nil {
+        if errors.Is(err, purpose.ErrUnknownCase) {
+            return nil, &refusal.Error{Reason: purpose.ErrUnknownCase, ID: caseReference}
+        }
+        return nil, err
+    }
+
+    err := s.auditLog.Log(ctx, audit.Entry{
+        Action:     audit.ActionLegalHoldPlace,
+        TargetType: "case",
+        Target:     caseReference,
+        Metadata:   map[string]string{"reason": reason},
+    })
+    if err != nil {
+        return nil, err
+    }
+    h := &LegalHold{
+        TenantID:      actor.TenantID,
+        CaseReference: caseReference,
+        Reason:        reason,
+        PlacedBy:      actor.ID,
+        PlacedAt:      time.Now(),
+    }
+    if err := s.store.PlaceHold(ctx, h); err != nil {
+        return nil, err
+    }
+    return h, nil
+}
+
+// ReleaseHold lets retention resume for the hold's case. Data already past
+// its period goes at the next sweep.
+func (s *Service) ReleaseHold(ctx context.Context, holdID string) (*LegalHold, error) {
+    actor := audit.ActorFromContext(ctx)
+    if err := s.policy.Require(ctx, actor, rbac.PermLegalHolds, nil); err != nil {
+        return nil, err
+    }
+    if err := s.auditLog.Log(ctx, audit.Entry{Action: audit.ActionLegalHoldRelease, TargetType: "legal_hold", Target: holdID}); err != nil {
+        return nil, err
+    }
+    return s.store.ReleaseHold(ctx, actor.TenantID, holdID, actor.ID)
+}
+
+// ListHolds returns the tenant's holds, released ones included
+func (s *Service) ListHolds(ctx context.Context) ([]LegalHold, error) {
+    actor := audit.ActorFromContext(ctx)
+    if err := s.policy.Require(ctx, actor, rbac.PermLegalHolds, nil); err != nil {
+        return nil, err
+    }
+    return s.store.ListHolds(ctx, actor.TenantID)
+}
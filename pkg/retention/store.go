@@ -0,0 +1,138 @@
+// pkg/retention/store.go
+package retention
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "strconv"
+    "time"
+
+    "gorm.io/gorm"
+    "secure-iran-intel/pkg/refusal"
+    "secure-iran-intel/pkg/tenancy"
+)
+
+// Store reads retention policies and keeps legal holds. PlaceHold must
+// refuse a second active hold on the same case, and ReleaseHold must only
+// release an active one, so two reviewers cannot race each other.
+type Store interface {
+    // Policies returns the policy of every tenant with data to sweep
+    Policies(ctx context.Context) ([]Policy, error)
+    ActiveHolds(ctx context.Context, tenantID string) ([]LegalHold, error)
+    ListHolds(ctx context.Context, tenantID string) ([]LegalHold, error)
+    // PlaceHold returns ErrHoldExists when the case is already held
+    PlaceHold(ctx context.Context, h *LegalHold) error
+    // ReleaseHold returns ErrUnknownHold or ErrHoldReleased
+    ReleaseHold(ctx context.Context, tenantID, holdID, releasedBy string) (*LegalHold, error)
+}
+
+// GormStore reads tenant settings from the tenants table (migration 007)
+// and keeps holds in legal_holds (migration 019)
+type GormStore struct {
+    db *gorm.DB
+}
+
+func NewGormStore(db *gorm.DB) *GormStore {
+    return &GormStore{db: db}
+}
+
+func (s *GormStore) Policies(ctx context.Context) ([]Policy, error) {
+    var rows []struct {
+        ID   string
+        Days string
+        Mode string
+    }
+    // Offboarded tenants have nothing left to sweep
+    err := s.db.WithContext(ctx).Table("tenants").
+        Select("id, settings->>'data_retention_days' AS days, COALESCE(settings->>'retention_mode', '') AS mode").
+        Where("status IN ('active', 'suspended') AND settings->>'data_retention_days' IS NOT NULL").
+        Scan(&rows).Error
+    if err != nil {
+        return nil, fmt.Errorf("failed to load retention policies: %w", err)
+    }
+
+    policies := make([]Policy, 0, len(rows))
+    for _, r := range rows {
+        days, err := strconv.Atoi(r.Days)
+        if err != nil || days <= 0 {
+            // A bad setting keeps the data rather than guessing a period
+            continue
+        }
+        mode := r.Mode
+        if mode != ModeRedact {
+            mode = ModeDelete
+        }
+        policies = append(policies, Policy{TenantID: r.ID, Days: days, Mode: mode})
+    }
+    return policies, nil
+}
+
+func (s *GormStore) ActiveHolds(ctx context.Context, tenantID string) ([]LegalHold, error) {
+    var holds []LegalHold
+    err := tenancy.Transaction(ctx, s.db, tenantID, func(tx *gorm.DB) error {
+        return tx.Where("tenant_id = ? AND released_at IS NULL", tenantID).Find(&holds).Error
+    })
+    if err != nil {
+        return nil, fmt.Errorf("failed to load legal holds: %w", err)
+    }
+    return holds, nil
+}
+
+func (s *GormStore) ListHolds(ctx context.Context, tenantID string) ([]LegalHold, error) {
+    var holds []LegalHold
+    err := tenancy.Transaction(ctx, s.db, tenantID, func(tx *gorm.DB) error {
+        return tx.Where("tenant_id = ?", tenantID).Order("placed_at DESC").Find(&holds).Error
+    })
+    return holds, err
+}
+
+func (s *GormStore) PlaceHold(ctx context.Context, h *LegalHold) error {
+    return tenancy.Transaction(ctx, s.db, h.TenantID, func(tx *gorm.DB) error {
+        var held int64
+        err := tx.Model(&LegalHold{}).
+            Where("tenant_id = ? AND case_reference = ? AND released_at IS NULL", h.TenantID, h.CaseReference).
+            Count(&held).Error
+        if err != nil {
+            return err
+        }
+        if held > 0 {
+            return &refusal.Error{Reason: ErrHoldExists, ID: h.CaseReference}
+        }
+        // The partial unique index catches a concurrent hold on the case
+        if err := tx.Create(h).Error; err != nil {
+            return fmt.Errorf("failed to place legal hold: %w", err)
+        }
+        return nil
+    })
+}
+
+func (s *GormStore) ReleaseHold(ctx context.Context, tenantID, holdID, releasedBy string) (*LegalHold, error) {
+    var h LegalHold
+    err := tenancy.Transaction(ctx, s.db, tenantID, func(tx *gorm.DB) error {
+        err := tx.Where("tenant_id = ? AND id = ?", tenantID, holdID).First(&h).Error
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            return &refusal.Error{Reason: ErrUnknownHold, ID: holdID}
+        }
+        if err != nil {
+            return err
+        }
+
+        now := time.Now()
+        result := tx.Model(&LegalHold{}).
+            Where("id = ? AND released_at IS NULL", holdID).
+            Updates(map[string]interface{}{"released_at": now, "released_by": releasedBy})
+        if result.Error != nil {
+            return fmt.Errorf("failed to release legal hold: %w", result.Error)
+        }
+        if result.RowsAffected == 0 {
+            return &refusal.Error{Reason: ErrHoldReleased, ID: holdID}
+        }
+        h.ReleasedAt, h.ReleasedBy = &now, releasedBy
+        return nil
+    })
+    if err != nil {
+        return nil, err
+    }
+    return &h, nil
+}
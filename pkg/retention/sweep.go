@@ -0,0 +1,256 @@
+// pkg/retention/sweep.go
+package retention
+
+import (
+    "context"
+    "database/sql"
+    "fmt"
+    "io/fs"
+    "log"
+    "os"
+    "path/filepath"
+    "time"
+
+    "github.com/go-redis/redis/v8"
+    "gorm.io/gorm"
+    "secure-iran-intel/pkg/tenancy"
+)
+
+// Sweeper removes or redacts one store's data recorded before cutoff.
+// Nothing recorded under a case in held may be touched.
+type Sweeper interface {
+    Sweep(ctx context.Context, policy Policy, cutoff time.Time, held []string) ([]Removal, error)
+}
+
+// target is a table of expiring rows. redact overwrites every column that
+// identifies a person; the row id is random, so 'redacted:' || id cannot
+// be traced back to the value it replaced. Blind indexes (migration 020)
+// are cleared with the values they index.
+type target struct {
+    table      string
+    timeColumn string
+    fileColumn string // Uploaded file the row points at, removed with it
+    redact     string
+}
+
+var targets = []target{
+    // 003_admin_features
+    {
+        table:      "bulk_jobs",
+        timeColumn: "created_at",
+        fileColumn: "file_path",
+        redact:     "name = '[redacted]', file_path = NULL, justification = NULL",
+    },
+    // 006_intelligence_engine
+    {
+        table:      "email_discovery_results",
+        timeColumn: "discovered_at",
+        redact:     "phone_number = 'redacted:' || id, phone_number_index = NULL, email = 'redacted:' || id, email_index = NULL, social_profiles = NULL, pattern_type = NULL",
+    },
+    {
+        table:      "social_graphs",
+        timeColumn: "generated_at",
+        redact:     "phone_number = 'redacted:' || id, phone_number_index = NULL, graph_data = '{}', central_node = NULL",
+    },
+    {
+        table:      "behavioral_patterns",
+        timeColumn: "detected_at",
+        redact:     "user_identifier = 'redacted:' || id, user_identifier_index = NULL, pattern_data = '{}'",
+    },
+    {
+        table:      "risk_assessments",
+        timeColumn: "assessed_at",
+        redact:     "phone_number = 'redacted:' || id, phone_number_index = NULL, factors = '[]'",
+    },
+    {
+        table:      "intelligence_reports",
+        timeColumn: "generated_at",
+        redact:     "phone_number = 'redacted:' || id, phone_number_index = NULL, report_data = '{}', executive_summary = NULL",
+    },
+}
+
+// PostgresSweeper deletes or redacts expired jobs, task results and
+// reports, and the files uploaded for deleted jobs
+type PostgresSweeper struct {
+    db *gorm.DB
+}
+
+func NewPostgresSweeper(db *gorm.DB) *PostgresSweeper {
+    return &PostgresSweeper{db: db}
+}
+
+func (p *PostgresSweeper) Sweep(ctx context.Context, policy Policy, cutoff time.Time, held []string) ([]Removal, error) {
+    action := "deleted"
+    if policy.Mode == ModeRedact {
+        action = "redacted"
+    }
+
+    var removed []Removal
+    var files []string
+    err := tenancy.Transaction(ctx, p.db, policy.TenantID, func(tx *gorm.DB) error {
+        for _, t := range targets {
+            where := fmt.Sprintf("tenant_id = ? AND %s < ?", t.timeColumn)
+            args := []interface{}{policy.TenantID, cutoff}
+            if len(held) > 0 {
+                where += " AND (case_reference IS NULL OR case_reference NOT IN ?)"
+                args = append(args, held)
+            }
+            if policy.Mode == ModeRedact {
+                where += " AND redacted_at IS NULL"
+            }
+
+            if t.fileColumn != "" {
+                var paths []sql.NullString
+                query := fmt.Sprintf("SELECT %s FROM %s WHERE %s AND %s IS NOT NULL", t.fileColumn, t.table, where, t.fileColumn)
+                if err := tx.Raw(query, args...).Scan(&paths).Error; err != nil {
+                    return fmt.Errorf("%s: %w", t.table, err)
+                }
+                for _, path := range paths {
+                    files = append(files, path.String)
+                }
+            }
+
+            statement := fmt.Sprintf("DELETE FROM %s WHERE %s", t.table, where)
+            if policy.Mode == ModeRedact {
+                statement = fmt.Sprintf("UPDATE %s SET %s, redacted_at = now() WHERE %s", t.table, t.redact, where)
+            }
+            result := tx.Exec(statement, args...)
+            if result.Error != nil {
+                return fmt.Errorf("%s: %w", t.table, result.Error)
+            }
+            removed = append(removed, Removal{Store: "postgres", Kind: t.table, Action: action, Count: result.RowsAffected})
+        }
+        return nil
+    })
+    if err != nil {
+        return nil, fmt.Errorf("failed to sweep expired rows: %w", err)
+    }
+
+    // Only once the rows are gone, so a failed sweep leaves no dangling path
+    var deleted int64
+    for _, path := range files {
+        if err := os.Remove(path); err != nil {
+            if !os.IsNotExist(err) {
+                log.Printf("⚠️ Failed to remove expired upload %s: %v", path, err)
+            }
+            continue
+        }
+        deleted++
+    }
+    removed = append(removed, Removal{Store: "files", Kind: "bulk_uploads", Action: "deleted", Count: deleted})
+    return removed, nil
+}
+
+// FileSweeper deletes expired report exports. Exports are written to
+// <dir>/<tenant ID>/<case reference>/; files directly under the tenant's
+// directory belong to no case and are never held.
+type FileSweeper struct {
+    dir string
+}
+
+func NewFileSweeper(dir string) *FileSweeper {
+    return &FileSweeper{dir: dir}
+}
+
+func (f *FileSweeper) Sweep(ctx context.Context, policy Policy, cutoff time.Time, held []string) ([]Removal, error) {
+    root := filepath.Join(f.dir, policy.TenantID)
+    skip := make(map[string]bool, len(held))
+    for _, ref := range held {
+        skip[filepath.Join(root, ref)] = true
+    }
+
+    var deleted int64
+    err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+        if os.IsNotExist(err) && path == root {
+            return fs.SkipDir
+        }
+        if err != nil {
+            return err
+        }
+        if err := ctx.Err(); err != nil {
+            return err
+        }
+        if d.IsDir() {
+            if skip[path] {
+                return fs.SkipDir
+            }
+            return nil
+        }
+        info, err := d.Info()
+        if err != nil {
+            return err
+        }
+        if !info.Mode().IsRegular() || !info.ModTime().Before(cutoff) {
+            return nil
+        }
+        if err := os.Remove(path); err != nil {
+            return err
+        }
+        deleted++
+        return nil
+    })
+    if err != nil {
+        return nil, fmt.Errorf("failed to sweep exports: %w", err)
+    }
+    return []Removal{{Store: "files", Kind: "exports", Action: "deleted", Count: deleted}}, nil
+}
+
+// Keys examined per SCAN round trip
+const redisBatch = 500
+
+// RedisSweeper enforces retention on the keys naming the tenant. Redis
+// keeps no creation time, so a key unused for the whole period is deleted
+// and every other key is given an expiry no later than the period. Cached
+// state is never evidence, so legal holds do not apply.
+type RedisSweeper struct {
+    redis *redis.Client
+}
+
+func NewRedisSweeper(client *redis.Client) *RedisSweeper {
+    return &RedisSweeper{redis: client}
+}
+
+func (r *RedisSweeper) Sweep(ctx context.Context, policy Policy, cutoff time.Time, held []string) ([]Removal, error) {
+    period := policy.Period()
+    var deleted, capped int64
+
+    iter := r.redis.Scan(ctx, 0, "*"+policy.TenantID+"*", redisBatch).Iterator()
+    for iter.Next(ctx) {
+        key := iter.Val()
+        idle, err := r.redis.ObjectIdleTime(ctx, key).Result()
+        if err == redis.Nil {
+            continue
+        }
+        if err != nil {
+            return nil, fmt.Errorf("failed to inspect key: %w", err)
+        }
+        if idle >= period {
+            n, err := r.redis.Del(ctx, key).Result()
+            if err != nil {
+                return nil, fmt.Errorf("failed to delete expired key: %w", err)
+            }
+            deleted += n
+            continue
+        }
+
+        ttl, err := r.redis.TTL(ctx, key).Result()
+        if err != nil {
+            return nil, fmt.Errorf("failed to inspect key: %w", err)
+        }
+        // -1 is no expiry; -2 is a key that has gone since the scan
+        if ttl == -2 || (ttl >= 0 && ttl <= period) {
+            continue
+        }
+        if err := r.redis.Expire(ctx, key, period).Err(); err != nil {
+            return nil, fmt.Errorf("failed to cap key expiry: %w", err)
+        }
+        capped++
+    }
+    if err := iter.Err(); err != nil {
+        return nil, fmt.Errorf("failed to scan tenant keys: %w", err)
+    }
+    return []Removal{
+        {Store: "redis", Kind: "keys", Action: "deleted", Count: deleted},
+        {Store: "redis", Kind: "keys", Action: "expiry_capped", Count: capped},
+    }, nil
+}
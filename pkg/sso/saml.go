@@ -0,0 +1,166 @@
+// pkg/sso/saml.go
+package sso
+
+import (
+    "encoding/xml"
+    "errors"
+    "fmt"
+    "log"
+    "net/http"
+    "net/url"
+    "strings"
+
+    "github.com/crewjam/saml"
+    "github.com/crewjam/saml/samlsp"
+
+    "secure-iran-intel/pkg/refusal"
+)
+
+// Attribute names IdPs commonly use, by friendly name and by OID
+var (
+    emailAttributes     = []string{"email", "mail", "emailaddress", "urn:oid:0.9.2342.19200300.100.1.3"}
+    firstNameAttributes = []string{"givenname", "firstname", "urn:oid:2.5.4.42"}
+    lastNameAttributes  = []string{"sn", "surname", "lastname", "urn:oid:2.5.4.4"}
+)
+
+// samlClient is this service acting as a SAML 2.0 service provider for one
+// tenant's IdP. Responses must be signed by a certificate from the IdP's
+// metadata.
+type samlClient struct {
+    provider *Provider
+    sp       *saml.ServiceProvider
+}
+
+// newSAMLClient builds the service provider. The entity ID is the URL of
+// its metadata, which is what the tenant registers at their IdP.
+func newSAMLClient(p *Provider, metadataURL, acsURL string) (*samlClient, error) {
+    idp, err := samlsp.ParseMetadata([]byte(p.IdPMetadataXML))
+    if err != nil {
+        return nil, &refusal.Error{Reason: ErrInvalidProvider, ID: p.ID, Detail: fmt.Sprintf("bad IdP metadata: %v", err)}
+    }
+    metadata, err := url.Parse(metadataURL)
+    if err != nil {
+        return nil, err
+    }
+    acs, err := url.Parse(acsURL)
+    if err != nil {
+        return nil, err
+    }
+    return &samlClient{
+        provider: p,
+        sp: &saml.ServiceProvider{
+            EntityID:          metadata.String(),
+            MetadataURL:       *metadata,
+            AcsURL:            *acs,
+            IDPMetadata:       idp,
+            AuthnNameIDFormat: saml.PersistentNameIDFormat,
+        },
+    }, nil
+}
+
+// AuthURL makes an AuthnRequest and returns where to redirect the user
+// with it. The request ID goes into s so the response can be matched.
+func (c *samlClient) AuthURL(state string, s *LoginState) (string, error) {
+    idpURL := c.sp.GetSSOBindingLocation(saml.HTTPRedirectBinding)
+    if idpURL == "" {
+        return "", &refusal.Error{Reason: ErrInvalidProvider, ID: c.provider.ID, Detail: "IdP metadata has no HTTP-Redirect sign-on endpoint"}
+    }
+    req, err := c.sp.MakeAuthenticationRequest(idpURL, saml.HTTPRedirectBinding, saml.HTTPPostBinding)
+    if err != nil {
+        return "", err
+    }
+    redirect, err := req.Redirect(state, c.sp)
+    if err != nil {
+        return "", err
+    }
+    s.RequestID = req.ID
+    return redirect.String(), nil
+}
+
+// ParseResponse checks the IdP's POSTed response against the AuthnRequest
+// it answers: signature, audience, recipient, validity window and ID
+func (c *samlClient) ParseResponse(r *http.Request, s *LoginState) (*Identity, error) {
+    assertion, err := c.sp.ParseResponse(r, []string{s.RequestID})
+    if err != nil {
+        // The public error says nothing; the private one says why
+        var invalid *saml.InvalidResponseError
+        if errors.As(err, &invalid) {
+            log.Printf("⚠️ SAML response from provider %s rejected: %v", c.provider.ID, invalid.PrivateErr)
+        }
+        return nil, c.reject("invalid SAML response")
+    }
+    if assertion.Subject == nil || assertion.Subject.NameID == nil || assertion.Subject.NameID.Value == "" {
+        return nil, c.reject("no NameID in assertion")
+    }
+
+    attrs := attributes(assertion)
+    email := first(attrs, emailAttributes)
+    if email == "" && assertion.Subject.NameID.Format == string(saml.EmailAddressNameIDFormat) {
+        email = assertion.Subject.NameID.Value
+    }
+    if email == "" {
+        return nil, c.reject("no email attribute")
+    }
+
+    var groups []string
+    if c.provider.GroupsClaim != "" {
+        groups = attrs[attributeKey(c.provider.GroupsClaim)]
+    }
+    return &Identity{
+        Subject:   assertion.Subject.NameID.Value,
+        Email:     email,
+        FirstName: first(attrs, firstNameAttributes),
+        LastName:  first(attrs, lastNameAttributes),
+        Groups:    groups,
+    }, nil
+}
+
+// Metadata is the service provider's metadata, for the tenant's IdP
+func (c *samlClient) Metadata() ([]byte, error) {
+    return xml.MarshalIndent(c.sp.Metadata(), "", "  ")
+}
+
+func (c *samlClient) reject(detail string) error {
+    return &refusal.Error{Reason: ErrAssertion, ID: c.provider.ID, Detail: detail}
+}
+
+// attributes indexes an assertion's attribute values by lowercased name
+// and friendly name
+func attributes(a *saml.Assertion) map[string][]string {
+    attrs := make(map[string][]string)
+    for _, statement := range a.AttributeStatements {
+        for _, attr := range statement.Attributes {
+            var values []string
+            for _, v := range attr.Values {
+                values = append(values, v.Value)
+            }
+            for _, name := range []string{attr.Name, attr.FriendlyName} {
+                if name == "" {
+                    continue
+                }
+                key := attributeKey(name)
+                attrs[key] = append(attrs[key], values...)
+            }
+        }
+    }
+    return attrs
+}
+
+// attributeKey lowercases an attribute name. Some IdPs use claim URIs,
+// .../claims/emailaddress, which are shortened to their last segment.
+func attributeKey(name string) string {
+    name = strings.ToLower(name)
+    if i := strings.LastIndex(name, "/"); i >= 0 && !strings.HasPrefix(name, "urn:") {
+        name = name[i+1:]
+    }
+    return name
+}
+
+func first(attrs map[string][]string, names []string) string {
+    for _, name := range names {
+        if values := attrs[name]; len(values) > 0 && values[0] != "" {
+            return values[0]
+        }
+    }
+    return ""
+}
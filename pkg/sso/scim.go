@@ -0,0 +1,363 @@
+// pkg/sso/scim.go
+package sso
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "net/http"
+    "regexp"
+    "strconv"
+    "strings"
+    "time"
+
+    "secure-iran-intel/pkg/audit"
+    "secure-iran-intel/pkg/refusal"
+)
+
+// SCIM 2.0 schema URNs (RFC 7643, RFC 7644)
+const (
+    SCIMUserSchema  = "urn:ietf:params:scim:schemas:core:2.0:User"
+    SCIMListSchema  = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+    SCIMErrorSchema = "urn:ietf:params:scim:api:messages:2.0:Error"
+    SCIMPatchSchema = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+)
+
+// Page sizes for SCIM list requests
+const (
+    scimDefaultCount = 100
+    scimMaxCount     = 200
+)
+
+// Users created over SCIM get the least privileged role until their first
+// sign-in maps their groups
+const provisionedRole = "viewer"
+
+// The only filter directories need to find a user before creating them
+var userNameFilter = regexp.MustCompile(`(?i)^\s*userName\s+eq\s+"([^"]*)"\s*$`)
+
+// SCIMName is the name attribute of a SCIM User
+type SCIMName struct {
+    GivenName  string `json:"givenName,omitempty"`
+    FamilyName string `json:"familyName,omitempty"`
+}
+
+// SCIMEmail is one of a SCIM User's emails
+type SCIMEmail struct {
+    Value   string `json:"value"`
+    Type    string `json:"type,omitempty"`
+    Primary bool   `json:"primary,omitempty"`
+}
+
+// SCIMMeta is a SCIM resource's metadata
+type SCIMMeta struct {
+    ResourceType string    `json:"resourceType"`
+    Created      time.Time `json:"created"`
+    LastModified time.Time `json:"lastModified"`
+    Location     string    `json:"location,omitempty"`
+}
+
+// SCIMUser is the SCIM representation of a user. userName is the email.
+type SCIMUser struct {
+    Schemas    []string    `json:"schemas"`
+    ID         string      `json:"id,omitempty"`
+    ExternalID string      `json:"externalId,omitempty"`
+    UserName   string      `json:"userName"`
+    Name       *SCIMName   `json:"name,omitempty"`
+    Emails     []SCIMEmail `json:"emails,omitempty"`
+    Active     *bool       `json:"active,omitempty"`
+    Meta       *SCIMMeta   `json:"meta,omitempty"`
+}
+
+// SCIMListResponse is a page of SCIM resources
+type SCIMListResponse struct {
+    Schemas      []string   `json:"schemas"`
+    TotalResults int64      `json:"totalResults"`
+    StartIndex   int        `json:"startIndex"`
+    ItemsPerPage int        `json:"itemsPerPage"`
+    Resources    []SCIMUser `json:"Resources"`
+}
+
+// SCIMPatch is a SCIM PatchOp request
+type SCIMPatch struct {
+    Schemas    []string `json:"schemas"`
+    Operations []struct {
+        Op    string      `json:"op"`
+        Path  string      `json:"path"`
+        Value interface{} `json:"value"`
+    } `json:"Operations"`
+}
+
+// SCIMErrorResponse is the body of a SCIM error
+type SCIMErrorResponse struct {
+    Schemas  []string `json:"schemas"`
+    Status   string   `json:"status"`
+    SCIMType string   `json:"scimType,omitempty"`
+    Detail   string   `json:"detail"`
+}
+
+// NewSCIMError builds the SCIM error body for err and its HTTP status
+func NewSCIMError(err error, status int) SCIMErrorResponse {
+    resp := SCIMErrorResponse{Schemas: []string{SCIMErrorSchema}, Status: strconv.Itoa(status), Detail: err.Error()}
+    switch {
+    case errors.Is(err, ErrUserExists):
+        resp.SCIMType = "uniqueness"
+    case errors.Is(err, ErrInvalidRequest):
+        resp.SCIMType = "invalidValue"
+    }
+    if status == http.StatusInternalServerError {
+        resp.Detail = "internal error"
+    }
+    return resp
+}
+
+func toSCIM(u *User) SCIMUser {
+    active := u.IsActive
+    return SCIMUser{
+        Schemas:    []string{SCIMUserSchema},
+        ID:         u.ID,
+        ExternalID: u.ExternalID,
+        UserName:   u.Email,
+        Name:       &SCIMName{GivenName: u.FirstName, FamilyName: u.LastName},
+        Emails:     []SCIMEmail{{Value: u.Email, Type: "work", Primary: true}},
+        Active:     &active,
+        Meta: &SCIMMeta{
+            ResourceType: "User",
+            Created:      u.CreatedAt,
+            LastModified: u.UpdatedAt,
+            Location:     "/scim/v2/Users/" + u.ID,
+        },
+    }
+}
+
+// apply copies what a SCIM user sets onto u
+func (su *SCIMUser) apply(u *User) error {
+    email := strings.TrimSpace(su.UserName)
+    if email == "" {
+        for _, e := range su.Emails {
+            if e.Primary || email == "" {
+                email = strings.TrimSpace(e.Value)
+            }
+        }
+    }
+    if !strings.Contains(email, "@") {
+        return &refusal.Error{Reason: ErrInvalidRequest, Detail: "userName must be an email address"}
+    }
+    u.Email = email
+    u.ExternalID = su.ExternalID
+    if su.Name != nil {
+        u.FirstName, u.LastName = su.Name.GivenName, su.Name.FamilyName
+    }
+    if su.Active != nil {
+        u.IsActive = *su.Active
+    }
+    return nil
+}
+
+// SCIMListUsers lists the caller's tenant's users. The only filter
+// supported is userName eq "...". startIndex is 1-based.
+func (s *Service) SCIMListUsers(ctx context.Context, filter string, startIndex, count int) (*SCIMListResponse, error) {
+    var email string
+    if filter != "" {
+        m := userNameFilter.FindStringSubmatch(filter)
+        if m == nil {
+            return nil, &refusal.Error{Reason: ErrInvalidRequest, Detail: "only userName eq filters are supported"}
+        }
+        email = m[1]
+    }
+    if startIndex < 1 {
+        startIndex = 1
+    }
+    if count <= 0 {
+        count = scimDefaultCount
+    }
+    if count > scimMaxCount {
+        count = scimMaxCount
+    }
+
+    users, total, err := s.store.ListUsers(ctx, audit.ActorFromContext(ctx).TenantID, email, startIndex-1, count)
+    if err != nil {
+        return nil, err
+    }
+    resp := &SCIMListResponse{
+        Schemas:      []string{SCIMListSchema},
+        TotalResults: total,
+        StartIndex:   startIndex,
+        ItemsPerPage: len(users),
+        Resources:    make([]SCIMUser, 0, len(users)),
+    }
+    for i := range users {
+        resp.Resources = append(resp.Resources, toSCIM(&users[i]))
+    }
+    return resp, nil
+}
+
+// SCIMGetUser returns one of the caller's tenant's users
+func (s *Service) SCIMGetUser(ctx context.Context, id string) (*SCIMUser, error) {
+    u, err := s.store.User(ctx, audit.ActorFromContext(ctx).TenantID, id)
+    if err != nil {
+        return nil, err
+    }
+    su := toSCIM(u)
+    return &su, nil
+}
+
+// SCIMCreateUser provisions a user in the caller's tenant
+func (s *Service) SCIMCreateUser(ctx context.Context, su *SCIMUser) (*SCIMUser, error) {
+    u := &User{TenantID: audit.ActorFromContext(ctx).TenantID, Role: provisionedRole, IsActive: true}
+    if err := su.apply(u); err != nil {
+        return nil, err
+    }
+
+    err := s.auditLog.Log(ctx, audit.Entry{
+        Action:     audit.ActionSCIMProvision,
+        TargetType: "email",
+        Target:     u.Email,
+        Metadata:   map[string]string{"external_id": u.ExternalID},
+    })
+    if err != nil {
+        return nil, err
+    }
+    if err := s.store.CreateUser(ctx, u, nil); err != nil {
+        return nil, err
+    }
+    created := toSCIM(u)
+    return &created, nil
+}
+
+// SCIMReplaceUser replaces a user's attributes (PUT)
+func (s *Service) SCIMReplaceUser(ctx context.Context, id string, su *SCIMUser) (*SCIMUser, error) {
+    u, err := s.store.User(ctx, audit.ActorFromContext(ctx).TenantID, id)
+    if err != nil {
+        return nil, err
+    }
+    wasActive := u.IsActive
+    if err := su.apply(u); err != nil {
+        return nil, err
+    }
+    return s.scimUpdate(ctx, u, wasActive)
+}
+
+// SCIMPatchUser applies a PatchOp. Directories mostly use it to set
+// active to false when someone leaves.
+func (s *Service) SCIMPatchUser(ctx context.Context, id string, patch *SCIMPatch) (*SCIMUser, error) {
+    u, err := s.store.User(ctx, audit.ActorFromContext(ctx).TenantID, id)
+    if err != nil {
+        return nil, err
+    }
+    wasActive := u.IsActive
+
+    for _, op := range patch.Operations {
+        switch strings.ToLower(op.Op) {
+        case "add", "replace":
+        default:
+            return nil, &refusal.Error{Reason: ErrInvalidRequest, Detail: fmt.Sprintf("unsupported op %q", op.Op)}
+        }
+        // Without a path the value is an object of attributes
+        values := map[string]interface{}{op.Path: op.Value}
+        if op.Path == "" {
+            object, ok := op.Value.(map[string]interface{})
+            if !ok {
+                return nil, &refusal.Error{Reason: ErrInvalidRequest, Detail: "value must be an object when path is omitted"}
+            }
+            values = object
+        }
+        for path, value := range values {
+            if err := patchUser(u, path, value); err != nil {
+                return nil, err
+            }
+        }
+    }
+    return s.scimUpdate(ctx, u, wasActive)
+}
+
+// SCIMDeleteUser deprovisions a user. The account is deactivated rather
+// than deleted, so the jobs and audit records it owns keep their owner.
+func (s *Service) SCIMDeleteUser(ctx context.Context, id string) error {
+    u, err := s.store.User(ctx, audit.ActorFromContext(ctx).TenantID, id)
+    if err != nil {
+        return err
+    }
+    wasActive := u.IsActive
+    u.IsActive = false
+    _, err = s.scimUpdate(ctx, u, wasActive)
+    return err
+}
+
+// scimUpdate audits and stores a user change. A user who is deactivated
+// loses access at once: every token they hold is revoked, and their API
+// keys stop resolving to a subject.
+func (s *Service) scimUpdate(ctx context.Context, u *User, wasActive bool) (*SCIMUser, error) {
+    action := audit.ActionSCIMUpdate
+    if wasActive && !u.IsActive {
+        action = audit.ActionSCIMDeprovision
+    }
+    err := s.auditLog.Log(ctx, audit.Entry{
+        Action:     action,
+        TargetType: "user",
+        Target:     u.ID,
+        Metadata:   map[string]string{"active": strconv.FormatBool(u.IsActive)},
+    })
+    if err != nil {
+        return nil, err
+    }
+    if err := s.store.UpdateUser(ctx, u); err != nil {
+        return nil, err
+    }
+    if !u.IsActive {
+        // Also on repeats, so a retried deprovisioning that failed here
+        // before still revokes
+        if err := s.revocations.RevokeUser(ctx, u.TenantID, u.ID); err != nil {
+            return nil, fmt.Errorf("user deactivated, but their tokens could not be revoked: %w", err)
+        }
+    }
+    updated := toSCIM(u)
+    return &updated, nil
+}
+
+func patchUser(u *User, path string, value interface{}) error {
+    str := func() (string, error) {
+        if v, ok := value.(string); ok {
+            return v, nil
+        }
+        return "", &refusal.Error{Reason: ErrInvalidRequest, Detail: path + " must be a string"}
+    }
+
+    var err error
+    switch strings.ToLower(path) {
+    case "active":
+        switch v := value.(type) {
+        case bool:
+            u.IsActive = v
+        case string: // Some directories send "False"
+            u.IsActive, err = strconv.ParseBool(v)
+            if err != nil {
+                return &refusal.Error{Reason: ErrInvalidRequest, Detail: "active must be a boolean"}
+            }
+        default:
+            return &refusal.Error{Reason: ErrInvalidRequest, Detail: "active must be a boolean"}
+        }
+    case "username":
+        u.Email, err = str()
+    case "externalid":
+        u.ExternalID, err = str()
+    case "name.givenname":
+        u.FirstName, err = str()
+    case "name.familyname":
+        u.LastName, err = str()
+    case "name":
+        name, ok := value.(map[string]interface{})
+        if !ok {
+            return &refusal.Error{Reason: ErrInvalidRequest, Detail: "name must be an object"}
+        }
+        for k, v := range name {
+            if err := patchUser(u, "name."+k, v); err != nil {
+                return err
+            }
+        }
+    default:
+        // Attributes this service does not keep are ignored, as RFC 7644
+        // allows for attributes the provider does not support
+    }
+    return err
+}
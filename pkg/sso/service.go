@@ -0,0 +1,322 @@
+// pkg/sso/service.go
+package sso
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "net/http"
+    "strings"
+    "time"
+
+    "golang.org/x/oauth2"
+    "secure-iran-intel/pkg/audit"
+    "secure-iran-intel/pkg/refusal"
+    "secure-iran-intel/pkg/token"
+)
+
+// Routes the service provider is reached on, relative to the base URL
+const (
+    OIDCCallbackPath = "/api/v1/auth/sso/oidc/callback"
+    SAMLACSPath      = "/api/v1/auth/sso/saml/acs"
+)
+
+// SAMLMetadataPath is where a provider's SAML service provider metadata is
+// served; it is also the service provider's entity ID
+func SAMLMetadataPath(providerID string) string {
+    return "/api/v1/auth/sso/providers/" + providerID + "/saml/metadata"
+}
+
+// Service signs users in through their tenant's identity provider, and
+// provisions users for SCIM
+type Service struct {
+    store       Store
+    states      StateStore
+    issuer      *token.Issuer
+    revocations *token.Revocations
+    auditLog    *audit.Logger
+    baseURL     string
+}
+
+// NewService creates the service. baseURL is the public URL of the gateway,
+// which IdPs redirect users back to.
+func NewService(store Store, states StateStore, issuer *token.Issuer, revocations *token.Revocations, auditLog *audit.Logger, baseURL string) *Service {
+    return &Service{
+        store:       store,
+        states:      states,
+        issuer:      issuer,
+        revocations: revocations,
+        auditLog:    auditLog,
+        baseURL:     strings.TrimRight(baseURL, "/"),
+    }
+}
+
+// SignIn is the outcome of a successful sign-in
+type SignIn struct {
+    AccessToken string    `json:"access_token"`
+    TokenType   string    `json:"token_type"`
+    ExpiresAt   time.Time `json:"expires_at"`
+    User        *User     `json:"user"`
+}
+
+// Begin starts a sign-in with a provider and returns the IdP URL to
+// redirect the user to
+func (s *Service) Begin(ctx context.Context, providerID string) (string, error) {
+    p, err := s.enabledProvider(ctx, providerID)
+    if err != nil {
+        return "", err
+    }
+    key, err := randomToken()
+    if err != nil {
+        return "", err
+    }
+    state := &LoginState{TenantID: p.TenantID, ProviderID: p.ID}
+
+    var redirect string
+    switch p.Protocol {
+    case ProtocolOIDC:
+        client, err := newOIDCClient(ctx, p, s.baseURL+OIDCCallbackPath)
+        if err != nil {
+            return "", err
+        }
+        if state.Nonce, err = randomToken(); err != nil {
+            return "", err
+        }
+        state.CodeVerifier = oauth2.GenerateVerifier()
+        redirect = client.AuthURL(key, state)
+    case ProtocolSAML:
+        client, err := s.samlClient(p)
+        if err != nil {
+            return "", err
+        }
+        if redirect, err = client.AuthURL(key, state); err != nil {
+            return "", err
+        }
+    default:
+        return "", &refusal.Error{Reason: ErrInvalidProvider, ID: p.ID}
+    }
+
+    if err := s.states.Put(ctx, key, state); err != nil {
+        return "", fmt.Errorf("failed to store sign-in state: %w", err)
+    }
+    return redirect, nil
+}
+
+// CompleteOIDC finishes an OIDC sign-in from the callback's state and code
+func (s *Service) CompleteOIDC(ctx context.Context, stateKey, code string) (*SignIn, error) {
+    state, p, err := s.resume(ctx, stateKey, ProtocolOIDC)
+    if err != nil {
+        return nil, err
+    }
+    client, err := newOIDCClient(ctx, p, s.baseURL+OIDCCallbackPath)
+    if err != nil {
+        return nil, err
+    }
+    identity, err := client.Exchange(ctx, code, state)
+    if err != nil {
+        return nil, err
+    }
+    return s.signIn(ctx, p, identity)
+}
+
+// CompleteSAML finishes a SAML sign-in from the response the IdP POSTed to
+// the ACS. The relay state is the sign-in state.
+func (s *Service) CompleteSAML(ctx context.Context, r *http.Request) (*SignIn, error) {
+    if err := r.ParseForm(); err != nil {
+        return nil, &refusal.Error{Reason: ErrAssertion, Detail: err.Error()}
+    }
+    state, p, err := s.resume(ctx, r.PostForm.Get("RelayState"), ProtocolSAML)
+    if err != nil {
+        return nil, err
+    }
+    client, err := s.samlClient(p)
+    if err != nil {
+        return nil, err
+    }
+    identity, err := client.ParseResponse(r, state)
+    if err != nil {
+        return nil, err
+    }
+    return s.signIn(ctx, p, identity)
+}
+
+// SAMLMetadata is the service provider metadata a tenant registers at
+// their IdP
+func (s *Service) SAMLMetadata(ctx context.Context, providerID string) ([]byte, error) {
+    p, err := s.store.Provider(ctx, providerID)
+    if err != nil {
+        return nil, err
+    }
+    if p.Protocol != ProtocolSAML {
+        return nil, &refusal.Error{Reason: ErrUnknownProvider, ID: providerID}
+    }
+    client, err := s.samlClient(p)
+    if err != nil {
+        return nil, err
+    }
+    return client.Metadata()
+}
+
+func (s *Service) samlClient(p *Provider) (*samlClient, error) {
+    return newSAMLClient(p, s.baseURL+SAMLMetadataPath(p.ID), s.baseURL+SAMLACSPath)
+}
+
+// resume takes a sign-in's state, once, and reloads its provider, which
+// may have been disabled in the meantime
+func (s *Service) resume(ctx context.Context, key, protocol string) (*LoginState, *Provider, error) {
+    if key == "" {
+        return nil, nil, &refusal.Error{Reason: ErrInvalidState}
+    }
+    state, err := s.states.Take(ctx, key)
+    if err != nil {
+        return nil, nil, err
+    }
+    p, err := s.enabledProvider(ctx, state.ProviderID)
+    if err != nil {
+        return nil, nil, err
+    }
+    if p.TenantID != state.TenantID || p.Protocol != protocol {
+        return nil, nil, &refusal.Error{Reason: ErrInvalidState, ID: p.ID}
+    }
+    return state, p, nil
+}
+
+func (s *Service) enabledProvider(ctx context.Context, id string) (*Provider, error) {
+    p, err := s.store.Provider(ctx, id)
+    if err != nil {
+        return nil, err
+    }
+    if !p.Enabled {
+        return nil, &refusal.Error{Reason: ErrProviderOff, ID: id}
+    }
+    return p, nil
+}
+
+// signIn finds or provisions the user an IdP vouched for, brings their
+// role and name in line with the IdP, and issues an access token.
+// Deprovisioned users are refused even when the IdP still knows them.
+func (s *Service) signIn(ctx context.Context, p *Provider, id *Identity) (*SignIn, error) {
+    role, err := p.RoleFor(id.Groups)
+    if err != nil {
+        return nil, err
+    }
+
+    provisioned := false
+    user, err := s.store.LinkedUser(ctx, p.TenantID, p.ID, id.Subject)
+    if errors.Is(err, ErrUnknownUser) {
+        user, provisioned, err = s.firstSignIn(ctx, p, id, role)
+    }
+    if err != nil {
+        return nil, err
+    }
+    if !user.IsActive {
+        return nil, &refusal.Error{Reason: ErrUserDisabled, ID: p.ID}
+    }
+
+    ctx = audit.WithActor(ctx, audit.Actor{TenantID: user.TenantID, Type: audit.ActorUser, ID: user.ID})
+    err = s.auditLog.Log(ctx, audit.Entry{
+        Action:     audit.ActionSSOLogin,
+        TargetType: "user",
+        Target:     user.ID,
+        Metadata: map[string]string{
+            "provider":    p.ID,
+            "protocol":    p.Protocol,
+            "role":        role,
+            "provisioned": fmt.Sprint(provisioned),
+        },
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    if user.Role != role || (id.FirstName != "" && user.FirstName != id.FirstName) || (id.LastName != "" && user.LastName != id.LastName) {
+        user.Role = role
+        if id.FirstName != "" {
+            user.FirstName = id.FirstName
+        }
+        if id.LastName != "" {
+            user.LastName = id.LastName
+        }
+        if err := s.store.UpdateUser(ctx, user); err != nil {
+            return nil, err
+        }
+    }
+
+    signed, claims, err := s.issuer.Issue(user.TenantID, user.ID, user.Role, token.AMRFederated)
+    if err != nil {
+        return nil, err
+    }
+    return &SignIn{AccessToken: signed, TokenType: "Bearer", ExpiresAt: claims.ExpiresAt.Time, User: user}, nil
+}
+
+// firstSignIn links an IdP subject to the tenant's user with the same
+// email, typically one provisioned over SCIM, or creates the user when the
+// provider allows just-in-time provisioning
+func (s *Service) firstSignIn(ctx context.Context, p *Provider, id *Identity, role string) (*User, bool, error) {
+    link := &Link{TenantID: p.TenantID, ProviderID: p.ID, Subject: id.Subject}
+
+    user, err := s.store.UserByEmail(ctx, p.TenantID, id.Email)
+    if err == nil {
+        link.UserID = user.ID
+        if err := s.store.Link(ctx, link); err != nil {
+            return nil, false, fmt.Errorf("failed to link identity: %w", err)
+        }
+        return user, false, nil
+    }
+    if !errors.Is(err, ErrUnknownUser) {
+        return nil, false, err
+    }
+    if !p.JITProvisioning {
+        return nil, false, &refusal.Error{Reason: ErrNotProvisioned, ID: p.ID}
+    }
+
+    user = &User{
+        TenantID:  p.TenantID,
+        Email:     id.Email,
+        FirstName: id.FirstName,
+        LastName:  id.LastName,
+        Role:      role,
+        IsActive:  true,
+    }
+    if err := s.store.CreateUser(ctx, user, link); err != nil {
+        return nil, false, err
+    }
+    return user, true, nil
+}
+
+// ListProviders lists the providers of the caller's tenant
+func (s *Service) ListProviders(ctx context.Context) ([]Provider, error) {
+    return s.store.ListProviders(ctx, audit.ActorFromContext(ctx).TenantID)
+}
+
+// SaveProvider creates or updates a provider of the caller's tenant. An
+// empty client secret keeps the stored one.
+func (s *Service) SaveProvider(ctx context.Context, p *Provider) error {
+    p.TenantID = audit.ActorFromContext(ctx).TenantID
+    if p.ID != "" {
+        existing, err := s.store.TenantProvider(ctx, p.TenantID, p.ID)
+        if err != nil {
+            return err
+        }
+        if p.ClientSecret == "" {
+            p.ClientSecret = existing.ClientSecret
+        }
+        p.CreatedAt = existing.CreatedAt
+    }
+    if err := p.Validate(); err != nil {
+        return err
+    }
+    if p.Protocol == ProtocolSAML {
+        // Refuse metadata that sign-in would later choke on
+        if _, err := s.samlClient(p); err != nil {
+            return err
+        }
+    }
+    return s.store.SaveProvider(ctx, p)
+}
+
+// DeleteProvider removes a provider of the caller's tenant. Users it
+// signed in keep their accounts.
+func (s *Service) DeleteProvider(ctx context.Context, id string) error {
+    return s.store.DeleteProvider(ctx, audit.ActorFromContext(ctx).TenantID, id)
+}
@@ -0,0 +1,150 @@
+// pkg/sso/sso.go
+package sso
+
+import (
+    "fmt"
+    "net/http"
+    "strings"
+    "time"
+
+    "secure-iran-intel/pkg/refusal"
+)
+
+// Protocols a tenant can federate sign-in with
+const (
+    ProtocolOIDC = "oidc"
+    ProtocolSAML = "saml"
+)
+
+// RoleMapping gives members of a directory group a tenant role
+type RoleMapping struct {
+    Group string `json:"group"`
+    Role  string `json:"role"`
+}
+
+// Provider is a tenant's identity provider configuration
+type Provider struct {
+    ID       string `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+    TenantID string `json:"tenant_id" gorm:"type:uuid;not null"`
+    Name     string `json:"name" gorm:"not null"`
+    Protocol string `json:"protocol" gorm:"not null"` // ProtocolOIDC, ProtocolSAML
+    Enabled  bool   `json:"enabled"`
+
+    // OIDC
+    IssuerURL    string `json:"issuer_url,omitempty"`
+    ClientID     string `json:"client_id,omitempty"`
+    ClientSecret string `json:"-"` // Never returned by the API
+
+    // SAML
+    IdPMetadataXML string `json:"idp_metadata_xml,omitempty"`
+
+    // GroupsClaim is the OIDC claim or SAML attribute listing the user's
+    // groups. The first mapping matching one of them decides the role;
+    // without a match users get DefaultRole, or are refused when it is empty.
+    GroupsClaim  string        `json:"groups_claim"`
+    RoleMappings []RoleMapping `json:"role_mappings" gorm:"serializer:json;type:jsonb"`
+    DefaultRole  string        `json:"default_role"`
+    // JITProvisioning creates users on first sign-in. Without it users must
+    // exist already, e.g. provisioned over SCIM.
+    JITProvisioning bool `json:"jit_provisioning"`
+
+    CreatedAt time.Time `json:"created_at"`
+    UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (Provider) TableName() string {
+    return "identity_providers"
+}
+
+// Validate checks the fields the provider's protocol needs
+func (p *Provider) Validate() error {
+    switch p.Protocol {
+    case ProtocolOIDC:
+        if p.IssuerURL == "" || p.ClientID == "" {
+            return &refusal.Error{Reason: ErrInvalidProvider, Detail: "OIDC needs issuer_url and client_id"}
+        }
+    case ProtocolSAML:
+        if p.IdPMetadataXML == "" {
+            return &refusal.Error{Reason: ErrInvalidProvider, Detail: "SAML needs idp_metadata_xml"}
+        }
+    default:
+        return &refusal.Error{Reason: ErrInvalidProvider, Detail: fmt.Sprintf("unknown protocol %q", p.Protocol)}
+    }
+    if strings.TrimSpace(p.Name) == "" {
+        return &refusal.Error{Reason: ErrInvalidProvider, Detail: "name is required"}
+    }
+    return nil
+}
+
+// RoleFor maps the groups an IdP asserted to a tenant role
+func (p *Provider) RoleFor(groups []string) (string, error) {
+    member := make(map[string]bool, len(groups))
+    for _, g := range groups {
+        member[g] = true
+    }
+    for _, m := range p.RoleMappings {
+        if member[m.Group] {
+            return m.Role, nil
+        }
+    }
+    if p.DefaultRole != "" {
+        return p.DefaultRole, nil
+    }
+    return "", &refusal.Error{Reason: ErrNoRole, ID: p.ID}
+}
+
+// Identity is what an IdP asserted about a user, whatever the protocol
+type Identity struct {
+    Subject   string // Stable IdP identifier: OIDC sub, SAML NameID
+    Email     string
+    FirstName string
+    LastName  string
+    Groups    []string
+}
+
+// User is the part of the users table sign-in and provisioning manage
+type User struct {
+    ID         string    `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+    TenantID   string    `json:"tenant_id" gorm:"type:uuid;not null"`
+    Email      string    `json:"email" gorm:"not null"`
+    FirstName  string    `json:"first_name"`
+    LastName   string    `json:"last_name"`
+    Role       string    `json:"role"`
+    IsActive   bool      `json:"is_active"`
+    ExternalID string    `json:"external_id,omitempty" gorm:"default:null"` // The directory's ID, set over SCIM
+    CreatedAt  time.Time `json:"created_at"`
+    UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func (User) TableName() string {
+    return "users"
+}
+
+// Link ties an IdP subject to a user, so email changes at the IdP cannot
+// move a sign-in to another account
+type Link struct {
+    TenantID   string `gorm:"type:uuid;not null"`
+    ProviderID string `gorm:"type:uuid;primary_key"`
+    Subject    string `gorm:"primary_key"`
+    UserID     string `gorm:"type:uuid;not null"`
+    CreatedAt  time.Time
+}
+
+func (Link) TableName() string {
+    return "user_identities"
+}
+
+// Refusal reasons. Match them with errors.Is.
+var (
+    ErrUnknownProvider = refusal.New("SSO_PROVIDER_NOT_FOUND", http.StatusNotFound, "identity provider not found")
+    ErrInvalidProvider = refusal.New("SSO_PROVIDER_INVALID", http.StatusBadRequest, "invalid identity provider configuration")
+    ErrProviderOff     = refusal.New("SSO_PROVIDER_DISABLED", http.StatusUnauthorized, "identity provider is disabled")
+    ErrInvalidState    = refusal.New("SSO_STATE_INVALID", http.StatusUnauthorized, "sign-in session expired or unknown")
+    ErrAssertion       = refusal.New("SSO_ASSERTION_REJECTED", http.StatusUnauthorized, "identity provider response rejected")
+    ErrNoRole          = refusal.New("SSO_NO_ROLE", http.StatusForbidden, "no tenant role for this user's groups")
+    ErrNotProvisioned  = refusal.New("SSO_NOT_PROVISIONED", http.StatusForbidden, "user has not been provisioned")
+    ErrUserDisabled    = refusal.New("SSO_USER_DISABLED", http.StatusForbidden, "user has been deprovisioned")
+    ErrUnknownUser     = refusal.New("SSO_USER_NOT_FOUND", http.StatusNotFound, "user not found")
+    ErrUserExists      = refusal.New("SSO_USER_EXISTS", http.StatusConflict, "user already exists")
+    ErrInvalidRequest  = refusal.New("SSO_REQUEST_INVALID", http.StatusBadRequest, "invalid provisioning request")
+)
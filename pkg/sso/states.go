@@ -0,0 +1,77 @@
+// pkg/sso/states.go
+package sso
+
+import (
+    "context"
+    "crypto/rand"
+    "encoding/base64"
+    "encoding/json"
+    "errors"
+    "time"
+
+    "github.com/go-redis/redis/v8"
+)
+
+// How long a user has to finish signing in at the IdP
+const stateTTL = 10 * time.Minute
+
+// LoginState is what a sign-in must come back with. It is used once.
+type LoginState struct {
+    TenantID     string `json:"tenant_id"`
+    ProviderID   string `json:"provider_id"`
+    Nonce        string `json:"nonce,omitempty"`         // OIDC
+    CodeVerifier string `json:"code_verifier,omitempty"` // OIDC PKCE
+    RequestID    string `json:"request_id,omitempty"`    // SAML AuthnRequest
+}
+
+// StateStore holds sign-ins in progress
+type StateStore interface {
+    Put(ctx context.Context, key string, s *LoginState) error
+    // Take returns and forgets the state, or ErrInvalidState
+    Take(ctx context.Context, key string) (*LoginState, error)
+}
+
+// RedisStateStore keeps sign-ins in progress in Redis, so any instance can
+// finish them
+type RedisStateStore struct {
+    redis *redis.Client
+}
+
+func NewRedisStateStore(client *redis.Client) *RedisStateStore {
+    return &RedisStateStore{redis: client}
+}
+
+func stateKey(key string) string {
+    return "sso_state:" + key
+}
+
+func (s *RedisStateStore) Put(ctx context.Context, key string, state *LoginState) error {
+    body, err := json.Marshal(state)
+    if err != nil {
+        return err
+    }
+    return s.redis.Set(ctx, stateKey(key), body, stateTTL).Err()
+}
+
+func (s *RedisStateStore) Take(ctx context.Context, key string) (*LoginState, error) {
+    body, err := s.redis.GetDel(ctx, stateKey(key)).Bytes()
+    if errors.Is(err, redis.Nil) {
+        return nil, ErrInvalidState
+    }
+    if err != nil {
+        return nil, err
+    }
+    var state LoginState
+    if err := json.Unmarshal(body, &state); err != nil {
+        return nil, err
+    }
+    return &state, nil
+}
+
+func randomToken() (string, error) {
+    b := make([]byte, 32)
+    if _, err := rand.Read(b); err != nil {
+        return "", err
+    }
+    return base64.RawURLEncoding.EncodeToString(b), nil
+}
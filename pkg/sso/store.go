@@ -0,0 +1,216 @@
+// pkg/sso/store.go
+package sso
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "strings"
+
+    "gorm.io/gorm"
+    "secure-iran-intel/pkg/refusal"
+    "secure-iran-intel/pkg/tenancy"
+)
+
+// Store persists providers, identity links and the users they sign in.
+// Everything but Provider is scoped to one tenant.
+type Store interface {
+    // Provider finds a provider by ID before its tenant is known, to start
+    // or finish a sign-in
+    Provider(ctx context.Context, id string) (*Provider, error)
+    TenantProvider(ctx context.Context, tenantID, id string) (*Provider, error)
+    ListProviders(ctx context.Context, tenantID string) ([]Provider, error)
+    SaveProvider(ctx context.Context, p *Provider) error
+    DeleteProvider(ctx context.Context, tenantID, id string) error
+
+    // LinkedUser returns ErrUnknownUser when nobody is linked to subject
+    LinkedUser(ctx context.Context, tenantID, providerID, subject string) (*User, error)
+    // CreateUser adds a user, and links it when link is not nil
+    CreateUser(ctx context.Context, u *User, link *Link) error
+    Link(ctx context.Context, link *Link) error
+
+    User(ctx context.Context, tenantID, id string) (*User, error)
+    UserByEmail(ctx context.Context, tenantID, email string) (*User, error)
+    ListUsers(ctx context.Context, tenantID, email string, offset, limit int) ([]User, int64, error)
+    UpdateUser(ctx context.Context, u *User) error
+}
+
+// GormStore keeps providers and links in the identity_providers and
+// user_identities tables (migration 016)
+type GormStore struct {
+    db *gorm.DB
+}
+
+func NewGormStore(db *gorm.DB) *GormStore {
+    return &GormStore{db: db}
+}
+
+func (s *GormStore) Provider(ctx context.Context, id string) (*Provider, error) {
+    var p Provider
+    err := tenancy.SystemTransaction(ctx, s.db, func(tx *gorm.DB) error {
+        return tx.Where("id = ?", id).First(&p).Error
+    })
+    if err := providerErr(err, id); err != nil {
+        return nil, err
+    }
+    return &p, nil
+}
+
+func (s *GormStore) TenantProvider(ctx context.Context, tenantID, id string) (*Provider, error) {
+    var p Provider
+    err := tenancy.Transaction(ctx, s.db, tenantID, func(tx *gorm.DB) error {
+        return tx.Where("tenant_id = ? AND id = ?", tenantID, id).First(&p).Error
+    })
+    if err := providerErr(err, id); err != nil {
+        return nil, err
+    }
+    return &p, nil
+}
+
+func providerErr(err error, id string) error {
+    if errors.Is(err, gorm.ErrRecordNotFound) {
+        return &refusal.Error{Reason: ErrUnknownProvider, ID: id}
+    }
+    return err
+}
+
+func (s *GormStore) ListProviders(ctx context.Context, tenantID string) ([]Provider, error) {
+    var providers []Provider
+    err := tenancy.Transaction(ctx, s.db, tenantID, func(tx *gorm.DB) error {
+        return tx.Where("tenant_id = ?", tenantID).Order("name").Find(&providers).Error
+    })
+    return providers, err
+}
+
+func (s *GormStore) SaveProvider(ctx context.Context, p *Provider) error {
+    err := tenancy.Transaction(ctx, s.db, p.TenantID, func(tx *gorm.DB) error {
+        if p.ID == "" {
+            return tx.Create(p).Error
+        }
+        return tx.Save(p).Error
+    })
+    if err != nil {
+        return fmt.Errorf("failed to save identity provider: %w", err)
+    }
+    return nil
+}
+
+func (s *GormStore) DeleteProvider(ctx context.Context, tenantID, id string) error {
+    var deleted int64
+    err := tenancy.Transaction(ctx, s.db, tenantID, func(tx *gorm.DB) error {
+        result := tx.Where("tenant_id = ? AND id = ?", tenantID, id).Delete(&Provider{})
+        deleted = result.RowsAffected
+        return result.Error
+    })
+    if err != nil {
+        return fmt.Errorf("failed to delete identity provider: %w", err)
+    }
+    if deleted == 0 {
+        return &refusal.Error{Reason: ErrUnknownProvider, ID: id}
+    }
+    return nil
+}
+
+func (s *GormStore) LinkedUser(ctx context.Context, tenantID, providerID, subject string) (*User, error) {
+    var u User
+    err := tenancy.Transaction(ctx, s.db, tenantID, func(tx *gorm.DB) error {
+        return tx.Joins("JOIN user_identities ui ON ui.user_id = users.id").
+            Where("ui.tenant_id = ? AND ui.provider_id = ? AND ui.subject = ?", tenantID, providerID, subject).
+            First(&u).Error
+    })
+    if err := userErr(err); err != nil {
+        return nil, err
+    }
+    return &u, nil
+}
+
+func (s *GormStore) CreateUser(ctx context.Context, u *User, link *Link) error {
+    err := tenancy.Transaction(ctx, s.db, u.TenantID, func(tx *gorm.DB) error {
+        if err := tx.Create(u).Error; err != nil {
+            return err
+        }
+        if link == nil {
+            return nil
+        }
+        link.UserID = u.ID
+        return tx.Create(link).Error
+    })
+    if err != nil && strings.Contains(err.Error(), "duplicate key") {
+        return &refusal.Error{Reason: ErrUserExists, Detail: u.Email}
+    }
+    if err != nil {
+        return fmt.Errorf("failed to create user: %w", err)
+    }
+    return nil
+}
+
+func (s *GormStore) Link(ctx context.Context, link *Link) error {
+    return tenancy.Transaction(ctx, s.db, link.TenantID, func(tx *gorm.DB) error {
+        return tx.Create(link).Error
+    })
+}
+
+func (s *GormStore) User(ctx context.Context, tenantID, id string) (*User, error) {
+    var u User
+    err := tenancy.Transaction(ctx, s.db, tenantID, func(tx *gorm.DB) error {
+        return tx.Where("tenant_id = ? AND id = ?", tenantID, id).First(&u).Error
+    })
+    if err := userErr(err); err != nil {
+        return nil, err
+    }
+    return &u, nil
+}
+
+func (s *GormStore) UserByEmail(ctx context.Context, tenantID, email string) (*User, error) {
+    var u User
+    err := tenancy.Transaction(ctx, s.db, tenantID, func(tx *gorm.DB) error {
+        return tx.Where("tenant_id = ? AND lower(email) = lower(?)", tenantID, email).First(&u).Error
+    })
+    if err := userErr(err); err != nil {
+        return nil, err
+    }
+    return &u, nil
+}
+
+func userErr(err error) error {
+    if errors.Is(err, gorm.ErrRecordNotFound) {
+        return ErrUnknownUser
+    }
+    return err
+}
+
+func (s *GormStore) ListUsers(ctx context.Context, tenantID, email string, offset, limit int) ([]User, int64, error) {
+    var users []User
+    var total int64
+    err := tenancy.Transaction(ctx, s.db, tenantID, func(tx *gorm.DB) error {
+        query := tx.Model(&User{}).Where("tenant_id = ?", tenantID).Session(&gorm.Session{})
+        if email != "" {
+            query = query.Where("lower(email) = lower(?)", email)
+        }
+        if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+            return err
+        }
+        return query.Session(&gorm.Session{}).Order("created_at").Offset(offset).Limit(limit).Find(&users).Error
+    })
+    return users, total, err
+}
+
+func (s *GormStore) UpdateUser(ctx context.Context, u *User) error {
+    err := tenancy.Transaction(ctx, s.db, u.TenantID, func(tx *gorm.DB) error {
+        return tx.Model(&User{}).
+            Where("tenant_id = ? AND id = ?", u.TenantID, u.ID).
+            Updates(map[string]interface{}{
+                "email":       u.Email,
+                "first_name":  u.FirstName,
+                "last_name":   u.LastName,
+                "role":        u.Role,
+                "is_active":   u.IsActive,
+                "external_id": gorm.Expr("NULLIF(?, '')", u.ExternalID),
+                "updated_at":  gorm.Expr("CURRENT_TIMESTAMP"),
+            }).Error
+    })
+    if err != nil {
+        return fmt.Errorf("failed to update user: %w", err)
+    }
+    return nil
+}
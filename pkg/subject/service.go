@@ -0,0 +1,345 @@
+// pkg/subject/service.go
+package subject
+
+import (
+    "context"
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    "fmt"
+    "log"
+    "os"
+    "path/filepath"
+    "strconv"
+    "strings"
+    "time"
+
+    "secure-iran-intel/pkg/audit"
+    "secure-iran-intel/pkg/normalizer"
+    "secure-iran-intel/pkg/rbac"
+    "secure-iran-intel/pkg/refusal"
+    "secure-iran-intel/pkg/tenancy"
+)
+
+// Open requests due within this long are reported by Run
+const dueSoon = 7 * 24 * time.Hour
+
+// NewRequest is what an operator records when a subject asks for access,
+// rectification or erasure
+type NewRequest struct {
+    Kind                  string   `json:"kind" binding:"required"`
+    PhoneNumber           string   `json:"phone_number" binding:"required"`
+    VerificationMethod    string   `json:"verification_method" binding:"required"`
+    VerificationReference string   `json:"verification_reference" binding:"required"`
+    Records               []string `json:"records,omitempty"`
+}
+
+// Service finds what every tenant holds about a data subject, exports it,
+// and erases it. Only operators of the platform tenant may use it.
+type Service struct {
+    store      Store
+    sources    []Source
+    auditLog   *audit.Logger
+    policy     *rbac.Engine
+    normalizer *normalizer.PhoneNormalizer
+    exportDir  string
+}
+
+// NewService returns a service that searches every store in sources and
+// writes access exports to exportDir
+func NewService(store Store, auditLog *audit.Logger, policy *rbac.Engine, exportDir string, sources ...Source) *Service {
+    return &Service{
+        store:      store,
+        sources:    sources,
+        auditLog:   auditLog,
+        policy:     policy,
+        normalizer: normalizer.NewPhoneNormalizer("IR"),
+        exportDir:  exportDir,
+    }
+}
+
+// Run reports open requests that are overdue or due within a week, each
+// interval until ctx is cancelled
+func (s *Service) Run(ctx context.Context, interval time.Duration) {
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+            if err := s.checkDeadlines(ctx, time.Now()); err != nil {
+                log.Printf("⚠️ Subject request deadline check failed: %v", err)
+            }
+        }
+    }
+}
+
+func (s *Service) checkDeadlines(ctx context.Context, now time.Time) error {
+    open, err := s.store.List(ctx, StatusOpen)
+    if err != nil {
+        return err
+    }
+    for _, r := range open {
+        switch {
+        case r.Overdue(now):
+            log.Printf("⏰ Subject %s request %s was due %s", r.Kind, r.ID, r.DueAt.UTC().Format(time.RFC3339))
+        case r.DueAt.Sub(now) < dueSoon:
+            log.Printf("⏳ Subject %s request %s is due %s", r.Kind, r.ID, r.DueAt.UTC().Format(time.RFC3339))
+        }
+    }
+    return nil
+}
+
+// Open records a request from a subject whose identity the operator has
+// verified. The deadline runs from now.
+func (s *Service) Open(ctx context.Context, req NewRequest) (*Request, error) {
+    if err := s.authorize(ctx); err != nil {
+        return nil, err
+    }
+    switch req.Kind {
+    case KindAccess, KindErasure:
+    case KindRectification:
+        if len(req.Records) == 0 {
+            return nil, &refusal.Error{Reason: ErrNoRecords}
+        }
+    default:
+        return nil, &refusal.Error{Reason: ErrInvalidKind, Detail: req.Kind}
+    }
+    if strings.TrimSpace(req.VerificationMethod) == "" || strings.TrimSpace(req.VerificationReference) == "" {
+        return nil, &refusal.Error{Reason: ErrNotVerified}
+    }
+    phone, err := s.normalizer.NormalizePhone(req.PhoneNumber, "IR")
+    if err != nil {
+        return nil, &refusal.Error{Reason: ErrInvalidPhone}
+    }
+
+    err = s.auditLog.Log(ctx, audit.Entry{
+        Action:     audit.ActionSubjectRequestOpen,
+        TargetType: "phone",
+        Target:     phone.Normalized,
+        Metadata:   map[string]string{"kind": req.Kind, "verification_method": req.VerificationMethod},
+    })
+    if err != nil {
+        return nil, err
+    }
+    hash, err := Hash(phone.Normalized)
+    if err != nil {
+        return nil, err
+    }
+    now := time.Now()
+    r := &Request{
+        Kind:                  req.Kind,
+        PhoneNumber:           phone.Normalized,
+        SubjectHash:           hash,
+        VerificationMethod:    req.VerificationMethod,
+        VerificationReference: req.VerificationReference,
+        Records:               req.Records,
+        Status:                StatusOpen,
+        ReceivedAt:            now,
+        DueAt:                 now.Add(ResponsePeriod),
+        OpenedBy:              audit.ActorFromContext(ctx).ID,
+    }
+    if err := s.store.Create(ctx, r); err != nil {
+        return nil, err
+    }
+    return r, nil
+}
+
+// Get returns a request
+func (s *Service) Get(ctx context.Context, requestID string) (*Request, error) {
+    if err := s.authorize(ctx); err != nil {
+        return nil, err
+    }
+    return s.store.Get(ctx, requestID)
+}
+
+// List returns requests with status, or all of them, soonest deadline first
+func (s *Service) List(ctx context.Context, status string) ([]Request, error) {
+    if err := s.authorize(ctx); err != nil {
+        return nil, err
+    }
+    return s.store.List(ctx, status)
+}
+
+// Search returns everything held about an open request's subject, without
+// closing it. Operators use it to review an erasure before running it and
+// to pick the records a rectification removes.
+func (s *Service) Search(ctx context.Context, requestID string) (*Export, error) {
+    r, err := s.open(ctx, requestID)
+    if err != nil {
+        return nil, err
+    }
+    if err := s.log(ctx, audit.ActionSubjectRequestSearch, r, nil); err != nil {
+        return nil, err
+    }
+    return s.export(ctx, r)
+}
+
+// Fulfil carries out an open request and closes it. An access request
+// returns the export, also kept in the export directory. An erasure
+// tombstones the subject first, so nothing about them is collected while
+// or after their records are deleted; records under a legal hold are
+// kept. A failed run leaves the request open and can be repeated.
+func (s *Service) Fulfil(ctx context.Context, requestID string) (*Request, *Export, error) {
+    r, err := s.open(ctx, requestID)
+    if err != nil {
+        return nil, nil, err
+    }
+    if err := s.log(ctx, audit.ActionSubjectRequestFulfil, r, nil); err != nil {
+        return nil, nil, err
+    }
+
+    var export *Export
+    outcome := &Outcome{}
+    switch r.Kind {
+    case KindAccess:
+        export, err = s.export(ctx, r)
+        if err != nil {
+            return nil, nil, err
+        }
+        if outcome.Export, err = s.write(export); err != nil {
+            return nil, nil, err
+        }
+    case KindErasure:
+        if err := s.store.AddTombstone(ctx, &Tombstone{SubjectHash: r.SubjectHash, RequestID: r.ID, CreatedAt: time.Now()}); err != nil {
+            return nil, nil, err
+        }
+        if outcome.Removed, err = s.erase(ctx, r.PhoneNumber, nil); err != nil {
+            return nil, nil, err
+        }
+    case KindRectification:
+        if outcome.Removed, err = s.erase(ctx, r.PhoneNumber, r.Records); err != nil {
+            return nil, nil, err
+        }
+    }
+
+    closed, err := s.store.Close(ctx, r.ID, StatusCompleted, audit.ActorFromContext(ctx).ID, outcome, r.Kind == KindErasure)
+    if err != nil {
+        return nil, nil, err
+    }
+    return closed, export, nil
+}
+
+// Reject closes an open request without acting on it, e.g. when the
+// requester turns out not to be the subject
+func (s *Service) Reject(ctx context.Context, requestID, reason string) (*Request, error) {
+    r, err := s.open(ctx, requestID)
+    if err != nil {
+        return nil, err
+    }
+    reason = strings.TrimSpace(reason)
+    if reason == "" {
+        return nil, &refusal.Error{Reason: ErrReasonRequired, ID: requestID}
+    }
+    if err := s.log(ctx, audit.ActionSubjectRequestReject, r, map[string]string{"reason": reason}); err != nil {
+        return nil, err
+    }
+    return s.store.Close(ctx, r.ID, StatusRejected, audit.ActorFromContext(ctx).ID, &Outcome{Reason: reason}, true)
+}
+
+// Extend moves an open request's deadline back by days, once
+func (s *Service) Extend(ctx context.Context, requestID string, days int, reason string) (*Request, error) {
+    r, err := s.open(ctx, requestID)
+    if err != nil {
+        return nil, err
+    }
+    extension := time.Duration(days) * 24 * time.Hour
+    if days < 1 || extension > MaxExtension {
+        return nil, &refusal.Error{Reason: ErrInvalidExtension, ID: requestID}
+    }
+    reason = strings.TrimSpace(reason)
+    if reason == "" {
+        return nil, &refusal.Error{Reason: ErrReasonRequired, ID: requestID}
+    }
+    if r.ExtendedAt != nil {
+        return nil, &refusal.Error{Reason: ErrAlreadyExtended, ID: requestID}
+    }
+    err = s.log(ctx, audit.ActionSubjectRequestExtend, r, map[string]string{"days": strconv.Itoa(days), "reason": reason})
+    if err != nil {
+        return nil, err
+    }
+    return s.store.Extend(ctx, r.ID, r.DueAt.Add(extension), reason)
+}
+
+// open loads a request that can still be acted on
+func (s *Service) open(ctx context.Context, requestID string) (*Request, error) {
+    if err := s.authorize(ctx); err != nil {
+        return nil, err
+    }
+    r, err := s.store.Get(ctx, requestID)
+    if err != nil {
+        return nil, err
+    }
+    if r.Status != StatusOpen {
+        return nil, &refusal.Error{Reason: ErrClosed, ID: requestID}
+    }
+    return r, nil
+}
+
+// export searches every source for the subject
+func (s *Service) export(ctx context.Context, r *Request) (*Export, error) {
+    export := &Export{
+        RequestID:   r.ID,
+        Subject:     r.PhoneNumber,
+        GeneratedAt: time.Now().UTC(),
+        Records:     []Record{},
+        Retained:    retained,
+    }
+    for _, source := range s.sources {
+        records, err := source.Find(ctx, r.PhoneNumber)
+        if err != nil {
+            return nil, err
+        }
+        export.Records = append(export.Records, records...)
+    }
+    return export, nil
+}
+
+func (s *Service) erase(ctx context.Context, phoneNumber string, only []string) ([]Removal, error) {
+    var removed []Removal
+    for _, source := range s.sources {
+        r, err := source.Erase(ctx, phoneNumber, only)
+        if err != nil {
+            return nil, err
+        }
+        removed = append(removed, r...)
+    }
+    return removed, nil
+}
+
+// write keeps a copy of an access export in the export directory
+func (s *Service) write(export *Export) (*ExportFile, error) {
+    encoded, err := json.MarshalIndent(export, "", "  ")
+    if err != nil {
+        return nil, err
+    }
+    name := fmt.Sprintf("%s-subject-export-%s.json", export.RequestID, export.GeneratedAt.Format("20060102T150405Z"))
+    if err := os.WriteFile(filepath.Join(s.exportDir, name), encoded, 0600); err != nil {
+        return nil, fmt.Errorf("failed to write subject export: %w", err)
+    }
+    sum := sha256.Sum256(encoded)
+    return &ExportFile{File: name, SHA256: hex.EncodeToString(sum[:]), Records: len(export.Records)}, nil
+}
+
+// authorize lets the platform's operators, and internal services, through
+func (s *Service) authorize(ctx context.Context) error {
+    actor := audit.ActorFromContext(ctx)
+    if actor.Type == audit.ActorSystem {
+        return nil
+    }
+    if actor.TenantID != tenancy.PlatformTenantID {
+        return &refusal.Error{Reason: ErrNotPlatform}
+    }
+    return s.policy.Require(ctx, actor, rbac.PermSubjectRequests, nil)
+}
+
+func (s *Service) log(ctx context.Context, action string, r *Request, metadata map[string]string) error {
+    if metadata == nil {
+        metadata = map[string]string{}
+    }
+    metadata["request_id"] = r.ID
+    metadata["kind"] = r.Kind
+    return s.auditLog.Log(ctx, audit.Entry{Action: action, TargetType: "phone", Target: r.PhoneNumber, Metadata: metadata})
+}
@@ -0,0 +1,392 @@
+// pkg/subject/source.go
+package subject
+
+import (
+    "context"
+    "database/sql"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/go-redis/redis/v8"
+    "github.com/streadway/amqp"
+    "gorm.io/gorm"
+    "secure-iran-intel/pkg/audit"
+    "secure-iran-intel/pkg/envelope"
+    "secure-iran-intel/pkg/lifecycle"
+    "secure-iran-intel/pkg/tenancy"
+)
+
+// Source is one store that may hold records about a subject, in every
+// tenant. phoneNumber is in E.164.
+type Source interface {
+    Find(ctx context.Context, phoneNumber string) ([]Record, error)
+    // Erase removes the subject's records, or only those with an ID in
+    // only when it is not empty
+    Erase(ctx context.Context, phoneNumber string, only []string) ([]Removal, error)
+}
+
+// table is a table of results or reports naming the subject in column.
+// Sealed rows are found by their blind index (migration 020) and rows
+// written before encryption by the plaintext value.
+type table struct {
+    name       string
+    column     string
+    index      string
+    timeColumn string
+}
+
+var tables = []table{
+    // 006_intelligence_engine
+    {name: "email_discovery_results", column: "phone_number", index: "phone_number_index", timeColumn: "discovered_at"},
+    {name: "social_graphs", column: "phone_number", index: "phone_number_index", timeColumn: "generated_at"},
+    {name: "behavioral_patterns", column: "user_identifier", index: "user_identifier_index", timeColumn: "detected_at"},
+    {name: "risk_assessments", column: "phone_number", index: "phone_number_index", timeColumn: "assessed_at"},
+    {name: "intelligence_reports", column: "phone_number", index: "phone_number_index", timeColumn: "generated_at"},
+}
+
+// notHeld excludes rows under a case on legal hold
+const notHeld = `NOT EXISTS (SELECT 1 FROM legal_holds h
+    WHERE h.tenant_id = t.tenant_id AND h.case_reference = t.case_reference AND h.released_at IS NULL)`
+
+// PostgresSource finds task results and reports in every tenant's tables
+type PostgresSource struct {
+    db   *gorm.DB
+    keys *envelope.Keyring
+}
+
+func NewPostgresSource(db *gorm.DB, keys *envelope.Keyring) *PostgresSource {
+    return &PostgresSource{db: db, keys: keys}
+}
+
+func (p *PostgresSource) Find(ctx context.Context, phoneNumber string) ([]Record, error) {
+    indexes, err := p.keys.BlindIndexes(ctx, phoneNumber)
+    if err != nil {
+        return nil, err
+    }
+
+    var records []Record
+    err = tenancy.SystemTransaction(ctx, p.db, func(tx *gorm.DB) error {
+        for _, t := range tables {
+            where, args := t.matching(phoneNumber, indexes, nil)
+            var rows []struct {
+                ID            string
+                TenantID      string
+                CaseReference sql.NullString
+                RecordedAt    *time.Time
+                Data          string
+            }
+            query := fmt.Sprintf("SELECT id, tenant_id, case_reference, %s AS recorded_at, row_to_json(t)::text AS data FROM %s t WHERE %s",
+                t.timeColumn, t.name, where)
+            if err := tx.Raw(query, args...).Scan(&rows).Error; err != nil {
+                return fmt.Errorf("%s: %w", t.name, err)
+            }
+            for _, row := range rows {
+                data, err := p.open(ctx, row.Data)
+                if err != nil {
+                    return fmt.Errorf("%s: %w", t.name, err)
+                }
+                records = append(records, Record{
+                    Store:         "postgres",
+                    Kind:          t.name,
+                    ID:            row.ID,
+                    TenantID:      row.TenantID,
+                    CaseReference: row.CaseReference.String,
+                    RecordedAt:    row.RecordedAt,
+                    Data:          data,
+                })
+            }
+        }
+        return nil
+    })
+    if err != nil {
+        return nil, fmt.Errorf("failed to search results: %w", err)
+    }
+    return records, nil
+}
+
+func (p *PostgresSource) Erase(ctx context.Context, phoneNumber string, only []string) ([]Removal, error) {
+    indexes, err := p.keys.BlindIndexes(ctx, phoneNumber)
+    if err != nil {
+        return nil, err
+    }
+
+    var removed []Removal
+    err = tenancy.SystemTransaction(ctx, p.db, func(tx *gorm.DB) error {
+        for _, t := range tables {
+            where, args := t.matching(phoneNumber, indexes, only)
+            var matched int64
+            if err := tx.Raw(fmt.Sprintf("SELECT count(*) FROM %s t WHERE %s", t.name, where), args...).Scan(&matched).Error; err != nil {
+                return fmt.Errorf("%s: %w", t.name, err)
+            }
+            result := tx.Exec(fmt.Sprintf("DELETE FROM %s t WHERE (%s) AND %s", t.name, where, notHeld), args...)
+            if result.Error != nil {
+                return fmt.Errorf("%s: %w", t.name, result.Error)
+            }
+            removed = append(removed, Removal{Store: "postgres", Kind: t.name, Action: "deleted", Count: result.RowsAffected})
+            if held := matched - result.RowsAffected; held > 0 {
+                removed = append(removed, Removal{Store: "postgres", Kind: t.name, Action: "held", Count: held})
+            }
+        }
+        return nil
+    })
+    if err != nil {
+        return nil, fmt.Errorf("failed to erase results: %w", err)
+    }
+    return removed, nil
+}
+
+// matching is the condition selecting the subject's rows of t
+func (t table) matching(phoneNumber string, indexes map[string]string, only []string) (string, []interface{}) {
+    where := fmt.Sprintf("(%s IS NULL AND %s = ?)", t.index, t.column)
+    args := []interface{}{phoneNumber}
+    if len(indexes) > 0 {
+        pairs := make([][]interface{}, 0, len(indexes))
+        for tenantID, index := range indexes {
+            pairs = append(pairs, []interface{}{tenantID, index})
+        }
+        where += fmt.Sprintf(" OR (tenant_id, %s) IN ?", t.index)
+        args = append(args, pairs)
+    }
+    if len(only) > 0 {
+        // Record IDs from other stores are not UUIDs
+        where = fmt.Sprintf("(%s) AND id::text IN ?", where)
+        args = append(args, only)
+    }
+    return where, args
+}
+
+// open returns a row as JSON with its sealed values opened and its blind
+// indexes dropped. Values of shredded tenants are left out.
+func (p *PostgresSource) open(ctx context.Context, row string) (json.RawMessage, error) {
+    var columns map[string]interface{}
+    if err := json.Unmarshal([]byte(row), &columns); err != nil {
+        return nil, err
+    }
+    for name, value := range columns {
+        if strings.HasSuffix(name, "_index") {
+            delete(columns, name)
+            continue
+        }
+        sealed, ok := value.(string)
+        if !ok || !envelope.IsSealed(sealed) {
+            continue
+        }
+        plaintext, err := p.keys.Open(ctx, sealed)
+        switch {
+        case errors.Is(err, envelope.ErrKeyDestroyed):
+            columns[name] = nil
+        case err != nil:
+            return nil, err
+        case json.Valid(plaintext):
+            columns[name] = json.RawMessage(plaintext)
+        default:
+            columns[name] = string(plaintext)
+        }
+    }
+    return json.Marshal(columns)
+}
+
+// QueueSource finds tasks naming the subject still waiting in RabbitMQ
+// queues, by their normalized number
+type QueueSource struct {
+    conn   *amqp.Connection
+    queues []string
+}
+
+func NewQueueSource(conn *amqp.Connection, queues ...string) *QueueSource {
+    return &QueueSource{conn: conn, queues: queues}
+}
+
+// queuedTask is the part of an orchestrator task a search reads
+type queuedTask struct {
+    ID            string     `json:"id"`
+    TenantID      string     `json:"tenant_id"`
+    Normalized    string     `json:"normalized"`
+    CaseReference string     `json:"case_reference"`
+    CreatedAt     *time.Time `json:"created_at"`
+}
+
+func parseTask(d amqp.Delivery) (queuedTask, bool) {
+    var task queuedTask
+    if err := json.Unmarshal(d.Body, &task); err != nil {
+        return task, false
+    }
+    return task, true
+}
+
+func (q *QueueSource) Find(ctx context.Context, phoneNumber string) ([]Record, error) {
+    var records []Record
+    for _, queue := range q.queues {
+        matched, err := lifecycle.SweepQueue(ctx, q.conn, queue, q.matching(phoneNumber, nil), false)
+        if err != nil {
+            return nil, fmt.Errorf("failed to search queue %s: %w", queue, err)
+        }
+        for _, d := range matched {
+            task, _ := parseTask(d)
+            records = append(records, Record{
+                Store:         "rabbitmq",
+                Kind:          queue,
+                ID:            task.ID,
+                TenantID:      task.TenantID,
+                CaseReference: task.CaseReference,
+                RecordedAt:    task.CreatedAt,
+                Data:          json.RawMessage(d.Body),
+            })
+        }
+    }
+    return records, nil
+}
+
+func (q *QueueSource) Erase(ctx context.Context, phoneNumber string, only []string) ([]Removal, error) {
+    var removed []Removal
+    for _, queue := range q.queues {
+        matched, err := lifecycle.SweepQueue(ctx, q.conn, queue, q.matching(phoneNumber, only), true)
+        if err != nil {
+            return nil, fmt.Errorf("failed to erase from queue %s: %w", queue, err)
+        }
+        removed = append(removed, Removal{Store: "rabbitmq", Kind: queue, Action: "deleted", Count: int64(len(matched))})
+    }
+    return removed, nil
+}
+
+func (q *QueueSource) matching(phoneNumber string, only []string) func(amqp.Delivery) bool {
+    return func(d amqp.Delivery) bool {
+        task, ok := parseTask(d)
+        if !ok || task.Normalized != phoneNumber {
+            return false
+        }
+        return len(only) == 0 || contains(only, task.ID)
+    }
+}
+
+// Keys examined per SCAN round trip
+const redisBatch = 500
+
+// RedisSource finds cached entries whose key contains the number, with or
+// without its leading +
+type RedisSource struct {
+    redis *redis.Client
+}
+
+func NewRedisSource(client *redis.Client) *RedisSource {
+    return &RedisSource{redis: client}
+}
+
+func (r *RedisSource) keys(ctx context.Context, phoneNumber string) ([]string, error) {
+    var keys []string
+    iter := r.redis.Scan(ctx, 0, "*"+strings.TrimPrefix(phoneNumber, "+")+"*", redisBatch).Iterator()
+    for iter.Next(ctx) {
+        keys = append(keys, iter.Val())
+    }
+    if err := iter.Err(); err != nil {
+        return nil, fmt.Errorf("failed to scan cached entries: %w", err)
+    }
+    return keys, nil
+}
+
+func (r *RedisSource) Find(ctx context.Context, phoneNumber string) ([]Record, error) {
+    keys, err := r.keys(ctx, phoneNumber)
+    if err != nil {
+        return nil, err
+    }
+    records := make([]Record, 0, len(keys))
+    for _, key := range keys {
+        record := Record{Store: "redis", Kind: "keys", ID: key}
+        // Only plain values are exported; other types are listed by key
+        value, err := r.redis.Get(ctx, key).Result()
+        switch {
+        case err == nil && json.Valid([]byte(value)):
+            record.Data = json.RawMessage(value)
+        case err == nil:
+            record.Data, _ = json.Marshal(value)
+        case err != redis.Nil && !strings.HasPrefix(err.Error(), "WRONGTYPE"):
+            return nil, fmt.Errorf("failed to read cached entry: %w", err)
+        }
+        records = append(records, record)
+    }
+    return records, nil
+}
+
+func (r *RedisSource) Erase(ctx context.Context, phoneNumber string, only []string) ([]Removal, error) {
+    keys, err := r.keys(ctx, phoneNumber)
+    if err != nil {
+        return nil, err
+    }
+    var deleted int64
+    for _, key := range keys {
+        if len(only) > 0 && !contains(only, key) {
+            continue
+        }
+        n, err := r.redis.Del(ctx, key).Result()
+        if err != nil {
+            return nil, fmt.Errorf("failed to delete cached entry: %w", err)
+        }
+        deleted += n
+    }
+    return []Removal{{Store: "redis", Kind: "keys", Action: "deleted", Count: deleted}}, nil
+}
+
+// AuditSource lists the audit records whose target is the number: which
+// jobs looked the subject up, for whom, under which case and why. The log
+// is append-only, so nothing is erased from it.
+type AuditSource struct {
+    store audit.Store
+}
+
+func NewAuditSource(store audit.Store) *AuditSource {
+    return &AuditSource{store: store}
+}
+
+func (a *AuditSource) Find(ctx context.Context, phoneNumber string) ([]Record, error) {
+    hash, err := Hash(phoneNumber)
+    if err != nil {
+        return nil, err
+    }
+    var records []Record
+    err = a.store.Scan(ctx, func(r *audit.Record) error {
+        if r.TargetHash != hash {
+            return nil
+        }
+        data, err := json.Marshal(map[string]interface{}{
+            "actor_type":    r.ActorType,
+            "actor_id":      r.ActorID,
+            "justification": r.Justification,
+            "metadata":      r.Metadata,
+        })
+        if err != nil {
+            return err
+        }
+        recordedAt := r.RecordedAt
+        records = append(records, Record{
+            Store:         "audit_log",
+            Kind:          r.Action,
+            ID:            strconv.FormatInt(r.Seq, 10),
+            TenantID:      r.TenantID,
+            CaseReference: r.Metadata["case_reference"],
+            RecordedAt:    &recordedAt,
+            Data:          data,
+        })
+        return nil
+    })
+    if err != nil {
+        return nil, fmt.Errorf("failed to search audit log: %w", err)
+    }
+    return records, nil
+}
+
+func (a *AuditSource) Erase(ctx context.Context, phoneNumber string, only []string) ([]Removal, error) {
+    return nil, nil
+}
+
+func contains(values []string, value string) bool {
+    for _, v := range values {
+        if v == value {
+            return true
+        }
+    }
+    return false
+}
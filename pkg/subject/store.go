@@ -0,0 +1,184 @@
+// pkg/subject/store.go
+package subject
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "time"
+
+    "gorm.io/gorm"
+    "gorm.io/gorm/clause"
+    "secure-iran-intel/pkg/refusal"
+    "secure-iran-intel/pkg/tenancy"
+)
+
+// Store keeps subject requests and tombstones. Close and Extend must only
+// change an open request, so two operators cannot both act on one.
+type Store interface {
+    Create(ctx context.Context, r *Request) error
+    // Get returns ErrUnknownRequest when there is no such request
+    Get(ctx context.Context, requestID string) (*Request, error)
+    // List returns requests with status, or every request when status is
+    // "", soonest deadline first
+    List(ctx context.Context, status string) ([]Request, error)
+    // Close moves an open request to status, clearing its phone number
+    // when forget is set; it returns ErrClosed for a closed request
+    Close(ctx context.Context, requestID, status, closedBy string, outcome *Outcome, forget bool) (*Request, error)
+    // Extend moves an open request's deadline once; it returns ErrClosed
+    // or ErrAlreadyExtended
+    Extend(ctx context.Context, requestID string, dueAt time.Time, reason string) (*Request, error)
+    // AddTombstone keeps the first tombstone of a subject
+    AddTombstone(ctx context.Context, t *Tombstone) error
+    Checker
+}
+
+// Checker reports which subjects have been erased
+type Checker interface {
+    // Erased returns the hashes, from Hash, that have a tombstone
+    Erased(ctx context.Context, hashes []string) (map[string]bool, error)
+}
+
+// ErasedNumbers returns which of the normalized numbers belong to erased
+// subjects. Every path that queues lookups drops them first.
+func ErasedNumbers(ctx context.Context, checker Checker, numbers []string) (map[string]bool, error) {
+    hashes := make([]string, 0, len(numbers))
+    for _, number := range numbers {
+        hash, err := Hash(number)
+        if err != nil {
+            return nil, err
+        }
+        hashes = append(hashes, hash)
+    }
+    found, err := checker.Erased(ctx, hashes)
+    if err != nil {
+        return nil, err
+    }
+
+    erased := make(map[string]bool)
+    for i, number := range numbers {
+        if found[hashes[i]] {
+            erased[number] = true
+        }
+    }
+    return erased, nil
+}
+
+// GormStore keeps requests and tombstones in the subject_requests and
+// subject_tombstones tables (migration 021). Neither is under row-level
+// security; sealing the phone number needs the platform tenant's scope.
+type GormStore struct {
+    db *gorm.DB
+}
+
+func NewGormStore(db *gorm.DB) *GormStore {
+    return &GormStore{db: db}
+}
+
+func (s *GormStore) Create(ctx context.Context, r *Request) error {
+    err := tenancy.Transaction(ctx, s.db, tenancy.PlatformTenantID, func(tx *gorm.DB) error {
+        return tx.Create(r).Error
+    })
+    if err != nil {
+        return fmt.Errorf("failed to store subject request: %w", err)
+    }
+    return nil
+}
+
+func (s *GormStore) Get(ctx context.Context, requestID string) (*Request, error) {
+    var r Request
+    err := s.db.WithContext(ctx).Where("id = ?", requestID).First(&r).Error
+    if errors.Is(err, gorm.ErrRecordNotFound) {
+        return nil, &refusal.Error{Reason: ErrUnknownRequest, ID: requestID}
+    }
+    if err != nil {
+        return nil, err
+    }
+    return &r, nil
+}
+
+func (s *GormStore) List(ctx context.Context, status string) ([]Request, error) {
+    query := s.db.WithContext(ctx).Order("due_at")
+    if status != "" {
+        query = query.Where("status = ?", status)
+    }
+    var requests []Request
+    if err := query.Find(&requests).Error; err != nil {
+        return nil, fmt.Errorf("failed to list subject requests: %w", err)
+    }
+    return requests, nil
+}
+
+func (s *GormStore) Close(ctx context.Context, requestID, status, closedBy string, outcome *Outcome, forget bool) (*Request, error) {
+    // Serializers do not apply to map updates
+    encoded, err := json.Marshal(outcome)
+    if err != nil {
+        return nil, err
+    }
+    updates := map[string]interface{}{
+        "status":    status,
+        "closed_by": closedBy,
+        "closed_at": time.Now(),
+        "outcome":   string(encoded),
+    }
+    if forget {
+        updates["phone_number"] = ""
+    }
+    return s.update(ctx, requestID, s.db.WithContext(ctx).Model(&Request{}).Where("status = ?", StatusOpen), updates)
+}
+
+func (s *GormStore) Extend(ctx context.Context, requestID string, dueAt time.Time, reason string) (*Request, error) {
+    updates := map[string]interface{}{
+        "due_at":           dueAt,
+        "extended_at":      time.Now(),
+        "extension_reason": reason,
+    }
+    r, err := s.update(ctx, requestID, s.db.WithContext(ctx).Model(&Request{}).Where("status = ? AND extended_at IS NULL", StatusOpen), updates)
+    if errors.Is(err, ErrClosed) {
+        // Tell an extended request from a closed one
+        if current, getErr := s.Get(ctx, requestID); getErr == nil && current.Status == StatusOpen {
+            return nil, &refusal.Error{Reason: ErrAlreadyExtended, ID: requestID}
+        }
+    }
+    return r, err
+}
+
+// update applies updates to the request when query still matches it
+func (s *GormStore) update(ctx context.Context, requestID string, query *gorm.DB, updates map[string]interface{}) (*Request, error) {
+    result := query.Where("id = ?", requestID).Updates(updates)
+    if result.Error != nil {
+        return nil, fmt.Errorf("failed to update subject request: %w", result.Error)
+    }
+    if result.RowsAffected == 0 {
+        if _, err := s.Get(ctx, requestID); err != nil {
+            return nil, err
+        }
+        return nil, &refusal.Error{Reason: ErrClosed, ID: requestID}
+    }
+    return s.Get(ctx, requestID)
+}
+
+func (s *GormStore) AddTombstone(ctx context.Context, t *Tombstone) error {
+    err := s.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(t).Error
+    if err != nil {
+        return fmt.Errorf("failed to store tombstone: %w", err)
+    }
+    return nil
+}
+
+func (s *GormStore) Erased(ctx context.Context, hashes []string) (map[string]bool, error) {
+    erased := make(map[string]bool)
+    if len(hashes) == 0 {
+        return erased, nil
+    }
+    var found []string
+    err := s.db.WithContext(ctx).Model(&Tombstone{}).Where("subject_hash IN ?", hashes).Pluck("subject_hash", &found).Error
+    if err != nil {
+        return nil, fmt.Errorf("failed to check tombstones: %w", err)
+    }
+    for _, h := range found {
+        erased[h] = true
+    }
+    return erased, nil
+}
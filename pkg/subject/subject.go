@@ -0,0 +1,158 @@
+// pkg/subject/subject.go
+package subject
+
+import (
+    "encoding/json"
+    "net/http"
+    "time"
+
+    "secure-iran-intel/pkg/audit"
+    "secure-iran-intel/pkg/refusal"
+)
+
+// What a data subject can ask for
+const (
+    KindAccess        = "access"        // A copy of everything held about them
+    KindRectification = "rectification" // Removal of records they have shown to be wrong
+    KindErasure       = "erasure"       // Removal of everything, and no collection again
+)
+
+// Request statuses. Only open requests can be fulfilled, extended or
+// rejected.
+const (
+    StatusOpen      = "open"
+    StatusCompleted = "completed"
+    StatusRejected  = "rejected"
+)
+
+// A request must be answered within ResponsePeriod of being received. The
+// deadline can be extended once, by at most MaxExtension, with a reason.
+const (
+    ResponsePeriod = 30 * 24 * time.Hour
+    MaxExtension   = 60 * 24 * time.Hour
+)
+
+// Request is one data-subject request. Requests span tenants, so only
+// platform operators handle them. The phone number is sealed under the
+// platform tenant's data key and cleared once an erasure is done; the
+// hash stays, for the tombstone and the audit log.
+type Request struct {
+    ID          string `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+    Kind        string `json:"kind"`
+    PhoneNumber string `json:"phone_number,omitempty" gorm:"serializer:encrypted"`
+    SubjectHash string `json:"subject_hash"`
+    // How the operator confirmed the requester is the subject, e.g.
+    // "sms_otp" and the verification's reference
+    VerificationMethod    string `json:"verification_method"`
+    VerificationReference string `json:"verification_reference"`
+    // Records is what a rectification removes, by record ID from an
+    // earlier search
+    Records         []string   `json:"records,omitempty" gorm:"serializer:json;type:jsonb"`
+    Status          string     `json:"status"`
+    ReceivedAt      time.Time  `json:"received_at"`
+    DueAt           time.Time  `json:"due_at"`
+    ExtendedAt      *time.Time `json:"extended_at,omitempty"`
+    ExtensionReason string     `json:"extension_reason,omitempty"`
+    OpenedBy        string     `json:"opened_by"`
+    ClosedBy        string     `json:"closed_by,omitempty"`
+    ClosedAt        *time.Time `json:"closed_at,omitempty"`
+    Outcome         *Outcome   `json:"outcome,omitempty" gorm:"serializer:json;type:jsonb"`
+}
+
+func (Request) TableName() string {
+    return "subject_requests"
+}
+
+// Overdue reports whether an open request has passed its deadline
+func (r *Request) Overdue(now time.Time) bool {
+    return r.Status == StatusOpen && now.After(r.DueAt)
+}
+
+// Outcome is how a request was closed
+type Outcome struct {
+    Export  *ExportFile `json:"export,omitempty"`
+    Removed []Removal   `json:"removed,omitempty"`
+    Reason  string      `json:"reason,omitempty"` // Why it was rejected
+}
+
+// ExportFile is the copy of an access request's export kept in the export
+// directory
+type ExportFile struct {
+    File    string `json:"file"`
+    SHA256  string `json:"sha256"`
+    Records int    `json:"records"`
+}
+
+// Record is one thing held about the subject
+type Record struct {
+    Store         string          `json:"store"` // postgres, rabbitmq, redis, audit_log
+    Kind          string          `json:"kind"`  // Table, queue, key class or audit action
+    ID            string          `json:"id"`
+    TenantID      string          `json:"tenant_id,omitempty"`
+    CaseReference string          `json:"case_reference,omitempty"`
+    RecordedAt    *time.Time      `json:"recorded_at,omitempty"`
+    Data          json.RawMessage `json:"data,omitempty"` // As stored, with sealed values opened
+}
+
+// Removal is what erasing the subject did to one kind of record
+type Removal struct {
+    Store  string `json:"store"`
+    Kind   string `json:"kind"`
+    Action string `json:"action"` // deleted, or held when a legal hold kept it
+    Count  int64  `json:"count"`
+}
+
+// Retention is data about the subject that is deliberately kept, and why
+type Retention struct {
+    Kind   string `json:"kind"`
+    Reason string `json:"reason"`
+}
+
+// retained is what no request removes
+var retained = []Retention{
+    {Kind: "audit_log", Reason: "append-only and hash-chained; the number is stored hashed, and the records are listed in access exports"},
+    {Kind: "legal_holds", Reason: "records under a case on legal hold are kept until the hold is released"},
+    {Kind: "bulk_uploads", Reason: "uploaded files are not searched; they are deleted with their job at the end of the tenant's retention period"},
+}
+
+// Export is the machine-readable answer to an access request
+type Export struct {
+    RequestID   string      `json:"request_id"`
+    Subject     string      `json:"subject"` // E.164
+    GeneratedAt time.Time   `json:"generated_at"`
+    Records     []Record    `json:"records"`
+    Retained    []Retention `json:"retained"`
+}
+
+// Tombstone marks an erased subject. Jobs drop numbers with a tombstone
+// before anything is looked up or stored.
+type Tombstone struct {
+    SubjectHash string    `json:"subject_hash" gorm:"primary_key"`
+    RequestID   string    `json:"request_id" gorm:"type:uuid"`
+    CreatedAt   time.Time `json:"created_at"`
+}
+
+func (Tombstone) TableName() string {
+    return "subject_tombstones"
+}
+
+// Hash identifies a subject by their E.164 number, the way the audit log
+// records phone targets, so both can be searched with it
+func Hash(phoneNumber string) (string, error) {
+    return audit.HashTarget("phone", phoneNumber)
+}
+
+// Refusal reasons. Match them with errors.Is.
+var (
+    ErrUnknownRequest   = refusal.New("SUBJECT_REQUEST_NOT_FOUND", http.StatusNotFound, "subject request not found")
+    ErrNotPlatform      = refusal.New("SUBJECT_REQUEST_NOT_PLATFORM_OPERATOR", http.StatusForbidden, "only platform operators can handle subject requests")
+    ErrInvalidKind      = refusal.New("SUBJECT_REQUEST_INVALID", http.StatusBadRequest, "unknown request kind")
+    ErrInvalidPhone     = refusal.New("SUBJECT_REQUEST_INVALID", http.StatusBadRequest, "not a valid phone number")
+    ErrNotVerified      = refusal.New("SUBJECT_NOT_VERIFIED", http.StatusBadRequest, "the requester's identity must be verified first")
+    ErrNoRecords        = refusal.New("SUBJECT_REQUEST_INVALID", http.StatusBadRequest, "a rectification must name the records to remove")
+    ErrClosed           = refusal.New("SUBJECT_REQUEST_CLOSED", http.StatusConflict, "subject request is already closed")
+    ErrAlreadyExtended  = refusal.New("SUBJECT_REQUEST_CLOSED", http.StatusConflict, "the deadline has already been extended")
+    ErrInvalidExtension = refusal.New("SUBJECT_REQUEST_INVALID", http.StatusBadRequest, "extensions are between one day and 60 days")
+    ErrReasonRequired   = refusal.New("SUBJECT_REQUEST_INVALID", http.StatusBadRequest, "a reason is required")
+    ErrErased           = refusal.New("SUBJECT_ERASED", http.StatusConflict, "the subject has had their data erased")
+)
@@ -0,0 +1,69 @@
+// pkg/suppression/screen.go
+package suppression
+
+import (
+    "context"
+    "log"
+
+    "secure-iran-intel/pkg/audit"
+)
+
+// Screener finds the numbers of a job that must not be processed. Every
+// path that queues lookups screens them first.
+type Screener struct {
+    checker  Checker
+    auditLog *audit.Logger
+}
+
+func NewScreener(checker Checker, auditLog *audit.Logger) *Screener {
+    return &Screener{checker: checker, auditLog: auditLog}
+}
+
+// Screen returns which of the tenant's normalized numbers are suppressed.
+// Each one is recorded in the audit log, which keeps only its hash, with
+// metadata such as the job it was left out of. Callers must drop them and
+// must not tell the requester which numbers they were.
+func (s *Screener) Screen(ctx context.Context, tenantID string, numbers []string, metadata map[string]string) (map[string]bool, error) {
+    hashes := make([]string, 0, len(numbers))
+    for _, number := range numbers {
+        hash, err := Hash(number)
+        if err != nil {
+            return nil, err
+        }
+        hashes = append(hashes, hash)
+    }
+    suppressed, err := s.checker.Suppressed(ctx, tenantID, hashes)
+    if err != nil {
+        return nil, err
+    }
+    if len(suppressed) == 0 {
+        return nil, nil
+    }
+
+    blocked := make(map[string]bool, len(suppressed))
+    var entries []audit.Entry
+    for i, number := range numbers {
+        category, ok := suppressed[hashes[i]]
+        if !ok || blocked[number] {
+            continue
+        }
+        blocked[number] = true
+
+        entryMetadata := map[string]string{"category": category}
+        for k, v := range metadata {
+            entryMetadata[k] = v
+        }
+        entries = append(entries, audit.Entry{
+            Action:     audit.ActionSuppressionBlock,
+            TargetType: "phone",
+            Target:     number,
+            Metadata:   entryMetadata,
+        })
+        log.Printf("🚫 Blocked suppressed number %s (%s) for tenant %s", hashes[i][:12], category, tenantID)
+    }
+    // Callers refuse the job when the blocks cannot be recorded
+    if err := s.auditLog.Log(ctx, entries...); err != nil {
+        return nil, err
+    }
+    return blocked, nil
+}
@@ -0,0 +1,108 @@
+// pkg/suppression/service.go
+package suppression
+
+import (
+    "context"
+    "strings"
+    "time"
+
+    "secure-iran-intel/pkg/audit"
+    "secure-iran-intel/pkg/normalizer"
+    "secure-iran-intel/pkg/rbac"
+    "secure-iran-intel/pkg/refusal"
+)
+
+// NewEntry is a number to stop processing
+type NewEntry struct {
+    PhoneNumber string `json:"phone_number" binding:"required"`
+    Category    string `json:"category" binding:"required"`
+    Reason      string `json:"reason" binding:"required"`
+}
+
+// Service keeps each tenant's suppression list. The platform tenant's list
+// is the global one.
+type Service struct {
+    store      Store
+    auditLog   *audit.Logger
+    policy     *rbac.Engine
+    normalizer *normalizer.PhoneNormalizer
+}
+
+func NewService(store Store, auditLog *audit.Logger, policy *rbac.Engine) *Service {
+    return &Service{
+        store:      store,
+        auditLog:   auditLog,
+        policy:     policy,
+        normalizer: normalizer.NewPhoneNormalizer("IR"),
+    }
+}
+
+// Add stops the actor's tenant, or every tenant when the actor operates
+// the platform, processing a number. Only its hash is kept.
+func (s *Service) Add(ctx context.Context, req NewEntry) (*Entry, error) {
+    actor := audit.ActorFromContext(ctx)
+    if err := s.policy.Require(ctx, actor, rbac.PermSuppressions, nil); err != nil {
+        return nil, err
+    }
+    switch req.Category {
+    case CategoryOptOut, CategoryMinor, CategoryProtected:
+    default:
+        return nil, &refusal.Error{Reason: ErrInvalidCategory, ID: actor.TenantID, Detail: req.Category}
+    }
+    reason := strings.TrimSpace(req.Reason)
+    if reason == "" {
+        return nil, &refusal.Error{Reason: ErrReasonRequired, ID: actor.TenantID}
+    }
+    phone, err := s.normalizer.NormalizePhone(req.PhoneNumber, "IR")
+    if err != nil {
+        return nil, &refusal.Error{Reason: ErrInvalidPhone, ID: actor.TenantID}
+    }
+
+    err = s.auditLog.Log(ctx, audit.Entry{
+        Action:     audit.ActionSuppressionAdd,
+        TargetType: "phone",
+        Target:     phone.Normalized,
+        Metadata:   map[string]string{"category": req.Category, "reason": reason},
+    })
+    if err != nil {
+        return nil, err
+    }
+    hash, err := Hash(phone.Normalized)
+    if err != nil {
+        return nil, err
+    }
+    e := &Entry{
+        TenantID:       actor.TenantID,
+        IdentifierHash: hash,
+        Category:       req.Category,
+        Reason:         reason,
+        AddedBy:        actor.ID,
+        AddedAt:        time.Now(),
+    }
+    if err := s.store.Add(ctx, e); err != nil {
+        return nil, err
+    }
+    return e, nil
+}
+
+// Remove lets the actor's tenant process the entry's number again. Global
+// entries block it for as long as they stand.
+func (s *Service) Remove(ctx context.Context, entryID string) (*Entry, error) {
+    actor := audit.ActorFromContext(ctx)
+    if err := s.policy.Require(ctx, actor, rbac.PermSuppressions, nil); err != nil {
+        return nil, err
+    }
+    if err := s.auditLog.Log(ctx, audit.Entry{Action: audit.ActionSuppressionRemove, TargetType: "suppression", Target: entryID}); err != nil {
+        return nil, err
+    }
+    return s.store.Remove(ctx, actor.TenantID, entryID, actor.ID)
+}
+
+// List returns the actor's tenant's entries, removed ones included
+func (s *Service) List(ctx context.Context) ([]Entry, error) {
+    actor := audit.ActorFromContext(ctx)
+    if err := s.policy.Require(ctx, actor, rbac.PermSuppressions, nil); err != nil {
+        return nil, err
+    }
+    return s.store.List(ctx, actor.TenantID)
+}
@@ -0,0 +1,123 @@
+// pkg/suppression/store.go
+package suppression
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "time"
+
+    "gorm.io/gorm"
+    "secure-iran-intel/pkg/refusal"
+    "secure-iran-intel/pkg/tenancy"
+)
+
+// Store keeps the registry. Add must refuse a second active entry for the
+// same number in a tenant, and Remove must only remove an active one.
+type Store interface {
+    // Add returns ErrAlreadySuppressed when the number is already blocked
+    // in the entry's tenant
+    Add(ctx context.Context, e *Entry) error
+    // Remove returns ErrUnknownEntry or ErrEntryRemoved
+    Remove(ctx context.Context, tenantID, entryID, removedBy string) (*Entry, error)
+    // List returns the tenant's entries, removed ones included, newest
+    // first
+    List(ctx context.Context, tenantID string) ([]Entry, error)
+    Checker
+}
+
+// Checker reports which numbers a tenant must not process
+type Checker interface {
+    // Suppressed returns the category of each hash, from Hash, blocked for
+    // the tenant by its own or a global entry
+    Suppressed(ctx context.Context, tenantID string, hashes []string) (map[string]string, error)
+}
+
+// GormStore keeps entries in the suppressions table (migration 022)
+type GormStore struct {
+    db *gorm.DB
+}
+
+func NewGormStore(db *gorm.DB) *GormStore {
+    return &GormStore{db: db}
+}
+
+func (s *GormStore) Add(ctx context.Context, e *Entry) error {
+    return tenancy.Transaction(ctx, s.db, e.TenantID, func(tx *gorm.DB) error {
+        var active int64
+        err := tx.Model(&Entry{}).
+            Where("tenant_id = ? AND identifier_hash = ? AND removed_at IS NULL", e.TenantID, e.IdentifierHash).
+            Count(&active).Error
+        if err != nil {
+            return err
+        }
+        if active > 0 {
+            return &refusal.Error{Reason: ErrAlreadySuppressed, ID: e.TenantID}
+        }
+        // The partial unique index catches a concurrent entry for the number
+        if err := tx.Create(e).Error; err != nil {
+            return fmt.Errorf("failed to add suppression entry: %w", err)
+        }
+        return nil
+    })
+}
+
+func (s *GormStore) Remove(ctx context.Context, tenantID, entryID, removedBy string) (*Entry, error) {
+    var e Entry
+    err := tenancy.Transaction(ctx, s.db, tenantID, func(tx *gorm.DB) error {
+        err := tx.Where("tenant_id = ? AND id = ?", tenantID, entryID).First(&e).Error
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            return &refusal.Error{Reason: ErrUnknownEntry, ID: tenantID}
+        }
+        if err != nil {
+            return err
+        }
+
+        now := time.Now()
+        result := tx.Model(&Entry{}).
+            Where("id = ? AND removed_at IS NULL", entryID).
+            Updates(map[string]interface{}{"removed_at": now, "removed_by": removedBy})
+        if result.Error != nil {
+            return fmt.Errorf("failed to remove suppression entry: %w", result.Error)
+        }
+        if result.RowsAffected == 0 {
+            return &refusal.Error{Reason: ErrEntryRemoved, ID: tenantID}
+        }
+        e.RemovedAt, e.RemovedBy = &now, removedBy
+        return nil
+    })
+    if err != nil {
+        return nil, err
+    }
+    return &e, nil
+}
+
+func (s *GormStore) List(ctx context.Context, tenantID string) ([]Entry, error) {
+    var entries []Entry
+    err := tenancy.Transaction(ctx, s.db, tenantID, func(tx *gorm.DB) error {
+        return tx.Where("tenant_id = ?", tenantID).Order("added_at DESC").Find(&entries).Error
+    })
+    return entries, err
+}
+
+func (s *GormStore) Suppressed(ctx context.Context, tenantID string, hashes []string) (map[string]string, error) {
+    suppressed := map[string]string{}
+    if len(hashes) == 0 {
+        return suppressed, nil
+    }
+    var entries []Entry
+    // Global entries belong to the platform tenant, outside the caller's
+    // scope; only the matches come back, never the list itself
+    err := tenancy.SystemTransaction(ctx, s.db, func(tx *gorm.DB) error {
+        return tx.Select("identifier_hash, category").
+            Where("tenant_id IN ? AND identifier_hash IN ? AND removed_at IS NULL", []string{tenantID, tenancy.PlatformTenantID}, hashes).
+            Find(&entries).Error
+    })
+    if err != nil {
+        return nil, fmt.Errorf("failed to check suppression list: %w", err)
+    }
+    for _, e := range entries {
+        suppressed[e.IdentifierHash] = e.Category
+    }
+    return suppressed, nil
+}
@@ -0,0 +1,58 @@
+// pkg/suppression/suppression.go
+package suppression
+
+import (
+    "net/http"
+    "time"
+
+    "secure-iran-intel/pkg/audit"
+    "secure-iran-intel/pkg/refusal"
+)
+
+// Why a number must not be processed
+const (
+    CategoryOptOut    = "opt_out"   // The person asked not to be looked up
+    CategoryMinor     = "minor"     // A reviewer found the number belongs to a minor
+    CategoryProtected = "protected" // Protected categories, e.g. journalists or counsel
+)
+
+// Entry keeps one number from being processed. Entries the platform's
+// operators add apply to every tenant; the others only to their own. The
+// number itself is never stored, only its hash.
+type Entry struct {
+    ID             string     `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+    TenantID       string     `json:"tenant_id" gorm:"type:uuid;not null"`
+    IdentifierHash string     `json:"identifier_hash" gorm:"not null"`
+    Category       string     `json:"category" gorm:"not null"`
+    Reason         string     `json:"reason"`
+    AddedBy        string     `json:"added_by"`
+    AddedAt        time.Time  `json:"added_at"`
+    RemovedBy      string     `json:"removed_by,omitempty"`
+    RemovedAt      *time.Time `json:"removed_at,omitempty"`
+}
+
+func (Entry) TableName() string {
+    return "suppressions"
+}
+
+// Active reports whether the entry still blocks the number
+func (e *Entry) Active() bool {
+    return e.RemovedAt == nil
+}
+
+// Hash is how a normalized number is identified in the registry. It is the
+// hash the audit log keeps for phone targets, so blocks and lookups of the
+// same number can be matched up.
+func Hash(phoneNumber string) (string, error) {
+    return audit.HashTarget("phone", phoneNumber)
+}
+
+// Refusal reasons. Match them with errors.Is.
+var (
+    ErrUnknownEntry      = refusal.New("SUPPRESSION_NOT_FOUND", http.StatusNotFound, "suppression entry not found")
+    ErrInvalidCategory   = refusal.New("SUPPRESSION_INVALID", http.StatusBadRequest, "unknown suppression category")
+    ErrInvalidPhone      = refusal.New("SUPPRESSION_INVALID", http.StatusBadRequest, "the phone number could not be normalized")
+    ErrAlreadySuppressed = refusal.New("SUPPRESSION_EXISTS", http.StatusConflict, "the number is already suppressed")
+    ErrEntryRemoved      = refusal.New("SUPPRESSION_REMOVED", http.StatusConflict, "suppression entry has already been removed")
+    ErrReasonRequired    = refusal.New("SUPPRESSION_INVALID", http.StatusBadRequest, "a reason is required")
+)
@@ -0,0 +1,73 @@
+// pkg/tenancy/tenancy.go
+package tenancy
+
+import (
+    "context"
+    "errors"
+
+    "gorm.io/gorm"
+)
+
+// PlatformTenantID owns the system roles (migration 007) and the operators
+// who administer other tenants
+const PlatformTenantID = "00000000-0000-0000-0000-000000000000"
+
+// ErrNoTenant is returned instead of running a query no tenant is scoped to
+var ErrNoTenant = errors.New("no tenant in scope")
+
+// ErrNotSystem is returned by SystemTransaction when the database login is
+// not a member of intel_system. Setting app.bypass_rls would then change
+// nothing, and fn would silently see no tenant's rows.
+var ErrNotSystem = errors.New("database login is not a member of intel_system")
+
+type tenantKey struct{}
+
+// WithTenant returns a context naming the tenant its queries are scoped to
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+    return context.WithValue(ctx, tenantKey{}, tenantID)
+}
+
+// FromContext returns the tenant a query runs for, or "" outside Transaction
+func FromContext(ctx context.Context) string {
+    tenantID, _ := ctx.Value(tenantKey{}).(string)
+    return tenantID
+}
+
+// Transaction runs fn in a transaction scoped to tenantID. Row-level
+// security (migration 013) hides every other tenant's rows from fn and
+// rejects writes of rows belonging to them. The scope is SET LOCAL, so it
+// ends with the transaction and never leaks to the next user of the
+// pooled connection. The statement context carries the tenant too, for
+// GORM serializers such as pkg/envelope's.
+func Transaction(ctx context.Context, db *gorm.DB, tenantID string, fn func(tx *gorm.DB) error) error {
+    if tenantID == "" {
+        return ErrNoTenant
+    }
+
+    return db.WithContext(WithTenant(ctx, tenantID)).Transaction(func(tx *gorm.DB) error {
+        if err := tx.Exec("SELECT set_config('app.tenant_id', ?, true)", tenantID).Error; err != nil {
+            return err
+        }
+        return fn(tx)
+    })
+}
+
+// SystemTransaction runs fn with row-level security bypassed. Only use it
+// for work that must span tenants, such as sweepers and sign-in before the
+// tenant is known. It returns ErrNotSystem unless the database login is a
+// member of intel_system (migration 013).
+func SystemTransaction(ctx context.Context, db *gorm.DB, fn func(tx *gorm.DB) error) error {
+    return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+        var member bool
+        if err := tx.Raw("SELECT pg_has_role(current_user, 'intel_system', 'MEMBER')").Scan(&member).Error; err != nil {
+            return err
+        }
+        if !member {
+            return ErrNotSystem
+        }
+        if err := tx.Exec("SELECT set_config('app.bypass_rls', 'on', true)").Error; err != nil {
+            return err
+        }
+        return fn(tx)
+    })
+}
@@ -0,0 +1,278 @@
+// pkg/token/issuer.go
+package token
+
+import (
+    "context"
+    "crypto"
+    "crypto/rand"
+    "encoding/hex"
+    "fmt"
+    "log"
+    "sync"
+    "time"
+
+    "github.com/golang-jwt/jwt/v4"
+)
+
+// Verification leeway for clocks that disagree, and for tokens issued just
+// before their key was retired
+const clockSkew = time.Minute
+
+// Issuer signs access tokens with the active key of a rotating ring
+type Issuer struct {
+    cfg   Config
+    store KeyStore
+
+    mu   sync.RWMutex
+    keys []*SigningKey // Published keys, oldest activation first
+}
+
+// NewIssuer loads the key ring, creating the first key if there is none
+func NewIssuer(ctx context.Context, cfg Config, store KeyStore) (*Issuer, error) {
+    i := &Issuer{cfg: cfg.withDefaults(), store: store}
+    if err := i.refresh(ctx); err != nil {
+        return nil, err
+    }
+    if i.signing(time.Now()) == nil {
+        // Nobody can hold a cached JWKS yet, so the first key signs at once
+        if err := i.rotate(ctx, time.Now(), 0); err != nil {
+            return nil, err
+        }
+    }
+    return i, nil
+}
+
+// Issue signs an access token for a user, recording how they signed in
+func (i *Issuer) Issue(tenantID, userID, role string, methods ...string) (string, *Claims, error) {
+    return i.sign(&Claims{TenantID: tenantID, UserID: userID, Role: role, AMR: methods}, time.Now())
+}
+
+// StepUp reissues a token after its user proved a second factor with
+// method. The new token counts as multi-factor, and as freshly
+// re-authenticated until StepUpTTL has passed.
+func (i *Issuer) StepUp(prev *Claims, method string) (string, *Claims, error) {
+    now := time.Now()
+    claims := &Claims{
+        TenantID:    prev.TenantID,
+        UserID:      prev.UserID,
+        Role:        prev.Role,
+        AMR:         append([]string{}, prev.AMR...),
+        StepUpUntil: jwt.NewNumericDate(now.Add(StepUpTTL)),
+    }
+    for _, m := range []string{method, AMRMFA} {
+        if !contains(claims.AMR, m) {
+            claims.AMR = append(claims.AMR, m)
+        }
+    }
+    return i.sign(claims, now)
+}
+
+func (i *Issuer) sign(claims *Claims, now time.Time) (string, *Claims, error) {
+    key := i.signing(now)
+    if key == nil {
+        return "", nil, ErrNoSigningKey
+    }
+    signer, err := key.Signer()
+    if err != nil {
+        return "", nil, err
+    }
+
+    jti := make([]byte, 16)
+    if _, err := rand.Read(jti); err != nil {
+        return "", nil, err
+    }
+    claims.RegisteredClaims = jwt.RegisteredClaims{
+        ID:        hex.EncodeToString(jti),
+        Issuer:    i.cfg.Issuer,
+        Subject:   claims.UserID,
+        Audience:  jwt.ClaimStrings{i.cfg.Audience},
+        IssuedAt:  jwt.NewNumericDate(now),
+        NotBefore: jwt.NewNumericDate(now),
+        ExpiresAt: jwt.NewNumericDate(now.Add(i.cfg.TokenTTL)),
+    }
+    claims.IssuedAtMicros = now.UnixMicro()
+
+    t := jwt.NewWithClaims(key.method(), claims)
+    t.Header["kid"] = key.ID
+    signed, err := t.SignedString(signer)
+    if err != nil {
+        return "", nil, fmt.Errorf("failed to sign token: %w", err)
+    }
+    return signed, claims, nil
+}
+
+// SignStatement signs claims that are not an access token, such as a
+// deletion certificate, with the active key. The key is returned too so
+// the statement can be verified after the key has left the JWKS.
+func (i *Issuer) SignStatement(claims jwt.Claims) (string, JWK, error) {
+    key := i.signing(time.Now())
+    if key == nil {
+        return "", JWK{}, ErrNoSigningKey
+    }
+    signer, err := key.Signer()
+    if err != nil {
+        return "", JWK{}, err
+    }
+    jwk, err := key.JWK()
+    if err != nil {
+        return "", JWK{}, err
+    }
+
+    t := jwt.NewWithClaims(key.method(), claims)
+    t.Header["kid"] = key.ID
+    signed, err := t.SignedString(signer)
+    if err != nil {
+        return "", JWK{}, fmt.Errorf("failed to sign statement: %w", err)
+    }
+    return signed, jwk, nil
+}
+
+// JWKS returns the public keys verifiers should accept, including keys
+// that are published but not yet signing
+func (i *Issuer) JWKS() JWKSet {
+    i.mu.RLock()
+    defer i.mu.RUnlock()
+
+    set := JWKSet{Keys: []JWK{}}
+    now := time.Now()
+    for _, k := range i.keys {
+        if !k.Published(now) {
+            continue
+        }
+        jwk, err := k.JWK()
+        if err != nil {
+            log.Printf("⚠️ Skipping signing key %s in JWKS: %v", k.ID, err)
+            continue
+        }
+        set.Keys = append(set.Keys, jwk)
+    }
+    return set
+}
+
+// PublicKey lets services that run the issuer verify without fetching
+// their own JWKS
+func (i *Issuer) PublicKey(ctx context.Context, kid string) (crypto.PublicKey, string, error) {
+    i.mu.RLock()
+    defer i.mu.RUnlock()
+
+    now := time.Now()
+    for _, k := range i.keys {
+        if k.ID == kid && k.Published(now) {
+            signer, err := k.Signer()
+            if err != nil {
+                return nil, "", err
+            }
+            return signer.Public(), k.Algorithm, nil
+        }
+    }
+    return nil, "", ErrUnknownKey
+}
+
+// Rotate publishes a new key now, which starts signing once gateways have
+// had time to fetch it. Tokens signed by older keys stay valid until they
+// expire.
+func (i *Issuer) Rotate(ctx context.Context) error {
+    return i.rotate(ctx, time.Now(), JWKSCacheTTL)
+}
+
+// Run reloads the ring, so keys made by other instances are used, and
+// rotates whenever the newest key is older than the rotation period
+func (i *Issuer) Run(ctx context.Context, interval time.Duration) {
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+            if err := i.refresh(ctx); err != nil {
+                log.Printf("⚠️ Failed to reload signing keys: %v", err)
+                continue
+            }
+            now := time.Now()
+            if newest := i.newest(); newest == nil || now.Sub(newest.CreatedAt) >= i.cfg.RotationPeriod {
+                if err := i.rotate(ctx, now, JWKSCacheTTL); err != nil {
+                    log.Printf("⚠️ Scheduled signing key rotation failed: %v", err)
+                }
+            }
+        }
+    }
+}
+
+func (i *Issuer) rotate(ctx context.Context, now time.Time, lead time.Duration) error {
+    next, err := GenerateKey(i.cfg.Algorithm, now.Add(lead))
+    if err != nil {
+        return err
+    }
+    // Older keys sign until next activates, then verify what they signed
+    previousExpire := next.ActivatesAt.Add(i.cfg.TokenTTL + clockSkew)
+
+    since := now.Add(-i.cfg.RotationPeriod)
+    if newest := i.newest(); newest != nil && newest.CreatedAt.After(since) {
+        since = newest.CreatedAt
+    }
+    added, err := i.store.Rotate(ctx, next, previousExpire, since)
+    if err != nil {
+        return err
+    }
+    if added {
+        log.Printf("🔑 Signing key %s (%s) published, signing from %s", next.ID, next.Algorithm, next.ActivatesAt.UTC().Format(time.RFC3339))
+    }
+    return i.refresh(ctx)
+}
+
+func (i *Issuer) refresh(ctx context.Context) error {
+    stored, err := i.store.Published(ctx, time.Now())
+    if err != nil {
+        return fmt.Errorf("failed to load signing keys: %w", err)
+    }
+
+    i.mu.Lock()
+    defer i.mu.Unlock()
+    // Keys are decoded here, once, and never modified once in the ring,
+    // so callers may keep using them after releasing the lock
+    decoded := make(map[string]crypto.Signer, len(i.keys))
+    for _, k := range i.keys {
+        decoded[k.ID] = k.signer
+    }
+    keys := make([]*SigningKey, 0, len(stored))
+    for idx := range stored {
+        k := &stored[idx]
+        if signer, ok := decoded[k.ID]; ok {
+            k.signer = signer
+        } else if k.signer, err = k.decode(); err != nil {
+            return fmt.Errorf("failed to load signing keys: %w", err)
+        }
+        keys = append(keys, k)
+    }
+    i.keys = keys
+    return nil
+}
+
+// signing is the most recently activated key
+func (i *Issuer) signing(now time.Time) *SigningKey {
+    i.mu.RLock()
+    defer i.mu.RUnlock()
+
+    var active *SigningKey
+    for _, k := range i.keys {
+        if !k.ActivatesAt.After(now) && k.Published(now) {
+            active = k
+        }
+    }
+    return active
+}
+
+func (i *Issuer) newest() *SigningKey {
+    i.mu.RLock()
+    defer i.mu.RUnlock()
+
+    var newest *SigningKey
+    for _, k := range i.keys {
+        if newest == nil || k.CreatedAt.After(newest.CreatedAt) {
+            newest = k
+        }
+    }
+    return newest
+}
@@ -0,0 +1,187 @@
+// pkg/token/keys.go
+package token
+
+import (
+    "crypto"
+    "crypto/ed25519"
+    "crypto/rand"
+    "crypto/rsa"
+    "crypto/x509"
+    "encoding/base64"
+    "encoding/hex"
+    "encoding/pem"
+    "errors"
+    "fmt"
+    "math/big"
+    "time"
+
+    "github.com/golang-jwt/jwt/v4"
+)
+
+// Signing algorithms tokens may use. HMAC is never accepted.
+const (
+    AlgRS256 = "RS256"
+    AlgEdDSA = "EdDSA"
+)
+
+const rsaKeyBits = 3072
+
+// SigningKey is one key of the issuer's ring. A key is published from
+// creation, signs from ActivatesAt until the next key activates, and stays
+// published until ExpiresAt so tokens it signed can still be verified.
+// The private key is sealed with the platform tenant's data key, so a copy
+// of the table is useless without the KMS.
+type SigningKey struct {
+    ID          string     `json:"kid" gorm:"primary_key"`
+    Algorithm   string     `json:"alg" gorm:"not null"`
+    PrivateKey  string     `json:"-" gorm:"not null;serializer:encrypted"` // PKCS#8 PEM
+    CreatedAt   time.Time  `json:"created_at"`
+    ActivatesAt time.Time  `json:"activates_at"`
+    ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+
+    signer crypto.Signer
+}
+
+func (SigningKey) TableName() string {
+    return "signing_keys"
+}
+
+// GenerateKey creates a key for alg that starts signing at activatesAt
+func GenerateKey(alg string, activatesAt time.Time) (*SigningKey, error) {
+    var signer crypto.Signer
+    var err error
+    switch alg {
+    case AlgRS256:
+        signer, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+    case AlgEdDSA:
+        _, signer, err = ed25519.GenerateKey(rand.Reader)
+    default:
+        return nil, fmt.Errorf("%w: %q", ErrUnsupportedAlgorithm, alg)
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to generate signing key: %w", err)
+    }
+
+    der, err := x509.MarshalPKCS8PrivateKey(signer)
+    if err != nil {
+        return nil, err
+    }
+    id := make([]byte, 8)
+    if _, err := rand.Read(id); err != nil {
+        return nil, err
+    }
+
+    return &SigningKey{
+        ID:          hex.EncodeToString(id),
+        Algorithm:   alg,
+        PrivateKey:  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+        CreatedAt:   time.Now(),
+        ActivatesAt: activatesAt,
+        signer:      signer,
+    }, nil
+}
+
+// Signer returns the private key. Keys generated or loaded by an Issuer
+// are decoded already; others are decoded on every call, so a key is never
+// modified once shared.
+func (k *SigningKey) Signer() (crypto.Signer, error) {
+    if k.signer != nil {
+        return k.signer, nil
+    }
+    return k.decode()
+}
+
+// decode parses the stored private key
+func (k *SigningKey) decode() (crypto.Signer, error) {
+    block, _ := pem.Decode([]byte(k.PrivateKey))
+    if block == nil {
+        return nil, fmt.Errorf("signing key %s: invalid PEM", k.ID)
+    }
+    parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+    if err != nil {
+        return nil, fmt.Errorf("signing key %s: %w", k.ID, err)
+    }
+    signer, ok := parsed.(crypto.Signer)
+    if !ok {
+        return nil, fmt.Errorf("signing key %s: not a signing key", k.ID)
+    }
+    return signer, nil
+}
+
+func (k *SigningKey) method() jwt.SigningMethod {
+    if k.Algorithm == AlgEdDSA {
+        return jwt.SigningMethodEdDSA
+    }
+    return jwt.SigningMethodRS256
+}
+
+// Published reports whether the key belongs in the JWKS at now
+func (k *SigningKey) Published(now time.Time) bool {
+    return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
+
+// JWK is a public key as published in the JWKS (RFC 7517, RFC 8037)
+type JWK struct {
+    KeyType   string `json:"kty"`
+    KeyID     string `json:"kid"`
+    Algorithm string `json:"alg"`
+    Use       string `json:"use"`
+    N         string `json:"n,omitempty"`   // RSA modulus
+    E         string `json:"e,omitempty"`   // RSA exponent
+    Curve     string `json:"crv,omitempty"` // OKP curve
+    X         string `json:"x,omitempty"`   // OKP public key
+}
+
+// JWKSet is the body of the JWKS endpoint
+type JWKSet struct {
+    Keys []JWK `json:"keys"`
+}
+
+// JWK returns the public half of the key
+func (k *SigningKey) JWK() (JWK, error) {
+    signer, err := k.Signer()
+    if err != nil {
+        return JWK{}, err
+    }
+    jwk := JWK{KeyID: k.ID, Algorithm: k.Algorithm, Use: "sig"}
+    switch pub := signer.Public().(type) {
+    case *rsa.PublicKey:
+        jwk.KeyType = "RSA"
+        jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+        jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+    case ed25519.PublicKey:
+        jwk.KeyType = "OKP"
+        jwk.Curve = "Ed25519"
+        jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+    default:
+        return JWK{}, fmt.Errorf("signing key %s: unsupported public key type", k.ID)
+    }
+    return jwk, nil
+}
+
+// PublicKey decodes a published key for verification
+func (j JWK) PublicKey() (crypto.PublicKey, error) {
+    switch {
+    case j.KeyType == "RSA" && j.Algorithm == AlgRS256:
+        n, err := base64.RawURLEncoding.DecodeString(j.N)
+        if err != nil {
+            return nil, err
+        }
+        e, err := base64.RawURLEncoding.DecodeString(j.E)
+        if err != nil {
+            return nil, err
+        }
+        return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+    case j.KeyType == "OKP" && j.Curve == "Ed25519" && j.Algorithm == AlgEdDSA:
+        x, err := base64.RawURLEncoding.DecodeString(j.X)
+        if err != nil {
+            return nil, err
+        }
+        if len(x) != ed25519.PublicKeySize {
+            return nil, errors.New("invalid Ed25519 public key")
+        }
+        return ed25519.PublicKey(x), nil
+    default:
+        return nil, fmt.Errorf("%w: %s/%s", ErrUnsupportedAlgorithm, j.KeyType, j.Algorithm)
+    }
+}
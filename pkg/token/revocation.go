@@ -0,0 +1,83 @@
+// pkg/token/revocation.go
+package token
+
+import (
+    "context"
+    "fmt"
+    "strconv"
+    "time"
+
+    "github.com/go-redis/redis/v8"
+)
+
+// Revocations is the Redis-backed revocation list. It is read on every
+// request, so a revocation takes effect as soon as it is written. Entries
+// expire once no token they cover can still be valid.
+type Revocations struct {
+    redis    *redis.Client
+    tokenTTL time.Duration
+}
+
+func NewRevocations(client *redis.Client, tokenTTL time.Duration) *Revocations {
+    if tokenTTL <= 0 {
+        tokenTTL = DefaultTokenTTL
+    }
+    return &Revocations{redis: client, tokenTTL: tokenTTL}
+}
+
+func tokenKey(jti string) string {
+    return "token_revoked:" + jti
+}
+
+func userKey(tenantID, userID string) string {
+    return fmt.Sprintf("token_revoked_user:%s:%s", tenantID, userID)
+}
+
+// RevokeToken revokes one token, as on logout
+func (r *Revocations) RevokeToken(ctx context.Context, claims *Claims) error {
+    ttl := time.Until(claims.ExpiresAt.Time) + clockSkew
+    if ttl <= 0 {
+        return nil
+    }
+    return r.redis.Set(ctx, tokenKey(claims.ID), 1, ttl).Err()
+}
+
+// RevokeUser revokes every token issued to a user until now, as when they
+// log out everywhere or are disabled. Tokens issued later, even within the
+// same second, stay valid.
+func (r *Revocations) RevokeUser(ctx context.Context, tenantID, userID string) error {
+    at := time.Now().UTC().Format(time.RFC3339Nano)
+    return r.redis.Set(ctx, userKey(tenantID, userID), at, r.tokenTTL+clockSkew).Err()
+}
+
+// revokedUntil parses a RevokeUser entry. Entries written as Unix seconds
+// cover the whole of their second.
+func revokedUntil(value string) (time.Time, error) {
+    if at, err := time.Parse(time.RFC3339Nano, value); err == nil {
+        return at, nil
+    }
+    seconds, err := strconv.ParseInt(value, 10, 64)
+    if err != nil {
+        return time.Time{}, err
+    }
+    return time.Unix(seconds+1, 0).Add(-time.Nanosecond), nil
+}
+
+// Check returns ErrRevoked for revoked tokens. Errors reading Redis are
+// returned as they are, so callers fail closed.
+func (r *Revocations) Check(ctx context.Context, claims *Claims) error {
+    values, err := r.redis.MGet(ctx, tokenKey(claims.ID), userKey(claims.TenantID, claims.UserID)).Result()
+    if err != nil {
+        return fmt.Errorf("revocation list unavailable: %w", err)
+    }
+    if values[0] != nil {
+        return ErrRevoked
+    }
+    if value, ok := values[1].(string); ok {
+        at, err := revokedUntil(value)
+        if err != nil || !claims.issuedAt().After(at) {
+            return ErrRevoked
+        }
+    }
+    return nil
+}
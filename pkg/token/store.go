@@ -0,0 +1,76 @@
+// pkg/token/store.go
+package token
+
+import (
+    "context"
+    "fmt"
+    "time"
+
+    "gorm.io/gorm"
+    "secure-iran-intel/pkg/tenancy"
+)
+
+// KeyStore persists the signing key ring shared by every auth-service
+// instance
+type KeyStore interface {
+    // Published returns keys that have not expired, oldest first
+    Published(ctx context.Context, now time.Time) ([]SigningKey, error)
+    // Rotate adds next and sets the expiry of every key without one,
+    // unless a key was created after since. It reports whether next was
+    // added, so instances rotating at once add a single key.
+    Rotate(ctx context.Context, next *SigningKey, previousExpire time.Time, since time.Time) (bool, error)
+}
+
+// GormKeyStore keeps keys in the signing_keys table (migration 015). The
+// encrypted serializer must be registered (envelope.Register) first.
+type GormKeyStore struct {
+    db *gorm.DB
+}
+
+func NewGormKeyStore(db *gorm.DB) *GormKeyStore {
+    return &GormKeyStore{db: db}
+}
+
+func (s *GormKeyStore) Published(ctx context.Context, now time.Time) ([]SigningKey, error) {
+    var keys []SigningKey
+    err := s.db.WithContext(platform(ctx)).
+        Where("expires_at IS NULL OR expires_at > ?", now).
+        Order("activates_at").
+        Find(&keys).Error
+    return keys, err
+}
+
+func (s *GormKeyStore) Rotate(ctx context.Context, next *SigningKey, previousExpire time.Time, since time.Time) (bool, error) {
+    added := false
+    err := s.db.WithContext(platform(ctx)).Transaction(func(tx *gorm.DB) error {
+        // Serialise rotations across instances
+        if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext('signing_keys'))").Error; err != nil {
+            return err
+        }
+        var newer int64
+        if err := tx.Model(&SigningKey{}).Where("created_at > ?", since).Count(&newer).Error; err != nil {
+            return err
+        }
+        if newer > 0 {
+            return nil
+        }
+        if err := tx.Model(&SigningKey{}).Where("expires_at IS NULL").Update("expires_at", previousExpire).Error; err != nil {
+            return err
+        }
+        if err := tx.Create(next).Error; err != nil {
+            return err
+        }
+        added = true
+        return nil
+    })
+    if err != nil {
+        return false, fmt.Errorf("failed to rotate signing key: %w", err)
+    }
+    return added, nil
+}
+
+// platform scopes ctx to the platform tenant, whose data key seals every
+// private key; signing keys belong to no one tenant
+func platform(ctx context.Context) context.Context {
+    return tenancy.WithTenant(ctx, tenancy.PlatformTenantID)
+}
@@ -0,0 +1,109 @@
+// pkg/token/token.go
+package token
+
+import (
+    "errors"
+    "time"
+
+    "github.com/golang-jwt/jwt/v4"
+)
+
+// Defaults for Config
+const (
+    DefaultTokenTTL       = 15 * time.Minute
+    DefaultRotationPeriod = 30 * 24 * time.Hour
+    // JWKSCacheTTL is how long gateways cache the JWKS. New keys are
+    // published at least this long before they sign anything.
+    JWKSCacheTTL = 5 * time.Minute
+    // StepUpTTL is how long a re-authentication counts as fresh for
+    // sensitive actions
+    StepUpTTL = 5 * time.Minute
+)
+
+// Authentication methods (RFC 8176), carried in the amr claim
+const (
+    AMRPassword     = "pwd"
+    AMRFederated    = "fed" // Signed in through the tenant's identity provider
+    AMROTP          = "otp"
+    AMRHardwareKey  = "hwk" // WebAuthn
+    AMRRecoveryCode = "rcv"
+    AMRMFA          = "mfa" // A second factor was verified
+)
+
+var (
+    ErrUnsupportedAlgorithm = errors.New("unsupported signing algorithm")
+    ErrUnknownKey           = errors.New("token signed by an unknown key")
+    ErrInvalidToken         = errors.New("invalid token")
+    ErrRevoked              = errors.New("token has been revoked")
+    ErrNoSigningKey         = errors.New("no active signing key")
+)
+
+// Claims are the claims of an access token. The subject is the user ID.
+type Claims struct {
+    TenantID string `json:"tenant_id"`
+    UserID   string `json:"user_id"`
+    Role     string `json:"role,omitempty"`
+    AMR      []string `json:"amr,omitempty"`
+    // StepUpUntil is set when the user has just proved a second factor.
+    // Sensitive actions demand it has not passed.
+    StepUpUntil *jwt.NumericDate `json:"step_up_until,omitempty"`
+    // IssuedAtMicros is IssuedAt to the microsecond; iat only has whole
+    // seconds. Revoking a user's tokens compares against it.
+    IssuedAtMicros int64 `json:"iat_us,omitempty"`
+    jwt.RegisteredClaims
+}
+
+// HasMFA reports whether the token was issued after a second factor
+func (c *Claims) HasMFA() bool {
+    return contains(c.AMR, AMRMFA)
+}
+
+// issuedAt is when the token was issued, as precisely as it records.
+// Tokens without an issue time report the zero time.
+func (c *Claims) issuedAt() time.Time {
+    switch {
+    case c.IssuedAtMicros != 0:
+        return time.UnixMicro(c.IssuedAtMicros)
+    case c.IssuedAt != nil:
+        return c.IssuedAt.Time
+    default:
+        return time.Time{}
+    }
+}
+
+// SteppedUp reports whether the user re-authenticated recently enough for
+// a sensitive action
+func (c *Claims) SteppedUp(now time.Time) bool {
+    return c.StepUpUntil != nil && now.Before(c.StepUpUntil.Time)
+}
+
+// Config configures an Issuer. Verifiers need the same Issuer and Audience.
+type Config struct {
+    Issuer         string
+    Audience       string
+    Algorithm      string        // AlgRS256 or AlgEdDSA
+    TokenTTL       time.Duration // Access token lifetime
+    RotationPeriod time.Duration // A new key is made when the newest is this old
+}
+
+func (c Config) withDefaults() Config {
+    if c.Algorithm == "" {
+        c.Algorithm = AlgEdDSA
+    }
+    if c.TokenTTL <= 0 {
+        c.TokenTTL = DefaultTokenTTL
+    }
+    if c.RotationPeriod <= 0 {
+        c.RotationPeriod = DefaultRotationPeriod
+    }
+    return c
+}
+
+func contains(list []string, s string) bool {
+    for _, v := range list {
+        if v == s {
+            return true
+        }
+    }
+    return false
+}
@@ -0,0 +1,185 @@
+// pkg/token/verifier.go
+package token
+
+import (
+    "context"
+    "crypto"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "sync"
+    "time"
+
+    "github.com/golang-jwt/jwt/v4"
+)
+
+// An unknown kid triggers a JWKS refetch at most this often, so forged
+// kids cannot hammer the auth-service
+const minRefetchInterval = 30 * time.Second
+
+// KeySource resolves the public key and algorithm a token's kid names
+type KeySource interface {
+    PublicKey(ctx context.Context, kid string) (crypto.PublicKey, string, error)
+}
+
+// JWKSCache is a KeySource backed by a remote JWKS endpoint
+type JWKSCache struct {
+    url    string
+    client *http.Client
+
+    mu        sync.Mutex
+    keys      map[string]JWK // Replaced on refetch, never modified
+    fetchedAt time.Time
+    fetchErr  error
+    // fetching is closed when the fetch in flight ends; nil when there is none
+    fetching chan struct{}
+}
+
+func NewJWKSCache(url string) *JWKSCache {
+    return &JWKSCache{url: url, client: &http.Client{Timeout: 5 * time.Second}}
+}
+
+func (c *JWKSCache) PublicKey(ctx context.Context, kid string) (crypto.PublicKey, string, error) {
+    keys, err := c.current(ctx, kid)
+    if err != nil {
+        return nil, "", err
+    }
+    jwk, ok := keys[kid]
+    if !ok {
+        return nil, "", ErrUnknownKey
+    }
+
+    pub, err := jwk.PublicKey()
+    if err != nil {
+        return nil, "", err
+    }
+    return pub, jwk.Algorithm, nil
+}
+
+// current returns the cached keys, refetching them first when they are
+// stale or lack kid. The fetch runs without the lock, so a slow issuer
+// does not hold up requests whose keys are cached; callers that need the
+// refetch wait for the one in flight rather than starting their own.
+func (c *JWKSCache) current(ctx context.Context, kid string) (map[string]JWK, error) {
+    c.mu.Lock()
+    now := time.Now()
+    _, ok := c.keys[kid]
+    stale := now.Sub(c.fetchedAt) >= JWKSCacheTTL
+    if !stale && (ok || now.Sub(c.fetchedAt) < minRefetchInterval) {
+        keys := c.keys
+        c.mu.Unlock()
+        return keys, nil
+    }
+
+    done := c.fetching
+    if done == nil {
+        done = make(chan struct{})
+        c.fetching = done
+        c.mu.Unlock()
+
+        keys, err := c.fetch(ctx)
+
+        c.mu.Lock()
+        if err == nil {
+            c.keys, c.fetchedAt = keys, time.Now()
+        }
+        c.fetchErr = err
+        c.fetching = nil
+        close(done)
+    } else {
+        c.mu.Unlock()
+        select {
+        case <-done:
+        case <-ctx.Done():
+            return nil, ctx.Err()
+        }
+        c.mu.Lock()
+    }
+    defer c.mu.Unlock()
+
+    // Keep verifying with what we have if the issuer is briefly down
+    if c.keys == nil {
+        return nil, c.fetchErr
+    }
+    return c.keys, nil
+}
+
+func (c *JWKSCache) fetch(ctx context.Context) (map[string]JWK, error) {
+    req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url, nil)
+    if err != nil {
+        return nil, err
+    }
+    resp, err := c.client.Do(req)
+    if err != nil {
+        return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+    }
+    defer resp.Body.Close()
+    if resp.StatusCode != http.StatusOK {
+        return nil, fmt.Errorf("failed to fetch JWKS: status %d", resp.StatusCode)
+    }
+
+    var set JWKSet
+    if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+        return nil, fmt.Errorf("failed to decode JWKS: %w", err)
+    }
+    keys := make(map[string]JWK, len(set.Keys))
+    for _, k := range set.Keys {
+        keys[k.KeyID] = k
+    }
+    return keys, nil
+}
+
+// Verifier checks access tokens: signature by a published key, issuer,
+// audience, lifetime, and finally the revocation list
+type Verifier struct {
+    keys        KeySource
+    revocations *Revocations
+    issuer      string
+    audience    string
+}
+
+func NewVerifier(keys KeySource, revocations *Revocations, issuer, audience string) *Verifier {
+    return &Verifier{keys: keys, revocations: revocations, issuer: issuer, audience: audience}
+}
+
+// Verify returns the token's claims. Revoked tokens fail with ErrRevoked;
+// other failures wrap ErrInvalidToken, except an unreachable revocation
+// list, which callers should treat as a server error.
+func (v *Verifier) Verify(ctx context.Context, tokenString string) (*Claims, error) {
+    claims := &Claims{}
+    parser := jwt.NewParser(jwt.WithValidMethods([]string{AlgRS256, AlgEdDSA}))
+    _, err := parser.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+        kid, _ := t.Header["kid"].(string)
+        if kid == "" {
+            return nil, ErrUnknownKey
+        }
+        pub, alg, err := v.keys.PublicKey(ctx, kid)
+        if err != nil {
+            return nil, err
+        }
+        // The key decides the algorithm, never the token
+        if t.Method.Alg() != alg {
+            return nil, ErrUnsupportedAlgorithm
+        }
+        return pub, nil
+    })
+    if err != nil {
+        return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+    }
+
+    switch {
+    case !claims.VerifyIssuer(v.issuer, true):
+        return nil, fmt.Errorf("%w: wrong issuer", ErrInvalidToken)
+    case !claims.VerifyAudience(v.audience, true):
+        return nil, fmt.Errorf("%w: wrong audience", ErrInvalidToken)
+    case claims.ID == "" || claims.IssuedAt == nil || claims.ExpiresAt == nil:
+        return nil, fmt.Errorf("%w: missing jti, iat or exp", ErrInvalidToken)
+    case claims.TenantID == "" || claims.UserID == "":
+        return nil, fmt.Errorf("%w: missing tenant or user", ErrInvalidToken)
+    }
+
+    if err := v.revocations.Check(ctx, claims); err != nil {
+        return nil, err
+    }
+    return claims, nil
+}
@@ -0,0 +1,257 @@
+// tests/integration/audit/chain.integration.test.go
+package integration
+
+import (
+    "context"
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    "fmt"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+
+    "github.com/stretchr/testify/suite"
+
+    "secure-iran-intel/pkg/audit"
+)
+
+var testTargetKey = []byte("integration-test-audit-target-key-0000")
+
+type AuditChainTestSuite struct {
+    suite.Suite
+    path  string
+    store *audit.FileStore
+}
+
+func TestAuditChainSuite(t *testing.T) {
+    suite.Run(t, new(AuditChainTestSuite))
+}
+
+func (suite *AuditChainTestSuite) SetupSuite() {
+    suite.Require().NoError(audit.SetTargetKey(testTargetKey))
+}
+
+// SetupTest writes a fresh log of five records, over two appends
+func (suite *AuditChainTestSuite) SetupTest() {
+    suite.path = filepath.Join(suite.T().TempDir(), "audit.jsonl")
+    suite.store = audit.NewFileStore(suite.path)
+    logger := audit.NewLogger(suite.store)
+
+    ctx := audit.WithActor(context.Background(), audit.Actor{TenantID: "tenant-a", Type: audit.ActorUser, ID: "analyst"})
+    ctx = audit.WithJustification(ctx, "case 2026-041")
+    for _, batch := range [][]string{{"+989121234567", "+989351234567", "+982112345678"}, {"+989121234567", "+4915112345678"}} {
+        entries := make([]audit.Entry, 0, len(batch))
+        for _, number := range batch {
+            entries = append(entries, audit.Entry{Action: audit.ActionPhoneLookup, TargetType: "phone", Target: number})
+        }
+        suite.Require().NoError(logger.Log(ctx, entries...))
+    }
+}
+
+// records reads the log file back as raw JSON objects
+func (suite *AuditChainTestSuite) records() []map[string]interface{} {
+    data, err := os.ReadFile(suite.path)
+    suite.Require().NoError(err)
+
+    var records []map[string]interface{}
+    for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+        var r map[string]interface{}
+        suite.Require().NoError(json.Unmarshal([]byte(line), &r))
+        records = append(records, r)
+    }
+    return records
+}
+
+func (suite *AuditChainTestSuite) rewrite(records []map[string]interface{}) {
+    var b strings.Builder
+    for _, r := range records {
+        line, err := json.Marshal(r)
+        suite.Require().NoError(err)
+        b.Write(line)
+        b.WriteByte('\n')
+    }
+    suite.Require().NoError(os.WriteFile(suite.path, []byte(b.String()), 0600))
+}
+
+// rehash recomputes a tampered record's hash, as an attacker with write
+// access to the log would
+func rehash(r map[string]interface{}) error {
+    encoded, err := json.Marshal(r)
+    if err != nil {
+        return err
+    }
+    var record audit.Record
+    if err := json.Unmarshal(encoded, &record); err != nil {
+        return err
+    }
+    r["hash"] = record.ComputeHash()
+    return nil
+}
+
+func (suite *AuditChainTestSuite) verify(anchors ...audit.Anchor) *audit.VerifyResult {
+    result, err := audit.Verify(context.Background(), audit.NewFileStore(suite.path), anchors...)
+    suite.Require().NoError(err)
+    return result
+}
+
+func problemKinds(result *audit.VerifyResult) []string {
+    kinds := make([]string, 0, len(result.Problems))
+    for _, p := range result.Problems {
+        kinds = append(kinds, fmt.Sprintf("%d:%s", p.Seq, p.Kind))
+    }
+    return kinds
+}
+
+func (suite *AuditChainTestSuite) TestAppendsLinkIntoOneChain() {
+    records := suite.records()
+    suite.Require().Len(records, 5)
+
+    prev := strings.Repeat("0", 64)
+    for i, r := range records {
+        suite.EqualValues(i+1, r["seq"])
+        suite.Equal(prev, r["prev_hash"])
+        prev = r["hash"].(string)
+    }
+
+    // A new store picks the chain up from the file's head
+    logger := audit.NewLogger(audit.NewFileStore(suite.path))
+    suite.Require().NoError(logger.Log(context.Background(), audit.Entry{Action: audit.ActionJobView, TargetType: "job", Target: "job-1"}))
+
+    result := suite.verify()
+    suite.True(result.OK(), problemKinds(result))
+    suite.EqualValues(6, result.Records)
+    suite.EqualValues(6, result.HeadSeq)
+}
+
+func (suite *AuditChainTestSuite) TestTamperingIsDetected() {
+    tests := []struct {
+        name   string
+        tamper func(records []map[string]interface{}) []map[string]interface{}
+        anchor int64 // Seq of a head published before tampering, if any
+        kinds  []string
+    }{
+        {
+            name: "edited contents",
+            tamper: func(records []map[string]interface{}) []map[string]interface{} {
+                records[1]["justification"] = "routine check"
+                return records
+            },
+            kinds: []string{"2:edited"},
+        },
+        {
+            name: "edited and rehashed",
+            tamper: func(records []map[string]interface{}) []map[string]interface{} {
+                records[1]["actor_id"] = "someone-else"
+                suite.Require().NoError(rehash(records[1]))
+                return records
+            },
+            kinds: []string{"3:relinked"},
+        },
+        {
+            name: "deleted record",
+            tamper: func(records []map[string]interface{}) []map[string]interface{} {
+                return append(records[:2], records[3:]...)
+            },
+            kinds: []string{"4:missing", "4:relinked"},
+        },
+        {
+            name: "deleted and relinked",
+            tamper: func(records []map[string]interface{}) []map[string]interface{} {
+                records[3]["prev_hash"] = records[1]["hash"]
+                suite.Require().NoError(rehash(records[3]))
+                return append(records[:2], records[3:]...)
+            },
+            kinds: []string{"4:missing", "5:relinked"},
+        },
+        {
+            name: "renumbered after a deletion",
+            tamper: func(records []map[string]interface{}) []map[string]interface{} {
+                records = append(records[:2], records[3:]...)
+                for i := 2; i < len(records); i++ {
+                    records[i]["seq"] = i + 1
+                    records[i]["prev_hash"] = records[i-1]["hash"]
+                    suite.Require().NoError(rehash(records[i]))
+                }
+                return records
+            },
+            anchor: 4,
+            kinds:  []string{"4:anchor"},
+        },
+        {
+            name: "reordered records",
+            tamper: func(records []map[string]interface{}) []map[string]interface{} {
+                records[2], records[3] = records[3], records[2]
+                return records
+            },
+            kinds: []string{"4:missing", "4:relinked", "3:missing", "3:relinked", "5:missing", "5:relinked"},
+        },
+        {
+            name: "truncated tail",
+            tamper: func(records []map[string]interface{}) []map[string]interface{} {
+                return records[:3]
+            },
+            anchor: 5,
+            kinds:  []string{"5:anchor"},
+        },
+    }
+
+    for _, tt := range tests {
+        suite.Run(tt.name, func() {
+            suite.SetupTest()
+            records := suite.records()
+            var anchors []audit.Anchor
+            if tt.anchor > 0 {
+                anchors = append(anchors, audit.Anchor{Seq: tt.anchor, Hash: records[tt.anchor-1]["hash"].(string)})
+            }
+            suite.Require().True(suite.verify(anchors...).OK())
+
+            suite.rewrite(tt.tamper(records))
+
+            result := suite.verify(anchors...)
+            suite.False(result.OK())
+            suite.Equal(tt.kinds, problemKinds(result))
+        })
+    }
+}
+
+// Without an anchor a truncated log is indistinguishable from a shorter one
+func (suite *AuditChainTestSuite) TestTruncationNeedsAnAnchor() {
+    records := suite.records()
+    suite.rewrite(records[:3])
+
+    result := suite.verify()
+    suite.True(result.OK())
+    suite.EqualValues(3, result.HeadSeq)
+
+    result = suite.verify(audit.Anchor{Seq: 3, Hash: records[2]["hash"].(string)})
+    suite.True(result.OK())
+}
+
+func (suite *AuditChainTestSuite) TestTargetsAreKeyedHashes() {
+    const number = "+989121234567"
+    unkeyed := sha256.Sum256([]byte("phone:" + number))
+
+    hash, err := audit.HashTarget("phone", number)
+    suite.Require().NoError(err)
+    suite.NotEqual(hex.EncodeToString(unkeyed[:]), hash)
+
+    other, err := audit.HashTarget("job", number)
+    suite.Require().NoError(err)
+    suite.NotEqual(hash, other)
+
+    // The log itself only ever holds the keyed hash
+    for _, r := range suite.records() {
+        suite.NotEqual(hex.EncodeToString(unkeyed[:]), r["target_hash"])
+        suite.NotContains(fmt.Sprint(r), number)
+    }
+
+    // Another key gives other hashes; short keys are refused
+    suite.Error(audit.SetTargetKey([]byte("too short")))
+    suite.Require().NoError(audit.SetTargetKey([]byte("another-integration-test-target-key-00")))
+    defer audit.SetTargetKey(testTargetKey)
+    rekeyed, err := audit.HashTarget("phone", number)
+    suite.Require().NoError(err)
+    suite.NotEqual(hash, rekeyed)
+}
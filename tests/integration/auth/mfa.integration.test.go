@@ -0,0 +1,253 @@
+// tests/integration/auth/mfa.integration.test.go
+package integration
+
+import (
+    "context"
+    "crypto/hmac"
+    "crypto/sha1"
+    "database/sql"
+    "encoding/base32"
+    "encoding/binary"
+    "fmt"
+    "path/filepath"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/alicebob/miniredis/v2"
+    "github.com/go-redis/redis/v8"
+    "github.com/golang-jwt/jwt/v4"
+    "github.com/stretchr/testify/suite"
+    "gorm.io/gorm"
+
+    "secure-iran-intel/database"
+    "secure-iran-intel/pkg/audit"
+    "secure-iran-intel/pkg/envelope"
+    "secure-iran-intel/pkg/mfa"
+    "secure-iran-intel/pkg/tenancy"
+    "secure-iran-intel/pkg/token"
+)
+
+// authenticatorCode is what an authenticator app would show for secret at t
+func authenticatorCode(secret string, t time.Time) string {
+    key, _ := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+    var msg [8]byte
+    binary.BigEndian.PutUint64(msg[:], uint64(t.Unix()/30))
+    mac := hmac.New(sha1.New, key)
+    mac.Write(msg[:])
+    sum := mac.Sum(nil)
+    offset := sum[len(sum)-1] & 0x0f
+    value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+    return fmt.Sprintf("%06d", value%1000000)
+}
+
+// registerKeyring seals encrypted columns, token signing keys included,
+// under a throwaway master key
+func registerKeyring(t *testing.T, db *gorm.DB) *envelope.Keyring {
+    kms, err := envelope.NewFileKMS(filepath.Join(t.TempDir(), "master-keys.json"))
+    if err != nil {
+        t.Fatalf("Failed to create master keys: %v", err)
+    }
+    keyring := envelope.NewKeyring(envelope.NewGormKeyStore(db), kms)
+    envelope.Register(keyring)
+    return keyring
+}
+
+type MFATestSuite struct {
+    suite.Suite
+    db       *gorm.DB
+    sqlDB    *sql.DB
+    ctx      context.Context
+    redis    *miniredis.Miniredis
+    issuer   *token.Issuer
+    verifier *token.Verifier
+    service  *mfa.Service
+    tenantID string
+}
+
+func TestMFASuite(t *testing.T) {
+    suite.Run(t, new(MFATestSuite))
+}
+
+func (suite *MFATestSuite) SetupSuite() {
+    suite.ctx = context.Background()
+
+    var err error
+    suite.db, suite.sqlDB, err = database.ConnectTest()
+    if err != nil {
+        suite.T().Fatalf("Failed to connect to test database: %v", err)
+    }
+    if err := database.MigrateTest(suite.db); err != nil {
+        suite.T().Fatalf("Failed to run test migrations: %v", err)
+    }
+
+    suite.redis, err = miniredis.Run()
+    if err != nil {
+        suite.T().Fatalf("Failed to start Redis: %v", err)
+    }
+    client := redis.NewClient(&redis.Options{Addr: suite.redis.Addr()})
+
+    registerKeyring(suite.T(), suite.db)
+    cfg := token.Config{Issuer: testIssuer, Audience: testAudience}
+    suite.issuer, err = token.NewIssuer(suite.ctx, cfg, token.NewGormKeyStore(suite.db))
+    if err != nil {
+        suite.T().Fatalf("Failed to create token issuer: %v", err)
+    }
+    suite.verifier = token.NewVerifier(suite.issuer, token.NewRevocations(client, 0), testIssuer, testAudience)
+
+    suite.Require().NoError(audit.SetTargetKey(testTargetKey))
+    auditLog := audit.NewLogger(audit.NewFileStore(filepath.Join(suite.T().TempDir(), "audit.log")))
+    suite.service, err = mfa.NewService(
+        mfa.NewGormStore(suite.db),
+        mfa.NewChallenges(client),
+        mfa.WebAuthnConfig{RPID: "gateway.example.test", RPDisplayName: "Intel", RPOrigins: []string{"https://gateway.example.test"}},
+        suite.issuer,
+        auditLog,
+    )
+    if err != nil {
+        suite.T().Fatalf("Failed to create MFA service: %v", err)
+    }
+
+    slug := fmt.Sprintf("mfa-%d", time.Now().UnixNano())
+    err = suite.db.Raw("INSERT INTO tenants (name, slug) VALUES (?, ?) RETURNING id", slug, slug).Scan(&suite.tenantID).Error
+    if err != nil {
+        suite.T().Fatalf("Failed to create tenant: %v", err)
+    }
+}
+
+func (suite *MFATestSuite) TearDownSuite() {
+    if suite.redis != nil {
+        suite.redis.Close()
+    }
+    if suite.db != nil {
+        suite.db.Exec("DELETE FROM tenants WHERE id = ?", suite.tenantID)
+    }
+    if suite.sqlDB != nil {
+        suite.sqlDB.Close()
+    }
+}
+
+// signedIn creates a user and returns the context and claims of a
+// password sign-in
+func (suite *MFATestSuite) signedIn(email string) (context.Context, *token.Claims) {
+    var userID string
+    err := tenancy.Transaction(suite.ctx, suite.db, suite.tenantID, func(tx *gorm.DB) error {
+        return tx.Raw(`INSERT INTO users (tenant_id, email, password_hash, role) VALUES (?, ?, 'x', 'user') RETURNING id`,
+            suite.tenantID, email).Scan(&userID).Error
+    })
+    suite.Require().NoError(err)
+
+    _, claims, err := suite.issuer.Issue(suite.tenantID, userID, "user", token.AMRPassword)
+    suite.Require().NoError(err)
+    ctx := audit.WithActor(suite.ctx, audit.Actor{TenantID: suite.tenantID, Type: audit.ActorUser, ID: userID})
+    return ctx, claims
+}
+
+// enrollTOTP enrolls and confirms an authenticator app, returning its
+// secret and the first confirmation
+func (suite *MFATestSuite) enrollTOTP(ctx context.Context, claims *token.Claims) (string, *mfa.Verification) {
+    enrollment, err := suite.service.EnrollTOTP(ctx, claims, "phone")
+    suite.Require().NoError(err)
+    verification, err := suite.service.ConfirmTOTP(ctx, claims, enrollment.Factor.ID, authenticatorCode(enrollment.Secret, time.Now()))
+    suite.Require().NoError(err)
+    return enrollment.Secret, verification
+}
+
+func (suite *MFATestSuite) TestTOTPEnrollmentStepsUp() {
+    ctx, claims := suite.signedIn("totp@mfa.example.test")
+    suite.False(claims.HasMFA())
+
+    _, verification := suite.enrollTOTP(ctx, claims)
+    suite.Len(verification.RecoveryCodes, mfa.RecoveryCodeCount)
+
+    stepped, err := suite.verifier.Verify(suite.ctx, verification.AccessToken)
+    suite.Require().NoError(err)
+    suite.True(stepped.HasMFA())
+    suite.Contains(stepped.AMR, token.AMRPassword)
+    suite.Contains(stepped.AMR, token.AMROTP)
+    suite.True(stepped.SteppedUp(time.Now()))
+    suite.False(stepped.SteppedUp(time.Now().Add(token.StepUpTTL)), "A step-up must expire")
+
+    factors, err := suite.service.Factors(ctx, claims)
+    suite.Require().NoError(err)
+    suite.Require().Len(factors, 1)
+    suite.NotNil(factors[0].ConfirmedAt)
+
+    // Neither the secret nor the recovery codes are stored readable
+    var stored string
+    var hashes []string
+    err = tenancy.Transaction(suite.ctx, suite.db, suite.tenantID, func(tx *gorm.DB) error {
+        if err := tx.Raw(`SELECT secret FROM mfa_factors WHERE id = ?`, factors[0].ID).Scan(&stored).Error; err != nil {
+            return err
+        }
+        return tx.Raw(`SELECT code_hash FROM mfa_recovery_codes WHERE user_id = ?`, claims.UserID).Scan(&hashes).Error
+    })
+    suite.Require().NoError(err)
+    suite.True(envelope.IsSealed(stored), "The TOTP secret must be sealed")
+    suite.Require().Len(hashes, mfa.RecoveryCodeCount)
+    for _, hash := range hashes {
+        suite.True(strings.HasPrefix(hash, "$2"), "Recovery codes must be hashed with bcrypt")
+    }
+}
+
+func (suite *MFATestSuite) TestTOTPCodeWorksOnce() {
+    ctx, claims := suite.signedIn("replay@mfa.example.test")
+    secret, _ := suite.enrollTOTP(ctx, claims)
+
+    // The confirmation spent the current code
+    _, err := suite.service.Verify(ctx, claims, mfa.MethodTOTP, authenticatorCode(secret, time.Now()))
+    suite.ErrorIs(err, mfa.ErrInvalidCode)
+
+    verification, err := suite.service.Verify(ctx, claims, mfa.MethodTOTP, authenticatorCode(secret, time.Now().Add(30*time.Second)))
+    suite.Require().NoError(err)
+    suite.NotEmpty(verification.AccessToken)
+}
+
+func (suite *MFATestSuite) TestRecoveryCodeWorksOnce() {
+    ctx, claims := suite.signedIn("recovery@mfa.example.test")
+    _, enrolled := suite.enrollTOTP(ctx, claims)
+    code := enrolled.RecoveryCodes[0]
+
+    verification, err := suite.service.Verify(ctx, claims, mfa.MethodRecoveryCode, code)
+    suite.Require().NoError(err)
+    stepped, err := suite.verifier.Verify(suite.ctx, verification.AccessToken)
+    suite.Require().NoError(err)
+    suite.Contains(stepped.AMR, token.AMRRecoveryCode)
+
+    _, err = suite.service.Verify(ctx, claims, mfa.MethodRecoveryCode, code)
+    suite.ErrorIs(err, mfa.ErrInvalidCode)
+}
+
+func (suite *MFATestSuite) TestFactorChangesNeedStepUp() {
+    ctx, claims := suite.signedIn("stepup@mfa.example.test")
+    _, enrolled := suite.enrollTOTP(ctx, claims)
+
+    // The original password-only token cannot add or remove factors
+    _, err := suite.service.EnrollTOTP(ctx, claims, "second phone")
+    suite.ErrorIs(err, mfa.ErrStepUpRequired)
+    factors, err := suite.service.Factors(ctx, claims)
+    suite.Require().NoError(err)
+    suite.ErrorIs(suite.service.RemoveFactor(ctx, claims, factors[0].ID), mfa.ErrStepUpRequired)
+
+    stepped, err := suite.verifier.Verify(suite.ctx, enrolled.AccessToken)
+    suite.Require().NoError(err)
+    suite.Require().NoError(suite.service.RemoveFactor(ctx, stepped, factors[0].ID))
+
+    // An expired step-up is refused too
+    stale := *stepped
+    stale.StepUpUntil = jwt.NewNumericDate(time.Now().Add(-time.Second))
+    _, err = suite.service.RegenerateRecoveryCodes(ctx, &stale)
+    suite.ErrorIs(err, mfa.ErrStepUpRequired)
+}
+
+func (suite *MFATestSuite) TestRepeatedFailuresLockOut() {
+    ctx, claims := suite.signedIn("lockout@mfa.example.test")
+    secret, _ := suite.enrollTOTP(ctx, claims)
+
+    for i := 0; i < mfa.MaxAttempts; i++ {
+        _, err := suite.service.Verify(ctx, claims, mfa.MethodTOTP, "000000")
+        suite.ErrorIs(err, mfa.ErrInvalidCode)
+    }
+    _, err := suite.service.Verify(ctx, claims, mfa.MethodTOTP, authenticatorCode(secret, time.Now().Add(30*time.Second)))
+    suite.ErrorIs(err, mfa.ErrTooManyAttempts)
+}
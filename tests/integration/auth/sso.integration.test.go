@@ -0,0 +1,407 @@
+// tests/integration/auth/sso.integration.test.go
+package integration
+
+import (
+    "context"
+    "crypto/rsa"
+    "crypto/sha256"
+    "database/sql"
+    "encoding/base64"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "net/url"
+    "path/filepath"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/alicebob/miniredis/v2"
+    "github.com/go-redis/redis/v8"
+    "github.com/golang-jwt/jwt/v4"
+    "github.com/stretchr/testify/suite"
+    "gorm.io/gorm"
+
+    "secure-iran-intel/database"
+    "secure-iran-intel/pkg/audit"
+    "secure-iran-intel/pkg/sso"
+    "secure-iran-intel/pkg/token"
+)
+
+const (
+    testIssuer   = "sso-test"
+    testAudience = "sso-test-api"
+    testClientID = "intel-gateway"
+)
+
+// testTargetKey keys audit target hashes in every suite of the package
+var testTargetKey = []byte("integration-test-audit-target-key-0000")
+
+// mockIdP is a minimal OpenID Connect provider: discovery, JWKS and a
+// token endpoint. Tests authorize a user by calling authorize with the
+// URL the service redirected to, standing in for the IdP's login page.
+type mockIdP struct {
+    server *httptest.Server
+    key    *token.SigningKey
+
+    mu    sync.Mutex
+    codes map[string]grant
+}
+
+type grant struct {
+    claims    jwt.MapClaims
+    challenge string
+}
+
+func newMockIdP() (*mockIdP, error) {
+    key, err := token.GenerateKey(token.AlgRS256, time.Now())
+    if err != nil {
+        return nil, err
+    }
+    idp := &mockIdP{key: key, codes: make(map[string]grant)}
+
+    mux := http.NewServeMux()
+    mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+        json.NewEncoder(w).Encode(map[string]interface{}{
+            "issuer":                                idp.server.URL,
+            "authorization_endpoint":                idp.server.URL + "/authorize",
+            "token_endpoint":                        idp.server.URL + "/token",
+            "jwks_uri":                              idp.server.URL + "/jwks",
+            "id_token_signing_alg_values_supported": []string{"RS256"},
+        })
+    })
+    mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+        jwk, err := idp.key.JWK()
+        if err != nil {
+            http.Error(w, err.Error(), http.StatusInternalServerError)
+            return
+        }
+        json.NewEncoder(w).Encode(token.JWKSet{Keys: []token.JWK{jwk}})
+    })
+    mux.HandleFunc("/token", idp.token)
+    idp.server = httptest.NewServer(mux)
+    return idp, nil
+}
+
+// authorize signs the user in at the IdP and returns the code the
+// callback would receive
+func (idp *mockIdP) authorize(redirect string, claims jwt.MapClaims) (state, code string, err error) {
+    u, err := url.Parse(redirect)
+    if err != nil {
+        return "", "", err
+    }
+    q := u.Query()
+    if q.Get("client_id") != testClientID || q.Get("code_challenge_method") != "S256" {
+        return "", "", fmt.Errorf("unexpected authorization request: %s", redirect)
+    }
+    claims["nonce"] = q.Get("nonce")
+
+    code = fmt.Sprintf("code-%d", time.Now().UnixNano())
+    idp.mu.Lock()
+    idp.codes[code] = grant{claims: claims, challenge: q.Get("code_challenge")}
+    idp.mu.Unlock()
+    return q.Get("state"), code, nil
+}
+
+func (idp *mockIdP) token(w http.ResponseWriter, r *http.Request) {
+    r.ParseForm()
+    idp.mu.Lock()
+    g, ok := idp.codes[r.PostForm.Get("code")]
+    delete(idp.codes, r.PostForm.Get("code"))
+    idp.mu.Unlock()
+    if !ok {
+        http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+        return
+    }
+    // PKCE: the verifier must hash to the challenge
+    sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+    if base64.RawURLEncoding.EncodeToString(sum[:]) != g.challenge {
+        http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+        return
+    }
+
+    now := time.Now()
+    // Like most IdPs, vouch for the email unless the test says otherwise
+    if _, ok := g.claims["email_verified"]; !ok {
+        g.claims["email_verified"] = true
+    }
+    g.claims["iss"] = idp.server.URL
+    g.claims["aud"] = testClientID
+    g.claims["iat"] = now.Unix()
+    g.claims["exp"] = now.Add(5 * time.Minute).Unix()
+    t := jwt.NewWithClaims(jwt.SigningMethodRS256, g.claims)
+    t.Header["kid"] = idp.key.ID
+    signer, err := idp.key.Signer()
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusInternalServerError)
+        return
+    }
+    idToken, err := t.SignedString(signer.(*rsa.PrivateKey))
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusInternalServerError)
+        return
+    }
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(map[string]interface{}{
+        "access_token": "idp-access-token",
+        "token_type":   "Bearer",
+        "expires_in":   300,
+        "id_token":     idToken,
+    })
+}
+
+type SSOTestSuite struct {
+    suite.Suite
+    db       *gorm.DB
+    sqlDB    *sql.DB
+    ctx      context.Context
+    redis    *miniredis.Miniredis
+    idp      *mockIdP
+    service  *sso.Service
+    verifier *token.Verifier
+    tenantID string
+    admin    context.Context // Acting as a tenant admin
+    scim     context.Context // Acting as the directory's API key
+}
+
+func TestSSOSuite(t *testing.T) {
+    suite.Run(t, new(SSOTestSuite))
+}
+
+func (suite *SSOTestSuite) SetupSuite() {
+    suite.ctx = context.Background()
+
+    var err error
+    suite.db, suite.sqlDB, err = database.ConnectTest()
+    if err != nil {
+        suite.T().Fatalf("Failed to connect to test database: %v", err)
+    }
+    if err := database.MigrateTest(suite.db); err != nil {
+        suite.T().Fatalf("Failed to run test migrations: %v", err)
+    }
+
+    suite.redis, err = miniredis.Run()
+    if err != nil {
+        suite.T().Fatalf("Failed to start Redis: %v", err)
+    }
+    client := redis.NewClient(&redis.Options{Addr: suite.redis.Addr()})
+
+    suite.idp, err = newMockIdP()
+    if err != nil {
+        suite.T().Fatalf("Failed to start mock IdP: %v", err)
+    }
+
+    registerKeyring(suite.T(), suite.db)
+    cfg := token.Config{Issuer: testIssuer, Audience: testAudience}
+    issuer, err := token.NewIssuer(suite.ctx, cfg, token.NewGormKeyStore(suite.db))
+    if err != nil {
+        suite.T().Fatalf("Failed to create token issuer: %v", err)
+    }
+    revocations := token.NewRevocations(client, 0)
+    suite.verifier = token.NewVerifier(issuer, revocations, testIssuer, testAudience)
+
+    suite.Require().NoError(audit.SetTargetKey(testTargetKey))
+    auditLog := audit.NewLogger(audit.NewFileStore(filepath.Join(suite.T().TempDir(), "audit.log")))
+    suite.service = sso.NewService(sso.NewGormStore(suite.db), sso.NewRedisStateStore(client), issuer, revocations, auditLog, "https://gateway.example.test")
+
+    slug := fmt.Sprintf("sso-%d", time.Now().UnixNano())
+    err = suite.db.Raw("INSERT INTO tenants (name, slug) VALUES (?, ?) RETURNING id", slug, slug).Scan(&suite.tenantID).Error
+    if err != nil {
+        suite.T().Fatalf("Failed to create tenant: %v", err)
+    }
+    suite.admin = audit.WithActor(suite.ctx, audit.Actor{TenantID: suite.tenantID, Type: audit.ActorUser, ID: "00000000-0000-0000-0000-000000000001"})
+    suite.scim = audit.WithActor(suite.ctx, audit.Actor{TenantID: suite.tenantID, Type: audit.ActorAPIKey, ID: "directory", OnBehalfOf: "00000000-0000-0000-0000-000000000001"})
+}
+
+func (suite *SSOTestSuite) TearDownSuite() {
+    if suite.idp != nil {
+        suite.idp.server.Close()
+    }
+    if suite.redis != nil {
+        suite.redis.Close()
+    }
+    if suite.db != nil {
+        suite.db.Exec("DELETE FROM tenants WHERE id = ?", suite.tenantID)
+    }
+    if suite.sqlDB != nil {
+        suite.sqlDB.Close()
+    }
+}
+
+func (suite *SSOTestSuite) provider(name string, jit bool) *sso.Provider {
+    p := &sso.Provider{
+        Name:            name,
+        Protocol:        sso.ProtocolOIDC,
+        Enabled:         true,
+        IssuerURL:       suite.idp.server.URL,
+        ClientID:        testClientID,
+        ClientSecret:    "secret",
+        GroupsClaim:     "groups",
+        RoleMappings:    []sso.RoleMapping{{Group: "intel-admins", Role: "admin"}, {Group: "analysts", Role: "user"}},
+        JITProvisioning: jit,
+    }
+    suite.Require().NoError(suite.service.SaveProvider(suite.admin, p))
+    return p
+}
+
+// signIn runs the whole redirect flow for a user the IdP vouches for
+func (suite *SSOTestSuite) signIn(providerID string, claims jwt.MapClaims) (*sso.SignIn, error) {
+    redirect, err := suite.service.Begin(suite.ctx, providerID)
+    suite.Require().NoError(err)
+    state, code, err := suite.idp.authorize(redirect, claims)
+    suite.Require().NoError(err)
+    return suite.service.CompleteOIDC(suite.ctx, state, code)
+}
+
+func (suite *SSOTestSuite) TestJITSignInMapsGroupsToRole() {
+    p := suite.provider("jit", true)
+
+    signIn, err := suite.signIn(p.ID, jwt.MapClaims{
+        "sub":         "jit-analyst",
+        "email":       "analyst@jit.example.test",
+        "given_name":  "Ana",
+        "family_name": "Lyst",
+        "groups":      []string{"staff", "analysts"},
+    })
+    suite.Require().NoError(err)
+    suite.Equal("user", signIn.User.Role)
+    suite.Equal("Ana", signIn.User.FirstName)
+    suite.True(signIn.User.IsActive)
+
+    claims, err := suite.verifier.Verify(suite.ctx, signIn.AccessToken)
+    suite.Require().NoError(err)
+    suite.Equal(signIn.User.ID, claims.UserID)
+    suite.Equal(suite.tenantID, claims.TenantID)
+
+    // Promoted at the IdP: the next sign-in carries the new role
+    signIn, err = suite.signIn(p.ID, jwt.MapClaims{
+        "sub":    "jit-analyst",
+        "email":  "analyst@jit.example.test",
+        "groups": []string{"intel-admins"},
+    })
+    suite.Require().NoError(err)
+    suite.Equal("admin", signIn.User.Role)
+}
+
+func (suite *SSOTestSuite) TestStateIsSingleUse() {
+    p := suite.provider("replay", true)
+
+    redirect, err := suite.service.Begin(suite.ctx, p.ID)
+    suite.Require().NoError(err)
+    claims := jwt.MapClaims{"sub": "replayer", "email": "replayer@example.test", "groups": []string{"analysts"}}
+    state, code, err := suite.idp.authorize(redirect, claims)
+    suite.Require().NoError(err)
+
+    _, err = suite.service.CompleteOIDC(suite.ctx, state, code)
+    suite.Require().NoError(err)
+    _, err = suite.service.CompleteOIDC(suite.ctx, state, code)
+    suite.ErrorIs(err, sso.ErrInvalidState)
+}
+
+func (suite *SSOTestSuite) TestUnmappedGroupsAreRefused() {
+    p := suite.provider("unmapped", true)
+
+    _, err := suite.signIn(p.ID, jwt.MapClaims{"sub": "outsider", "email": "outsider@example.test", "groups": []string{"contractors"}})
+    suite.ErrorIs(err, sso.ErrNoRole)
+}
+
+func (suite *SSOTestSuite) TestWithoutJITUsersMustBeProvisioned() {
+    p := suite.provider("scim-only", false)
+    claims := func() jwt.MapClaims {
+        return jwt.MapClaims{"sub": "dir-1234", "email": "Provisioned@Example.test", "groups": []string{"analysts"}}
+    }
+
+    _, err := suite.signIn(p.ID, claims())
+    suite.ErrorIs(err, sso.ErrNotProvisioned)
+
+    created, err := suite.service.SCIMCreateUser(suite.scim, &sso.SCIMUser{
+        Schemas:    []string{sso.SCIMUserSchema},
+        UserName:   "provisioned@example.test",
+        ExternalID: "dir-1234",
+        Name:       &sso.SCIMName{GivenName: "Pro", FamilyName: "Visioned"},
+    })
+    suite.Require().NoError(err)
+    suite.True(*created.Active)
+
+    signIn, err := suite.signIn(p.ID, claims())
+    suite.Require().NoError(err)
+    suite.Equal(created.ID, signIn.User.ID, "Sign-in must link to the provisioned user")
+    suite.Equal("user", signIn.User.Role)
+
+    list, err := suite.service.SCIMListUsers(suite.scim, `userName eq "provisioned@example.test"`, 1, 10)
+    suite.Require().NoError(err)
+    suite.Equal(int64(1), list.TotalResults)
+    suite.Equal("dir-1234", list.Resources[0].ExternalID)
+}
+
+func (suite *SSOTestSuite) TestUnverifiedEmailDoesNotLink() {
+    p := suite.provider("unverified", true)
+    created, err := suite.service.SCIMCreateUser(suite.scim, &sso.SCIMUser{
+        Schemas:  []string{sso.SCIMUserSchema},
+        UserName: "victim@example.test",
+    })
+    suite.Require().NoError(err)
+
+    // Anyone can claim an address at an IdP that does not check it
+    for _, verified := range []interface{}{false, nil} {
+        _, err := suite.signIn(p.ID, jwt.MapClaims{
+            "sub":            "attacker",
+            "email":          "victim@example.test",
+            "email_verified": verified,
+            "groups":         []string{"analysts"},
+        })
+        suite.ErrorIs(err, sso.ErrAssertion, "email_verified %v", verified)
+    }
+
+    signIn, err := suite.signIn(p.ID, jwt.MapClaims{"sub": "victim", "email": "victim@example.test", "groups": []string{"analysts"}})
+    suite.Require().NoError(err)
+    suite.Equal(created.ID, signIn.User.ID)
+}
+
+func (suite *SSOTestSuite) TestSCIMDeprovisioningCutsAccessImmediately() {
+    p := suite.provider("leavers", true)
+    claims := func() jwt.MapClaims {
+        return jwt.MapClaims{"sub": "leaver", "email": "leaver@example.test", "groups": []string{"analysts"}}
+    }
+
+    signIn, err := suite.signIn(p.ID, claims())
+    suite.Require().NoError(err)
+    _, err = suite.verifier.Verify(suite.ctx, signIn.AccessToken)
+    suite.Require().NoError(err)
+
+    // Azure AD style: active as a string, no path
+    patch := &sso.SCIMPatch{Schemas: []string{sso.SCIMPatchSchema}}
+    suite.Require().NoError(json.Unmarshal([]byte(`{"Operations":[{"op":"Replace","value":{"active":"False"}}]}`), patch))
+    updated, err := suite.service.SCIMPatchUser(suite.scim, signIn.User.ID, patch)
+    suite.Require().NoError(err)
+    suite.False(*updated.Active)
+
+    // The token issued before is dead, well before it expires
+    _, err = suite.verifier.Verify(suite.ctx, signIn.AccessToken)
+    suite.ErrorIs(err, token.ErrRevoked)
+
+    // And the IdP vouching for them again does not get them back in
+    _, err = suite.signIn(p.ID, claims())
+    suite.ErrorIs(err, sso.ErrUserDisabled)
+}
+
+func (suite *SSOTestSuite) TestSCIMIsTenantScoped() {
+    slug := fmt.Sprintf("sso-other-%d", time.Now().UnixNano())
+    var otherTenant string
+    suite.Require().NoError(suite.db.Raw("INSERT INTO tenants (name, slug) VALUES (?, ?) RETURNING id", slug, slug).Scan(&otherTenant).Error)
+    defer suite.db.Exec("DELETE FROM tenants WHERE id = ?", otherTenant)
+
+    created, err := suite.service.SCIMCreateUser(suite.scim, &sso.SCIMUser{UserName: "scoped@example.test"})
+    suite.Require().NoError(err)
+
+    other := audit.WithActor(suite.ctx, audit.Actor{TenantID: otherTenant, Type: audit.ActorAPIKey, ID: "other-directory"})
+    _, err = suite.service.SCIMGetUser(other, created.ID)
+    suite.ErrorIs(err, sso.ErrUnknownUser)
+    err = suite.service.SCIMDeleteUser(other, created.ID)
+    suite.ErrorIs(err, sso.ErrUnknownUser)
+
+    user, err := suite.service.SCIMGetUser(suite.scim, created.ID)
+    suite.Require().NoError(err)
+    suite.True(*user.Active)
+}
@@ -0,0 +1,238 @@
+// tests/integration/auth/token.integration.test.go
+package integration
+
+import (
+    "context"
+    "crypto"
+    "encoding/json"
+    "errors"
+    "net/http"
+    "net/http/httptest"
+    "strconv"
+    "sync/atomic"
+    "testing"
+    "time"
+
+    "github.com/alicebob/miniredis/v2"
+    "github.com/go-redis/redis/v8"
+    "github.com/golang-jwt/jwt/v4"
+    "github.com/stretchr/testify/suite"
+
+    "secure-iran-intel/pkg/token"
+)
+
+// staticKeys is a KeySource over keys the test holds the private half of
+type staticKeys map[string]*token.SigningKey
+
+func (s staticKeys) PublicKey(ctx context.Context, kid string) (crypto.PublicKey, string, error) {
+    key, ok := s[kid]
+    if !ok {
+        return nil, "", token.ErrUnknownKey
+    }
+    signer, err := key.Signer()
+    if err != nil {
+        return nil, "", err
+    }
+    return signer.Public(), key.Algorithm, nil
+}
+
+type TokenTestSuite struct {
+    suite.Suite
+    ctx         context.Context
+    redis       *miniredis.Miniredis
+    revocations *token.Revocations
+    edKey       *token.SigningKey
+    rsaKey      *token.SigningKey
+    verifier    *token.Verifier
+}
+
+func TestTokenSuite(t *testing.T) {
+    suite.Run(t, new(TokenTestSuite))
+}
+
+func (suite *TokenTestSuite) SetupSuite() {
+    suite.ctx = context.Background()
+
+    var err error
+    suite.edKey, err = token.GenerateKey(token.AlgEdDSA, time.Now())
+    suite.Require().NoError(err)
+    suite.rsaKey, err = token.GenerateKey(token.AlgRS256, time.Now())
+    suite.Require().NoError(err)
+}
+
+func (suite *TokenTestSuite) SetupTest() {
+    var err error
+    suite.redis, err = miniredis.Run()
+    if err != nil {
+        suite.T().Fatalf("Failed to start Redis: %v", err)
+    }
+    suite.revocations = token.NewRevocations(redis.NewClient(&redis.Options{Addr: suite.redis.Addr()}), 0)
+    keys := staticKeys{suite.edKey.ID: suite.edKey, suite.rsaKey.ID: suite.rsaKey}
+    suite.verifier = token.NewVerifier(keys, suite.revocations, testIssuer, testAudience)
+}
+
+func (suite *TokenTestSuite) TearDownTest() {
+    suite.redis.Close()
+}
+
+// claims are valid access token claims issued at issuedAt
+func (suite *TokenTestSuite) claims(issuedAt time.Time) *token.Claims {
+    return &token.Claims{
+        TenantID: "tenant-1",
+        UserID:   "user-1",
+        RegisteredClaims: jwt.RegisteredClaims{
+            ID:        "jti-" + issuedAt.Format(time.RFC3339Nano),
+            Issuer:    testIssuer,
+            Subject:   "user-1",
+            Audience:  jwt.ClaimStrings{testAudience},
+            IssuedAt:  jwt.NewNumericDate(issuedAt),
+            NotBefore: jwt.NewNumericDate(issuedAt),
+            ExpiresAt: jwt.NewNumericDate(issuedAt.Add(token.DefaultTokenTTL)),
+        },
+        IssuedAtMicros: issuedAt.UnixMicro(),
+    }
+}
+
+// sign signs claims with key's private half using method, naming kid
+func (suite *TokenTestSuite) sign(method jwt.SigningMethod, kid string, key interface{}, claims *token.Claims) string {
+    t := jwt.NewWithClaims(method, claims)
+    t.Header["kid"] = kid
+    signed, err := t.SignedString(key)
+    suite.Require().NoError(err)
+    return signed
+}
+
+func (suite *TokenTestSuite) signer(key *token.SigningKey) crypto.Signer {
+    signer, err := key.Signer()
+    suite.Require().NoError(err)
+    return signer
+}
+
+func (suite *TokenTestSuite) TestValidTokensVerify() {
+    for _, tt := range []struct {
+        name   string
+        method jwt.SigningMethod
+        key    *token.SigningKey
+    }{
+        {"EdDSA", jwt.SigningMethodEdDSA, suite.edKey},
+        {"RS256", jwt.SigningMethodRS256, suite.rsaKey},
+    } {
+        suite.Run(tt.name, func() {
+            signed := suite.sign(tt.method, tt.key.ID, suite.signer(tt.key), suite.claims(time.Now()))
+            claims, err := suite.verifier.Verify(suite.ctx, signed)
+            suite.Require().NoError(err)
+            suite.Equal("user-1", claims.UserID)
+        })
+    }
+}
+
+func (suite *TokenTestSuite) TestKeyDecidesAlgorithm() {
+    tests := []struct {
+        name   string
+        signed string
+    }{
+        // Signed by the RSA key, but naming the Ed25519 key
+        {"alg does not match kid", suite.sign(jwt.SigningMethodRS256, suite.edKey.ID, suite.signer(suite.rsaKey), suite.claims(time.Now()))},
+        // HMAC keyed with something public must never be accepted
+        {"HMAC", suite.sign(jwt.SigningMethodHS256, suite.edKey.ID, []byte(suite.edKey.ID), suite.claims(time.Now()))},
+        {"unknown kid", suite.sign(jwt.SigningMethodEdDSA, "not-a-key", suite.signer(suite.edKey), suite.claims(time.Now()))},
+        {"no kid", suite.sign(jwt.SigningMethodEdDSA, "", suite.signer(suite.edKey), suite.claims(time.Now()))},
+    }
+
+    for _, tt := range tests {
+        suite.Run(tt.name, func() {
+            _, err := suite.verifier.Verify(suite.ctx, tt.signed)
+            suite.True(errors.Is(err, token.ErrInvalidToken), err)
+        })
+    }
+}
+
+func (suite *TokenTestSuite) TestExpiredTokenRejected() {
+    // Issued long enough ago that the token expired beyond any leeway
+    claims := suite.claims(time.Now().Add(-token.DefaultTokenTTL - time.Hour))
+    signed := suite.sign(jwt.SigningMethodEdDSA, suite.edKey.ID, suite.signer(suite.edKey), claims)
+
+    _, err := suite.verifier.Verify(suite.ctx, signed)
+    suite.True(errors.Is(err, token.ErrInvalidToken), err)
+}
+
+func (suite *TokenTestSuite) TestRevokedTokensRejected() {
+    issuedAt := time.Now().Add(-time.Minute)
+    claims := suite.claims(issuedAt)
+    signed := suite.sign(jwt.SigningMethodEdDSA, suite.edKey.ID, suite.signer(suite.edKey), claims)
+    other := suite.claims(issuedAt.Add(time.Second))
+    otherSigned := suite.sign(jwt.SigningMethodEdDSA, suite.edKey.ID, suite.signer(suite.edKey), other)
+
+    // Revoking one token leaves the user's other tokens valid
+    suite.Require().NoError(suite.revocations.RevokeToken(suite.ctx, claims))
+    _, err := suite.verifier.Verify(suite.ctx, signed)
+    suite.ErrorIs(err, token.ErrRevoked)
+    _, err = suite.verifier.Verify(suite.ctx, otherSigned)
+    suite.NoError(err)
+
+    // Revoking the user covers every token issued until now
+    suite.Require().NoError(suite.revocations.RevokeUser(suite.ctx, "tenant-1", "user-1"))
+    _, err = suite.verifier.Verify(suite.ctx, otherSigned)
+    suite.ErrorIs(err, token.ErrRevoked)
+}
+
+func (suite *TokenTestSuite) TestUserRevocationSparesLaterTokensInSameSecond() {
+    // Start just past a second boundary so every token shares the second
+    time.Sleep(time.Until(time.Now().Truncate(time.Second).Add(time.Second)))
+    before := suite.sign(jwt.SigningMethodEdDSA, suite.edKey.ID, suite.signer(suite.edKey), suite.claims(time.Now()))
+    time.Sleep(time.Millisecond)
+    suite.Require().NoError(suite.revocations.RevokeUser(suite.ctx, "tenant-1", "user-1"))
+    time.Sleep(time.Millisecond)
+    claims := suite.claims(time.Now())
+    after := suite.sign(jwt.SigningMethodEdDSA, suite.edKey.ID, suite.signer(suite.edKey), claims)
+
+    _, err := suite.verifier.Verify(suite.ctx, before)
+    suite.ErrorIs(err, token.ErrRevoked)
+    _, err = suite.verifier.Verify(suite.ctx, after)
+    suite.NoError(err, "A token issued after the revocation must verify")
+
+    // Entries written in whole seconds still cover their entire second
+    suite.Require().NoError(suite.redis.Set("token_revoked_user:tenant-1:user-1", strconv.FormatInt(claims.IssuedAt.Unix(), 10)))
+    _, err = suite.verifier.Verify(suite.ctx, after)
+    suite.ErrorIs(err, token.ErrRevoked)
+}
+
+func (suite *TokenTestSuite) TestRevocationListDownFailsClosed() {
+    signed := suite.sign(jwt.SigningMethodEdDSA, suite.edKey.ID, suite.signer(suite.edKey), suite.claims(time.Now()))
+    suite.redis.Close()
+
+    claims, err := suite.verifier.Verify(suite.ctx, signed)
+    suite.Error(err, "A token must not verify when revocations cannot be read")
+    suite.Nil(claims)
+    // A server error, not a bad token
+    suite.False(errors.Is(err, token.ErrInvalidToken))
+    suite.False(errors.Is(err, token.ErrRevoked))
+}
+
+func (suite *TokenTestSuite) TestJWKSCacheKeepsKeysWhileIssuerDown() {
+    jwk, err := suite.edKey.JWK()
+    suite.Require().NoError(err)
+    var down atomic.Bool
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if down.Load() {
+            w.WriteHeader(http.StatusServiceUnavailable)
+            return
+        }
+        json.NewEncoder(w).Encode(token.JWKSet{Keys: []token.JWK{jwk}})
+    }))
+    defer server.Close()
+
+    verifier := token.NewVerifier(token.NewJWKSCache(server.URL), suite.revocations, testIssuer, testAudience)
+    signed := suite.sign(jwt.SigningMethodEdDSA, suite.edKey.ID, suite.signer(suite.edKey), suite.claims(time.Now()))
+    _, err = verifier.Verify(suite.ctx, signed)
+    suite.Require().NoError(err)
+
+    // Unknown kids are not refetched until the interval has passed, and the
+    // cached keys go on verifying meanwhile
+    down.Store(true)
+    unknown := suite.sign(jwt.SigningMethodRS256, suite.rsaKey.ID, suite.signer(suite.rsaKey), suite.claims(time.Now()))
+    _, err = verifier.Verify(suite.ctx, unknown)
+    suite.True(errors.Is(err, token.ErrInvalidToken), err)
+    _, err = verifier.Verify(suite.ctx, signed)
+    suite.NoError(err)
+}
@@ -0,0 +1,159 @@
+// tests/integration/database/api_keys.integration.test.go
+package integration
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+    "path/filepath"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/suite"
+    "gorm.io/gorm"
+
+    "secure-iran-intel/database"
+    "secure-iran-intel/pkg/apikey"
+    "secure-iran-intel/pkg/audit"
+    "secure-iran-intel/pkg/rbac"
+    "secure-iran-intel/pkg/tenancy"
+)
+
+type APIKeyTestSuite struct {
+    suite.Suite
+    db       *gorm.DB
+    sqlDB    *sql.DB
+    ctx      context.Context
+    keys     *apikey.Service
+    tenantID string
+    userID   string
+}
+
+func TestAPIKeySuite(t *testing.T) {
+    suite.Run(t, new(APIKeyTestSuite))
+}
+
+func (suite *APIKeyTestSuite) SetupSuite() {
+    var err error
+    suite.db, suite.sqlDB, err = database.ConnectTest()
+    if err != nil {
+        suite.T().Fatalf("Failed to connect to test database: %v", err)
+    }
+    if err := database.MigrateTest(suite.db); err != nil {
+        suite.T().Fatalf("Failed to run test migrations: %v", err)
+    }
+
+    suite.Require().NoError(audit.SetTargetKey(testTargetKey))
+    suite.keys = apikey.NewService(
+        apikey.NewGormStore(suite.db),
+        rbac.NewEngine(rbac.NewGormStore(suite.db)),
+        audit.NewLogger(audit.NewFileStore(filepath.Join(suite.T().TempDir(), "audit.jsonl"))),
+    )
+}
+
+func (suite *APIKeyTestSuite) TearDownSuite() {
+    if suite.sqlDB != nil {
+        suite.sqlDB.Close()
+    }
+}
+
+func (suite *APIKeyTestSuite) SetupTest() {
+    slug := fmt.Sprintf("api-keys-%d", time.Now().UnixNano())
+    err := suite.db.Raw(`INSERT INTO tenants (name, slug) VALUES (?, ?) RETURNING id`, slug, slug).Scan(&suite.tenantID).Error
+    suite.Require().NoError(err)
+
+    system := audit.WithActor(context.Background(), audit.Actor{TenantID: suite.tenantID, Type: audit.ActorSystem, ID: "api-keys-test"})
+    suite.Require().NoError(rbac.NewGormStore(suite.db).SaveRoles(system, rbac.DefaultRoles(suite.tenantID)))
+    err = tenancy.Transaction(system, suite.db, suite.tenantID, func(tx *gorm.DB) error {
+        return tx.Raw(`INSERT INTO users (tenant_id, email, password_hash, role) VALUES (?, ?, 'x', 'user') RETURNING id`,
+            suite.tenantID, slug+"@example.test").Scan(&suite.userID).Error
+    })
+    suite.Require().NoError(err)
+
+    // Keys are created by, and act for, a plain user
+    suite.ctx = audit.WithActor(context.Background(), audit.Actor{TenantID: suite.tenantID, Type: audit.ActorUser, ID: suite.userID})
+}
+
+func (suite *APIKeyTestSuite) TearDownTest() {
+    suite.db.Exec("DELETE FROM tenants WHERE id = ?", suite.tenantID)
+}
+
+func (suite *APIKeyTestSuite) create(scopes ...string) *apikey.Issued {
+    issued, err := suite.keys.Create(suite.ctx, apikey.CreateRequest{Name: "ingest", Scopes: scopes})
+    suite.Require().NoError(err)
+    return issued
+}
+
+func (suite *APIKeyTestSuite) TestScopesMustBeHeldByOwner() {
+    issued := suite.create(rbac.PermJobsCreate, rbac.PermPhoneLookup)
+    suite.True(issued.Key.Allows(rbac.PermJobsCreate))
+    suite.False(issued.Key.Allows(rbac.PermBulkJobs))
+
+    // Users hold neither roles:write nor any wildcard
+    for _, scope := range []string{rbac.PermRolesWrite, "*", "jobs:*"} {
+        _, err := suite.keys.Create(suite.ctx, apikey.CreateRequest{Name: "escalate", Scopes: []string{rbac.PermJobsCreate, scope}})
+        suite.True(errors.Is(err, apikey.ErrScopeNotHeld), scope)
+    }
+
+    // Only users own keys
+    service := audit.WithActor(context.Background(), audit.Actor{TenantID: suite.tenantID, Type: audit.ActorSystem, ID: "api-keys-test"})
+    _, err := suite.keys.Create(service, apikey.CreateRequest{Name: "orphan", Scopes: []string{rbac.PermJobsCreate}})
+    suite.True(errors.Is(err, apikey.ErrNoOwner), err)
+}
+
+func (suite *APIKeyTestSuite) TestRotatedKeysOverlap() {
+    old := suite.create(rbac.PermJobsCreate)
+
+    next, err := suite.keys.Rotate(suite.ctx, old.Key.ID, time.Hour)
+    suite.Require().NoError(err)
+    suite.NotEqual(old.Secret, next.Secret)
+    suite.Equal(old.Key.Scopes, next.Key.Scopes)
+
+    // Both work until the overlap ends
+    for _, secret := range []string{old.Secret, next.Secret} {
+        _, err := suite.keys.Authenticate(suite.ctx, secret)
+        suite.NoError(err)
+    }
+    replaced, err := suite.keys.Authenticate(suite.ctx, old.Secret)
+    suite.Require().NoError(err)
+    suite.Equal(next.Key.ID, replaced.ReplacedBy)
+    suite.Require().NotNil(replaced.ExpiresAt)
+    suite.WithinDuration(time.Now().Add(time.Hour), *replaced.ExpiresAt, time.Minute)
+
+    // A key is only ever rotated once
+    _, err = suite.keys.Rotate(suite.ctx, old.Key.ID, time.Hour)
+    suite.True(errors.Is(err, apikey.ErrRevoked), err)
+
+    // Without an overlap the replaced key stops at once
+    last, err := suite.keys.Rotate(suite.ctx, next.Key.ID, 0)
+    suite.Require().NoError(err)
+    _, err = suite.keys.Authenticate(suite.ctx, next.Secret)
+    suite.True(errors.Is(err, apikey.ErrExpired), err)
+    _, err = suite.keys.Authenticate(suite.ctx, last.Secret)
+    suite.NoError(err)
+
+    _, err = suite.keys.Rotate(suite.ctx, last.Key.ID, apikey.MaxRotationOverlap+time.Hour)
+    suite.True(errors.Is(err, apikey.ErrInvalidOverlap), err)
+}
+
+func (suite *APIKeyTestSuite) TestRevokedKeysStopWorking() {
+    issued := suite.create(rbac.PermJobsCreate)
+    _, err := suite.keys.Authenticate(suite.ctx, issued.Secret)
+    suite.Require().NoError(err)
+
+    suite.Require().NoError(suite.keys.Revoke(suite.ctx, issued.Key.ID))
+
+    _, err = suite.keys.Authenticate(suite.ctx, issued.Secret)
+    suite.True(errors.Is(err, apikey.ErrRevoked), err)
+
+    // Revoked keys cannot be rotated back to life
+    _, err = suite.keys.Rotate(suite.ctx, issued.Key.ID, time.Hour)
+    suite.True(errors.Is(err, apikey.ErrRevoked), err)
+
+    keys, err := suite.keys.List(suite.ctx)
+    suite.Require().NoError(err)
+    suite.Require().Len(keys, 1)
+    suite.NotNil(keys[0].RevokedAt)
+    suite.Equal(suite.userID, keys[0].RevokedBy)
+}
@@ -0,0 +1,175 @@
+// tests/integration/database/approvals.integration.test.go
+package integration
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+    "path/filepath"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/suite"
+    "gorm.io/gorm"
+
+    "secure-iran-intel/database"
+    "secure-iran-intel/pkg/approval"
+    "secure-iran-intel/pkg/audit"
+    "secure-iran-intel/pkg/rbac"
+    "secure-iran-intel/pkg/refusal"
+    "secure-iran-intel/pkg/tenancy"
+)
+
+type ApprovalTestSuite struct {
+    suite.Suite
+    db        *gorm.DB
+    sqlDB     *sql.DB
+    workflow  *approval.Workflow
+    tenantID  string
+    requester string
+    approvers []string
+}
+
+func TestApprovalSuite(t *testing.T) {
+    suite.Run(t, new(ApprovalTestSuite))
+}
+
+func (suite *ApprovalTestSuite) SetupSuite() {
+    var err error
+    suite.db, suite.sqlDB, err = database.ConnectTest()
+    if err != nil {
+        suite.T().Fatalf("Failed to connect to test database: %v", err)
+    }
+    if err := database.MigrateTest(suite.db); err != nil {
+        suite.T().Fatalf("Failed to run test migrations: %v", err)
+    }
+
+    suite.Require().NoError(audit.SetTargetKey(testTargetKey))
+    suite.workflow = approval.NewWorkflow(
+        approval.NewGormStore(suite.db),
+        rbac.NewEngine(rbac.NewGormStore(suite.db)),
+        audit.NewLogger(audit.NewFileStore(filepath.Join(suite.T().TempDir(), "audit.jsonl"))),
+    )
+}
+
+func (suite *ApprovalTestSuite) TearDownSuite() {
+    if suite.sqlDB != nil {
+        suite.sqlDB.Close()
+    }
+}
+
+func (suite *ApprovalTestSuite) SetupTest() {
+    slug := fmt.Sprintf("approvals-%d", time.Now().UnixNano())
+    err := suite.db.Raw(`INSERT INTO tenants (name, slug) VALUES (?, ?) RETURNING id`, slug, slug).Scan(&suite.tenantID).Error
+    suite.Require().NoError(err)
+
+    system := audit.WithActor(context.Background(), audit.Actor{TenantID: suite.tenantID, Type: audit.ActorSystem, ID: "approvals-test"})
+    suite.Require().NoError(rbac.NewGormStore(suite.db).SaveRoles(system, rbac.DefaultRoles(suite.tenantID)))
+
+    // Every user is an admin, so all of them hold approvals:grant
+    suite.approvers = nil
+    for i, name := range []string{"requester", "first", "second"} {
+        var userID string
+        err := tenancy.Transaction(system, suite.db, suite.tenantID, func(tx *gorm.DB) error {
+            return tx.Raw(`INSERT INTO users (tenant_id, email, password_hash, role) VALUES (?, ?, 'x', 'admin') RETURNING id`,
+                suite.tenantID, fmt.Sprintf("%s-%s@example.test", name, slug)).Scan(&userID).Error
+        })
+        suite.Require().NoError(err)
+        if i == 0 {
+            suite.requester = userID
+        } else {
+            suite.approvers = append(suite.approvers, userID)
+        }
+    }
+}
+
+func (suite *ApprovalTestSuite) TearDownTest() {
+    suite.db.Exec("DELETE FROM tenants WHERE id = ?", suite.tenantID)
+}
+
+// as returns a context acting as userID
+func (suite *ApprovalTestSuite) as(userID string) context.Context {
+    return audit.WithActor(context.Background(), audit.Actor{TenantID: suite.tenantID, Type: audit.ActorUser, ID: userID})
+}
+
+// gate opens a request for a job too large to run unapproved
+func (suite *ApprovalTestSuite) gate(jobID string) *approval.Request {
+    r, err := suite.workflow.Gate(suite.as(suite.requester), approval.JobTypeIntelligence, jobID, approval.Subject{Size: 500})
+    suite.Require().NoError(err)
+    suite.Require().NotNil(r)
+    suite.Equal(approval.StatusPending, r.Status)
+    return r
+}
+
+func (suite *ApprovalTestSuite) TestRequesterCannotApproveOwnJob() {
+    suite.gate("job-self")
+
+    _, err := suite.workflow.Approve(suite.as(suite.requester), "job-self", "looks fine to me")
+    suite.True(errors.Is(err, approval.ErrSelfApproval), err)
+    suite.Equal("SELF_APPROVAL", refusal.Code(err))
+
+    // Still pending, so another admin can decide it
+    r, err := suite.workflow.Approve(suite.as(suite.approvers[0]), "job-self", "numbers match the warrant")
+    suite.Require().NoError(err)
+    suite.Equal(approval.StatusApproved, r.Status)
+    suite.Equal(suite.approvers[0], r.DecidedBy)
+}
+
+func (suite *ApprovalTestSuite) TestExpiredRequestsCannotBeDecided() {
+    r := suite.gate("job-expired")
+    err := tenancy.Transaction(suite.as(suite.requester), suite.db, suite.tenantID, func(tx *gorm.DB) error {
+        return tx.Exec("UPDATE approval_requests SET expires_at = ? WHERE id = ?", time.Now().Add(-time.Minute), r.ID).Error
+    })
+    suite.Require().NoError(err)
+
+    // Refused before the sweeper has moved it to expired...
+    _, err = suite.workflow.Approve(suite.as(suite.approvers[0]), "job-expired", "late but fine")
+    suite.True(errors.Is(err, approval.ErrExpired), err)
+
+    // ...and after
+    expired, err := suite.workflow.ExpireOverdue(context.Background(), approval.JobTypeIntelligence)
+    suite.Require().NoError(err)
+    var ids []string
+    for _, e := range expired {
+        ids = append(ids, e.ID)
+    }
+    suite.Contains(ids, r.ID)
+
+    _, err = suite.workflow.Reject(suite.as(suite.approvers[0]), "job-expired", "too late")
+    suite.True(errors.Is(err, approval.ErrNotPending), err)
+}
+
+func (suite *ApprovalTestSuite) TestConcurrentDecisionsDecideOnce() {
+    suite.gate("job-race")
+
+    errs := make([]error, len(suite.approvers))
+    var wg sync.WaitGroup
+    for i, approver := range suite.approvers {
+        wg.Add(1)
+        go func(i int, approver string) {
+            defer wg.Done()
+            if i == 0 {
+                _, errs[i] = suite.workflow.Approve(suite.as(approver), "job-race", "numbers match the warrant")
+            } else {
+                _, errs[i] = suite.workflow.Reject(suite.as(approver), "job-race", "scope is too broad")
+            }
+        }(i, approver)
+    }
+    wg.Wait()
+
+    decided := 0
+    for _, err := range errs {
+        if err == nil {
+            decided++
+            continue
+        }
+        suite.True(errors.Is(err, approval.ErrNotPending), err)
+    }
+    suite.Equal(1, decided)
+
+    pending, err := suite.workflow.Pending(suite.as(suite.requester), suite.tenantID)
+    suite.Require().NoError(err)
+    suite.Empty(pending)
+}
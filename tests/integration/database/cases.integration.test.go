@@ -0,0 +1,137 @@
+// tests/integration/database/cases.integration.test.go
+package integration
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+    "path/filepath"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/suite"
+    "gorm.io/gorm"
+
+    "secure-iran-intel/database"
+    "secure-iran-intel/pkg/audit"
+    "secure-iran-intel/pkg/purpose"
+    "secure-iran-intel/pkg/rbac"
+    "secure-iran-intel/pkg/refusal"
+)
+
+type CaseTestSuite struct {
+    suite.Suite
+    db        *gorm.DB
+    sqlDB     *sql.DB
+    ctx       context.Context
+    service   *purpose.CaseService
+    validator *purpose.Validator
+    tenantID  string
+}
+
+func TestCaseSuite(t *testing.T) {
+    suite.Run(t, new(CaseTestSuite))
+}
+
+func (suite *CaseTestSuite) SetupSuite() {
+    var err error
+    suite.db, suite.sqlDB, err = database.ConnectTest()
+    if err != nil {
+        suite.T().Fatalf("Failed to connect to test database: %v", err)
+    }
+    if err := database.MigrateTest(suite.db); err != nil {
+        suite.T().Fatalf("Failed to run test migrations: %v", err)
+    }
+
+    suite.Require().NoError(audit.SetTargetKey(testTargetKey))
+    cases := purpose.NewGormCaseStore(suite.db)
+    suite.service = purpose.NewCaseService(
+        cases,
+        audit.NewLogger(audit.NewFileStore(filepath.Join(suite.T().TempDir(), "audit.jsonl"))),
+        rbac.NewEngine(rbac.NewGormStore(suite.db)),
+    )
+    suite.validator = purpose.NewValidator(cases)
+}
+
+func (suite *CaseTestSuite) TearDownSuite() {
+    if suite.sqlDB != nil {
+        suite.sqlDB.Close()
+    }
+}
+
+func (suite *CaseTestSuite) SetupTest() {
+    slug := fmt.Sprintf("cases-%d", time.Now().UnixNano())
+    err := suite.db.Raw(`INSERT INTO tenants (name, slug) VALUES (?, ?) RETURNING id`, slug, slug).Scan(&suite.tenantID).Error
+    suite.Require().NoError(err)
+
+    // The system actor passes the permission check
+    suite.ctx = audit.WithActor(context.Background(), audit.Actor{TenantID: suite.tenantID, Type: audit.ActorSystem, ID: "cases-test"})
+}
+
+func (suite *CaseTestSuite) TearDownTest() {
+    suite.db.Exec("DELETE FROM tenants WHERE id = ?", suite.tenantID)
+}
+
+func (suite *CaseTestSuite) jobPurpose(reference, basis string) purpose.Purpose {
+    return purpose.Purpose{CaseReference: reference, LegalBasis: basis, Justification: "tracing the numbers named in the warrant"}
+}
+
+func (suite *CaseTestSuite) TestOpenedCasesAuthoriseJobs() {
+    opened, err := suite.service.Open(suite.ctx, purpose.NewCase{
+        Reference:  "CASE-2026-07",
+        Title:      "Warrant 2026/07",
+        LegalBases: []string{purpose.BasisCourtOrder},
+    })
+    suite.Require().NoError(err)
+    suite.Equal("open", opened.Status)
+    suite.Equal(suite.tenantID, opened.TenantID)
+
+    c, err := suite.validator.Validate(suite.ctx, suite.tenantID, suite.jobPurpose("CASE-2026-07", purpose.BasisCourtOrder))
+    suite.Require().NoError(err)
+    suite.Equal(opened.ID, c.ID)
+
+    _, err = suite.validator.Validate(suite.ctx, suite.tenantID, suite.jobPurpose("CASE-2026-07", purpose.BasisConsent))
+    suite.True(errors.Is(err, purpose.ErrBasisNotAllowed))
+
+    open, err := suite.service.ListOpen(suite.ctx)
+    suite.Require().NoError(err)
+    suite.Len(open, 1)
+
+    // Closed cases stop authorising new jobs
+    suite.Require().NoError(suite.service.Close(suite.ctx, "CASE-2026-07"))
+    _, err = suite.validator.Validate(suite.ctx, suite.tenantID, suite.jobPurpose("CASE-2026-07", purpose.BasisCourtOrder))
+    suite.True(errors.Is(err, purpose.ErrCaseClosed))
+
+    open, err = suite.service.ListOpen(suite.ctx)
+    suite.Require().NoError(err)
+    suite.Empty(open)
+}
+
+func (suite *CaseTestSuite) TestInvalidCasesAreRefused() {
+    past := time.Now().Add(-time.Hour)
+    _, err := suite.service.Open(suite.ctx, purpose.NewCase{Reference: "CASE-EXISTING", LegalBases: []string{purpose.BasisConsent}})
+    suite.Require().NoError(err)
+
+    tests := []struct {
+        name   string
+        req    purpose.NewCase
+        reason error
+    }{
+        {"no legal bases", purpose.NewCase{Reference: "CASE-A"}, purpose.ErrNoLegalBases},
+        {"unknown legal basis", purpose.NewCase{Reference: "CASE-A", LegalBases: []string{"curiosity"}}, purpose.ErrInvalidLegalBasis},
+        {"already expired", purpose.NewCase{Reference: "CASE-A", LegalBases: []string{purpose.BasisConsent}, ExpiresAt: &past}, purpose.ErrCaseClosed},
+        {"reference taken", purpose.NewCase{Reference: "CASE-EXISTING", LegalBases: []string{purpose.BasisConsent}}, purpose.ErrCaseExists},
+    }
+
+    for _, tt := range tests {
+        suite.Run(tt.name, func() {
+            _, err := suite.service.Open(suite.ctx, tt.req)
+            suite.True(errors.Is(err, tt.reason), err)
+            suite.NotEmpty(refusal.Code(err))
+        })
+    }
+
+    err = suite.service.Close(suite.ctx, "CASE-MISSING")
+    suite.True(errors.Is(err, purpose.ErrUnknownCase))
+}
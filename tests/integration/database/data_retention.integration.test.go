@@ -0,0 +1,182 @@
+// tests/integration/database/data_retention.integration.test.go
+package integration
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+    "os"
+    "path/filepath"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/suite"
+    "gorm.io/gorm"
+
+    "secure-iran-intel/database"
+    "secure-iran-intel/pkg/audit"
+    "secure-iran-intel/pkg/purpose"
+    "secure-iran-intel/pkg/rbac"
+    "secure-iran-intel/pkg/retention"
+    "secure-iran-intel/pkg/tenancy"
+)
+
+type DataRetentionTestSuite struct {
+    suite.Suite
+    db        *gorm.DB
+    sqlDB     *sql.DB
+    ctx       context.Context
+    exportDir string
+    service   *retention.Service
+    tenantID  string
+    userID    string
+}
+
+func TestDataRetentionSuite(t *testing.T) {
+    suite.Run(t, new(DataRetentionTestSuite))
+}
+
+func (suite *DataRetentionTestSuite) SetupSuite() {
+    var err error
+    suite.db, suite.sqlDB, err = database.ConnectTest()
+    if err != nil {
+        suite.T().Fatalf("Failed to connect to test database: %v", err)
+    }
+    if err := database.MigrateTest(suite.db); err != nil {
+        suite.T().Fatalf("Failed to run test migrations: %v", err)
+    }
+
+    suite.Require().NoError(audit.SetTargetKey(testTargetKey))
+    suite.exportDir = suite.T().TempDir()
+    suite.service = retention.NewService(
+        retention.NewGormStore(suite.db),
+        purpose.NewGormCaseStore(suite.db),
+        audit.NewLogger(audit.NewFileStore(filepath.Join(suite.exportDir, "audit.jsonl"))),
+        rbac.NewEngine(rbac.NewGormStore(suite.db)),
+        retention.NewPostgresSweeper(suite.db),
+        retention.NewFileSweeper(suite.exportDir),
+    )
+}
+
+func (suite *DataRetentionTestSuite) TearDownSuite() {
+    if suite.sqlDB != nil {
+        suite.sqlDB.Close()
+    }
+}
+
+func (suite *DataRetentionTestSuite) SetupTest() {
+    slug := fmt.Sprintf("retention-%d", time.Now().UnixNano())
+    err := suite.db.Raw(`INSERT INTO tenants (name, slug, settings) VALUES (?, ?, '{"data_retention_days": 30}') RETURNING id`, slug, slug).
+        Scan(&suite.tenantID).Error
+    suite.Require().NoError(err)
+
+    // The system actor passes the permission check; holds go to its tenant
+    suite.ctx = audit.WithActor(context.Background(), audit.Actor{TenantID: suite.tenantID, Type: audit.ActorSystem, ID: "retention-test"})
+    err = tenancy.Transaction(suite.ctx, suite.db, suite.tenantID, func(tx *gorm.DB) error {
+        if err := tx.Raw(`INSERT INTO users (tenant_id, email, password_hash) VALUES (?, ?, 'x') RETURNING id`,
+            suite.tenantID, slug+"@example.test").Scan(&suite.userID).Error; err != nil {
+            return err
+        }
+        return tx.Exec(`INSERT INTO cases (tenant_id, reference, legal_bases) VALUES (?, 'CASE-HELD', '["court_order"]')`, suite.tenantID).Error
+    })
+    suite.Require().NoError(err)
+}
+
+func (suite *DataRetentionTestSuite) TearDownTest() {
+    suite.db.Exec("DELETE FROM tenants WHERE id = ?", suite.tenantID)
+}
+
+// seedJob inserts a job created age ago under caseReference
+func (suite *DataRetentionTestSuite) seedJob(name, caseReference string, age time.Duration) {
+    err := tenancy.Transaction(suite.ctx, suite.db, suite.tenantID, func(tx *gorm.DB) error {
+        return tx.Exec(`INSERT INTO bulk_jobs (tenant_id, name, user_id, total_numbers, platforms, case_reference, created_at) VALUES (?, ?, ?, 1, '[]', NULLIF(?, ''), ?)`,
+            suite.tenantID, name, suite.userID, caseReference, time.Now().Add(-age)).Error
+    })
+    suite.Require().NoError(err)
+}
+
+func (suite *DataRetentionTestSuite) jobNames() []string {
+    var names []string
+    err := tenancy.Transaction(suite.ctx, suite.db, suite.tenantID, func(tx *gorm.DB) error {
+        return tx.Raw("SELECT name FROM bulk_jobs WHERE tenant_id = ? ORDER BY name", suite.tenantID).Scan(&names).Error
+    })
+    suite.Require().NoError(err)
+    return names
+}
+
+func (suite *DataRetentionTestSuite) policy(mode string) retention.Policy {
+    return retention.Policy{TenantID: suite.tenantID, Days: 30, Mode: mode}
+}
+
+func (suite *DataRetentionTestSuite) TestExpiredDataIsDeleted() {
+    suite.seedJob("expired", "", 45*24*time.Hour)
+    suite.seedJob("fresh", "", 24*time.Hour)
+
+    export := filepath.Join(suite.exportDir, suite.tenantID, "CASE-OTHER", "report.pdf")
+    suite.Require().NoError(os.MkdirAll(filepath.Dir(export), 0700))
+    suite.Require().NoError(os.WriteFile(export, []byte("%PDF"), 0600))
+    old := time.Now().Add(-45 * 24 * time.Hour)
+    suite.Require().NoError(os.Chtimes(export, old, old))
+
+    removed, err := suite.service.Sweep(suite.ctx, suite.policy(retention.ModeDelete), time.Now())
+    suite.Require().NoError(err)
+
+    counts := map[string]int64{}
+    for _, r := range removed {
+        counts[r.Store+"/"+r.Kind] = r.Count
+    }
+    suite.EqualValues(1, counts["postgres/bulk_jobs"])
+    suite.EqualValues(1, counts["files/exports"])
+    suite.Equal([]string{"fresh"}, suite.jobNames())
+    _, err = os.Stat(export)
+    suite.True(os.IsNotExist(err))
+}
+
+func (suite *DataRetentionTestSuite) TestLegalHoldPausesDeletion() {
+    suite.seedJob("held", "CASE-HELD", 45*24*time.Hour)
+    suite.seedJob("unheld", "", 45*24*time.Hour)
+
+    hold, err := suite.service.PlaceHold(suite.ctx, "CASE-HELD", "preservation order 2026/114")
+    suite.Require().NoError(err)
+
+    _, err = suite.service.PlaceHold(suite.ctx, "CASE-HELD", "again")
+    suite.True(errors.Is(err, retention.ErrHoldExists))
+
+    _, err = suite.service.Sweep(suite.ctx, suite.policy(retention.ModeDelete), time.Now())
+    suite.Require().NoError(err)
+    suite.Equal([]string{"held"}, suite.jobNames())
+
+    // Once released, the next sweep takes it
+    _, err = suite.service.ReleaseHold(suite.ctx, hold.ID)
+    suite.Require().NoError(err)
+    _, err = suite.service.Sweep(suite.ctx, suite.policy(retention.ModeDelete), time.Now())
+    suite.Require().NoError(err)
+    suite.Empty(suite.jobNames())
+}
+
+func (suite *DataRetentionTestSuite) TestHoldNeedsKnownCase() {
+    _, err := suite.service.PlaceHold(suite.ctx, "CASE-MISSING", "preservation order")
+    suite.True(errors.Is(err, purpose.ErrUnknownCase))
+}
+
+func (suite *DataRetentionTestSuite) TestRedactModeKeepsRows() {
+    suite.seedJob("Operation Sparrow", "", 45*24*time.Hour)
+
+    removed, err := suite.service.Sweep(suite.ctx, suite.policy(retention.ModeRedact), time.Now())
+    suite.Require().NoError(err)
+    suite.Equal([]string{"[redacted]"}, suite.jobNames())
+    for _, r := range removed {
+        if r.Kind == "bulk_jobs" {
+            suite.Equal("redacted", r.Action)
+            suite.EqualValues(1, r.Count)
+        }
+    }
+
+    // Redacted rows are not redacted again
+    removed, err = suite.service.Sweep(suite.ctx, suite.policy(retention.ModeRedact), time.Now())
+    suite.Require().NoError(err)
+    for _, r := range removed {
+        suite.Zero(r.Count, r.Kind)
+    }
+}
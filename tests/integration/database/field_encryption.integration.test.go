@@ -0,0 +1,307 @@
+// tests/integration/database/field_encryption.integration.test.go
+package integration
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+    "path/filepath"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/suite"
+    "gorm.io/gorm"
+
+    "secure-iran-intel/database"
+    "secure-iran-intel/pkg/envelope"
+    "secure-iran-intel/pkg/results"
+    "secure-iran-intel/pkg/tenancy"
+)
+
+// sealedReport maps the encrypted columns of intelligence_reports
+type sealedReport struct {
+    ID               string `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+    TenantID         string `gorm:"type:uuid"`
+    ReportID         string
+    PhoneNumber      string `gorm:"serializer:encrypted"`
+    PhoneNumberIndex string
+    ReportType       string
+    ReportData       map[string]interface{} `gorm:"serializer:encrypted"`
+}
+
+func (sealedReport) TableName() string {
+    return "intelligence_reports"
+}
+
+type FieldEncryptionTestSuite struct {
+    suite.Suite
+    db       *gorm.DB
+    sqlDB    *sql.DB
+    ctx      context.Context
+    kms      *envelope.FileKMS
+    keyring  *envelope.Keyring
+    tenantID string
+}
+
+func TestFieldEncryptionSuite(t *testing.T) {
+    suite.Run(t, new(FieldEncryptionTestSuite))
+}
+
+func (suite *FieldEncryptionTestSuite) SetupSuite() {
+    suite.ctx = context.Background()
+
+    var err error
+    suite.db, suite.sqlDB, err = database.ConnectTest()
+    if err != nil {
+        suite.T().Fatalf("Failed to connect to test database: %v", err)
+    }
+    if err := database.MigrateTest(suite.db); err != nil {
+        suite.T().Fatalf("Failed to run test migrations: %v", err)
+    }
+
+    suite.kms, err = envelope.NewFileKMS(filepath.Join(suite.T().TempDir(), "master-keys.json"))
+    if err != nil {
+        suite.T().Fatalf("Failed to create master keys: %v", err)
+    }
+    suite.keyring = envelope.NewKeyring(envelope.NewGormKeyStore(suite.db), suite.kms)
+    envelope.Register(suite.keyring)
+}
+
+func (suite *FieldEncryptionTestSuite) TearDownSuite() {
+    if suite.sqlDB != nil {
+        suite.sqlDB.Close()
+    }
+}
+
+func (suite *FieldEncryptionTestSuite) SetupTest() {
+    slug := fmt.Sprintf("sealed-%d", time.Now().UnixNano())
+    err := suite.db.Raw(`INSERT INTO tenants (name, slug) VALUES (?, ?) RETURNING id`, slug, slug).Scan(&suite.tenantID).Error
+    suite.Require().NoError(err)
+}
+
+func (suite *FieldEncryptionTestSuite) TearDownTest() {
+    suite.db.Exec("DELETE FROM tenants WHERE id = ?", suite.tenantID)
+}
+
+func (suite *FieldEncryptionTestSuite) store(phone string) {
+    index, err := suite.keyring.BlindIndex(suite.ctx, suite.tenantID, phone)
+    suite.Require().NoError(err)
+    err = tenancy.Transaction(suite.ctx, suite.db, suite.tenantID, func(tx *gorm.DB) error {
+        return tx.Create(&sealedReport{
+            ReportID:         fmt.Sprintf("r-%d", time.Now().UnixNano()),
+            PhoneNumber:      phone,
+            PhoneNumberIndex: index,
+            ReportType:       "comprehensive",
+            ReportData:       map[string]interface{}{"carrier": "MCI"},
+        }).Error
+    })
+    suite.Require().NoError(err)
+}
+
+func (suite *FieldEncryptionTestSuite) find(phone string) ([]sealedReport, error) {
+    index, err := suite.keyring.BlindIndex(suite.ctx, suite.tenantID, phone)
+    if err != nil {
+        return nil, err
+    }
+    var rows []sealedReport
+    err = tenancy.Transaction(suite.ctx, suite.db, suite.tenantID, func(tx *gorm.DB) error {
+        return tx.Where("phone_number_index = ?", index).Find(&rows).Error
+    })
+    return rows, err
+}
+
+func (suite *FieldEncryptionTestSuite) TestColumnsAreSealedAndSearchable() {
+    suite.store("+989121234567")
+    suite.store("+989351234567")
+
+    var raw []string
+    err := tenancy.Transaction(suite.ctx, suite.db, suite.tenantID, func(tx *gorm.DB) error {
+        return tx.Raw("SELECT phone_number || report_data FROM intelligence_reports WHERE tenant_id = ?", suite.tenantID).Scan(&raw).Error
+    })
+    suite.Require().NoError(err)
+    suite.Len(raw, 2)
+    for _, value := range raw {
+        suite.True(envelope.IsSealed(value))
+        suite.NotContains(value, "989")
+        suite.NotContains(value, "MCI")
+    }
+
+    rows, err := suite.find("+989121234567")
+    suite.Require().NoError(err)
+    suite.Require().Len(rows, 1)
+    suite.Equal("+989121234567", rows[0].PhoneNumber)
+    suite.Equal("MCI", rows[0].ReportData["carrier"])
+}
+
+func (suite *FieldEncryptionTestSuite) TestRotationKeepsRowsReadable() {
+    suite.store("+989121234567")
+
+    _, err := suite.kms.Rotate()
+    suite.Require().NoError(err)
+    moved, err := suite.keyring.Rewrap(suite.ctx)
+    suite.Require().NoError(err)
+    suite.GreaterOrEqual(moved, 1)
+
+    var masterKeyID string
+    err = tenancy.Transaction(suite.ctx, suite.db, suite.tenantID, func(tx *gorm.DB) error {
+        return tx.Raw("SELECT master_key_id FROM tenant_data_keys WHERE tenant_id = ?", suite.tenantID).Scan(&masterKeyID).Error
+    })
+    suite.Require().NoError(err)
+    suite.Equal(suite.kms.CurrentKeyID(), masterKeyID)
+
+    // A fresh keyring unwraps under the new master key
+    fresh := envelope.NewKeyring(envelope.NewGormKeyStore(suite.db), suite.kms)
+    envelope.Register(fresh)
+    defer envelope.Register(suite.keyring)
+
+    rows, err := suite.find("+989121234567")
+    suite.Require().NoError(err)
+    suite.Require().Len(rows, 1)
+    suite.Equal("+989121234567", rows[0].PhoneNumber)
+}
+
+func (suite *FieldEncryptionTestSuite) TestShreddingMakesRowsUnreadable() {
+    suite.store("+989121234567")
+    suite.Require().NoError(suite.keyring.Shred(suite.ctx, suite.tenantID))
+
+    var sealed string
+    err := tenancy.Transaction(suite.ctx, suite.db, suite.tenantID, func(tx *gorm.DB) error {
+        return tx.Raw("SELECT phone_number FROM intelligence_reports WHERE tenant_id = ?", suite.tenantID).Scan(&sealed).Error
+    })
+    suite.Require().NoError(err)
+    suite.True(strings.HasPrefix(sealed, "enc:v1:"+suite.tenantID+":"))
+
+    _, err = suite.keyring.Open(suite.ctx, sealed)
+    suite.True(errors.Is(err, envelope.ErrKeyDestroyed))
+
+    // Nothing new can be sealed for a shredded tenant
+    _, err = suite.keyring.Seal(suite.ctx, suite.tenantID, []byte("+989121234567"))
+    suite.True(errors.Is(err, envelope.ErrKeyDestroyed))
+}
+
+func (suite *FieldEncryptionTestSuite) TestBackfillSealsExistingRows() {
+    // Written before encryption: plaintext and no blind index
+    err := tenancy.Transaction(suite.ctx, suite.db, suite.tenantID, func(tx *gorm.DB) error {
+        return tx.Exec(`INSERT INTO intelligence_reports (tenant_id, report_id, phone_number, report_type, report_data)
+            VALUES (?, ?, ?, 'comprehensive', ?)`,
+            suite.tenantID, fmt.Sprintf("r-%d", time.Now().UnixNano()), "+989121234567", `{"carrier": "MCI"}`).Error
+    })
+    suite.Require().NoError(err)
+
+    reports := envelope.Table{
+        Name: "intelligence_reports",
+        Columns: []envelope.Column{
+            {Name: "phone_number", Index: "phone_number_index"},
+            {Name: "report_data"},
+            {Name: "executive_summary"},
+        },
+        Where: fmt.Sprintf("tenant_id = '%s'", suite.tenantID),
+    }
+    result, err := suite.keyring.Backfill(suite.ctx, suite.db, reports, 10)
+    suite.Require().NoError(err)
+    suite.Equal(int64(1), result.Sealed)
+
+    var raw string
+    err = tenancy.Transaction(suite.ctx, suite.db, suite.tenantID, func(tx *gorm.DB) error {
+        return tx.Raw("SELECT phone_number || report_data FROM intelligence_reports WHERE tenant_id = ?", suite.tenantID).Scan(&raw).Error
+    })
+    suite.Require().NoError(err)
+    suite.NotContains(raw, "989")
+    suite.NotContains(raw, "MCI")
+
+    rows, err := suite.find("+989121234567")
+    suite.Require().NoError(err)
+    suite.Require().Len(rows, 1)
+    suite.Equal("+989121234567", rows[0].PhoneNumber)
+    suite.Equal("MCI", rows[0].ReportData["carrier"])
+
+    // Nothing is left to do the second time
+    result, err = suite.keyring.Backfill(suite.ctx, suite.db, reports, 10)
+    suite.Require().NoError(err)
+    suite.Equal(envelope.BackfillResult{}, result)
+}
+
+func (suite *FieldEncryptionTestSuite) TestOpenRefusesOtherTenantsAndPlaintext() {
+    sealed, err := suite.keyring.SealString(suite.ctx, suite.tenantID, "+989121234567")
+    suite.Require().NoError(err)
+
+    opened, err := suite.keyring.OpenString(tenancy.WithTenant(suite.ctx, suite.tenantID), sealed)
+    suite.Require().NoError(err)
+    suite.Equal("+989121234567", opened)
+
+    // Copied into another tenant's row, and read in their transaction
+    _, err = suite.keyring.OpenString(tenancy.WithTenant(suite.ctx, tenancy.PlatformTenantID), sealed)
+    suite.True(errors.Is(err, envelope.ErrTenantMismatch), err)
+
+    // Plaintext is read as it is until the backfill has run
+    fresh := envelope.NewKeyring(envelope.NewGormKeyStore(suite.db), suite.kms)
+    opened, err = fresh.OpenString(suite.ctx, "+989121234567")
+    suite.Require().NoError(err)
+    suite.Equal("+989121234567", opened)
+
+    suite.Require().NoError(envelope.MarkBackfilled(suite.ctx, suite.db))
+    backfilled, err := envelope.Backfilled(suite.ctx, suite.db)
+    suite.Require().NoError(err)
+    suite.True(backfilled)
+    fresh.RejectPlaintext()
+
+    _, err = fresh.OpenString(suite.ctx, "+989121234567")
+    suite.True(errors.Is(err, envelope.ErrNotSealed), err)
+    // Empty values and redaction placeholders are never sealed
+    for _, value := range []string{"", "{}", "redacted:" + suite.tenantID} {
+        opened, err = fresh.OpenString(suite.ctx, value)
+        suite.NoError(err)
+        suite.Equal(value, opened)
+    }
+    opened, err = fresh.OpenString(suite.ctx, sealed)
+    suite.Require().NoError(err)
+    suite.Equal("+989121234567", opened)
+}
+
+func (suite *FieldEncryptionTestSuite) TestResultsAreSealedAndIndexed() {
+    store := results.NewGormStore(suite.db, suite.keyring)
+    for _, email := range []string{"ali@example.test", "Ali@Example.test"} {
+        err := store.Save(suite.ctx, suite.tenantID, &results.EmailDiscovery{
+            PhoneNumber:    "+989121234567",
+            Email:          email,
+            Confidence:     0.9,
+            Source:         "pattern",
+            SocialProfiles: []byte(`[{"platform": "telegram"}]`),
+            DiscoveredAt:   time.Now(),
+        })
+        suite.Require().NoError(err)
+    }
+
+    var rows []struct {
+        Email            string
+        SocialProfiles   string
+        PhoneNumberIndex string
+        EmailIndex       string
+    }
+    err := tenancy.Transaction(suite.ctx, suite.db, suite.tenantID, func(tx *gorm.DB) error {
+        return tx.Raw("SELECT email, social_profiles, phone_number_index, email_index FROM email_discovery_results WHERE tenant_id = ?",
+            suite.tenantID).Scan(&rows).Error
+    })
+    suite.Require().NoError(err)
+    // Finding the address again refreshed the row
+    suite.Require().Len(rows, 1)
+    suite.True(envelope.IsSealed(rows[0].Email))
+    suite.NotContains(rows[0].SocialProfiles, "telegram")
+
+    phoneIndex, err := suite.keyring.BlindIndex(suite.ctx, suite.tenantID, "+989121234567")
+    suite.Require().NoError(err)
+    emailIndex, err := suite.keyring.BlindIndex(suite.ctx, suite.tenantID, "ali@example.test")
+    suite.Require().NoError(err)
+    suite.Equal(phoneIndex, rows[0].PhoneNumberIndex)
+    suite.Equal(emailIndex, rows[0].EmailIndex)
+
+    var found results.EmailDiscovery
+    err = tenancy.Transaction(suite.ctx, suite.db, suite.tenantID, func(tx *gorm.DB) error {
+        return tx.Where("phone_number_index = ? AND email_index = ?", phoneIndex, emailIndex).First(&found).Error
+    })
+    suite.Require().NoError(err)
+    suite.Equal("ali@example.test", found.Email)
+    suite.JSONEq(`[{"platform": "telegram"}]`, string(found.SocialProfiles))
+}
@@ -0,0 +1,184 @@
+// tests/integration/database/rbac.integration.test.go
+package integration
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/suite"
+    "gorm.io/gorm"
+
+    "secure-iran-intel/database"
+    "secure-iran-intel/pkg/audit"
+    "secure-iran-intel/pkg/rbac"
+    "secure-iran-intel/pkg/tenancy"
+)
+
+type RBACTestSuite struct {
+    suite.Suite
+    db       *gorm.DB
+    sqlDB    *sql.DB
+    ctx      context.Context
+    roles    *rbac.GormStore
+    engine   *rbac.Engine
+    tenantID string
+    teamID   string
+}
+
+func TestRBACSuite(t *testing.T) {
+    suite.Run(t, new(RBACTestSuite))
+}
+
+func (suite *RBACTestSuite) SetupSuite() {
+    var err error
+    suite.db, suite.sqlDB, err = database.ConnectTest()
+    if err != nil {
+        suite.T().Fatalf("Failed to connect to test database: %v", err)
+    }
+    if err := database.MigrateTest(suite.db); err != nil {
+        suite.T().Fatalf("Failed to run test migrations: %v", err)
+    }
+
+    suite.roles = rbac.NewGormStore(suite.db)
+    suite.engine = rbac.NewEngine(suite.roles)
+}
+
+func (suite *RBACTestSuite) TearDownSuite() {
+    if suite.sqlDB != nil {
+        suite.sqlDB.Close()
+    }
+}
+
+func (suite *RBACTestSuite) SetupTest() {
+    slug := fmt.Sprintf("rbac-%d", time.Now().UnixNano())
+    err := suite.db.Raw(`INSERT INTO tenants (name, slug) VALUES (?, ?) RETURNING id`, slug, slug).Scan(&suite.tenantID).Error
+    suite.Require().NoError(err)
+
+    suite.ctx = audit.WithActor(context.Background(), audit.Actor{TenantID: suite.tenantID, Type: audit.ActorSystem, ID: "rbac-test"})
+    suite.Require().NoError(suite.roles.SaveRoles(suite.ctx, rbac.DefaultRoles(suite.tenantID)))
+    err = tenancy.Transaction(suite.ctx, suite.db, suite.tenantID, func(tx *gorm.DB) error {
+        return tx.Raw(`INSERT INTO teams (tenant_id, name) VALUES (?, 'north') RETURNING id`, suite.tenantID).Scan(&suite.teamID).Error
+    })
+    suite.Require().NoError(err)
+}
+
+func (suite *RBACTestSuite) TearDownTest() {
+    suite.db.Exec("DELETE FROM tenants WHERE id = ?", suite.tenantID)
+}
+
+// role saves a custom role for the tenant
+func (suite *RBACTestSuite) role(name string, inherits []string, rules ...rbac.Rule) {
+    err := suite.roles.SaveRoles(suite.ctx, []rbac.Role{{TenantID: suite.tenantID, Name: name, Inherits: inherits, Rules: rules}})
+    suite.Require().NoError(err)
+}
+
+// user inserts an active member of the tenant with role, in the team when
+// inTeam is set, and returns the subject it acts as
+func (suite *RBACTestSuite) user(role string, inTeam bool) rbac.Subject {
+    var teamID interface{}
+    if inTeam {
+        teamID = suite.teamID
+    }
+    var userID string
+    err := tenancy.Transaction(suite.ctx, suite.db, suite.tenantID, func(tx *gorm.DB) error {
+        return tx.Raw(`INSERT INTO users (tenant_id, email, password_hash, role, team_id) VALUES (?, ?, 'x', ?, ?) RETURNING id`,
+            suite.tenantID, fmt.Sprintf("%s-%d@example.test", role, time.Now().UnixNano()), role, teamID).Scan(&userID).Error
+    })
+    suite.Require().NoError(err)
+
+    subject, err := suite.engine.SubjectFor(suite.ctx, audit.Actor{TenantID: suite.tenantID, Type: audit.ActorUser, ID: userID})
+    suite.Require().NoError(err)
+    return *subject
+}
+
+func (suite *RBACTestSuite) job(ownerID, caseReference string) *rbac.Resource {
+    return &rbac.Resource{Type: "job", ID: "job-1", TenantID: suite.tenantID, OwnerID: ownerID, CaseReference: caseReference}
+}
+
+func (suite *RBACTestSuite) TestDenyOverridesAllow() {
+    // An admin allowed everything, with exports denied on top
+    suite.role("restricted-admin", []string{"admin"}, rbac.Rule{Effect: rbac.EffectDeny, Permission: "exports:*"})
+    subject := suite.user("restricted-admin", false)
+
+    d, err := suite.engine.Authorize(suite.ctx, subject, rbac.PermExportsRead, suite.job(subject.UserID, ""))
+    suite.Require().NoError(err)
+    suite.False(d.Allowed, d.Reason)
+    suite.Equal("restricted-admin", d.RuleRole)
+    suite.Equal(rbac.EffectDeny, d.Rule.Effect)
+
+    // Route-level checks honour tenant-wide denies too
+    d, err = suite.engine.Authorize(suite.ctx, subject, rbac.PermExportsRead, nil)
+    suite.Require().NoError(err)
+    suite.False(d.Allowed)
+
+    d, err = suite.engine.Authorize(suite.ctx, subject, rbac.PermReportsRead, suite.job(subject.UserID, ""))
+    suite.Require().NoError(err)
+    suite.True(d.Allowed, d.Reason)
+}
+
+func (suite *RBACTestSuite) TestRolesInheritRules() {
+    suite.role("analyst", []string{"user"})
+    subject := suite.user("analyst", false)
+
+    // jobs:create from user, reports:read from the viewer user inherits
+    for _, permission := range []string{rbac.PermJobsCreate, rbac.PermReportsRead} {
+        d, err := suite.engine.Authorize(suite.ctx, subject, permission, nil)
+        suite.Require().NoError(err)
+        suite.True(d.Allowed, permission)
+    }
+    d, err := suite.engine.Authorize(suite.ctx, subject, rbac.PermReportsRead, nil)
+    suite.Require().NoError(err)
+    suite.Equal([]string{"analyst", "user", "viewer"}, d.Roles)
+    suite.Equal("viewer", d.RuleRole)
+
+    // Nothing in the chain grants admin permissions
+    d, err = suite.engine.Authorize(suite.ctx, subject, rbac.PermRolesWrite, nil)
+    suite.Require().NoError(err)
+    suite.False(d.Allowed)
+}
+
+func (suite *RBACTestSuite) TestScopesLimitResources() {
+    suite.role("team-reader", nil, rbac.Rule{Effect: rbac.EffectAllow, Permission: rbac.PermJobsRead, Scope: rbac.ScopeTeam})
+    suite.role("case-reader", nil, rbac.Rule{Effect: rbac.EffectAllow, Permission: rbac.PermJobsRead, Scope: rbac.ScopeCase("CASE-7")})
+    owner := suite.user("user", true)
+    teammate := suite.user("team-reader", true)
+    outsider := suite.user("team-reader", false)
+    caseReader := suite.user("case-reader", false)
+
+    tests := []struct {
+        name     string
+        subject  rbac.Subject
+        resource *rbac.Resource
+        allowed  bool
+    }{
+        {"own job", owner, suite.job(owner.UserID, ""), true},
+        {"another user's job", owner, suite.job(teammate.UserID, ""), false},
+        {"teammate's job", teammate, suite.job(owner.UserID, ""), true},
+        {"other team's job", outsider, suite.job(owner.UserID, ""), false},
+        {"job under the case", caseReader, suite.job(owner.UserID, "CASE-7"), true},
+        {"job under another case", caseReader, suite.job(owner.UserID, "CASE-8"), false},
+        {"job of another tenant", owner, &rbac.Resource{Type: "job", TenantID: "00000000-0000-0000-0000-000000000001", OwnerID: owner.UserID}, false},
+    }
+    for _, tt := range tests {
+        suite.Run(tt.name, func() {
+            d, err := suite.engine.Authorize(suite.ctx, tt.subject, rbac.PermJobsRead, tt.resource)
+            suite.Require().NoError(err)
+            suite.Equal(tt.allowed, d.Allowed, d.Reason)
+        })
+    }
+}
+
+func (suite *RBACTestSuite) TestContextsWithoutActorAreDenied() {
+    actor := audit.ActorFromContext(context.Background())
+    suite.Equal(audit.ActorNone, actor.Type)
+
+    err := suite.engine.Require(context.Background(), actor, rbac.PermJobsCreate, nil)
+    suite.True(errors.Is(err, rbac.ErrDenied), err)
+
+    // The system actor is only ever set explicitly
+    suite.NoError(suite.engine.Require(suite.ctx, audit.ActorFromContext(suite.ctx), rbac.PermJobsCreate, nil))
+}
@@ -0,0 +1,246 @@
+// tests/integration/database/subject_requests.integration.test.go
+package integration
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+    "os"
+    "path/filepath"
+    "testing"
+    "time"
+
+    "github.com/alicebob/miniredis/v2"
+    "github.com/go-redis/redis/v8"
+    "github.com/stretchr/testify/suite"
+    "gorm.io/gorm"
+
+    "secure-iran-intel/database"
+    "secure-iran-intel/pkg/audit"
+    "secure-iran-intel/pkg/envelope"
+    "secure-iran-intel/pkg/rbac"
+    "secure-iran-intel/pkg/subject"
+    "secure-iran-intel/pkg/tenancy"
+)
+
+type SubjectRequestTestSuite struct {
+    suite.Suite
+    db        *gorm.DB
+    sqlDB     *sql.DB
+    ctx       context.Context
+    redis     *miniredis.Miniredis
+    exportDir string
+    auditLog  *audit.Logger
+    keyring   *envelope.Keyring
+    store     *subject.GormStore
+    service   *subject.Service
+    tenantIDs []string
+    phone     string
+}
+
+func TestSubjectRequestSuite(t *testing.T) {
+    suite.Run(t, new(SubjectRequestTestSuite))
+}
+
+func (suite *SubjectRequestTestSuite) SetupSuite() {
+    // Internal services run as the system actor, which may handle requests
+    suite.ctx = audit.WithActor(context.Background(), audit.Actor{Type: audit.ActorSystem, ID: "subject-test"})
+
+    var err error
+    suite.db, suite.sqlDB, err = database.ConnectTest()
+    if err != nil {
+        suite.T().Fatalf("Failed to connect to test database: %v", err)
+    }
+    if err := database.MigrateTest(suite.db); err != nil {
+        suite.T().Fatalf("Failed to run test migrations: %v", err)
+    }
+
+    suite.redis, err = miniredis.Run()
+    if err != nil {
+        suite.T().Fatalf("Failed to start Redis: %v", err)
+    }
+
+    suite.exportDir = suite.T().TempDir()
+    kms, err := envelope.NewFileKMS(filepath.Join(suite.exportDir, "master-keys.json"))
+    if err != nil {
+        suite.T().Fatalf("Failed to create master keys: %v", err)
+    }
+    suite.keyring = envelope.NewKeyring(envelope.NewGormKeyStore(suite.db), kms)
+    envelope.Register(suite.keyring)
+
+    auditStore := audit.NewFileStore(filepath.Join(suite.exportDir, "audit.jsonl"))
+    suite.Require().NoError(audit.SetTargetKey(testTargetKey))
+    suite.auditLog = audit.NewLogger(auditStore)
+    suite.store = subject.NewGormStore(suite.db)
+    suite.service = subject.NewService(
+        suite.store,
+        suite.auditLog,
+        rbac.NewEngine(rbac.NewGormStore(suite.db)),
+        suite.exportDir,
+        subject.NewPostgresSource(suite.db, suite.keyring),
+        subject.NewRedisSource(redis.NewClient(&redis.Options{Addr: suite.redis.Addr()})),
+        subject.NewAuditSource(auditStore),
+    )
+}
+
+func (suite *SubjectRequestTestSuite) TearDownSuite() {
+    if suite.redis != nil {
+        suite.redis.Close()
+    }
+    if suite.sqlDB != nil {
+        suite.sqlDB.Close()
+    }
+}
+
+// SetupTest gives the subject a report in two tenants, one of them under
+// a case on legal hold, and a cached lookup
+func (suite *SubjectRequestTestSuite) SetupTest() {
+    suite.phone = fmt.Sprintf("+98912%07d", time.Now().UnixNano()%10000000)
+    suite.tenantIDs = nil
+    for i := 0; i < 2; i++ {
+        var tenantID string
+        slug := fmt.Sprintf("subject-%d-%d", i, time.Now().UnixNano())
+        suite.Require().NoError(suite.db.Raw(`INSERT INTO tenants (name, slug) VALUES (?, ?) RETURNING id`, slug, slug).Scan(&tenantID).Error)
+        suite.tenantIDs = append(suite.tenantIDs, tenantID)
+
+        index, err := suite.keyring.BlindIndex(suite.ctx, tenantID, suite.phone)
+        suite.Require().NoError(err)
+        err = tenancy.Transaction(suite.ctx, suite.db, tenantID, func(tx *gorm.DB) error {
+            return tx.Create(&sealedReport{
+                ReportID:         fmt.Sprintf("r-%d", time.Now().UnixNano()),
+                PhoneNumber:      suite.phone,
+                PhoneNumberIndex: index,
+                ReportType:       "comprehensive",
+                ReportData:       map[string]interface{}{"carrier": "MCI"},
+            }).Error
+        })
+        suite.Require().NoError(err)
+    }
+
+    // A report written before encryption, under a held case
+    held := suite.tenantIDs[1]
+    err := tenancy.Transaction(suite.ctx, suite.db, held, func(tx *gorm.DB) error {
+        if err := tx.Exec(`INSERT INTO cases (tenant_id, reference, legal_bases) VALUES (?, 'CASE-HELD', '["court_order"]')`, held).Error; err != nil {
+            return err
+        }
+        if err := tx.Exec(`INSERT INTO legal_holds (tenant_id, case_reference, reason, placed_by) VALUES (?, 'CASE-HELD', 'preservation order', 'test')`, held).Error; err != nil {
+            return err
+        }
+        return tx.Exec(`INSERT INTO intelligence_reports (tenant_id, report_id, phone_number, report_type, report_data, case_reference) VALUES (?, ?, ?, 'basic', '{}', 'CASE-HELD')`,
+            held, fmt.Sprintf("held-%d", time.Now().UnixNano()), suite.phone).Error
+    })
+    suite.Require().NoError(err)
+
+    suite.Require().NoError(suite.redis.Set("lookup:"+suite.phone[1:], `{"carrier":"MCI"}`))
+    suite.Require().NoError(suite.auditLog.Log(suite.ctx, audit.Entry{
+        Action:     audit.ActionJobCreate,
+        TargetType: "phone",
+        Target:     suite.phone,
+        Metadata:   map[string]string{"job_id": "job-1", "case_reference": "CASE-1"},
+    }))
+}
+
+func (suite *SubjectRequestTestSuite) TearDownTest() {
+    for _, tenantID := range suite.tenantIDs {
+        suite.db.Exec("DELETE FROM tenants WHERE id = ?", tenantID)
+    }
+    suite.redis.FlushAll()
+}
+
+func (suite *SubjectRequestTestSuite) open(kind string) *subject.Request {
+    r, err := suite.service.Open(suite.ctx, subject.NewRequest{
+        Kind:                  kind,
+        PhoneNumber:           "0" + suite.phone[3:],
+        VerificationMethod:    "sms_otp",
+        VerificationReference: "otp-4411",
+    })
+    suite.Require().NoError(err)
+    suite.Equal(suite.phone, r.PhoneNumber)
+    return r
+}
+
+func (suite *SubjectRequestTestSuite) reportCount() int64 {
+    var n int64
+    err := tenancy.SystemTransaction(suite.ctx, suite.db, func(tx *gorm.DB) error {
+        return tx.Table("intelligence_reports").Where("tenant_id IN ?", suite.tenantIDs).Count(&n).Error
+    })
+    suite.Require().NoError(err)
+    return n
+}
+
+func (suite *SubjectRequestTestSuite) TestAccessExportsEveryTenant() {
+    r := suite.open(subject.KindAccess)
+
+    closed, export, err := suite.service.Fulfil(suite.ctx, r.ID)
+    suite.Require().NoError(err)
+    suite.Equal(subject.StatusCompleted, closed.Status)
+
+    counts := map[string]int{}
+    tenants := map[string]bool{}
+    for _, record := range export.Records {
+        counts[record.Store]++
+        if record.Store == "postgres" {
+            tenants[record.TenantID] = true
+            // Sealed columns are exported opened, blind indexes not at all
+            suite.Contains(string(record.Data), suite.phone)
+            suite.NotContains(string(record.Data), "enc:v1:")
+            suite.NotContains(string(record.Data), "phone_number_index")
+        }
+    }
+    suite.Equal(3, counts["postgres"])
+    suite.Equal(1, counts["redis"])
+    suite.GreaterOrEqual(counts["audit_log"], 2) // The job and this request
+    suite.Len(tenants, 2)
+
+    suite.Require().NotNil(closed.Outcome.Export)
+    _, err = os.Stat(filepath.Join(suite.exportDir, closed.Outcome.Export.File))
+    suite.NoError(err)
+    suite.Equal(len(export.Records), closed.Outcome.Export.Records)
+    suite.EqualValues(3, suite.reportCount()) // Access leaves everything in place
+}
+
+func (suite *SubjectRequestTestSuite) TestErasureKeepsHeldRecordsAndBlocksRecollection() {
+    r := suite.open(subject.KindErasure)
+
+    closed, export, err := suite.service.Fulfil(suite.ctx, r.ID)
+    suite.Require().NoError(err)
+    suite.Nil(export)
+    suite.Empty(closed.PhoneNumber)
+
+    removed := map[string]int64{}
+    for _, rm := range closed.Outcome.Removed {
+        removed[rm.Store+"/"+rm.Kind+"/"+rm.Action] = rm.Count
+    }
+    suite.EqualValues(2, removed["postgres/intelligence_reports/deleted"])
+    suite.EqualValues(1, removed["postgres/intelligence_reports/held"])
+    suite.EqualValues(1, removed["redis/keys/deleted"])
+    suite.EqualValues(1, suite.reportCount())
+
+    erased, err := subject.ErasedNumbers(suite.ctx, suite.store, []string{suite.phone})
+    suite.Require().NoError(err)
+    suite.True(erased[suite.phone])
+
+    _, _, err = suite.service.Fulfil(suite.ctx, r.ID)
+    suite.True(errors.Is(err, subject.ErrClosed))
+}
+
+func (suite *SubjectRequestTestSuite) TestRequestsNeedVerifiedIdentity() {
+    _, err := suite.service.Open(suite.ctx, subject.NewRequest{Kind: subject.KindErasure, PhoneNumber: suite.phone})
+    suite.True(errors.Is(err, subject.ErrNotVerified))
+}
+
+func (suite *SubjectRequestTestSuite) TestDeadlineExtendsOnce() {
+    r := suite.open(subject.KindAccess)
+    suite.WithinDuration(r.ReceivedAt.Add(subject.ResponsePeriod), r.DueAt, time.Second)
+
+    _, err := suite.service.Extend(suite.ctx, r.ID, 90, "complex request")
+    suite.True(errors.Is(err, subject.ErrInvalidExtension))
+
+    extended, err := suite.service.Extend(suite.ctx, r.ID, 60, "complex request")
+    suite.Require().NoError(err)
+    suite.WithinDuration(r.DueAt.Add(60*24*time.Hour), extended.DueAt, time.Second)
+
+    _, err = suite.service.Extend(suite.ctx, r.ID, 10, "more time")
+    suite.True(errors.Is(err, subject.ErrAlreadyExtended))
+}
@@ -0,0 +1,192 @@
+// tests/integration/database/suppression.integration.test.go
+package integration
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+    "path/filepath"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/suite"
+    "gorm.io/gorm"
+
+    "secure-iran-intel/database"
+    "secure-iran-intel/pkg/audit"
+    "secure-iran-intel/pkg/envelope"
+    "secure-iran-intel/pkg/rbac"
+    "secure-iran-intel/pkg/suppression"
+    "secure-iran-intel/pkg/tenancy"
+)
+
+// registerKeyring seals encrypted columns, token signing keys included,
+// under a throwaway master key
+func registerKeyring(t *testing.T, db *gorm.DB) *envelope.Keyring {
+    kms, err := envelope.NewFileKMS(filepath.Join(t.TempDir(), "master-keys.json"))
+    if err != nil {
+        t.Fatalf("Failed to create master keys: %v", err)
+    }
+    keyring := envelope.NewKeyring(envelope.NewGormKeyStore(db), kms)
+    envelope.Register(keyring)
+    return keyring
+}
+
+type SuppressionTestSuite struct {
+    suite.Suite
+    db         *gorm.DB
+    sqlDB      *sql.DB
+    auditStore *audit.FileStore
+    service    *suppression.Service
+    screener   *suppression.Screener
+    tenantIDs  []string
+    numbers    []string
+}
+
+func TestSuppressionSuite(t *testing.T) {
+    suite.Run(t, new(SuppressionTestSuite))
+}
+
+func (suite *SuppressionTestSuite) SetupSuite() {
+    var err error
+    suite.db, suite.sqlDB, err = database.ConnectTest()
+    if err != nil {
+        suite.T().Fatalf("Failed to connect to test database: %v", err)
+    }
+    if err := database.MigrateTest(suite.db); err != nil {
+        suite.T().Fatalf("Failed to run test migrations: %v", err)
+    }
+
+    suite.Require().NoError(audit.SetTargetKey(testTargetKey))
+    suite.auditStore = audit.NewFileStore(filepath.Join(suite.T().TempDir(), "audit.jsonl"))
+    auditLog := audit.NewLogger(suite.auditStore)
+    store := suppression.NewGormStore(suite.db)
+    suite.service = suppression.NewService(store, auditLog, rbac.NewEngine(rbac.NewGormStore(suite.db)))
+    suite.screener = suppression.NewScreener(store, auditLog)
+}
+
+func (suite *SuppressionTestSuite) TearDownSuite() {
+    if suite.sqlDB != nil {
+        suite.sqlDB.Close()
+    }
+}
+
+func (suite *SuppressionTestSuite) SetupTest() {
+    suite.tenantIDs = nil
+    for i := 0; i < 2; i++ {
+        var tenantID string
+        slug := fmt.Sprintf("suppression-%d-%d", i, time.Now().UnixNano())
+        suite.Require().NoError(suite.db.Raw(`INSERT INTO tenants (name, slug) VALUES (?, ?) RETURNING id`, slug, slug).Scan(&tenantID).Error)
+        suite.tenantIDs = append(suite.tenantIDs, tenantID)
+    }
+    suite.numbers = nil
+    for i := 0; i < 3; i++ {
+        suite.numbers = append(suite.numbers, fmt.Sprintf("+98912%07d", (time.Now().UnixNano()+int64(i))%10000000))
+    }
+}
+
+func (suite *SuppressionTestSuite) TearDownTest() {
+    for _, tenantID := range suite.tenantIDs {
+        suite.db.Exec("DELETE FROM tenants WHERE id = ?", tenantID)
+    }
+    // Global entries belong to the platform tenant, which stays
+    hashes := make([]string, 0, len(suite.numbers))
+    for _, number := range suite.numbers {
+        hashes = append(hashes, suite.hash(number))
+    }
+    tenancy.SystemTransaction(context.Background(), suite.db, func(tx *gorm.DB) error {
+        return tx.Exec("DELETE FROM suppressions WHERE identifier_hash IN ?", hashes).Error
+    })
+}
+
+func (suite *SuppressionTestSuite) hash(number string) string {
+    hash, err := suppression.Hash(number)
+    suite.Require().NoError(err)
+    return hash
+}
+
+// as acts for tenantID; the system actor passes the permission check
+func (suite *SuppressionTestSuite) as(tenantID string) context.Context {
+    return audit.WithActor(context.Background(), audit.Actor{TenantID: tenantID, Type: audit.ActorSystem, ID: "suppression-test"})
+}
+
+func (suite *SuppressionTestSuite) add(tenantID, number, category string) *suppression.Entry {
+    e, err := suite.service.Add(suite.as(tenantID), suppression.NewEntry{PhoneNumber: number, Category: category, Reason: "test"})
+    suite.Require().NoError(err)
+    return e
+}
+
+func (suite *SuppressionTestSuite) TestScreenBlocksTenantAndGlobalEntries() {
+    tenantA, tenantB := suite.tenantIDs[0], suite.tenantIDs[1]
+    optedOut, minor, free := suite.numbers[0], suite.numbers[1], suite.numbers[2]
+    suite.add(tenantA, optedOut, suppression.CategoryOptOut)
+    suite.add(tenancy.PlatformTenantID, "0"+minor[3:], suppression.CategoryMinor)
+
+    blocked, err := suite.screener.Screen(suite.as(tenantA), tenantA, suite.numbers, map[string]string{"job_id": "job-a"})
+    suite.Require().NoError(err)
+    suite.Equal(map[string]bool{optedOut: true, minor: true}, blocked)
+    suite.False(blocked[free])
+
+    // Another tenant's list does not apply; the global one does
+    blocked, err = suite.screener.Screen(suite.as(tenantB), tenantB, suite.numbers, nil)
+    suite.Require().NoError(err)
+    suite.Equal(map[string]bool{minor: true}, blocked)
+
+    // Blocks are recorded against the job by hash only
+    var records []*audit.Record
+    err = suite.auditStore.Scan(context.Background(), func(r *audit.Record) error {
+        if r.Action == audit.ActionSuppressionBlock && r.Metadata["job_id"] == "job-a" {
+            records = append(records, r)
+        }
+        return nil
+    })
+    suite.Require().NoError(err)
+    suite.Len(records, 2)
+    for _, r := range records {
+        suite.Contains([]string{suite.hash(optedOut), suite.hash(minor)}, r.TargetHash)
+        suite.NotContains(fmt.Sprint(r.Metadata), optedOut)
+        suite.NotContains(fmt.Sprint(r.Metadata), minor)
+    }
+}
+
+func (suite *SuppressionTestSuite) TestRegistryKeepsOnlyHashes() {
+    tenantA := suite.tenantIDs[0]
+    e := suite.add(tenantA, suite.numbers[0], suppression.CategoryProtected)
+    suite.Equal(suite.hash(suite.numbers[0]), e.IdentifierHash)
+
+    var stored int64
+    err := tenancy.SystemTransaction(context.Background(), suite.db, func(tx *gorm.DB) error {
+        return tx.Raw(`SELECT count(*) FROM suppressions s WHERE s::text LIKE ?`, "%"+suite.numbers[0][1:]+"%").Scan(&stored).Error
+    })
+    suite.Require().NoError(err)
+    suite.Zero(stored)
+}
+
+func (suite *SuppressionTestSuite) TestRemovedEntriesStopBlocking() {
+    tenantA := suite.tenantIDs[0]
+    e := suite.add(tenantA, suite.numbers[0], suppression.CategoryOptOut)
+
+    _, err := suite.service.Add(suite.as(tenantA), suppression.NewEntry{PhoneNumber: suite.numbers[0], Category: suppression.CategoryMinor, Reason: "again"})
+    suite.True(errors.Is(err, suppression.ErrAlreadySuppressed))
+
+    removed, err := suite.service.Remove(suite.as(tenantA), e.ID)
+    suite.Require().NoError(err)
+    suite.False(removed.Active())
+
+    blocked, err := suite.screener.Screen(suite.as(tenantA), tenantA, suite.numbers[:1], nil)
+    suite.Require().NoError(err)
+    suite.Empty(blocked)
+
+    _, err = suite.service.Remove(suite.as(tenantA), e.ID)
+    suite.True(errors.Is(err, suppression.ErrEntryRemoved))
+
+    // Other tenants cannot see or remove the entry
+    _, err = suite.service.Remove(suite.as(suite.tenantIDs[1]), e.ID)
+    suite.True(errors.Is(err, suppression.ErrUnknownEntry))
+}
+
+func (suite *SuppressionTestSuite) TestCategoryMustBeKnown() {
+    _, err := suite.service.Add(suite.as(suite.tenantIDs[0]), suppression.NewEntry{PhoneNumber: suite.numbers[0], Category: "celebrity", Reason: "test"})
+    suite.True(errors.Is(err, suppression.ErrInvalidCategory))
+}
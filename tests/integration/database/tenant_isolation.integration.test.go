@@ -0,0 +1,295 @@
+// tests/integration/database/tenant_isolation.integration.test.go
+package integration
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/suite"
+    "gorm.io/gorm"
+
+    "secure-iran-intel/database"
+    "secure-iran-intel/pkg/purpose"
+    "secure-iran-intel/pkg/tenancy"
+)
+
+// Superusers and BYPASSRLS roles skip row-level security, so every query
+// here runs as rls_probe, an ordinary role like the services use, or as
+// rls_sweeper, which is also a member of intel_system
+const (
+    probeRole   = "rls_probe"
+    sweeperRole = "rls_sweeper"
+)
+
+type TenantIsolationTestSuite struct {
+    suite.Suite
+    db      *gorm.DB
+    sqlDB   *sql.DB
+    ctx     context.Context
+    tenantA string
+    tenantB string
+}
+
+func TestTenantIsolationSuite(t *testing.T) {
+    suite.Run(t, new(TenantIsolationTestSuite))
+}
+
+func (suite *TenantIsolationTestSuite) SetupSuite() {
+    suite.ctx = context.Background()
+
+    var err error
+    suite.db, suite.sqlDB, err = database.ConnectTest()
+    if err != nil {
+        suite.T().Fatalf("Failed to connect to test database: %v", err)
+    }
+    if err := database.MigrateTest(suite.db); err != nil {
+        suite.T().Fatalf("Failed to run test migrations: %v", err)
+    }
+
+    for _, role := range []string{probeRole, sweeperRole} {
+        statements := []string{
+            fmt.Sprintf(`DO $$ BEGIN
+                IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = '%s') THEN CREATE ROLE %s NOLOGIN; END IF;
+            END $$`, role, role),
+            "GRANT USAGE ON SCHEMA public TO " + role,
+            "GRANT SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA public TO " + role,
+            "GRANT EXECUTE ON ALL FUNCTIONS IN SCHEMA public TO " + role,
+            "GRANT " + role + " TO CURRENT_USER",
+        }
+        for _, statement := range statements {
+            if err := suite.db.Exec(statement).Error; err != nil {
+                suite.T().Fatalf("Failed to prepare %s: %v", role, err)
+            }
+        }
+    }
+    if err := suite.db.Exec("GRANT intel_system TO " + sweeperRole).Error; err != nil {
+        suite.T().Fatalf("Failed to prepare %s: %v", sweeperRole, err)
+    }
+
+    // tenants is the registry and has no policy
+    suffix := time.Now().UnixNano()
+    suite.tenantA = suite.createTenant(fmt.Sprintf("rls-a-%d", suffix))
+    suite.tenantB = suite.createTenant(fmt.Sprintf("rls-b-%d", suffix))
+
+    for tenantID, reference := range map[string]string{suite.tenantA: "RLS-A-1", suite.tenantB: "RLS-B-1"} {
+        tenantID, reference := tenantID, reference
+        err := suite.asTenant(tenantID, func(tx *gorm.DB) error {
+            var userID string
+            err := tx.Raw(`INSERT INTO users (tenant_id, email, password_hash) VALUES (?, ?, 'x') RETURNING id`,
+                tenantID, reference+"@example.test").Scan(&userID).Error
+            if err != nil {
+                return err
+            }
+            err = tx.Exec(`INSERT INTO bulk_jobs (tenant_id, name, user_id, total_numbers, platforms) VALUES (?, ?, ?, 1, '[]')`,
+                tenantID, reference, userID).Error
+            if err != nil {
+                return err
+            }
+            return tx.Create(&purpose.Case{
+                TenantID:   tenantID,
+                Reference:  reference,
+                LegalBases: []string{purpose.BasisCourtOrder},
+                Status:     "open",
+            }).Error
+        })
+        if err != nil {
+            suite.T().Fatalf("Failed to seed tenant %s: %v", tenantID, err)
+        }
+    }
+}
+
+func (suite *TenantIsolationTestSuite) TearDownSuite() {
+    if suite.db != nil {
+        // Foreign key cascades are not subject to row-level security
+        suite.db.Exec("DELETE FROM tenants WHERE id IN (?, ?)", suite.tenantA, suite.tenantB)
+    }
+    if suite.sqlDB != nil {
+        suite.sqlDB.Close()
+    }
+}
+
+func (suite *TenantIsolationTestSuite) TestTenantSeesOnlyOwnRows() {
+    var cases []purpose.Case
+    err := suite.asTenant(suite.tenantA, func(tx *gorm.DB) error {
+        // No tenant filter: the policy is the only thing scoping this query
+        return tx.Where("reference LIKE 'RLS-%'").Find(&cases).Error
+    })
+    suite.NoError(err)
+    suite.Len(cases, 1)
+    for _, c := range cases {
+        suite.Equal(suite.tenantA, c.TenantID, "Tenant A must only see its own cases")
+    }
+}
+
+func (suite *TenantIsolationTestSuite) TestCrossTenantReadByKeyFails() {
+    err := suite.asTenant(suite.tenantA, func(tx *gorm.DB) error {
+        var c purpose.Case
+        return tx.Where("reference = ?", "RLS-B-1").First(&c).Error
+    })
+    suite.True(errors.Is(err, gorm.ErrRecordNotFound), "Tenant B's case must be invisible to tenant A, got %v", err)
+
+    // Even asking for the other tenant explicitly returns nothing
+    err = suite.asTenant(suite.tenantA, func(tx *gorm.DB) error {
+        var c purpose.Case
+        return tx.Where("tenant_id = ? AND reference = ?", suite.tenantB, "RLS-B-1").First(&c).Error
+    })
+    suite.True(errors.Is(err, gorm.ErrRecordNotFound), "Filtering on tenant B must not widen tenant A's scope, got %v", err)
+}
+
+func (suite *TenantIsolationTestSuite) TestBulkJobsListedPerTenant() {
+    var names []string
+    err := suite.asTenant(suite.tenantB, func(tx *gorm.DB) error {
+        return tx.Table("bulk_jobs").Where("name LIKE 'RLS-%'").Pluck("name", &names).Error
+    })
+    suite.NoError(err)
+    suite.Equal([]string{"RLS-B-1"}, names)
+
+    var users int64
+    err = suite.asTenant(suite.tenantB, func(tx *gorm.DB) error {
+        return tx.Table("users").Where("email LIKE 'RLS-%'").Count(&users).Error
+    })
+    suite.NoError(err)
+    suite.Equal(int64(1), users)
+}
+
+func (suite *TenantIsolationTestSuite) TestCrossTenantWriteFails() {
+    err := suite.asTenant(suite.tenantA, func(tx *gorm.DB) error {
+        return tx.Create(&purpose.Case{
+            TenantID:   suite.tenantB,
+            Reference:  "RLS-PLANTED",
+            LegalBases: []string{purpose.BasisConsent},
+            Status:     "open",
+        }).Error
+    })
+    suite.Error(err, "Tenant A must not be able to insert rows for tenant B")
+    suite.Contains(err.Error(), "row-level security")
+}
+
+func (suite *TenantIsolationTestSuite) TestCrossTenantUpdateAffectsNothing() {
+    var affected int64
+    err := suite.asTenant(suite.tenantA, func(tx *gorm.DB) error {
+        result := tx.Model(&purpose.Case{}).Where("reference = ?", "RLS-B-1").Update("status", "closed")
+        affected = result.RowsAffected
+        return result.Error
+    })
+    suite.NoError(err)
+    suite.Equal(int64(0), affected)
+
+    // Moving a row into another tenant is rejected too
+    err = suite.asTenant(suite.tenantA, func(tx *gorm.DB) error {
+        return tx.Model(&purpose.Case{}).Where("reference = ?", "RLS-A-1").Update("tenant_id", suite.tenantB).Error
+    })
+    suite.Error(err)
+
+    var c purpose.Case
+    err = suite.asTenant(suite.tenantB, func(tx *gorm.DB) error {
+        return tx.Where("reference = ?", "RLS-B-1").First(&c).Error
+    })
+    suite.NoError(err)
+    suite.Equal("open", c.Status)
+}
+
+func (suite *TenantIsolationTestSuite) TestNoTenantSeesNothing() {
+    var count int64
+    err := suite.db.Transaction(func(tx *gorm.DB) error {
+        if err := tx.Exec("SET LOCAL ROLE " + probeRole).Error; err != nil {
+            return err
+        }
+        return tx.Model(&purpose.Case{}).Where("reference LIKE 'RLS-%'").Count(&count).Error
+    })
+    suite.NoError(err)
+    suite.Equal(int64(0), count, "Queries outside a tenant scope must see no tenant rows")
+
+    err = tenancy.Transaction(suite.ctx, suite.db, "", func(tx *gorm.DB) error { return nil })
+    suite.ErrorIs(err, tenancy.ErrNoTenant)
+}
+
+func (suite *TenantIsolationTestSuite) TestScopeEndsWithTransaction() {
+    var count int64
+    err := suite.db.Connection(func(conn *gorm.DB) error {
+        // Scope one transaction to tenant A, then reuse the same connection
+        err := tenancy.Transaction(suite.ctx, conn, suite.tenantA, func(tx *gorm.DB) error { return nil })
+        if err != nil {
+            return err
+        }
+        return conn.Transaction(func(tx *gorm.DB) error {
+            if err := tx.Exec("SET LOCAL ROLE " + probeRole).Error; err != nil {
+                return err
+            }
+            return tx.Model(&purpose.Case{}).Where("reference LIKE 'RLS-%'").Count(&count).Error
+        })
+    })
+    suite.NoError(err)
+    suite.Equal(int64(0), count, "SET LOCAL must not leak to the next transaction on the connection")
+}
+
+func (suite *TenantIsolationTestSuite) TestBypassNeedsIntelSystem() {
+    var count int64
+    err := suite.asTenant(suite.tenantA, func(tx *gorm.DB) error {
+        if err := tx.Exec("SELECT set_config('app.bypass_rls', 'on', true)").Error; err != nil {
+            return err
+        }
+        return tx.Model(&purpose.Case{}).Where("reference LIKE 'RLS-%'").Count(&count).Error
+    })
+    suite.NoError(err)
+    suite.Equal(int64(1), count, "Setting app.bypass_rls without intel_system must not widen the scope")
+}
+
+func (suite *TenantIsolationTestSuite) TestSystemTransactionNeedsIntelSystem() {
+    count := func(tx *gorm.DB) (int64, error) {
+        var n int64
+        err := tx.Model(&purpose.Case{}).Where("reference LIKE 'RLS-%'").Count(&n).Error
+        return n, err
+    }
+
+    // Without membership the bypass would quietly show nothing
+    var seen int64
+    err := suite.asSystem(probeRole, func(tx *gorm.DB) error {
+        var err error
+        seen, err = count(tx)
+        return err
+    })
+    suite.True(errors.Is(err, tenancy.ErrNotSystem), err)
+    suite.Zero(seen)
+
+    err = suite.asSystem(sweeperRole, func(tx *gorm.DB) error {
+        var err error
+        seen, err = count(tx)
+        return err
+    })
+    suite.NoError(err)
+    suite.Equal(int64(2), seen)
+}
+
+// asSystem runs fn through tenancy.SystemTransaction as role
+func (suite *TenantIsolationTestSuite) asSystem(role string, fn func(tx *gorm.DB) error) error {
+    return suite.db.WithContext(suite.ctx).Transaction(func(tx *gorm.DB) error {
+        if err := tx.Exec("SET LOCAL ROLE " + role).Error; err != nil {
+            return err
+        }
+        return tenancy.SystemTransaction(suite.ctx, tx, fn)
+    })
+}
+
+// asTenant runs fn scoped to tenantID as the unprivileged probe role
+func (suite *TenantIsolationTestSuite) asTenant(tenantID string, fn func(tx *gorm.DB) error) error {
+    return tenancy.Transaction(suite.ctx, suite.db, tenantID, func(tx *gorm.DB) error {
+        if err := tx.Exec("SET LOCAL ROLE " + probeRole).Error; err != nil {
+            return err
+        }
+        return fn(tx)
+    })
+}
+
+func (suite *TenantIsolationTestSuite) createTenant(slug string) string {
+    var id string
+    err := suite.db.Raw("INSERT INTO tenants (name, slug) VALUES (?, ?) RETURNING id", slug, slug).Scan(&id).Error
+    if err != nil {
+        suite.T().Fatalf("Failed to create tenant %s: %v", slug, err)
+    }
+    return id
+}
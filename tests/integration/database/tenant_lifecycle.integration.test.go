@@ -0,0 +1,225 @@
+// tests/integration/database/tenant_lifecycle.integration.test.go
+package integration
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/alicebob/miniredis/v2"
+    "github.com/go-redis/redis/v8"
+    "github.com/golang-jwt/jwt/v4"
+    "github.com/stretchr/testify/suite"
+    "gorm.io/gorm"
+
+    "secure-iran-intel/database"
+    "secure-iran-intel/pkg/audit"
+    "secure-iran-intel/pkg/lifecycle"
+    "secure-iran-intel/pkg/rbac"
+    "secure-iran-intel/pkg/tenancy"
+    "secure-iran-intel/pkg/token"
+)
+
+// testTargetKey keys audit target hashes in every suite of the package
+var testTargetKey = []byte("integration-test-audit-target-key-0000")
+
+type TenantLifecycleTestSuite struct {
+    suite.Suite
+    db        *gorm.DB
+    sqlDB     *sql.DB
+    ctx       context.Context
+    redis     *miniredis.Miniredis
+    exportDir string
+    auditLog  *audit.Logger
+    service   *lifecycle.Service
+    tenantID  string
+    otherID   string
+}
+
+func TestTenantLifecycleSuite(t *testing.T) {
+    suite.Run(t, new(TenantLifecycleTestSuite))
+}
+
+func (suite *TenantLifecycleTestSuite) SetupSuite() {
+    // Internal services run as the system actor, which may manage tenants
+    suite.ctx = audit.WithActor(context.Background(), audit.Actor{Type: audit.ActorSystem, ID: "lifecycle-test"})
+
+    var err error
+    suite.db, suite.sqlDB, err = database.ConnectTest()
+    if err != nil {
+        suite.T().Fatalf("Failed to connect to test database: %v", err)
+    }
+    if err := database.MigrateTest(suite.db); err != nil {
+        suite.T().Fatalf("Failed to run test migrations: %v", err)
+    }
+
+    suite.redis, err = miniredis.Run()
+    if err != nil {
+        suite.T().Fatalf("Failed to start Redis: %v", err)
+    }
+    client := redis.NewClient(&redis.Options{Addr: suite.redis.Addr()})
+
+    registerKeyring(suite.T(), suite.db)
+    issuer, err := token.NewIssuer(suite.ctx, token.Config{Issuer: "lifecycle-test", Audience: "lifecycle-test"}, token.NewGormKeyStore(suite.db))
+    if err != nil {
+        suite.T().Fatalf("Failed to create token issuer: %v", err)
+    }
+
+    suite.exportDir = suite.T().TempDir()
+    auditStore := audit.NewFileStore(filepath.Join(suite.exportDir, "audit.jsonl"))
+    suite.Require().NoError(audit.SetTargetKey(testTargetKey))
+    suite.auditLog = audit.NewLogger(auditStore)
+    suite.service = lifecycle.NewService(
+        lifecycle.NewGormStore(suite.db),
+        auditStore,
+        suite.auditLog,
+        rbac.NewEngine(rbac.NewGormStore(suite.db)),
+        issuer,
+        suite.exportDir,
+        lifecycle.NewPostgresPurger(suite.db),
+        lifecycle.NewRedisPurger(client),
+    )
+}
+
+func (suite *TenantLifecycleTestSuite) TearDownSuite() {
+    if suite.db != nil {
+        suite.db.Exec("DELETE FROM tenant_deletion_certificates WHERE tenant_id IN (?, ?)", suite.tenantID, suite.otherID)
+        suite.db.Exec("DELETE FROM tenants WHERE id IN (?, ?)", suite.tenantID, suite.otherID)
+    }
+    if suite.redis != nil {
+        suite.redis.Close()
+    }
+    if suite.sqlDB != nil {
+        suite.sqlDB.Close()
+    }
+}
+
+func (suite *TenantLifecycleTestSuite) SetupTest() {
+    suffix := time.Now().UnixNano()
+    suite.tenantID = suite.seedTenant(fmt.Sprintf("offboard-%d", suffix))
+    suite.otherID = suite.seedTenant(fmt.Sprintf("bystander-%d", suffix))
+}
+
+// seedTenant creates a tenant with a user, a job, a report, a suppression
+// entry, a Redis key and an audit record
+func (suite *TenantLifecycleTestSuite) seedTenant(slug string) string {
+    var tenantID string
+    err := suite.db.Raw(`INSERT INTO tenants (name, slug, billing_email) VALUES (?, ?, 'billing@example.test') RETURNING id`, slug, slug).
+        Scan(&tenantID).Error
+    suite.Require().NoError(err)
+
+    err = tenancy.Transaction(suite.ctx, suite.db, tenantID, func(tx *gorm.DB) error {
+        var userID string
+        err := tx.Raw(`INSERT INTO users (tenant_id, email, password_hash) VALUES (?, ?, 'x') RETURNING id`,
+            tenantID, slug+"@example.test").Scan(&userID).Error
+        if err != nil {
+            return err
+        }
+        err = tx.Exec(`INSERT INTO bulk_jobs (tenant_id, name, user_id, total_numbers, platforms) VALUES (?, ?, ?, 1, '[]')`,
+            tenantID, slug, userID).Error
+        if err != nil {
+            return err
+        }
+        err = tx.Exec(`INSERT INTO intelligence_reports (tenant_id, report_id, phone_number, report_type, report_data) VALUES (?, ?, '+989121234567', 'comprehensive', '{}')`,
+            tenantID, slug).Error
+        if err != nil {
+            return err
+        }
+        return tx.Exec(`INSERT INTO suppressions (tenant_id, identifier_hash, category, reason, added_by) VALUES (?, ?, 'opt_out', 'asked to be left out', 'seed')`,
+            tenantID, strings.Repeat("a", 64)).Error
+    })
+    suite.Require().NoError(err)
+
+    suite.redis.Set(fmt.Sprintf("rate_limit:tenant:%s:1", tenantID), "3")
+    err = suite.auditLog.Log(suite.ctx, audit.Entry{
+        Action:     audit.ActionJobCreate,
+        TargetType: "job",
+        Target:     slug,
+        Actor:      &audit.Actor{TenantID: tenantID, Type: audit.ActorUser, ID: "seed"},
+    })
+    suite.Require().NoError(err)
+    return tenantID
+}
+
+func (suite *TenantLifecycleTestSuite) status(tenantID string) string {
+    var status string
+    suite.Require().NoError(suite.db.Raw("SELECT status FROM tenants WHERE id = ?", tenantID).Scan(&status).Error)
+    return status
+}
+
+func (suite *TenantLifecycleTestSuite) TestSuspendAndResume() {
+    suite.NoError(suite.service.Suspend(suite.ctx, suite.tenantID, "unpaid invoice"))
+    suite.Equal(lifecycle.StatusSuspended, suite.status(suite.tenantID))
+
+    err := suite.service.Suspend(suite.ctx, suite.tenantID, "again")
+    suite.True(errors.Is(err, lifecycle.ErrInvalidState), "A suspended tenant cannot be suspended again")
+
+    suite.NoError(suite.service.Resume(suite.ctx, suite.tenantID))
+    suite.Equal(lifecycle.StatusActive, suite.status(suite.tenantID))
+}
+
+func (suite *TenantLifecycleTestSuite) TestSuspendNeedsReason() {
+    err := suite.service.Suspend(suite.ctx, suite.tenantID, "  ")
+    suite.True(errors.Is(err, lifecycle.ErrReasonRequired))
+    suite.Equal(lifecycle.StatusActive, suite.status(suite.tenantID))
+}
+
+func (suite *TenantLifecycleTestSuite) TestTenantsCannotManageOthers() {
+    ctx := audit.WithActor(context.Background(), audit.Actor{TenantID: suite.otherID, Type: audit.ActorUser, ID: "someone"})
+    err := suite.service.Suspend(ctx, suite.tenantID, "hostile")
+    suite.True(errors.Is(err, lifecycle.ErrNotPlatform))
+    suite.Equal(lifecycle.StatusActive, suite.status(suite.tenantID))
+}
+
+func (suite *TenantLifecycleTestSuite) TestOffboardPurgesAndCertifies() {
+    cert, err := suite.service.Offboard(suite.ctx, suite.tenantID)
+    suite.Require().NoError(err)
+
+    suite.True(cert.Verified)
+    suite.Equal(lifecycle.StatusOffboarded, suite.status(suite.tenantID))
+    removed := map[string]int64{}
+    for _, r := range cert.Removed {
+        removed[r.Store+"/"+r.Kind] = r.Count
+    }
+    suite.EqualValues(1, removed["postgres/users"])
+    suite.EqualValues(1, removed["postgres/bulk_jobs"])
+    suite.EqualValues(1, removed["postgres/intelligence_reports"])
+    suite.EqualValues(1, removed["postgres/suppressions"])
+    suite.EqualValues(1, removed["redis/keys"])
+
+    // Nothing of the tenant is left; the bystander is untouched
+    var remaining int64
+    err = tenancy.SystemTransaction(suite.ctx, suite.db, func(tx *gorm.DB) error {
+        return tx.Raw("SELECT (SELECT COUNT(*) FROM users WHERE tenant_id = ?) + (SELECT COUNT(*) FROM bulk_jobs WHERE tenant_id = ?)",
+            suite.tenantID, suite.tenantID).Scan(&remaining).Error
+    })
+    suite.NoError(err)
+    suite.Zero(remaining)
+    suite.False(suite.redis.Exists(fmt.Sprintf("rate_limit:tenant:%s:1", suite.tenantID)))
+    suite.True(suite.redis.Exists(fmt.Sprintf("rate_limit:tenant:%s:1", suite.otherID)))
+
+    // The export holds the tenant's own audit records only
+    suite.EqualValues(1, cert.AuditExport.Records)
+    _, err = os.Stat(filepath.Join(suite.exportDir, cert.AuditExport.File))
+    suite.NoError(err)
+
+    // The certificate verifies against the key that signed it
+    key, err := cert.PublicKey.PublicKey()
+    suite.Require().NoError(err)
+    parsed, err := jwt.Parse(cert.Signature, func(*jwt.Token) (interface{}, error) { return key, nil })
+    suite.NoError(err)
+    suite.Equal(cert.TenantID, parsed.Claims.(jwt.MapClaims)["sub"])
+
+    stored, err := suite.service.Certificate(suite.ctx, suite.tenantID)
+    suite.NoError(err)
+    suite.Equal(cert.ID, stored.ID)
+
+    _, err = suite.service.Offboard(suite.ctx, suite.tenantID)
+    suite.True(errors.Is(err, lifecycle.ErrInvalidState), "An offboarded tenant cannot be offboarded again")
+}
@@ -0,0 +1,322 @@
+// tests/integration/normalizer/batch.integration.test.go
+package integration
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/suite"
+
+    "secure-iran-intel/pkg/normalizer"
+)
+
+type BatchTestSuite struct {
+    suite.Suite
+    normalizer *normalizer.PhoneNormalizer
+}
+
+func TestBatchSuite(t *testing.T) {
+    suite.Run(t, new(BatchTestSuite))
+}
+
+func (suite *BatchTestSuite) SetupSuite() {
+    suite.normalizer = normalizer.NewPhoneNormalizer("IR")
+}
+
+func (suite *BatchTestSuite) TestResultsKeepInputOrder() {
+    inputs := make([]string, 200)
+    for i := range inputs {
+        inputs[i] = fmt.Sprintf("0912%07d", 1000000+i)
+    }
+    inputs[17] = "not a number"
+
+    for _, workers := range []int{1, 3, 16} {
+        suite.Run(fmt.Sprintf("%d workers", workers), func() {
+            feed := make(chan string)
+            go func() {
+                defer close(feed)
+                for _, input := range inputs {
+                    feed <- input
+                }
+            }()
+
+            summary := normalizer.CollectBatch(suite.normalizer.NormalizeStream(context.Background(), feed, normalizer.BatchOptions{Workers: workers}))
+            suite.Require().Len(summary.Results, len(inputs))
+            for i, result := range summary.Results {
+                suite.Equal(i, result.Index)
+                suite.Equal(inputs[i], result.Input)
+            }
+            suite.Len(summary.Unique, len(inputs)-1)
+            suite.Require().Len(summary.Failed, 1)
+            suite.Equal(17, summary.Failed[0].Index)
+            suite.Equal(normalizer.CodeNotANumber, summary.Failed[0].Code)
+            suite.NotEmpty(summary.Failed[0].Error)
+        })
+    }
+}
+
+func (suite *BatchTestSuite) TestDuplicatesPointAtTheirFirstOccurrence() {
+    tests := []struct {
+        name    string
+        inputs  []string
+        first   []int // FirstIndex per input; -1 for failures
+        indices [][]int
+    }{
+        {
+            name:    "formats of one number",
+            inputs:  []string{"09121234567", "+98 912 123 4567", "۰۹۱۲۱۲۳۴۵۶۷", "00989121234567"},
+            first:   []int{0, 0, 0, 0},
+            indices: [][]int{{0, 1, 2, 3}},
+        },
+        {
+            name:    "extensions are distinct targets",
+            inputs:  []string{"02112345678", "02112345678 ext. 1", "+982112345678;ext=1", "02112345678 x2"},
+            first:   []int{0, 1, 1, 3},
+            indices: [][]int{{0}, {1, 2}, {3}},
+        },
+        {
+            name:    "failures are never duplicates",
+            inputs:  []string{"abc", "09121234567", "abc", "9121234567"},
+            first:   []int{-1, 1, -1, 1},
+            indices: [][]int{{1, 3}},
+        },
+        {
+            name:    "short codes",
+            inputs:  []string{"110", "۱۱۰", "115"},
+            first:   []int{0, 0, 2},
+            indices: [][]int{{0, 1}, {2}},
+        },
+    }
+
+    for _, tt := range tests {
+        suite.Run(tt.name, func() {
+            summary := suite.normalizer.NormalizeBatch(tt.inputs, "IR")
+            suite.Require().Len(summary.Results, len(tt.inputs))
+
+            for i, result := range summary.Results {
+                if tt.first[i] < 0 {
+                    suite.Error(result.Err)
+                    suite.False(result.IsDuplicate())
+                    continue
+                }
+                suite.Equal(tt.first[i], result.FirstIndex)
+                suite.Equal(tt.first[i] != i, result.IsDuplicate())
+            }
+
+            indices := make([][]int, 0, len(summary.Unique))
+            for _, unique := range summary.Unique {
+                indices = append(indices, unique.Indices)
+            }
+            suite.Equal(tt.indices, indices)
+        })
+    }
+}
+
+func (suite *BatchTestSuite) TestReaderIndexesByLine() {
+    input := "09121234567\n\nabc\n  +98 912 123 4567  \n"
+    results, readErr := suite.normalizer.NormalizeReader(context.Background(), strings.NewReader(input), normalizer.BatchOptions{Workers: 2})
+
+    summary := normalizer.CollectBatch(results)
+    suite.NoError(readErr())
+    suite.Require().Len(summary.Results, 4)
+    suite.Equal("+98 912 123 4567", summary.Results[3].Input)
+    suite.Equal(0, summary.Results[3].FirstIndex)
+    suite.Len(summary.Failed, 2)
+}
+
+func (suite *BatchTestSuite) TestCancelledStreamsClose() {
+    ctx, cancel := context.WithCancel(context.Background())
+    feed := make(chan string)
+    results := suite.normalizer.NormalizeStream(ctx, feed, normalizer.BatchOptions{Workers: 2})
+
+    feed <- "09121234567"
+    suite.Equal(0, (<-results).Index)
+    cancel()
+
+    select {
+    case _, open := <-results:
+        suite.False(open)
+    case <-time.After(2 * time.Second):
+        suite.Fail("results channel left open after cancellation")
+    }
+}
+
+type CacheTestSuite struct {
+    suite.Suite
+}
+
+func TestCacheSuite(t *testing.T) {
+    suite.Run(t, new(CacheTestSuite))
+}
+
+func (suite *CacheTestSuite) TestRepeatedInputsAreServedFromCache() {
+    cache := normalizer.NewNormalizationCache(100, time.Hour)
+    pn := normalizer.NewPhoneNormalizer("IR").WithCache(cache)
+
+    first, err := pn.NormalizePhone("09121234567", "")
+    suite.Require().NoError(err)
+    first.Carrier = "edited by caller"
+
+    second, err := pn.NormalizePhone("09121234567", "")
+    suite.Require().NoError(err)
+    suite.NotEqual("edited by caller", second.Carrier)
+
+    // Failures are cached as well
+    _, err = pn.NormalizePhone("abc", "")
+    suite.ErrorIs(err, normalizer.ErrNotANumber)
+    _, err = pn.NormalizePhone("abc", "")
+    suite.ErrorIs(err, normalizer.ErrNotANumber)
+
+    stats := cache.Stats()
+    suite.Equal(uint64(2), stats.Hits)
+    suite.Equal(uint64(2), stats.Misses)
+    suite.Equal(2, stats.Size)
+}
+
+func (suite *CacheTestSuite) TestKeysSeparateEverythingThatChangesAResult() {
+    cache := normalizer.NewNormalizationCache(100, time.Hour)
+    base := normalizer.NewPhoneNormalizer("IR").WithCache(cache)
+    tight := &fixedDetector{candidates: []normalizer.CountryCandidate{
+        {Region: "US", Confidence: 0.6},
+        {Region: "DE", Confidence: 0.5},
+    }}
+
+    tests := []struct {
+        name  string
+        pn    *normalizer.PhoneNormalizer
+        input string
+        hint  string
+        check func(phone *normalizer.NormalizedPhone, err error)
+    }{
+        {"default region", base, "2015550123", "", func(phone *normalizer.NormalizedPhone, err error) {
+            suite.Require().NoError(err)
+            suite.Equal("IR", phone.Country)
+        }},
+        {"hint", base, "2015550123", "US", func(phone *normalizer.NormalizedPhone, err error) {
+            suite.Require().NoError(err)
+            suite.Equal("US", phone.Country)
+        }},
+        {"tenant default", base.WithCountryDetector(normalizer.NewPrefixCountryDetector("US")).WithTenantDefault("US"), "2015550123", "", func(phone *normalizer.NormalizedPhone, err error) {
+            suite.Require().NoError(err)
+            suite.Equal("US", phone.Country)
+        }},
+        {"strict mode", base.WithStrictMode(false), "+9991234567", "", func(phone *normalizer.NormalizedPhone, err error) {
+            suite.ErrorIs(err, normalizer.ErrFallbackUsed)
+            suite.NotNil(phone)
+        }},
+        {"country detector", base.WithCountryDetector(tight), "2015550123", "", func(phone *normalizer.NormalizedPhone, err error) {
+            suite.ErrorIs(err, normalizer.ErrAmbiguousCountry)
+        }},
+        {"prefix detector regions", base.WithCountryDetector(normalizer.NewPrefixCountryDetector("US", "DE")), "2015550123", "", func(phone *normalizer.NormalizedPhone, err error) {
+            suite.ErrorIs(err, normalizer.ErrAmbiguousCountry)
+        }},
+    }
+
+    // Run twice: the second pass must be answered from each test's own entry
+    for pass := 1; pass <= 2; pass++ {
+        for _, tt := range tests {
+            suite.Run(fmt.Sprintf("%s pass %d", tt.name, pass), func() {
+                tt.check(tt.pn.NormalizePhone(tt.input, tt.hint))
+            })
+        }
+    }
+    suite.Equal(uint64(len(tests)), cache.Stats().Hits)
+
+    // Prefix detectors built alike share entries
+    _, err := base.WithCountryDetector(normalizer.NewPrefixCountryDetector("US", "DE")).NormalizePhone("2015550123", "")
+    suite.ErrorIs(err, normalizer.ErrAmbiguousCountry)
+    suite.Equal(uint64(len(tests)+1), cache.Stats().Hits)
+}
+
+func (suite *CacheTestSuite) TestSizeAndAgeAreBounded() {
+    tests := []struct {
+        name      string
+        capacity  int
+        ttl       time.Duration
+        wait      time.Duration
+        hits      uint64
+        evictions uint64
+    }{
+        {"within bounds", 10, time.Hour, 0, 3, 0},
+        {"least recently used evicted", 2, time.Hour, 0, 0, 4},
+        {"expired entries recomputed", 10, 20 * time.Millisecond, 50 * time.Millisecond, 0, 0},
+    }
+
+    inputs := []string{"09121234567", "09351234567", "02112345678"}
+    for _, tt := range tests {
+        suite.Run(tt.name, func() {
+            cache := normalizer.NewNormalizationCache(tt.capacity, tt.ttl)
+            pn := normalizer.NewPhoneNormalizer("IR").WithCache(cache)
+
+            for pass := 0; pass < 2; pass++ {
+                for _, input := range inputs {
+                    _, err := pn.NormalizePhone(input, "")
+                    suite.Require().NoError(err)
+                }
+                time.Sleep(tt.wait)
+            }
+
+            stats := cache.Stats()
+            suite.Equal(tt.hits, stats.Hits)
+            suite.Equal(tt.evictions, stats.Evictions)
+            suite.LessOrEqual(stats.Size, tt.capacity)
+        })
+    }
+}
+
+// A numbering plan reload flushes results computed under the old plans
+func (suite *CacheTestSuite) TestPlanReloadInvalidatesEntries() {
+    plans := normalizer.DefaultPlanStore()
+    embedded := plans.Version()
+    defer func() {
+        suite.Require().NoError(suite.restoreEmbeddedPlans(plans, embedded))
+    }()
+
+    cache := normalizer.NewNormalizationCache(100, time.Hour)
+    pn := normalizer.NewPhoneNormalizer("IR").WithCache(cache)
+
+    before, err := pn.NormalizePhone("09121234567", "")
+    suite.Require().NoError(err)
+    suite.Contains(before.Carrier, "MCI")
+
+    suite.Require().NoError(plans.Replace(planTables("reloaded")))
+    after, err := pn.NormalizePhone("09121234567", "")
+    suite.Require().NoError(err)
+    suite.Equal("MCI", after.Carrier)
+    suite.Equal("Tehran North", after.Region)
+    suite.Equal("IR-reloaded", after.PlanVersion)
+
+    stats := cache.Stats()
+    suite.Equal(uint64(1), stats.Invalidations)
+    suite.Equal(uint64(0), stats.Hits)
+    suite.Equal(1, stats.Size)
+}
+
+// restoreEmbeddedPlans puts the shipped plan files back into the default store
+func (suite *CacheTestSuite) restoreEmbeddedPlans(plans *normalizer.PlanStore, version string) error {
+    files, err := filepath.Glob("../../../pkg/normalizer/data/numbering_plan_*.json")
+    if err != nil {
+        return err
+    }
+
+    tables := &normalizer.PlanTables{Version: version}
+    for _, file := range files {
+        data, err := os.ReadFile(file)
+        if err != nil {
+            return err
+        }
+        var plan normalizer.NumberingPlan
+        if err := json.Unmarshal(data, &plan); err != nil {
+            return err
+        }
+        tables.Plans = append(tables.Plans, plan)
+    }
+
+    return plans.Replace(tables)
+}
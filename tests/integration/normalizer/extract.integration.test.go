@@ -0,0 +1,96 @@
+// tests/integration/normalizer/extract.integration.test.go
+package integration
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/suite"
+
+    "secure-iran-intel/pkg/normalizer"
+)
+
+type ExtractorTestSuite struct {
+    suite.Suite
+    extractor *normalizer.PhoneExtractor
+}
+
+func TestExtractorSuite(t *testing.T) {
+    suite.Run(t, new(ExtractorTestSuite))
+}
+
+func (suite *ExtractorTestSuite) SetupSuite() {
+    suite.extractor = normalizer.NewPhoneExtractor(normalizer.NewPhoneNormalizer("IR"))
+}
+
+// found is what a test expects of one extracted span
+type found struct {
+    raw        string
+    normalized string
+    extension  string
+}
+
+func (suite *ExtractorTestSuite) TestExtract() {
+    tests := []struct {
+        name string
+        text string
+        hint string
+        want []found
+    }{
+        {"ascii with separators", "Call 0912-123-4567 today", "", []found{
+            {"0912-123-4567", "+989121234567", ""},
+        }},
+        {"persian digits in persian text", "شماره من ۰۹۱۲ ۱۲۳ ۴۵۶۷ است", "", []found{
+            {"۰۹۱۲ ۱۲۳ ۴۵۶۷", "+989121234567", ""},
+        }},
+        {"arabic-indic digits", "رقم: ٠٩١٢١٢٣٤٥٦٧", "", []found{
+            {"٠٩١٢١٢٣٤٥٦٧", "+989121234567", ""},
+        }},
+        {"full-width forms", "TEL ＋９８（９１２）１２３－４５６７", "", []found{
+            {"＋９８（９１２）１２３－４５６７", "+989121234567", ""},
+        }},
+        {"bidi marks inside the number", "‏+98‎ 912⁦123⁩4567", "", []found{
+            {"+98‎ 912⁦123⁩4567", "+989121234567", ""},
+        }},
+        {"ext keyword", "office +98 21 1234 5678 ext. 42, ask for Reza", "", []found{
+            {"+98 21 1234 5678 ext. 42", "+982112345678", "42"},
+        }},
+        {"persian extension keyword", "۰۲۱-۱۲۳۴۵۶۷۸ داخلی ۴۲", "", []found{
+            {"۰۲۱-۱۲۳۴۵۶۷۸ داخلی ۴۲", "+982112345678", "42"},
+        }},
+        {"bidi mark before extension", "02112345678‏ x42", "", []found{
+            {"02112345678‏ x42", "+982112345678", "42"},
+        }},
+        {"tel uri with extension", "<a href=\"tel:+982112345678;ext=7\">", "", []found{
+            {"tel:+982112345678;ext=7", "+982112345678", "7"},
+        }},
+        {"several in order", "+4915112345678 or 09121234567", "", []found{
+            {"+4915112345678", "+4915112345678", ""},
+            {"09121234567", "+989121234567", ""},
+        }},
+        {"lines are never joined", "0912123\n4567", "", nil},
+        {"dates are skipped", "on 2026-03-14 and 14/03/2026", "", nil},
+        {"identifiers glued to letters", "order AB09121234567 and 09121234567ZZ", "", nil},
+        {"merely possible numbers", "ticket 1234567", "", nil},
+        {"hint picks the region", "(201) 555-0123", "US", []found{
+            {"(201) 555-0123", "+12015550123", ""},
+        }},
+    }
+
+    for _, tt := range tests {
+        suite.Run(tt.name, func() {
+            spans := suite.extractor.Extract(tt.text, tt.hint)
+            suite.Require().Len(spans, len(tt.want))
+
+            for i, span := range spans {
+                suite.Equal(tt.want[i].raw, span.Raw)
+                suite.Equal(span.Raw, tt.text[span.Start:span.End])
+                suite.Equal(tt.want[i].normalized, span.Phone.Normalized)
+                suite.Equal(tt.want[i].extension, span.Extension)
+                suite.Equal(tt.want[i].extension, span.Phone.Extension)
+                if i > 0 {
+                    suite.Greater(span.Start, spans[i-1].End-1)
+                }
+            }
+        })
+    }
+}
@@ -0,0 +1,258 @@
+// tests/integration/normalizer/normalize.integration.test.go
+package integration
+
+import (
+    "errors"
+    "testing"
+
+    "github.com/stretchr/testify/suite"
+
+    "secure-iran-intel/pkg/normalizer"
+)
+
+// fixedDetector ranks every input the same way
+type fixedDetector struct {
+    candidates []normalizer.CountryCandidate
+}
+
+func (d *fixedDetector) DetectCountry(cleaned string, dctx normalizer.DetectionContext) []normalizer.CountryCandidate {
+    return d.candidates
+}
+
+type NormalizerTestSuite struct {
+    suite.Suite
+    normalizer *normalizer.PhoneNormalizer
+}
+
+func TestNormalizerSuite(t *testing.T) {
+    suite.Run(t, new(NormalizerTestSuite))
+}
+
+func (suite *NormalizerTestSuite) SetupSuite() {
+    suite.normalizer = normalizer.NewPhoneNormalizer("IR")
+}
+
+func (suite *NormalizerTestSuite) TestFailuresCarryStableCodes() {
+    tests := []struct {
+        name   string
+        input  string
+        hint   string
+        strict bool
+        reason error
+        code   string
+    }{
+        {"empty input", "", "", true, normalizer.ErrNotANumber, normalizer.CodeNotANumber},
+        {"letters only", "call me", "", true, normalizer.ErrNotANumber, normalizer.CodeNotANumber},
+        {"unassigned calling code", "+9991234567", "", true, normalizer.ErrInvalidCountryCode, normalizer.CodeInvalidCountryCode},
+        {"too short after calling code", "+98 1", "", true, normalizer.ErrTooShort, normalizer.CodeTooShort},
+        {"too long", "+98912123456789012345", "", true, normalizer.ErrTooLong, normalizer.CodeTooLong},
+        {"fallback for unparseable digits", "+9991234567", "", false, normalizer.ErrFallbackUsed, normalizer.CodeFallbackUsed},
+        {"fallback for overlong digits", "98765432109876543210", "", false, normalizer.ErrFallbackUsed, normalizer.CodeFallbackUsed},
+    }
+
+    for _, tt := range tests {
+        suite.Run(tt.name, func() {
+            phone, err := suite.normalizer.WithStrictMode(tt.strict).NormalizePhone(tt.input, tt.hint)
+            suite.Require().Error(err)
+            suite.ErrorIs(err, tt.reason)
+            suite.Equal(tt.code, normalizer.ErrorCode(err))
+
+            var normErr *normalizer.NormalizationError
+            suite.Require().ErrorAs(err, &normErr)
+            suite.Equal(tt.input, normErr.Input)
+
+            if tt.strict {
+                suite.Nil(phone)
+                return
+            }
+            // Fallback keeps the digits as given and still names the real failure
+            suite.Require().NotNil(phone)
+            suite.False(phone.IsValid)
+            suite.Equal("UNKNOWN", phone.Type)
+            suite.Contains(tt.input, phone.National)
+            suite.NotErrorIs(normErr.Err, normalizer.ErrFallbackUsed)
+        })
+    }
+
+    suite.Equal("", normalizer.ErrorCode(nil))
+    suite.Equal(normalizer.CodeUnknown, normalizer.ErrorCode(errors.New("boom")))
+}
+
+func (suite *NormalizerTestSuite) TestDetectorRanksCandidates() {
+    detector := normalizer.NewPrefixCountryDetector("US", "DE")
+
+    tests := []struct {
+        name    string
+        cleaned string
+        dctx    normalizer.DetectionContext
+        regions []string // Best first
+        reason  string   // Expected among the winner's reasons
+    }{
+        {"calling code", "+4915112345678", normalizer.DetectionContext{ServiceDefault: "IR"}, []string{"DE"}, "calling code +49"},
+        {"international prefix", "004915112345678", normalizer.DetectionContext{ServiceDefault: "IR"}, []string{"DE"}, "international prefix 00 +49"},
+        {"trunk prefix and service default", "09121234567", normalizer.DetectionContext{ServiceDefault: "IR"}, []string{"IR", "DE", "US"}, "trunk prefix 0"},
+        {"valid range beats default", "015112345678", normalizer.DetectionContext{ServiceDefault: "IR"}, []string{"DE", "IR", "US"}, "valid number range for DE"},
+        {"tenant default breaks a tie", "2015550123", normalizer.DetectionContext{ServiceDefault: "IR", TenantDefault: "US"}, []string{"US", "DE", "IR"}, "tenant default region"},
+        {"unassigned calling code", "+9991234567", normalizer.DetectionContext{ServiceDefault: "IR"}, nil, ""},
+    }
+
+    for _, tt := range tests {
+        suite.Run(tt.name, func() {
+            candidates := detector.DetectCountry(tt.cleaned, tt.dctx)
+
+            regions := make([]string, 0, len(candidates))
+            for i, candidate := range candidates {
+                regions = append(regions, candidate.Region)
+                if i > 0 {
+                    suite.GreaterOrEqual(candidates[i-1].Confidence, candidate.Confidence)
+                }
+            }
+            if tt.regions == nil {
+                suite.Empty(candidates)
+                return
+            }
+            suite.Equal(tt.regions, regions)
+            suite.Contains(candidates[0].Reasons, tt.reason)
+        })
+    }
+}
+
+func (suite *NormalizerTestSuite) TestCountryChoice() {
+    tight := &fixedDetector{candidates: []normalizer.CountryCandidate{
+        {Region: "US", Confidence: 0.6},
+        {Region: "DE", Confidence: 0.5},
+    }}
+    decisive := &fixedDetector{candidates: []normalizer.CountryCandidate{
+        {Region: "DE", Confidence: 0.75},
+        {Region: "IR", Confidence: 0.25},
+    }}
+
+    tests := []struct {
+        name     string
+        detector normalizer.CountryDetector
+        input    string
+        hint     string
+        source   string // "" when the input is refused as ambiguous
+        country  string
+    }{
+        {"hint always wins", tight, "2015550123", "us", "hint", "US"},
+        {"clear winner", decisive, "015112345678", "", "detected", "DE"},
+        {"runner-up too close", tight, "2015550123", "", "", ""},
+        {"nothing matched", &fixedDetector{}, "09121234567", "", "default", "IR"},
+        {"tie between extra regions", normalizer.NewPrefixCountryDetector("US", "DE"), "2015550123", "", "", ""},
+    }
+
+    for _, tt := range tests {
+        suite.Run(tt.name, func() {
+            phone, err := suite.normalizer.WithCountryDetector(tt.detector).NormalizePhone(tt.input, tt.hint)
+            if tt.source == "" {
+                suite.ErrorIs(err, normalizer.ErrAmbiguousCountry)
+                suite.Equal(normalizer.CodeAmbiguousCountry, normalizer.ErrorCode(err))
+                var normErr *normalizer.NormalizationError
+                suite.Require().ErrorAs(err, &normErr)
+                suite.GreaterOrEqual(len(normErr.Candidates), 2)
+                return
+            }
+            suite.Require().NoError(err)
+            suite.Equal(tt.source, phone.Detection.Source)
+            suite.Equal(tt.country, phone.Country)
+        })
+    }
+}
+
+func (suite *NormalizerTestSuite) TestDigitScriptsAndExtensions() {
+    tests := []struct {
+        name       string
+        input      string
+        normalized string
+        extension  string
+    }{
+        {"ascii national", "0912 123 4567", "+989121234567", ""},
+        {"persian digits", "۰۹۱۲۱۲۳۴۵۶۷", "+989121234567", ""},
+        {"arabic-indic digits", "٠٩١٢١٢٣٤٥٦٧", "+989121234567", ""},
+        {"full-width digits and plus", "＋９８９１２１２３４５６７", "+989121234567", ""},
+        {"devanagari digits", "०९१२१२३४५६७", "+989121234567", ""},
+        {"00 international prefix", "00989121234567", "+989121234567", ""},
+        {"ext keyword", "+98 21 1234 5678 ext. 42", "+982112345678", "42"},
+        {"x marker", "+98 21 1234 5678 x42", "+982112345678", "42"},
+        {"persian keyword and digits", "۰۲۱۱۲۳۴۵۶۷۸ داخلی ۴۲", "+982112345678", "42"},
+        {"rfc 3966 parameter", "+982112345678;ext=42", "+982112345678", "42"},
+    }
+
+    for _, tt := range tests {
+        suite.Run(tt.name, func() {
+            phone, err := suite.normalizer.NormalizePhone(tt.input, "")
+            suite.Require().NoError(err)
+            suite.Equal(tt.normalized, phone.Normalized)
+            suite.Equal(tt.extension, phone.Extension)
+            suite.Equal(tt.input, phone.Original)
+
+            // Dialable round-trips through the normalizer
+            again, err := suite.normalizer.NormalizePhone(phone.Dialable(), "")
+            suite.Require().NoError(err)
+            suite.Equal(phone.Dialable(), again.Dialable())
+        })
+    }
+}
+
+func (suite *NormalizerTestSuite) TestShortCodes() {
+    tests := []struct {
+        name       string
+        input      string
+        hint       string
+        normalized string
+        numberType string
+    }{
+        {"police", "110", "IR", "110", "EMERGENCY"},
+        {"ambulance without hint", "115", "", "115", "EMERGENCY"},
+        {"service code", "1818", "IR", "1818", "SHORT_CODE"},
+        {"persian digits", "۱۱۰", "IR", "110", "EMERGENCY"},
+    }
+
+    for _, tt := range tests {
+        suite.Run(tt.name, func() {
+            phone, err := suite.normalizer.NormalizePhone(tt.input, tt.hint)
+            suite.Require().NoError(err)
+            suite.Equal(tt.normalized, phone.Normalized)
+            suite.Equal(tt.numberType, phone.Type)
+            suite.Equal("IR", phone.Country)
+            suite.Equal(int32(98), phone.CountryCode)
+            suite.True(phone.IsValid)
+            suite.Empty(phone.International)
+        })
+    }
+
+    // With a calling code the digits are not a short code
+    phone, err := suite.normalizer.NormalizePhone("+110", "")
+    suite.Require().NoError(err)
+    suite.NotEqual("EMERGENCY", phone.Type)
+    suite.False(phone.IsValid)
+}
+
+func (suite *NormalizerTestSuite) TestTimezones() {
+    tests := []struct {
+        input    string
+        primary  string
+        multiple bool
+    }{
+        {"+989121234567", "Asia/Tehran", false},
+        {"+982112345678", "Asia/Tehran", false},
+        {"+12015550123", "America/New_York", false},
+        {"+79161234567", "Europe/Moscow", false},
+        {"+4915112345678", "Europe/Berlin", false},
+        // Calling code without a valid range: every zone of the country
+        {"+110", "America/Adak", true},
+    }
+
+    for _, tt := range tests {
+        suite.Run(tt.input, func() {
+            phone, err := suite.normalizer.NormalizePhone(tt.input, "")
+            suite.Require().NoError(err)
+            suite.Equal(tt.primary, phone.Timezone)
+            suite.Require().NotEmpty(phone.Timezones)
+            suite.Equal(tt.primary, phone.Timezones[0])
+            suite.Equal(tt.multiple, len(phone.Timezones) > 1)
+            suite.NotContains(phone.Timezones, "Etc/Unknown")
+        })
+    }
+}
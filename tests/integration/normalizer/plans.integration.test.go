@@ -0,0 +1,268 @@
+// tests/integration/normalizer/plans.integration.test.go
+package integration
+
+import (
+    "context"
+    "encoding/json"
+    "os"
+    "path/filepath"
+    "testing"
+    "time"
+
+    "github.com/nyaruka/phonenumbers"
+    "github.com/stretchr/testify/suite"
+
+    "secure-iran-intel/pkg/normalizer"
+)
+
+type PlanStoreTestSuite struct {
+    suite.Suite
+}
+
+func TestPlanStoreSuite(t *testing.T) {
+    suite.Run(t, new(PlanStoreTestSuite))
+}
+
+// planTables is a small Iranian plan with nested ranges and per-range zones
+func planTables(version string) *normalizer.PlanTables {
+    return &normalizer.PlanTables{
+        Version: version,
+        Plans: []normalizer.NumberingPlan{{
+            Version:     "IR-" + version,
+            CountryCode: 98,
+            Timezones:   []string{"Asia/Tehran"},
+            Ranges: []normalizer.NumberRange{
+                {Prefix: "912", Type: "MOBILE", Carrier: "MCI", Region: "Tehran"},
+                {Prefix: "9121", Type: "MOBILE", Carrier: "MCI", Region: "Tehran North"},
+                {Prefix: "935", Type: "MOBILE", Carrier: "Irancell"},
+                {Prefix: "21", Type: "FIXED_LINE", Region: "Tehran"},
+                {Prefix: "2199", Type: "FIXED_LINE", Region: "Kish Relay", Timezones: []string{"Asia/Dubai"}},
+            },
+        }},
+    }
+}
+
+func writePlanFile(path string, tables *normalizer.PlanTables, modTime time.Time) error {
+    encoded, err := json.Marshal(tables)
+    if err != nil {
+        return err
+    }
+    if err := os.WriteFile(path, encoded, 0600); err != nil {
+        return err
+    }
+    return os.Chtimes(path, modTime, modTime)
+}
+
+func (suite *PlanStoreTestSuite) store(version string) *normalizer.PlanStore {
+    store, err := normalizer.NewPlanStore(planTables(version))
+    suite.Require().NoError(err)
+    return store
+}
+
+func (suite *PlanStoreTestSuite) TestLookupUsesTheLongestPrefix() {
+    store := suite.store("v1")
+
+    tests := []struct {
+        name        string
+        countryCode int32
+        national    string
+        prefix      string // "" when nothing matches
+    }{
+        {"nested range wins", 98, "9121234567", "9121"},
+        {"enclosing range", 98, "9129876543", "912"},
+        {"sibling range", 98, "9351234567", "935"},
+        {"fixed line area code", 98, "2188776655", "21"},
+        {"nested fixed line range", 98, "2199001122", "2199"},
+        {"prefix of a prefix is no match", 98, "91", ""},
+        {"unknown range", 98, "9131234567", ""},
+        {"country without a plan", 1, "2015550123", ""},
+        {"empty number", 98, "", ""},
+    }
+
+    for _, tt := range tests {
+        suite.Run(tt.name, func() {
+            r, version, ok := store.Lookup(tt.countryCode, tt.national)
+            if tt.prefix == "" {
+                suite.False(ok)
+                suite.Nil(r)
+                return
+            }
+            suite.Require().True(ok)
+            suite.Equal(tt.prefix, r.Prefix)
+            suite.Equal("IR-v1", version)
+        })
+    }
+}
+
+func (suite *PlanStoreTestSuite) TestTimezonesPreferTheMostSpecificRange() {
+    store := suite.store("v1")
+
+    tests := []struct {
+        name        string
+        countryCode int32
+        national    string
+        zones       []string
+        ok          bool
+    }{
+        {"range with its own zones", 98, "2199001122", []string{"Asia/Dubai"}, true},
+        {"plan default", 98, "2188776655", []string{"Asia/Tehran"}, true},
+        {"plan default outside every range", 98, "4412345678", []string{"Asia/Tehran"}, true},
+        {"country without a plan", 1, "2015550123", nil, false},
+    }
+
+    for _, tt := range tests {
+        suite.Run(tt.name, func() {
+            zones, _, ok := store.Timezones(tt.countryCode, tt.national)
+            suite.Equal(tt.ok, ok)
+            suite.Equal(tt.zones, zones)
+        })
+    }
+}
+
+func (suite *PlanStoreTestSuite) TestInvalidTablesAreRejected() {
+    tests := []struct {
+        name   string
+        mutate func(t *normalizer.PlanTables)
+    }{
+        {"missing version", func(t *normalizer.PlanTables) { t.Plans[0].Version = "" }},
+        {"unknown country code", func(t *normalizer.PlanTables) { t.Plans[0].CountryCode = 999 }},
+        {"country code defined twice", func(t *normalizer.PlanTables) { t.Plans = append(t.Plans, t.Plans[0]) }},
+        {"empty prefix", func(t *normalizer.PlanTables) { t.Plans[0].Ranges[0].Prefix = "" }},
+        {"non-digit prefix", func(t *normalizer.PlanTables) { t.Plans[0].Ranges[0].Prefix = "91a" }},
+        {"prefix with a calling code", func(t *normalizer.PlanTables) { t.Plans[0].Ranges[0].Prefix = "+98912" }},
+        {"duplicate prefix", func(t *normalizer.PlanTables) {
+            t.Plans[0].Ranges = append(t.Plans[0].Ranges, normalizer.NumberRange{Prefix: "935", Type: "MVNO"})
+        }},
+        {"nested range repeating its parent", func(t *normalizer.PlanTables) {
+            t.Plans[0].Ranges[1].Region = "Tehran"
+        }},
+        {"blank plan timezone", func(t *normalizer.PlanTables) { t.Plans[0].Timezones = []string{""} }},
+        {"malformed range timezone", func(t *normalizer.PlanTables) {
+            t.Plans[0].Ranges[4].Timezones = []string{"Asia/ Dubai"}
+        }},
+    }
+
+    for _, tt := range tests {
+        suite.Run(tt.name, func() {
+            tables := planTables("bad")
+            tt.mutate(tables)
+
+            _, err := normalizer.NewPlanStore(tables)
+            suite.Error(err)
+
+            // A rejected replacement leaves the active index alone
+            store := suite.store("v1")
+            suite.Error(store.Replace(tables))
+            suite.Equal("v1", store.Version())
+            _, version, ok := store.Lookup(98, "9121234567")
+            suite.True(ok)
+            suite.Equal("IR-v1", version)
+        })
+    }
+}
+
+func (suite *PlanStoreTestSuite) TestLoadFileReplacesRatherThanMerges() {
+    dir := suite.T().TempDir()
+    store := suite.store("v1")
+
+    tables := planTables("v2")
+    tables.Plans[0].Ranges = tables.Plans[0].Ranges[2:3] // Only 935
+    path := filepath.Join(dir, "plans.json")
+    suite.Require().NoError(writePlanFile(path, tables, time.Now()))
+
+    suite.Require().NoError(store.LoadFile(path))
+    suite.Equal("v2", store.Version())
+    _, _, ok := store.Lookup(98, "9121234567")
+    suite.False(ok)
+
+    tests := []struct {
+        name     string
+        contents string
+    }{
+        {"malformed json", `{"version": "v3", "plans": [`},
+        {"invalid plan", `{"version": "v3", "plans": [{"country_code": 98, "ranges": []}]}`},
+    }
+    for _, tt := range tests {
+        suite.Run(tt.name, func() {
+            bad := filepath.Join(dir, "bad.json")
+            suite.Require().NoError(os.WriteFile(bad, []byte(tt.contents), 0600))
+            suite.Error(store.LoadFile(bad))
+            suite.Equal("v2", store.Version())
+        })
+    }
+
+    suite.Error(store.LoadFile(filepath.Join(dir, "missing.json")))
+    suite.Equal("v2", store.Version())
+}
+
+func (suite *PlanStoreTestSuite) TestWatchReloadsChangedFiles() {
+    path := filepath.Join(suite.T().TempDir(), "plans.json")
+    modTime := time.Now().Add(-time.Hour)
+    suite.Require().NoError(writePlanFile(path, planTables("v1"), modTime))
+
+    store := suite.store("v1")
+    ctx, cancel := context.WithCancel(context.Background())
+    defer cancel()
+    go store.Watch(ctx, path, 10*time.Millisecond)
+
+    // Let the watcher record the current modification time
+    time.Sleep(50 * time.Millisecond)
+
+    modTime = modTime.Add(time.Minute)
+    suite.Require().NoError(writePlanFile(path, planTables("v2"), modTime))
+    suite.Eventually(func() bool { return store.Version() == "v2" }, 2*time.Second, 10*time.Millisecond)
+
+    // A broken edit is logged and the last good plans stay active
+    modTime = modTime.Add(time.Minute)
+    suite.Require().NoError(os.WriteFile(path, []byte("{"), 0600))
+    suite.Require().NoError(os.Chtimes(path, modTime, modTime))
+    time.Sleep(100 * time.Millisecond)
+    suite.Equal("v2", store.Version())
+
+    modTime = modTime.Add(time.Minute)
+    suite.Require().NoError(writePlanFile(path, planTables("v3"), modTime))
+    suite.Eventually(func() bool { return store.Version() == "v3" }, 2*time.Second, 10*time.Millisecond)
+}
+
+func (suite *PlanStoreTestSuite) TestDetectorsAnswerFromTheirPlans() {
+    store := suite.store("v1")
+    carriers := normalizer.NewCarrierDetectorWithPlans(store)
+    locator := normalizer.NewGeoLocatorWithPlans(store)
+
+    tests := []struct {
+        number    string
+        carrier   string
+        region    string
+        timezones []string
+        version   string
+    }{
+        {"+989121234567", "MCI", "Tehran North", []string{"Asia/Tehran"}, "IR-v1"},
+        {"+989351234567", "Irancell", "IR", []string{"Asia/Tehran"}, "IR-v1"},
+        {"+982199001122", "Unknown Iranian Carrier", "Kish Relay", []string{"Asia/Dubai"}, "IR-v1"},
+        {"+989131234567", "Unknown Iranian Carrier", "IR", []string{"Asia/Tehran"}, "IR-v1"},
+        // No plan for +1: libphonenumber metadata answers
+        {"+12015550123", "Unknown", "US", []string{"America/New_York"}, ""},
+    }
+
+    for _, tt := range tests {
+        suite.Run(tt.number, func() {
+            num, err := phonenumbers.Parse(tt.number, "")
+            suite.Require().NoError(err)
+
+            carrier := carriers.GetCarrier(num)
+            suite.Equal(tt.carrier, carrier.Value)
+            suite.Equal(tt.version, carrier.Version)
+            suite.Equal(tt.region, locator.GetRegion(num).Value)
+            suite.Equal(tt.timezones, locator.GetTimezones(num))
+            suite.Equal(tt.timezones[0], locator.GetTimezone(num))
+        })
+    }
+}
+
+func (suite *PlanStoreTestSuite) TestEmbeddedPlansAreServedByDefault() {
+    r, version, ok := normalizer.DefaultPlanStore().Lookup(98, "9121234567")
+    suite.Require().True(ok)
+    suite.Equal("MOBILE", r.Type)
+    suite.Contains(r.Carrier, "MCI")
+    suite.NotEmpty(version)
+}
@@ -0,0 +1,169 @@
+// tests/integration/reports/redaction.integration.test.go
+package integration
+
+import (
+    "encoding/json"
+    "os"
+    "path/filepath"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/suite"
+
+    "secure-iran-intel/pkg/purpose"
+    "secure-iran-intel/pkg/redaction"
+)
+
+// report has the shape of a rendered intelligence report
+type report struct {
+    PhoneNumber    string                 `json:"phone_number"`
+    LegalBasis     string                 `json:"legal_basis"`
+    GeneratedAt    time.Time              `json:"generated_at"`
+    EmailDiscovery *emailSection          `json:"email_discovery"`
+    SocialGraph    map[string]interface{} `json:"social_graph"`
+    Behavioral     map[string]interface{} `json:"behavioral_analysis"`
+    Profile        *profile               `json:"profile"`
+    RawData        map[string]interface{} `json:"raw_data"`
+}
+
+type emailSection struct {
+    TotalFound int      `json:"total_found"`
+    Emails     []string `json:"emails"`
+}
+
+type profile struct {
+    Address     string    `json:"address"`
+    DateOfBirth time.Time `json:"date_of_birth"`
+    Username    string    `json:"username"`
+}
+
+type RedactionTestSuite struct {
+    suite.Suite
+    policies *redaction.Set
+}
+
+func TestRedactionSuite(t *testing.T) {
+    suite.Run(t, new(RedactionTestSuite))
+}
+
+func (suite *RedactionTestSuite) SetupSuite() {
+    suite.policies = redaction.Default()
+}
+
+func (suite *RedactionTestSuite) report(basis string) *report {
+    return &report{
+        PhoneNumber:    "+989121234567",
+        LegalBasis:     basis,
+        GeneratedAt:    time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+        EmailDiscovery: &emailSection{TotalFound: 1, Emails: []string{"ali.rezaei@example.ir"}},
+        SocialGraph:    map[string]interface{}{"connections": 42.0},
+        Behavioral:     map[string]interface{}{"activity": "HIGH"},
+        Profile: &profile{
+            Address:     "12 Vali-e Asr St, Tajrish, Tehran",
+            DateOfBirth: time.Date(1990, 7, 14, 0, 0, 0, 0, time.UTC),
+            Username:    "ali_rezaei_90",
+        },
+        RawData: map[string]interface{}{"source": "breach-db"},
+    }
+}
+
+func (suite *RedactionTestSuite) render(basis, role string) (*report, *redaction.Manifest) {
+    rendered := &report{}
+    manifest, err := suite.policies.Render(suite.report(basis), rendered, basis, role)
+    suite.Require().NoError(err)
+    return rendered, manifest
+}
+
+func withheldPaths(m *redaction.Manifest) map[string]string {
+    paths := map[string]string{}
+    for _, w := range m.Withheld {
+        paths[w.Path] = w.Policy + ":" + w.Action
+    }
+    return paths
+}
+
+func (suite *RedactionTestSuite) TestAdministratorsUnderCourtOrderSeeEverything() {
+    for _, role := range []string{"super_admin", "admin"} {
+        suite.Run(role, func() {
+            rendered, manifest := suite.render(purpose.BasisCourtOrder, role)
+
+            suite.Equal(suite.report(purpose.BasisCourtOrder), rendered)
+            suite.Empty(manifest.Policies)
+            suite.Empty(manifest.Withheld)
+        })
+    }
+}
+
+func (suite *RedactionTestSuite) TestConsentJobsLeaveOutProfiling() {
+    rendered, manifest := suite.render(purpose.BasisConsent, "user")
+
+    suite.Nil(rendered.SocialGraph)
+    suite.Nil(rendered.Behavioral)
+    suite.Nil(rendered.RawData)
+    suite.Equal("Tehran", rendered.Profile.Address)
+    suite.Equal(time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC), rendered.Profile.DateOfBirth)
+    // Users still see identifiers
+    suite.Equal("+989121234567", rendered.PhoneNumber)
+    suite.Equal([]string{"ali.rezaei@example.ir"}, rendered.EmailDiscovery.Emails)
+
+    paths := withheldPaths(manifest)
+    suite.Equal("consent-and-legitimate-interest:drop", paths["social_graph"])
+    suite.Equal("consent-and-legitimate-interest:coarsen", paths["**.address"])
+    suite.Equal("raw-data-administrators-only:drop", paths["raw_data"])
+    for _, w := range manifest.Withheld {
+        suite.NotEmpty(w.Reason)
+    }
+}
+
+func (suite *RedactionTestSuite) TestViewersSeeFindingsNotIdentifiers() {
+    rendered, manifest := suite.render(purpose.BasisCriminalInvestigation, "viewer")
+
+    suite.Equal("•••••••••4567", rendered.PhoneNumber)
+    suite.Equal([]string{"@example.ir"}, rendered.EmailDiscovery.Emails)
+    suite.Equal(1, rendered.EmailDiscovery.TotalFound)
+    suite.NotContains(rendered.Profile.Username, "ali")
+    suite.Equal("Tehran", rendered.Profile.Address)
+    suite.NotNil(rendered.SocialGraph)
+    suite.Contains(manifest.Policies, "viewers")
+}
+
+// Custom roles are not exempt from anything until a policy says so
+func (suite *RedactionTestSuite) TestUnknownRolesAndBasesGetTheStrictestView() {
+    rendered, manifest := suite.render("", "auditor")
+
+    suite.Equal(redaction.UnknownBasis, manifest.LegalBasis)
+    suite.Nil(rendered.EmailDiscovery)
+    suite.Nil(rendered.SocialGraph)
+    suite.Nil(rendered.RawData)
+    suite.NotEqual("+989121234567", rendered.PhoneNumber)
+    suite.ElementsMatch([]string{"raw-data-administrators-only", "no-recorded-legal-basis", "viewers"}, manifest.Policies)
+}
+
+func (suite *RedactionTestSuite) TestPoliciesLoadFromFile() {
+    policies := []redaction.Policy{{
+        Name:       "legal-obligation",
+        LegalBases: []string{purpose.BasisLegalObligation},
+        Reason:     "Only the count is needed",
+        Rules:      []redaction.Rule{{Path: "email_discovery.emails.*", Action: redaction.ActionDrop}},
+    }}
+    encoded, err := json.Marshal(policies)
+    suite.Require().NoError(err)
+    path := filepath.Join(suite.T().TempDir(), "redaction.json")
+    suite.Require().NoError(os.WriteFile(path, encoded, 0600))
+
+    set, err := redaction.LoadFile(path)
+    suite.Require().NoError(err)
+    rendered := &report{}
+    manifest, err := set.Render(suite.report(purpose.BasisLegalObligation), rendered, purpose.BasisLegalObligation, "admin")
+    suite.Require().NoError(err)
+    suite.Equal([]string{""}, rendered.EmailDiscovery.Emails)
+    suite.Equal(1, manifest.Withheld[0].Count)
+
+    // Rules naming unknown coarseners are refused up front
+    _, err = redaction.NewSet(redaction.Policy{
+        Name:   "bad",
+        Reason: "bad",
+        Rules:  []redaction.Rule{{Path: "phone_number", Action: redaction.ActionCoarsen, Coarsener: "nearest_city"}},
+    })
+    suite.Error(err)
+}